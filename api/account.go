@@ -5,13 +5,40 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"time"
 
 	db "github.com/crackz/simple-bank/db/sqlc"
+	"github.com/crackz/simple-bank/money"
+	"github.com/crackz/simple-bank/pagination"
 	"github.com/crackz/simple-bank/token"
 	"github.com/gin-gonic/gin"
 	"github.com/lib/pq"
 )
 
+type accountResponse struct {
+	ID               int64       `json:"id"`
+	Owner            string      `json:"owner"`
+	Balance          money.Money `json:"balance"`
+	HeldBalance      money.Money `json:"heldBalance"`
+	AvailableBalance money.Money `json:"availableBalance"`
+	Currency         string      `json:"currency"`
+	Status           string      `json:"status"`
+	CreatedAt        time.Time   `json:"createdAt"`
+}
+
+func newAccountResponse(account db.Account) accountResponse {
+	return accountResponse{
+		ID:               account.ID,
+		Owner:            account.Owner,
+		Balance:          money.New(account.Balance, account.Currency),
+		HeldBalance:      money.New(account.HeldBalance, account.Currency),
+		AvailableBalance: money.New(account.AvailableBalance, account.Currency),
+		Currency:         account.Currency,
+		Status:           account.Status,
+		CreatedAt:        account.CreatedAt,
+	}
+}
+
 type createAccountDto struct {
 	Currency string `json:"currency" binding:"required,currency"`
 }
@@ -31,8 +58,21 @@ func (server *Server) createAccount(ctx *gin.Context) {
 		Balance:  0,
 	}
 
-	account, err := server.store.CreateAccount(ctx, arg)
+	idempotency, err := idempotencyParams(ctx, authPayload.Username, createDto)
 	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	account, err := server.store.CreateAccountTx(ctx, db.CreateAccountTxParams{
+		CreateAccountParams: arg,
+		Idempotency:         idempotency,
+	})
+	if err != nil {
+		if errors.Is(err, db.ErrIdempotencyKeyConflict) {
+			ctx.JSON(http.StatusConflict, errorResponse(err))
+			return
+		}
 		if pgErr, ok := err.(*pq.Error); ok {
 			switch pgErr.Code.Name() {
 			case "foreign_key_violation":
@@ -48,7 +88,9 @@ func (server *Server) createAccount(ctx *gin.Context) {
 		return
 	}
 
-	ctx.JSON(http.StatusCreated, account)
+	setAuditTarget(ctx, auditTargetAccount, account.ID)
+	setAuditAfter(ctx, account)
+	ctx.JSON(http.StatusCreated, newAccountResponse(account))
 }
 
 type getAccountParam struct {
@@ -63,9 +105,19 @@ func (server *Server) getAccount(ctx *gin.Context) {
 		return
 	}
 
-	account, err := server.checkAccountExist(ctx, params.AccountID)
+	account, ok := server.checkAccountOwner(ctx, params.AccountID)
+	if !ok {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newAccountResponse(account))
+}
+
+// checkAccountOwner loads an account and checks that it belongs to the current
+// user. The error response is already written when ok is false.
+func (server *Server) checkAccountOwner(ctx *gin.Context, accountID int64) (account db.Account, ok bool) {
+	account, err := server.checkAccountExist(ctx, accountID)
 	if err != nil {
-		ctx.JSON(http.StatusNotFound, errorResponse(err))
 		return
 	}
 
@@ -75,61 +127,72 @@ func (server *Server) getAccount(ctx *gin.Context) {
 		return
 	}
 
-	ctx.JSON(http.StatusOK, account)
-}
-
-type getAccountsQuery struct {
-	Page  int32 `form:"page" binding:"min=1"`
-	Limit int32 `form:"limit" binding:"min=1,max=100"`
+	return account, true
 }
 
+// getAccounts lists the current user's accounts, oldest first, a page at a
+// time.
 func (server *Server) getAccounts(ctx *gin.Context) {
-	var query getAccountsQuery
+	var query pageQuery
 
 	if err := ctx.ShouldBindQuery(&query); err != nil {
 		ctx.JSON(http.StatusBadRequest, errorResponse(err))
 		return
 	}
 
-	if query.Page == 0 {
-		query.Page = 1
-	}
-	if query.Limit == 0 {
-		query.Limit = 1
-	}
-
 	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	scope := "accounts:" + authPayload.Username
 
-	arg := db.ListAccountsParams{
-		Owner:  authPayload.Username,
-		Limit:  query.Limit,
-		Offset: (query.Page - 1) * query.Limit,
+	cursor, err := server.cursors.Decode(scope, query.Cursor)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	arg := db.ListAccountsAfterParams{
+		Owner:          authPayload.Username,
+		AfterCreatedAt: cursor.CreatedAt,
+		AfterID:        cursor.ID,
+		LimitCount:     query.limit() + 1,
 	}
 
-	accounts, err := server.store.ListAccounts(ctx, arg)
+	accounts, err := server.store.ListAccountsAfter(ctx, arg)
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
 
+	response, err := newPageResponse(server, scope, accounts, query.limit(), accountCursor, newAccountResponse)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
 		return
 	}
 
-	ctx.JSON(http.StatusOK, accounts)
+	ctx.JSON(http.StatusOK, response)
 }
 
-type updateAccountParam struct {
-	AccountID int64 `uri:"accountID" binding:"required,min=1"`
+func accountCursor(account db.Account) pagination.Cursor {
+	return pagination.Cursor{CreatedAt: account.CreatedAt, ID: account.ID}
+}
+
+type closeAccountResponse struct {
+	Account accountResponse     `json:"account"`
+	Sweep   *transferTxResponse `json:"sweep,omitempty"`
 }
 
-type updateAccountDto struct {
-	Balance int64 `json:"balance" binding:"required"`
+type closeAccountDto struct {
+	SweepToAccountID int64 `json:"sweepToAccountID" binding:"omitempty,min=1"`
 }
 
-func (server *Server) updateAccount(ctx *gin.Context) {
-	var dto updateAccountDto
-	var params updateAccountParam
+// closeAccount closes one of the current user's accounts. A remaining balance
+// has to be swept to another of their accounts, converted at the current rate
+// if that account holds another currency.
+func (server *Server) closeAccount(ctx *gin.Context) {
+	var params getAccountParam
+	var dto closeAccountDto
 
 	if err := ctx.ShouldBindUri(&params); err != nil {
-		ctx.JSON(http.StatusNotFound, errorResponse(err))
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
 		return
 	}
 	if err := ctx.ShouldBindJSON(&dto); err != nil {
@@ -137,59 +200,77 @@ func (server *Server) updateAccount(ctx *gin.Context) {
 		return
 	}
 
-	foundAccount, err := server.checkAccountExist(ctx, params.AccountID)
-	if err != nil {
+	setAuditTarget(ctx, auditTargetAccount, params.AccountID)
+
+	account, ok := server.checkAccountOwner(ctx, params.AccountID)
+	if !ok {
 		return
 	}
+	setAuditBefore(ctx, account)
 
-	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
-	if authPayload.Username != foundAccount.Owner {
-		ctx.JSON(http.StatusForbidden, errorResponse(fmt.Errorf("account id: %v doesn't belong to current user", params.AccountID)))
-		return
+	arg := db.CloseAccountTxParams{
+		AccountID:        account.ID,
+		SweepToAccountID: dto.SweepToAccountID,
 	}
 
-	arg := db.UpdateAccountParams{
-		ID:      foundAccount.ID,
-		Balance: dto.Balance,
+	if dto.SweepToAccountID != 0 {
+		sweepAccount, ok := server.checkAccountOwner(ctx, dto.SweepToAccountID)
+		if !ok {
+			return
+		}
+
+		if sweepAccount.Currency != account.Currency {
+			rate, err := server.rateProvider.Rate(ctx, account.Currency, sweepAccount.Currency)
+			if err != nil {
+				ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+				return
+			}
+			arg.SweepRate = rate.String()
+		}
 	}
 
-	updatedAccount, err := server.store.UpdateAccount(ctx, arg)
+	result, err := server.store.CloseAccountTx(ctx, arg)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		ctx.JSON(transferErrorStatus(err), errorResponse(err))
 		return
 	}
+	setAuditAfter(ctx, result)
 
-	ctx.JSON(http.StatusCreated, updatedAccount)
+	response := closeAccountResponse{Account: newAccountResponse(result.Account)}
+	if result.Sweep != nil {
+		sweep := newTransferTxResponse(*result.Sweep)
+		response.Sweep = &sweep
+	}
+
+	ctx.JSON(http.StatusOK, response)
 }
 
-type deleteAccountParam struct {
-	AccountID int64 `uri:"accountID" binding:"required,min=1"`
+func (server *Server) freezeAccount(ctx *gin.Context) {
+	server.updateAccountStatus(ctx, db.AccountFrozen)
+}
+
+func (server *Server) unfreezeAccount(ctx *gin.Context) {
+	server.updateAccountStatus(ctx, db.AccountActive)
 }
 
-func (server *Server) deleteAccount(ctx *gin.Context) {
-	var params deleteAccountParam
+func (server *Server) updateAccountStatus(ctx *gin.Context, status string) {
+	var params getAccountParam
+
 	if err := ctx.ShouldBindUri(&params); err != nil {
-		ctx.JSON(http.StatusNotFound, errorResponse(err))
-		return
-	}
-	foundAccount, err := server.checkAccountExist(ctx, params.AccountID)
-	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
 		return
 	}
 
-	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
-	if authPayload.Username != foundAccount.Owner {
-		ctx.JSON(http.StatusForbidden, errorResponse(fmt.Errorf("account id: %v doesn't belong to current user", params.AccountID)))
-		return
-	}
+	setAuditTarget(ctx, auditTargetAccount, params.AccountID)
 
-	err = server.store.DeleteAccount(ctx, foundAccount.ID)
+	account, err := server.store.UpdateAccountStatusTx(ctx, params.AccountID, status)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		ctx.JSON(transferErrorStatus(err), errorResponse(err))
 		return
 	}
+	setAuditAfter(ctx, account)
 
-	ctx.JSON(http.StatusCreated, foundAccount)
+	ctx.JSON(http.StatusOK, newAccountResponse(account))
 }
 
 func (server *Server) checkAccountExist(ctx *gin.Context, id int64) (account db.Account, err error) {
@@ -0,0 +1,179 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/crackz/simple-bank/audit"
+	db "github.com/crackz/simple-bank/db/sqlc"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	auditTargetAccount      = "account"
+	auditTargetUser         = "user"
+	auditTargetInterestPlan = "interest_plan"
+	auditTargetFeeSchedule  = "fee_schedule"
+	auditTargetRiskDecision = "risk_decision"
+	auditTargetCurrency     = "currency"
+)
+
+// auditMiddleware records every mutating call, successful or not, together with
+// the caller, route, response status and client IP. It wraps the whole chain,
+// so calls rejected by the auth middleware are recorded as well. The entry is
+// carried by the request's context, so the store writes it in the first
+// transaction the call makes. Handlers fill it in through setAuditTarget,
+// setAuditBefore and setAuditAfter, and the auth middleware names the caller.
+func (server *Server) auditMiddleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		switch ctx.Request.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			ctx.Next()
+			return
+		}
+
+		route := ctx.FullPath()
+		if route == "" {
+			route = ctx.Request.URL.Path
+		}
+
+		call := audit.Call{
+			Method:   ctx.Request.Method,
+			Route:    route,
+			ClientIP: ctx.ClientIP(),
+		}
+		entry := audit.NewEntry(call)
+		ctx.Request = ctx.Request.WithContext(audit.NewContext(ctx.Request.Context(), entry))
+		ctx.Next()
+
+		call.StatusCode = int32(ctx.Writer.Status())
+		audit.Record(server.auditRecorder, entry, call)
+	}
+}
+
+// getAuditEntry returns the entry of the call. The server's engine falls back
+// to the request's context, which the audit middleware put it in.
+func getAuditEntry(ctx *gin.Context) *audit.Entry {
+	return audit.FromContext(ctx)
+}
+
+// setAuditActor names the caller: the token's user, or for unauthenticated
+// calls e.g. the user logging in.
+func setAuditActor(ctx *gin.Context, username string) {
+	getAuditEntry(ctx).SetActor(username)
+}
+
+func setAuditTarget(ctx *gin.Context, targetType string, targetID interface{}) {
+	getAuditEntry(ctx).SetTarget(targetType, targetID)
+}
+
+func setAuditBefore(ctx *gin.Context, snapshot interface{}) {
+	getAuditEntry(ctx).SetBefore(snapshot)
+}
+
+func setAuditAfter(ctx *gin.Context, snapshot interface{}) {
+	getAuditEntry(ctx).SetAfter(snapshot)
+}
+
+type auditLogResponse struct {
+	ID int64 `json:"id"`
+	// Actor is only set when the caller is known.
+	Actor  *string `json:"actor,omitempty"`
+	Method string  `json:"method"`
+	Route  string  `json:"route"`
+	// StatusCode is only set once the call was answered.
+	StatusCode *int32 `json:"statusCode,omitempty"`
+	// TargetType and TargetID are only set for calls on a single resource.
+	TargetType *string `json:"targetType,omitempty"`
+	TargetID   *string `json:"targetID,omitempty"`
+	// Before and After are only set when the call took a snapshot.
+	Before    json.RawMessage `json:"before,omitempty"`
+	After     json.RawMessage `json:"after,omitempty"`
+	ClientIP  string          `json:"clientIP"`
+	CreatedAt time.Time       `json:"createdAt"`
+}
+
+func newAuditLogResponse(entry db.AuditLog) auditLogResponse {
+	return auditLogResponse{
+		ID:         entry.ID,
+		Actor:      stringPointer(entry.Actor),
+		Method:     entry.Method,
+		Route:      entry.Route,
+		StatusCode: int32Pointer(entry.StatusCode),
+		TargetType: stringPointer(entry.TargetType),
+		TargetID:   stringPointer(entry.TargetID),
+		Before:     auditSnapshot(entry.Before),
+		After:      auditSnapshot(entry.After),
+		ClientIP:   entry.ClientIp,
+		CreatedAt:  entry.CreatedAt,
+	}
+}
+
+// auditSnapshot drops a snapshot that was stored as JSON null, so it's left
+// out of the response like the other values that aren't set.
+func auditSnapshot(snapshot json.RawMessage) json.RawMessage {
+	if len(snapshot) == 0 || string(snapshot) == "null" {
+		return nil
+	}
+
+	return snapshot
+}
+
+type getAuditLogQuery struct {
+	Actor      string     `form:"actor"`
+	TargetType string     `form:"targetType"`
+	TargetID   string     `form:"targetID"`
+	Route      string     `form:"route"`
+	From       *time.Time `form:"from"`
+	To         *time.Time `form:"to"`
+	Page       int32      `form:"page" binding:"min=1"`
+	Limit      int32      `form:"limit" binding:"min=1,max=100"`
+}
+
+// getAuditLog lists audit log entries, newest first, narrowed down by any of
+// the given filters.
+func (server *Server) getAuditLog(ctx *gin.Context) {
+	var query getAuditLogQuery
+
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if query.Page == 0 {
+		query.Page = 1
+	}
+	if query.Limit == 0 {
+		query.Limit = 10
+	}
+
+	arg := db.ListAuditLogsParams{
+		Actor:       sql.NullString{String: query.Actor, Valid: query.Actor != ""},
+		TargetType:  sql.NullString{String: query.TargetType, Valid: query.TargetType != ""},
+		TargetID:    sql.NullString{String: query.TargetID, Valid: query.TargetID != ""},
+		Route:       sql.NullString{String: query.Route, Valid: query.Route != ""},
+		LimitCount:  query.Limit,
+		OffsetCount: (query.Page - 1) * query.Limit,
+	}
+	if query.From != nil {
+		arg.FromTime = sql.NullTime{Time: *query.From, Valid: true}
+	}
+	if query.To != nil {
+		arg.ToTime = sql.NullTime{Time: *query.To, Valid: true}
+	}
+
+	entries, err := server.store.ListAuditLogs(ctx, arg)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	response := make([]auditLogResponse, 0, len(entries))
+	for _, entry := range entries {
+		response = append(response, newAuditLogResponse(entry))
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
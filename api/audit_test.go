@@ -0,0 +1,272 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	mockdb "github.com/crackz/simple-bank/db/mock"
+	db "github.com/crackz/simple-bank/db/sqlc"
+	"github.com/crackz/simple-bank/token"
+	"github.com/crackz/simple-bank/util"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuditMiddleware(t *testing.T) {
+	user, password := randomInMemoryUser(t)
+	account := randomInMemoryAccount(user.Username)
+	closedAccount := account
+	closedAccount.Balance = 0
+	closedAccount.Status = db.AccountClosed
+
+	testCases := []struct {
+		name       string
+		method     string
+		url        string
+		body       gin.H
+		setupAuth  func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStubs func(store *mockdb.MockStore)
+	}{
+		{
+			name:   "Close Account",
+			method: http.MethodPost,
+			url:    fmt.Sprintf("/accounts/%d/close", account.ID),
+			body:   gin.H{},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorizationHeader(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().
+					CloseAccountTx(gomock.Any(), gomock.Eq(db.CloseAccountTxParams{AccountID: account.ID})).
+					Times(1).
+					Return(db.CloseAccountTxResult{Account: closedAccount}, nil)
+				store.EXPECT().
+					CreateAuditLog(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.CreateAuditLogParams) (db.AuditLog, error) {
+						require.Equal(t, sql.NullString{String: user.Username, Valid: true}, arg.Actor)
+						require.Equal(t, http.MethodPost, arg.Method)
+						require.Equal(t, "/accounts/:accountID/close", arg.Route)
+						require.Equal(t, sql.NullInt32{Int32: http.StatusOK, Valid: true}, arg.StatusCode)
+						require.Equal(t, sql.NullString{String: auditTargetAccount, Valid: true}, arg.TargetType)
+						require.Equal(t, sql.NullString{String: fmt.Sprint(account.ID), Valid: true}, arg.TargetID)
+						require.Equal(t, "203.0.113.7", arg.ClientIp)
+
+						var before db.Account
+						var after db.CloseAccountTxResult
+						require.NoError(t, json.Unmarshal(arg.Before, &before))
+						require.NoError(t, json.Unmarshal(arg.After, &after))
+						require.Equal(t, account.Status, before.Status)
+						require.Equal(t, closedAccount.Status, after.Account.Status)
+						return db.AuditLog{}, nil
+					})
+			},
+		},
+		{
+			name:   "Failed Login",
+			method: http.MethodPost,
+			url:    "/users/login",
+			body:   gin.H{"username": user.Username, "password": password + "x"},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(user, nil)
+				store.EXPECT().
+					CreateAuditLog(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.CreateAuditLogParams) (db.AuditLog, error) {
+						require.Equal(t, sql.NullString{String: user.Username, Valid: true}, arg.Actor)
+						require.Equal(t, "/users/login", arg.Route)
+						require.Equal(t, sql.NullInt32{Int32: http.StatusUnauthorized, Valid: true}, arg.StatusCode)
+						require.Equal(t, sql.NullString{String: user.Username, Valid: true}, arg.TargetID)
+						require.JSONEq(t, "null", string(arg.After))
+						require.NotContains(t, string(arg.Before)+string(arg.After), user.HashedPassword)
+						return db.AuditLog{}, nil
+					})
+			},
+		},
+		{
+			name:   "UnAuthorized",
+			method: http.MethodPost,
+			url:    "/transfers",
+			body:   gin.H{},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateAuditLog(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.CreateAuditLogParams) (db.AuditLog, error) {
+						require.False(t, arg.Actor.Valid)
+						require.Equal(t, "/transfers", arg.Route)
+						require.Equal(t, sql.NullInt32{Int32: http.StatusUnauthorized, Valid: true}, arg.StatusCode)
+						require.False(t, arg.TargetType.Valid)
+						return db.AuditLog{}, nil
+					})
+			},
+		},
+		{
+			name:   "Reads Aren't Audited",
+			method: http.MethodGet,
+			url:    fmt.Sprintf("/accounts/%d", account.ID),
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorizationHeader(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().CreateAuditLog(gomock.Any(), gomock.Any()).Times(0)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			server.auditRecorder = store
+			recorder := httptest.NewRecorder()
+
+			var body bytes.Buffer
+			if tc.body != nil {
+				require.NoError(t, json.NewEncoder(&body).Encode(tc.body))
+			}
+
+			request, err := http.NewRequest(tc.method, tc.url, &body)
+			require.NoError(t, err)
+			request.RemoteAddr = "203.0.113.7:51234"
+
+			tc.setupAuth(t, request, server.tokenMaker)
+			server.router.ServeHTTP(recorder, request)
+		})
+	}
+}
+
+func TestGetAuditLogAPI(t *testing.T) {
+	admin, _ := randomInMemoryUser(t)
+	admin.Role = util.AdminRole
+
+	customer, _ := randomInMemoryUser(t)
+	customer.Role = util.CustomerRole
+
+	from := time.Date(2023, time.January, 1, 0, 0, 0, 0, time.UTC)
+	entries := []db.AuditLog{{
+		ID:         1,
+		Actor:      sql.NullString{String: customer.Username, Valid: true},
+		Method:     http.MethodPost,
+		Route:      "/transfers",
+		StatusCode: sql.NullInt32{Int32: http.StatusCreated, Valid: true},
+		Before:     []byte("null"),
+		After:      []byte(`{"id":7}`),
+		ClientIp:   "203.0.113.7",
+	}}
+
+	testCases := []struct {
+		name          string
+		query         url.Values
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "Filtered",
+			query: url.Values{
+				"actor":      []string{customer.Username},
+				"targetType": []string{auditTargetAccount},
+				"from":       []string{from.Format(time.RFC3339)},
+				"page":       []string{"1"},
+				"limit":      []string{"5"},
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorizationHeader(t, request, tokenMaker, authorizationTypeBearer, admin.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(admin.Username)).Times(1).Return(admin, nil)
+				store.EXPECT().
+					ListAuditLogs(gomock.Any(), gomock.Eq(db.ListAuditLogsParams{
+						Actor:       sql.NullString{String: customer.Username, Valid: true},
+						TargetType:  sql.NullString{String: auditTargetAccount, Valid: true},
+						FromTime:    sql.NullTime{Time: from, Valid: true},
+						LimitCount:  5,
+						OffsetCount: 0,
+					})).
+					Times(1).
+					Return(entries, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				require.JSONEq(t, fmt.Sprintf(`[{
+					"id":1,"actor":%q,"method":"POST","route":"/transfers","statusCode":201,
+					"after":{"id":7},"clientIP":"203.0.113.7","createdAt":"0001-01-01T00:00:00Z"
+				}]`, customer.Username), recorder.Body.String())
+			},
+		},
+		{
+			name:  "Forbidden",
+			query: url.Values{},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorizationHeader(t, request, tokenMaker, authorizationTypeBearer, customer.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(customer.Username)).Times(1).Return(customer, nil)
+				store.EXPECT().ListAuditLogs(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:  "Invalid Time",
+			query: url.Values{"from": []string{"yesterday"}},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorizationHeader(t, request, tokenMaker, authorizationTypeBearer, admin.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(admin.Username)).Times(1).Return(admin, nil)
+				store.EXPECT().ListAuditLogs(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodGet, "/admin/audit-log?"+tc.query.Encode(), nil)
+			require.NoError(t, err)
+
+			tc.setupAuth(t, request, server.tokenMaker)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
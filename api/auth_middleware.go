@@ -1,11 +1,14 @@
 package api
 
 import (
+	"database/sql"
 	"errors"
 	"net/http"
 	"strings"
 
+	db "github.com/crackz/simple-bank/db/sqlc"
 	"github.com/crackz/simple-bank/token"
+	"github.com/crackz/simple-bank/util"
 	"github.com/gin-gonic/gin"
 )
 
@@ -44,6 +47,33 @@ func authMiddleware(tokenMaster token.Maker) gin.HandlerFunc {
 		}
 
 		ctx.Set(authorizationPayloadKey, payload)
+		setAuditActor(ctx, payload.Username)
+		ctx.Next()
+	}
+}
+
+// adminMiddleware must run after authMiddleware. The role is read from the
+// database rather than the token so revoking it takes effect immediately.
+func adminMiddleware(store db.Store) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+		user, err := store.GetUser(ctx, authPayload.Username)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				ctx.AbortWithStatusJSON(http.StatusForbidden, errorResponse(errors.New("admin access is required")))
+				return
+			}
+
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, errorResponse(err))
+			return
+		}
+
+		if user.Role != util.AdminRole {
+			ctx.AbortWithStatusJSON(http.StatusForbidden, errorResponse(errors.New("admin access is required")))
+			return
+		}
+
 		ctx.Next()
 	}
 }
@@ -0,0 +1,75 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+
+	db "github.com/crackz/simple-bank/db/sqlc"
+	"github.com/gin-gonic/gin"
+)
+
+// getCurrencies lists the currencies accounts and transfers can use.
+func (server *Server) getCurrencies(ctx *gin.Context) {
+	currencies, err := server.currencies.Enabled(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, currencies)
+}
+
+// getAllCurrencies lists every known currency, enabled or not.
+func (server *Server) getAllCurrencies(ctx *gin.Context) {
+	currencies, err := server.store.ListCurrencies(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, currencies)
+}
+
+type currencyParam struct {
+	Code string `uri:"code" binding:"required,len=3,uppercase"`
+}
+
+func (server *Server) enableCurrency(ctx *gin.Context) {
+	server.setCurrencyEnabled(ctx, true)
+}
+
+// disableCurrency stops new accounts, transfers and quotes from using a
+// currency. Existing accounts in it keep their balance.
+func (server *Server) disableCurrency(ctx *gin.Context) {
+	server.setCurrencyEnabled(ctx, false)
+}
+
+func (server *Server) setCurrencyEnabled(ctx *gin.Context, enabled bool) {
+	var params currencyParam
+
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	setAuditTarget(ctx, auditTargetCurrency, params.Code)
+
+	currency, err := server.store.UpdateCurrencyEnabled(ctx, db.UpdateCurrencyEnabledParams{
+		Code:    params.Code,
+		Enabled: enabled,
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(errors.New("currency not found")))
+		} else {
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		}
+		return
+	}
+	setAuditAfter(ctx, currency)
+
+	server.currencies.Invalidate()
+
+	ctx.JSON(http.StatusOK, currency)
+}
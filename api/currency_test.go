@@ -0,0 +1,191 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/crackz/simple-bank/currency"
+	mockdb "github.com/crackz/simple-bank/db/mock"
+	db "github.com/crackz/simple-bank/db/sqlc"
+	"github.com/crackz/simple-bank/util"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetCurrenciesAPI(t *testing.T) {
+	user, _ := randomInMemoryUser(t)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	server := newTestServer(t, store)
+	recorder := httptest.NewRecorder()
+
+	request, err := http.NewRequest(http.MethodGet, "/currencies", nil)
+	require.NoError(t, err)
+
+	addAuthorizationHeader(t, request, server.tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	var currencies []db.Currency
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &currencies))
+	require.Len(t, currencies, 3)
+	for _, c := range currencies {
+		require.True(t, c.Enabled)
+	}
+}
+
+func TestCreateAccountDisabledCurrencyAPI(t *testing.T) {
+	user, _ := randomInMemoryUser(t)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().CreateAccount(gomock.Any(), gomock.Any()).Times(0)
+
+	server := newTestServer(t, store)
+	recorder := httptest.NewRecorder()
+
+	data, err := json.Marshal(gin.H{"currency": "JPY"})
+	require.NoError(t, err)
+
+	request, err := http.NewRequest(http.MethodPost, "/accounts", bytes.NewReader(data))
+	require.NoError(t, err)
+
+	addAuthorizationHeader(t, request, server.tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+}
+
+func TestSetCurrencyEnabledAPI(t *testing.T) {
+	admin, _ := randomInMemoryUser(t)
+	admin.Role = util.AdminRole
+
+	jpy := db.Currency{Code: "JPY", NumericCode: "392", Name: "Yen", MinorUnits: 0}
+
+	testCases := []struct {
+		name          string
+		code          string
+		action        string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:   "Enable",
+			code:   jpy.Code,
+			action: "enable",
+			buildStubs: func(store *mockdb.MockStore) {
+				enabled := jpy
+				enabled.Enabled = true
+				store.EXPECT().
+					UpdateCurrencyEnabled(gomock.Any(), gomock.Eq(db.UpdateCurrencyEnabledParams{Code: jpy.Code, Enabled: true})).
+					Times(1).
+					Return(enabled, nil)
+				// the registry is reloaded after the change
+				store.EXPECT().
+					ListCurrencies(gomock.Any()).
+					Times(1).
+					Return([]db.Currency{enabled}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				requireBodyMatchCurrency(t, recorder.Body, db.Currency{Code: jpy.Code, NumericCode: jpy.NumericCode, Name: jpy.Name, Enabled: true})
+			},
+		},
+		{
+			name:   "Disable",
+			code:   util.CAD,
+			action: "disable",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					UpdateCurrencyEnabled(gomock.Any(), gomock.Eq(db.UpdateCurrencyEnabledParams{Code: util.CAD})).
+					Times(1).
+					Return(db.Currency{Code: util.CAD, MinorUnits: 2}, nil)
+				store.EXPECT().
+					ListCurrencies(gomock.Any()).
+					Times(1).
+					Return([]db.Currency{{Code: util.CAD, MinorUnits: 2}}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:   "NotFound",
+			code:   "XXX",
+			action: "enable",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					UpdateCurrencyEnabled(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.Currency{}, sql.ErrNoRows)
+				store.EXPECT().ListCurrencies(gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:   "InvalidCode",
+			code:   "usd",
+			action: "enable",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().UpdateCurrencyEnabled(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			store.EXPECT().GetUser(gomock.Any(), gomock.Eq(admin.Username)).Times(1).Return(admin, nil)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			server.currencies = currency.NewRegistry(store, time.Hour)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/admin/currencies/%s/%s", tc.code, tc.action)
+			request, err := http.NewRequest(http.MethodPost, url, nil)
+			require.NoError(t, err)
+
+			addAuthorizationHeader(t, request, server.tokenMaker, authorizationTypeBearer, admin.Username, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+
+			// the next lookup sees the change
+			if recorder.Code == http.StatusOK {
+				require.Equal(t, tc.action == "enable", server.currencies.IsEnabled(request.Context(), tc.code))
+			}
+		})
+	}
+}
+
+func requireBodyMatchCurrency(t *testing.T, body io.Reader, currency db.Currency) {
+	data, err := io.ReadAll(body)
+	require.NoError(t, err)
+
+	var gotCurrency db.Currency
+	err = json.Unmarshal(data, &gotCurrency)
+	require.NoError(t, err)
+	require.Equal(t, currency, gotCurrency)
+}
@@ -0,0 +1,63 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+
+	db "github.com/crackz/simple-bank/db/sqlc"
+	"github.com/crackz/simple-bank/pagination"
+	"github.com/gin-gonic/gin"
+)
+
+// getAccountEntries lists the entries of one of the current user's accounts,
+// oldest first, a page at a time.
+func (server *Server) getAccountEntries(ctx *gin.Context) {
+	var params getAccountParam
+	var query pageQuery
+
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	scope := fmt.Sprintf("entries:%d", params.AccountID)
+	cursor, err := server.cursors.Decode(scope, query.Cursor)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	account, ok := server.checkAccountOwner(ctx, params.AccountID)
+	if !ok {
+		return
+	}
+
+	entries, err := server.store.ListEntriesAfter(ctx, db.ListEntriesAfterParams{
+		AccountID:      account.ID,
+		AfterCreatedAt: cursor.CreatedAt,
+		AfterID:        cursor.ID,
+		LimitCount:     query.limit() + 1,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	response, err := newPageResponse(server, scope, entries, query.limit(), entryCursor, func(entry db.Entry) entryResponse {
+		return newEntryResponse(entry, account.Currency)
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+func entryCursor(entry db.Entry) pagination.Cursor {
+	return pagination.Cursor{CreatedAt: entry.CreatedAt, ID: entry.ID}
+}
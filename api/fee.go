@@ -0,0 +1,212 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"time"
+
+	db "github.com/crackz/simple-bank/db/sqlc"
+	"github.com/crackz/simple-bank/money"
+	"github.com/gin-gonic/gin"
+)
+
+type previewTransferFeeResponse struct {
+	Amount money.Money `json:"amount"`
+	Fee    money.Money `json:"fee"`
+	// Total is what the source account is debited.
+	Total money.Money `json:"total"`
+	// FeeScheduleID is only set when a fee schedule applies.
+	FeeScheduleID *int64 `json:"feeScheduleID,omitempty"`
+}
+
+// previewTransferFee shows the fee a transfer would be charged without making
+// it. It takes the same body as createTransfer.
+func (server *Server) previewTransferFee(ctx *gin.Context) {
+	var createDto createTransferDto
+
+	if err := ctx.ShouldBindJSON(&createDto); err != nil {
+		ctx.JSON(http.StatusUnprocessableEntity, errorResponse(err))
+		return
+	}
+
+	fromAccount, _, ok := server.checkTransferAccounts(ctx, createDto.FromAccountID, createDto.ToAccountID, createDto.Currency)
+	if !ok {
+		return
+	}
+
+	amount, ok := parseAmount(ctx, createDto.Amount, fromAccount.Currency)
+	if !ok {
+		return
+	}
+
+	preview, err := server.store.PreviewTransferFee(ctx, fromAccount.ID, amount)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, previewTransferFeeResponse{
+		Amount:        money.New(preview.Amount, fromAccount.Currency),
+		Fee:           money.New(preview.Fee, fromAccount.Currency),
+		Total:         money.New(preview.Total, fromAccount.Currency),
+		FeeScheduleID: int64Pointer(preview.FeeScheduleID),
+	})
+}
+
+// createFeeScheduleDto takes decimal amounts in currency. Omitted flat and
+// minimum amounts are zero, and an omitted maximum doesn't cap the fee.
+type createFeeScheduleDto struct {
+	Currency         string  `json:"currency" binding:"required,currency"`
+	FlatAmount       string  `json:"flatAmount" binding:"omitempty,amount=Currency"`
+	Percentage       string  `json:"percentage" binding:"omitempty,numeric"`
+	MinAmount        string  `json:"minAmount" binding:"omitempty,amount=Currency"`
+	MaxAmount        *string `json:"maxAmount" binding:"omitempty,amount=Currency"`
+	RevenueAccountID int64   `json:"revenueAccountID" binding:"required,min=1"`
+}
+
+type feeScheduleResponse struct {
+	ID         int64       `json:"id"`
+	Currency   string      `json:"currency"`
+	FlatAmount money.Money `json:"flatAmount"`
+	Percentage string      `json:"percentage"`
+	MinAmount  money.Money `json:"minAmount"`
+	// MaxAmount is only set when the fee is capped.
+	MaxAmount        *money.Money `json:"maxAmount,omitempty"`
+	RevenueAccountID int64        `json:"revenueAccountID"`
+	Active           bool         `json:"active"`
+	CreatedAt        time.Time    `json:"createdAt"`
+}
+
+func newFeeScheduleResponse(schedule db.FeeSchedule) feeScheduleResponse {
+	return feeScheduleResponse{
+		ID:               schedule.ID,
+		Currency:         schedule.Currency,
+		FlatAmount:       money.New(schedule.FlatAmount, schedule.Currency),
+		Percentage:       schedule.Percentage,
+		MinAmount:        money.New(schedule.MinAmount, schedule.Currency),
+		MaxAmount:        moneyPointer(schedule.MaxAmount, schedule.Currency),
+		RevenueAccountID: schedule.RevenueAccountID,
+		Active:           schedule.Active,
+		CreatedAt:        schedule.CreatedAt,
+	}
+}
+
+// createFeeSchedule replaces the active fee schedule of a currency.
+func (server *Server) createFeeSchedule(ctx *gin.Context) {
+	var createDto createFeeScheduleDto
+
+	if err := ctx.ShouldBindJSON(&createDto); err != nil {
+		ctx.JSON(http.StatusUnprocessableEntity, errorResponse(err))
+		return
+	}
+
+	arg := db.CreateFeeScheduleParams{
+		Currency:         createDto.Currency,
+		Percentage:       createDto.Percentage,
+		RevenueAccountID: createDto.RevenueAccountID,
+	}
+	if arg.Percentage == "" {
+		arg.Percentage = "0"
+	}
+
+	var ok bool
+	if createDto.FlatAmount != "" {
+		if arg.FlatAmount, ok = parseAmount(ctx, createDto.FlatAmount, createDto.Currency); !ok {
+			return
+		}
+	}
+	if createDto.MinAmount != "" {
+		if arg.MinAmount, ok = parseAmount(ctx, createDto.MinAmount, createDto.Currency); !ok {
+			return
+		}
+	}
+	if arg.MaxAmount, ok = parseNullAmount(ctx, createDto.MaxAmount, createDto.Currency); !ok {
+		return
+	}
+
+	schedule, err := server.store.CreateFeeScheduleTx(ctx, arg)
+	if err != nil {
+		ctx.JSON(feeErrorStatus(err), errorResponse(err))
+		return
+	}
+	setAuditTarget(ctx, auditTargetFeeSchedule, schedule.ID)
+	setAuditAfter(ctx, schedule)
+
+	ctx.JSON(http.StatusCreated, newFeeScheduleResponse(schedule))
+}
+
+type getFeeSchedulesQuery struct {
+	Page  int32 `form:"page" binding:"min=1"`
+	Limit int32 `form:"limit" binding:"min=1,max=100"`
+}
+
+func (server *Server) getFeeSchedules(ctx *gin.Context) {
+	var query getFeeSchedulesQuery
+
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if query.Page == 0 {
+		query.Page = 1
+	}
+	if query.Limit == 0 {
+		query.Limit = 10
+	}
+
+	schedules, err := server.store.ListFeeSchedules(ctx, db.ListFeeSchedulesParams{
+		Limit:  query.Limit,
+		Offset: (query.Page - 1) * query.Limit,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	response := make([]feeScheduleResponse, 0, len(schedules))
+	for _, schedule := range schedules {
+		response = append(response, newFeeScheduleResponse(schedule))
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+type feeScheduleParam struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+// deactivateFeeSchedule stops charging a fee schedule. Transfers it was
+// charged on keep referring to it.
+func (server *Server) deactivateFeeSchedule(ctx *gin.Context) {
+	var params feeScheduleParam
+
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	setAuditTarget(ctx, auditTargetFeeSchedule, params.ID)
+
+	schedule, err := server.store.DeactivateFeeSchedule(ctx, params.ID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(errors.New("fee schedule not found")))
+		} else {
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		}
+		return
+	}
+	setAuditAfter(ctx, schedule)
+
+	ctx.JSON(http.StatusOK, newFeeScheduleResponse(schedule))
+}
+
+func feeErrorStatus(err error) int {
+	if errors.Is(err, db.ErrInvalidFeeSchedule) {
+		return http.StatusUnprocessableEntity
+	}
+
+	return transferErrorStatus(err)
+}
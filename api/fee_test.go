@@ -0,0 +1,287 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	mockdb "github.com/crackz/simple-bank/db/mock"
+	db "github.com/crackz/simple-bank/db/sqlc"
+	"github.com/crackz/simple-bank/money"
+	"github.com/crackz/simple-bank/util"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPreviewTransferFeeAPI(t *testing.T) {
+	amount := int64(1000)
+
+	user1, _ := randomInMemoryUser(t)
+	user2, _ := randomInMemoryUser(t)
+
+	account1 := randomInMemoryAccount(user1.Username)
+	account2 := randomInMemoryAccount(user2.Username)
+	account1.Currency = util.USD
+	account2.Currency = util.USD
+	account2.ID = account1.ID + 1
+
+	body := gin.H{
+		"fromAccountID": account1.ID,
+		"toAccountID":   account2.ID,
+		"amount":        "10.00",
+		"currency":      util.USD,
+	}
+
+	testCases := []struct {
+		name          string
+		username      string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "OK",
+			username: user1.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+				store.EXPECT().
+					PreviewTransferFee(gomock.Any(), gomock.Eq(account1.ID), gomock.Eq(amount)).
+					Times(1).
+					Return(db.TransferFeePreview{
+						Amount:        amount,
+						Fee:           25,
+						Total:         amount + 25,
+						FeeScheduleID: sql.NullInt64{Int64: 1, Valid: true},
+					}, nil)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var got previewTransferFeeResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &got))
+				require.Equal(t, money.New(amount, util.USD), got.Amount)
+				require.Equal(t, money.New(25, util.USD), got.Fee)
+				require.Equal(t, money.New(amount+25, util.USD), got.Total)
+			},
+		},
+		{
+			name:     "Forbidden",
+			username: user2.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+				store.EXPECT().PreviewTransferFee(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:     "InternalError",
+			username: user1.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+				store.EXPECT().
+					PreviewTransferFee(gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.TransferFeePreview{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(body)
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/transfers/preview", bytes.NewReader(data))
+			require.NoError(t, err)
+
+			addAuthorizationHeader(t, request, server.tokenMaker, authorizationTypeBearer, tc.username, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestCreateFeeScheduleAPI(t *testing.T) {
+	admin, _ := randomInMemoryUser(t)
+	admin.Role = util.AdminRole
+
+	revenueAccountID := util.RandomInt(1, 1000)
+
+	testCases := []struct {
+		name          string
+		body          gin.H
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			body: gin.H{
+				"currency":         util.USD,
+				"flatAmount":       "0.10",
+				"percentage":       "0.005",
+				"minAmount":        "0.25",
+				"maxAmount":        "5.00",
+				"revenueAccountID": revenueAccountID,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.CreateFeeScheduleParams{
+					Currency:         util.USD,
+					FlatAmount:       10,
+					Percentage:       "0.005",
+					MinAmount:        25,
+					MaxAmount:        sql.NullInt64{Int64: 500, Valid: true},
+					RevenueAccountID: revenueAccountID,
+				}
+				store.EXPECT().
+					CreateFeeScheduleTx(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(db.FeeSchedule{ID: 1, Currency: util.USD, FlatAmount: 10, MinAmount: 25, MaxAmount: arg.MaxAmount, Active: true}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusCreated, recorder.Code)
+
+				var got feeScheduleResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &got))
+				require.Equal(t, money.New(10, util.USD), got.FlatAmount)
+				require.Equal(t, money.New(25, util.USD), got.MinAmount)
+				require.Equal(t, money.New(500, util.USD), *got.MaxAmount)
+			},
+		},
+		{
+			name: "Flat",
+			body: gin.H{
+				"currency":         util.USD,
+				"flatAmount":       "0.10",
+				"revenueAccountID": revenueAccountID,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.CreateFeeScheduleParams{
+					Currency:         util.USD,
+					FlatAmount:       10,
+					Percentage:       "0",
+					RevenueAccountID: revenueAccountID,
+				}
+				store.EXPECT().
+					CreateFeeScheduleTx(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(db.FeeSchedule{ID: 1, Currency: util.USD, Active: true}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusCreated, recorder.Code)
+			},
+		},
+		{
+			name: "InvalidSchedule",
+			body: gin.H{
+				"currency":         util.USD,
+				"minAmount":        "5.00",
+				"maxAmount":        "0.25",
+				"revenueAccountID": revenueAccountID,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateFeeScheduleTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.FeeSchedule{}, db.ErrInvalidFeeSchedule)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+			},
+		},
+		{
+			name: "RevenueAccountNotFound",
+			body: gin.H{
+				"currency":         util.USD,
+				"flatAmount":       "0.10",
+				"revenueAccountID": revenueAccountID,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateFeeScheduleTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.FeeSchedule{}, db.ErrAccountNotFound)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name: "TooManyDecimals",
+			body: gin.H{
+				"currency":         util.USD,
+				"flatAmount":       "0.105",
+				"revenueAccountID": revenueAccountID,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateFeeScheduleTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+			},
+		},
+		{
+			name: "InvalidPercentage",
+			body: gin.H{
+				"currency":         util.USD,
+				"percentage":       "half",
+				"revenueAccountID": revenueAccountID,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateFeeScheduleTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			store.EXPECT().GetUser(gomock.Any(), gomock.Eq(admin.Username)).Times(1).Return(admin, nil)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/admin/fee-schedules", bytes.NewReader(data))
+			require.NoError(t, err)
+
+			addAuthorizationHeader(t, request, server.tokenMaker, authorizationTypeBearer, admin.Username, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
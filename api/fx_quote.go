@@ -0,0 +1,87 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	db "github.com/crackz/simple-bank/db/sqlc"
+	"github.com/crackz/simple-bank/fx"
+	"github.com/crackz/simple-bank/money"
+	"github.com/crackz/simple-bank/token"
+	"github.com/gin-gonic/gin"
+)
+
+const defaultFXQuoteTTL = 30 * time.Second
+
+type createFxQuoteDto struct {
+	FromCurrency string `json:"fromCurrency" binding:"required,currency"`
+	ToCurrency   string `json:"toCurrency" binding:"required,currency,nefield=FromCurrency"`
+	Amount       string `json:"amount" binding:"omitempty,amount=FromCurrency"`
+}
+
+type fxQuoteResponse struct {
+	db.FxQuote
+	Amount   *money.Money `json:"amount,omitempty"`
+	ToAmount *money.Money `json:"toAmount,omitempty"`
+}
+
+// createFxQuote locks the current rate of a currency pair for the configured
+// TTL. The quote can then be used once by an exchange transfer of its owner.
+func (server *Server) createFxQuote(ctx *gin.Context) {
+	var createDto createFxQuoteDto
+
+	if err := ctx.ShouldBindJSON(&createDto); err != nil {
+		ctx.JSON(http.StatusUnprocessableEntity, errorResponse(err))
+		return
+	}
+
+	rate, err := server.rateProvider.Rate(ctx, createDto.FromCurrency, createDto.ToCurrency)
+	if err != nil {
+		if errors.Is(err, fx.ErrRateNotFound) {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	var response fxQuoteResponse
+	if createDto.Amount != "" {
+		amount, ok := parseAmount(ctx, createDto.Amount, createDto.FromCurrency)
+		if !ok {
+			return
+		}
+
+		toAmount, err := rate.Convert(amount)
+		if err != nil {
+			ctx.JSON(http.StatusUnprocessableEntity, errorResponse(err))
+			return
+		}
+
+		from, to := money.New(amount, rate.From), money.New(toAmount, rate.To)
+		response.Amount, response.ToAmount = &from, &to
+	}
+
+	ttl := server.config.FXQuoteTTL
+	if ttl <= 0 {
+		ttl = defaultFXQuoteTTL
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	arg := db.CreateFxQuoteParams{
+		Owner:        authPayload.Username,
+		FromCurrency: rate.From,
+		ToCurrency:   rate.To,
+		Rate:         rate.String(),
+		ExpiresAt:    time.Now().Add(ttl),
+	}
+
+	response.FxQuote, err = server.store.CreateFxQuote(ctx, arg)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, response)
+}
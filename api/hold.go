@@ -0,0 +1,301 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	db "github.com/crackz/simple-bank/db/sqlc"
+	"github.com/crackz/simple-bank/money"
+	"github.com/crackz/simple-bank/token"
+	"github.com/gin-gonic/gin"
+)
+
+const defaultHoldTTL = 7 * 24 * time.Hour
+
+type createHoldDto struct {
+	FromAccountID int64      `json:"fromAccountID" binding:"required,min=1"`
+	ToAccountID   int64      `json:"toAccountID" binding:"required,min=1"`
+	Amount        string     `json:"amount" binding:"required,amount=Currency"`
+	Currency      string     `json:"currency" binding:"required,currency"`
+	ExpiresAt     *time.Time `json:"expiresAt"`
+}
+
+type holdResponse struct {
+	ID          int64       `json:"id"`
+	AccountID   int64       `json:"accountID"`
+	ToAccountID int64       `json:"toAccountID"`
+	Amount      money.Money `json:"amount"`
+	Status      string      `json:"status"`
+	ExpiresAt   time.Time   `json:"expiresAt"`
+	// TransferID is only set when the hold was captured.
+	TransferID *int64 `json:"transferID,omitempty"`
+	// ResolvedAt is only set once the hold is no longer active.
+	ResolvedAt *time.Time `json:"resolvedAt,omitempty"`
+	CreatedAt  time.Time  `json:"createdAt"`
+}
+
+// newHoldResponse takes the currency of the account the hold is made on.
+func newHoldResponse(hold db.AccountHold, currency string) holdResponse {
+	return holdResponse{
+		ID:          hold.ID,
+		AccountID:   hold.AccountID,
+		ToAccountID: hold.ToAccountID,
+		Amount:      money.New(hold.Amount, currency),
+		Status:      hold.Status,
+		ExpiresAt:   hold.ExpiresAt,
+		TransferID:  int64Pointer(hold.TransferID),
+		ResolvedAt:  timePointer(hold.ResolvedAt),
+		CreatedAt:   hold.CreatedAt,
+	}
+}
+
+type holdTxResponse struct {
+	Hold    holdResponse    `json:"hold"`
+	Account accountResponse `json:"account"`
+	// Transfer is only set when the hold was captured.
+	Transfer *transferTxResponse `json:"transfer,omitempty"`
+}
+
+func newHoldTxResponse(result db.HoldTxResult) holdTxResponse {
+	response := holdTxResponse{
+		Hold:    newHoldResponse(result.Hold, result.Account.Currency),
+		Account: newAccountResponse(result.Account),
+	}
+	if result.Transfer != nil {
+		transfer := newTransferTxResponse(*result.Transfer)
+		response.Transfer = &transfer
+	}
+
+	return response
+}
+
+// createHold reserves funds of the current user's account for a payment to
+// another account that's captured or voided later.
+func (server *Server) createHold(ctx *gin.Context) {
+	var createDto createHoldDto
+
+	if err := ctx.ShouldBindJSON(&createDto); err != nil {
+		ctx.JSON(http.StatusUnprocessableEntity, errorResponse(err))
+		return
+	}
+
+	expiresAt := time.Now().Add(server.holdTTL())
+	if createDto.ExpiresAt != nil {
+		if !createDto.ExpiresAt.After(time.Now()) {
+			ctx.JSON(http.StatusUnprocessableEntity, errorResponse(errors.New("expiresAt must be in the future")))
+			return
+		}
+		expiresAt = *createDto.ExpiresAt
+	}
+
+	fromAccount, toAccount, ok := server.checkTransferAccounts(ctx, createDto.FromAccountID, createDto.ToAccountID, createDto.Currency)
+	if !ok {
+		return
+	}
+
+	amount, ok := parseAmount(ctx, createDto.Amount, fromAccount.Currency)
+	if !ok {
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	idempotency, err := idempotencyParams(ctx, authPayload.Username, createDto)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	arg := db.CreateHoldTxParams{
+		AccountID:   fromAccount.ID,
+		ToAccountID: toAccount.ID,
+		Amount:      amount,
+		ExpiresAt:   expiresAt,
+		Idempotency: idempotency,
+	}
+
+	// the hold is screened as the transfer its capture makes, which is
+	// screened again then
+	if !server.screenImmediateTransfer(ctx, authPayload.Username, &db.TransferTxParams{
+		FromAccountID: arg.AccountID,
+		ToAccountID:   arg.ToAccountID,
+		Amount:        arg.Amount,
+		Idempotency:   idempotency,
+	}) {
+		return
+	}
+
+	result, err := server.store.CreateHoldTx(ctx, arg)
+	if err != nil {
+		ctx.JSON(transferErrorStatus(err), errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, newHoldTxResponse(result))
+}
+
+type holdParam struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+func (server *Server) getHold(ctx *gin.Context) {
+	var params holdParam
+
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	hold, fromAccount, toAccount, ok := server.checkHoldAccounts(ctx, params.ID)
+	if !ok {
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if !isOwner(fromAccount, authPayload.Username) && !isOwner(toAccount, authPayload.Username) {
+		ctx.JSON(http.StatusForbidden, errorResponse(errors.New("you are not allowed to access this hold")))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newHoldResponse(hold, fromAccount.Currency))
+}
+
+type captureHoldDto struct {
+	// Amount is a decimal amount in the currency of the held account. It
+	// defaults to the whole hold.
+	Amount string `json:"amount" binding:"omitempty,amount"`
+}
+
+// captureHold transfers all or part of a hold to the account it was made for.
+// Only the owner of that account can capture it.
+func (server *Server) captureHold(ctx *gin.Context) {
+	var params holdParam
+	var captureDto captureHoldDto
+
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	if err := ctx.ShouldBindJSON(&captureDto); err != nil {
+		ctx.JSON(http.StatusUnprocessableEntity, errorResponse(err))
+		return
+	}
+
+	hold, fromAccount, toAccount, ok := server.checkHoldAccounts(ctx, params.ID)
+	if !ok {
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if !isOwner(toAccount, authPayload.Username) {
+		ctx.JSON(http.StatusForbidden, errorResponse(fmt.Errorf("account id: %v doesn't belong to current user", toAccount.ID)))
+		return
+	}
+
+	var amount int64
+	if captureDto.Amount != "" {
+		amount, ok = parseAmount(ctx, captureDto.Amount, fromAccount.Currency)
+		if !ok {
+			return
+		}
+	}
+
+	idempotency, err := idempotencyParams(ctx, authPayload.Username, gin.H{"holdID": hold.ID, "amount": captureDto.Amount})
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	// the capture is a transfer of the hold's owner, so it's screened as theirs
+	screenArg := db.TransferTxParams{
+		FromAccountID: hold.AccountID,
+		ToAccountID:   hold.ToAccountID,
+		Amount:        amount,
+		Idempotency:   idempotency,
+	}
+	if amount == 0 {
+		screenArg.Amount = hold.Amount
+	}
+	if !server.screenImmediateTransfer(ctx, fromAccount.Owner, &screenArg) {
+		return
+	}
+
+	result, err := server.store.CaptureHoldTx(ctx, db.CaptureHoldTxParams{
+		HoldID:      hold.ID,
+		Amount:      amount,
+		Idempotency: idempotency,
+		Risk:        screenArg.Risk,
+	})
+	if err != nil {
+		ctx.JSON(transferErrorStatus(err), errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newHoldTxResponse(result))
+}
+
+// voidHold releases a hold without moving any money. Either side of the hold
+// can void it.
+func (server *Server) voidHold(ctx *gin.Context) {
+	var params holdParam
+
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	hold, fromAccount, toAccount, ok := server.checkHoldAccounts(ctx, params.ID)
+	if !ok {
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if !isOwner(fromAccount, authPayload.Username) && !isOwner(toAccount, authPayload.Username) {
+		ctx.JSON(http.StatusForbidden, errorResponse(errors.New("you are not allowed to access this hold")))
+		return
+	}
+
+	result, err := server.store.VoidHoldTx(ctx, hold.ID)
+	if err != nil {
+		ctx.JSON(transferErrorStatus(err), errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newHoldTxResponse(result))
+}
+
+// checkHoldAccounts loads a hold with both of its accounts. The error response
+// is already written when ok is false.
+func (server *Server) checkHoldAccounts(ctx *gin.Context, id int64) (hold db.AccountHold, fromAccount db.Account, toAccount db.Account, ok bool) {
+	hold, err := server.store.GetAccountHold(ctx, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(db.ErrHoldNotFound))
+		} else {
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		}
+		return
+	}
+
+	fromAccount, err = server.checkAccountExist(ctx, hold.AccountID)
+	if err != nil {
+		return
+	}
+
+	toAccount, err = server.checkAccountExist(ctx, hold.ToAccountID)
+	if err != nil {
+		return
+	}
+
+	return hold, fromAccount, toAccount, true
+}
+
+func (server *Server) holdTTL() time.Duration {
+	if server.config.HoldTTL > 0 {
+		return server.config.HoldTTL
+	}
+
+	return defaultHoldTTL
+}
@@ -0,0 +1,32 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+
+	db "github.com/crackz/simple-bank/db/sqlc"
+	"github.com/gin-gonic/gin"
+)
+
+const idempotencyKeyHeaderName = "Idempotency-Key"
+
+// idempotencyParams reads the Idempotency-Key header of the request and hashes
+// the route with its bound dto, so reusing a key for a different request can be
+// detected. It returns nil if no key was sent.
+func idempotencyParams(ctx *gin.Context, owner string, dto interface{}) (*db.IdempotencyKeyParams, error) {
+	key := ctx.GetHeader(idempotencyKeyHeaderName)
+	if len(key) == 0 {
+		return nil, nil
+	}
+
+	if len(key) > db.MaxIdempotencyKeyLength {
+		return nil, fmt.Errorf("%s header must be at most %d characters", idempotencyKeyHeaderName, db.MaxIdempotencyKeyLength)
+	}
+
+	body, err := json.Marshal(dto)
+	if err != nil {
+		return nil, err
+	}
+
+	return db.NewIdempotencyKeyParams(owner, key, ctx.Request.Method+" "+ctx.FullPath(), body), nil
+}
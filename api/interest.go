@@ -0,0 +1,253 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"time"
+
+	db "github.com/crackz/simple-bank/db/sqlc"
+	"github.com/crackz/simple-bank/interest"
+	"github.com/crackz/simple-bank/money"
+	"github.com/gin-gonic/gin"
+)
+
+type createInterestPlanDto struct {
+	Name             string `json:"name" binding:"required"`
+	Currency         string `json:"currency" binding:"required,currency"`
+	AnnualRate       string `json:"annualRate" binding:"required"`
+	DayCount         string `json:"dayCount" binding:"required,oneof=ACT/365 30/360"`
+	ExpenseAccountID int64  `json:"expenseAccountID" binding:"required,min=1"`
+}
+
+type interestPlanResponse struct {
+	ID       int64  `json:"id"`
+	Name     string `json:"name"`
+	Currency string `json:"currency"`
+	// AnnualRate is a fraction, e.g. 0.025 for 2.5%.
+	AnnualRate       string    `json:"annualRate"`
+	DayCount         string    `json:"dayCount"`
+	ExpenseAccountID int64     `json:"expenseAccountID"`
+	CreatedAt        time.Time `json:"createdAt"`
+}
+
+func newInterestPlanResponse(plan db.InterestPlan) interestPlanResponse {
+	return interestPlanResponse{
+		ID:               plan.ID,
+		Name:             plan.Name,
+		Currency:         plan.Currency,
+		AnnualRate:       plan.AnnualRate,
+		DayCount:         plan.DayCount,
+		ExpenseAccountID: plan.ExpenseAccountID,
+		CreatedAt:        plan.CreatedAt,
+	}
+}
+
+type accountInterestResponse struct {
+	AccountID int64 `json:"accountID"`
+	PlanID    int64 `json:"planID"`
+	// Accrued is the interest that will be posted so far. The fraction of a
+	// minor unit accrued on top is carried over and not shown.
+	Accrued        money.Money `json:"accrued"`
+	AccruedThrough time.Time   `json:"accruedThrough"`
+	CreatedAt      time.Time   `json:"createdAt"`
+}
+
+// newAccountInterestResponse takes the currency of the interest plan.
+func newAccountInterestResponse(accountInterest db.AccountInterest, currency string) (accountInterestResponse, error) {
+	accrued, err := interest.ParseAccrued(accountInterest.Accrued)
+	if err != nil {
+		return accountInterestResponse{}, err
+	}
+	whole, _, err := interest.Split(accrued)
+	if err != nil {
+		return accountInterestResponse{}, err
+	}
+
+	return accountInterestResponse{
+		AccountID:      accountInterest.AccountID,
+		PlanID:         accountInterest.PlanID,
+		Accrued:        money.New(whole, currency),
+		AccruedThrough: accountInterest.AccruedThrough,
+		CreatedAt:      accountInterest.CreatedAt,
+	}, nil
+}
+
+func (server *Server) createInterestPlan(ctx *gin.Context) {
+	var createDto createInterestPlanDto
+
+	if err := ctx.ShouldBindJSON(&createDto); err != nil {
+		ctx.JSON(http.StatusUnprocessableEntity, errorResponse(err))
+		return
+	}
+
+	rate, err := interest.ParseRate(createDto.AnnualRate)
+	if err != nil {
+		ctx.JSON(http.StatusUnprocessableEntity, errorResponse(err))
+		return
+	}
+
+	expenseAccount, err := server.checkAccountExist(ctx, createDto.ExpenseAccountID)
+	if err != nil {
+		return
+	}
+	if !isValidAccountCurrency(expenseAccount, createDto.Currency) {
+		ctx.JSON(http.StatusBadRequest, errorResponse(db.ErrCurrencyMismatch))
+		return
+	}
+
+	plan, err := server.store.CreateInterestPlan(ctx, db.CreateInterestPlanParams{
+		Name:             createDto.Name,
+		Currency:         createDto.Currency,
+		AnnualRate:       rate.FloatString(interest.AccrualScale),
+		DayCount:         createDto.DayCount,
+		ExpenseAccountID: expenseAccount.ID,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	setAuditTarget(ctx, auditTargetInterestPlan, plan.ID)
+	setAuditAfter(ctx, plan)
+
+	ctx.JSON(http.StatusCreated, newInterestPlanResponse(plan))
+}
+
+type getInterestPlansQuery struct {
+	Page  int32 `form:"page" binding:"min=1"`
+	Limit int32 `form:"limit" binding:"min=1,max=100"`
+}
+
+func (server *Server) getInterestPlans(ctx *gin.Context) {
+	var query getInterestPlansQuery
+
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if query.Page == 0 {
+		query.Page = 1
+	}
+	if query.Limit == 0 {
+		query.Limit = 10
+	}
+
+	plans, err := server.store.ListInterestPlans(ctx, db.ListInterestPlansParams{
+		Limit:  query.Limit,
+		Offset: (query.Page - 1) * query.Limit,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	response := make([]interestPlanResponse, 0, len(plans))
+	for _, plan := range plans {
+		response = append(response, newInterestPlanResponse(plan))
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+type assignInterestPlanDto struct {
+	PlanID int64 `json:"planID" binding:"required,min=1"`
+}
+
+func (server *Server) assignInterestPlan(ctx *gin.Context) {
+	var params getAccountParam
+	var dto assignInterestPlanDto
+
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	if err := ctx.ShouldBindJSON(&dto); err != nil {
+		ctx.JSON(http.StatusUnprocessableEntity, errorResponse(err))
+		return
+	}
+
+	setAuditTarget(ctx, auditTargetAccount, params.AccountID)
+
+	accountInterest, err := server.store.AssignInterestPlanTx(ctx, db.AssignInterestPlanTxParams{
+		AccountID: params.AccountID,
+		PlanID:    dto.PlanID,
+	})
+	if err != nil {
+		ctx.JSON(interestErrorStatus(err), errorResponse(err))
+		return
+	}
+	setAuditAfter(ctx, accountInterest)
+
+	plan, err := server.store.GetInterestPlan(ctx, accountInterest.PlanID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	response, err := newAccountInterestResponse(accountInterest, plan.Currency)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+type getAccountInterestResponse struct {
+	AccountInterest accountInterestResponse `json:"accountInterest"`
+	Plan            interestPlanResponse    `json:"plan"`
+}
+
+// getAccountInterest shows the plan of an account and the interest it accrued
+// since the last posting.
+func (server *Server) getAccountInterest(ctx *gin.Context) {
+	var params getAccountParam
+
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if _, ok := server.checkAccountOwner(ctx, params.AccountID); !ok {
+		return
+	}
+
+	accountInterest, err := server.store.GetAccountInterest(ctx, params.AccountID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(db.ErrInterestNotAssigned))
+		} else {
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		}
+		return
+	}
+
+	plan, err := server.store.GetInterestPlan(ctx, accountInterest.PlanID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	response, err := newAccountInterestResponse(accountInterest, plan.Currency)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, getAccountInterestResponse{
+		AccountInterest: response,
+		Plan:            newInterestPlanResponse(plan),
+	})
+}
+
+func interestErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, db.ErrInterestPlanNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, db.ErrInterestExpenseAccount):
+		return http.StatusUnprocessableEntity
+	}
+
+	return transferErrorStatus(err)
+}
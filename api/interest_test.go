@@ -0,0 +1,260 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	mockdb "github.com/crackz/simple-bank/db/mock"
+	db "github.com/crackz/simple-bank/db/sqlc"
+	"github.com/crackz/simple-bank/util"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateInterestPlanAPI(t *testing.T) {
+	admin, _ := randomInMemoryUser(t)
+	admin.Role = util.AdminRole
+
+	expenseAccount := randomInMemoryAccount(admin.Username)
+	expenseAccount.Currency = util.USD
+	plan := db.InterestPlan{
+		ID:               util.RandomInt(1, 1000),
+		Name:             "savings",
+		Currency:         expenseAccount.Currency,
+		AnnualRate:       "0.0250000000",
+		DayCount:         "ACT/365",
+		ExpenseAccountID: expenseAccount.ID,
+	}
+
+	testCases := []struct {
+		name          string
+		body          gin.H
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			body: gin.H{
+				"name":             plan.Name,
+				"currency":         plan.Currency,
+				"annualRate":       "0.025",
+				"dayCount":         plan.DayCount,
+				"expenseAccountID": expenseAccount.ID,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(expenseAccount.ID)).Times(1).Return(expenseAccount, nil)
+				store.EXPECT().
+					CreateInterestPlan(gomock.Any(), gomock.Eq(db.CreateInterestPlanParams{
+						Name:             plan.Name,
+						Currency:         plan.Currency,
+						AnnualRate:       plan.AnnualRate,
+						DayCount:         plan.DayCount,
+						ExpenseAccountID: expenseAccount.ID,
+					})).
+					Times(1).
+					Return(plan, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusCreated, recorder.Code)
+
+				var got db.InterestPlan
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &got))
+				require.Equal(t, plan, got)
+			},
+		},
+		{
+			name: "InvalidRate",
+			body: gin.H{
+				"name":             plan.Name,
+				"currency":         plan.Currency,
+				"annualRate":       "-0.01",
+				"dayCount":         plan.DayCount,
+				"expenseAccountID": expenseAccount.ID,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateInterestPlan(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+			},
+		},
+		{
+			name: "InvalidDayCount",
+			body: gin.H{
+				"name":             plan.Name,
+				"currency":         plan.Currency,
+				"annualRate":       "0.025",
+				"dayCount":         "ACT/ACT",
+				"expenseAccountID": expenseAccount.ID,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateInterestPlan(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+			},
+		},
+		{
+			name: "ExpenseAccountNotFound",
+			body: gin.H{
+				"name":             plan.Name,
+				"currency":         plan.Currency,
+				"annualRate":       "0.025",
+				"dayCount":         plan.DayCount,
+				"expenseAccountID": expenseAccount.ID,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(1).Return(db.Account{}, sql.ErrNoRows)
+				store.EXPECT().CreateInterestPlan(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name: "CurrencyMismatch",
+			body: gin.H{
+				"name":             plan.Name,
+				"currency":         util.CAD,
+				"annualRate":       "0.025",
+				"dayCount":         plan.DayCount,
+				"expenseAccountID": expenseAccount.ID,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(1).Return(expenseAccount, nil)
+				store.EXPECT().CreateInterestPlan(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			store.EXPECT().GetUser(gomock.Any(), gomock.Eq(admin.Username)).Times(1).Return(admin, nil)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/admin/interest-plans", bytes.NewReader(data))
+			require.NoError(t, err)
+
+			addAuthorizationHeader(t, request, server.tokenMaker, authorizationTypeBearer, admin.Username, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestAssignInterestPlanAPI(t *testing.T) {
+	admin, _ := randomInMemoryUser(t)
+	admin.Role = util.AdminRole
+
+	customer, _ := randomInMemoryUser(t)
+	customer.Role = util.CustomerRole
+
+	account := randomInMemoryAccount(customer.Username)
+	planID := util.RandomInt(1, 1000)
+
+	testCases := []struct {
+		name          string
+		user          db.User
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			user: admin,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					AssignInterestPlanTx(gomock.Any(), gomock.Eq(db.AssignInterestPlanTxParams{AccountID: account.ID, PlanID: planID})).
+					Times(1).
+					Return(db.AccountInterest{AccountID: account.ID, PlanID: planID, Accrued: "1234.5678000000"}, nil)
+				store.EXPECT().
+					GetInterestPlan(gomock.Any(), gomock.Eq(planID)).
+					Times(1).
+					Return(db.InterestPlan{ID: planID, Currency: account.Currency}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Contains(t, recorder.Body.String(), fmt.Sprintf(`"planID":%d`, planID))
+				require.Contains(t, recorder.Body.String(), fmt.Sprintf(`"accrued":{"amount":"12.34","currency":%q}`, account.Currency))
+			},
+		},
+		{
+			name: "PlanNotFound",
+			user: admin,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().AssignInterestPlanTx(gomock.Any(), gomock.Any()).Times(1).Return(db.AccountInterest{}, db.ErrInterestPlanNotFound)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name: "CurrencyMismatch",
+			user: admin,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().AssignInterestPlanTx(gomock.Any(), gomock.Any()).Times(1).Return(db.AccountInterest{}, db.ErrCurrencyMismatch)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "NotAdmin",
+			user: customer,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().AssignInterestPlanTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			store.EXPECT().GetUser(gomock.Any(), gomock.Eq(tc.user.Username)).Times(1).Return(tc.user, nil)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(gin.H{"planID": planID})
+			require.NoError(t, err)
+
+			url := fmt.Sprintf("/admin/accounts/%d/interest-plan", account.ID)
+			request, err := http.NewRequest(http.MethodPut, url, bytes.NewReader(data))
+			require.NoError(t, err)
+
+			addAuthorizationHeader(t, request, server.tokenMaker, authorizationTypeBearer, tc.user.Username, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
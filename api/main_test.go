@@ -1,10 +1,13 @@
 package api
 
 import (
+	"context"
+	"net"
 	"os"
 	"testing"
 	"time"
 
+	"github.com/crackz/simple-bank/currency"
 	db "github.com/crackz/simple-bank/db/sqlc"
 	"github.com/crackz/simple-bank/util"
 	"github.com/gin-gonic/gin"
@@ -17,13 +20,47 @@ func newTestServer(t *testing.T, store db.Store) *Server {
 		JwtDuration: time.Minute,
 	}
 
-	server, err := NewServer(config, store)
+	server, err := NewServer(config, store, currency.NewRegistry(testCurrencyStore{}, time.Hour))
 	require.NoError(t, err)
+
+	// tests that check the audit log record to the store again
+	server.auditRecorder = discardAuditRecorder{}
+	// tests don't resolve webhook hosts
+	server.resolver = testResolver{}
 	return server
 
 }
 
+// testCurrencyStore has the currencies that are enabled by default.
+type testCurrencyStore struct{}
+
+func (testCurrencyStore) ListCurrencies(ctx context.Context) ([]db.Currency, error) {
+	return []db.Currency{
+		{Code: util.CAD, NumericCode: "124", Name: "Canadian Dollar", MinorUnits: 2, Enabled: true},
+		{Code: util.EUR, NumericCode: "978", Name: "Euro", MinorUnits: 2, Enabled: true},
+		{Code: "JPY", NumericCode: "392", Name: "Yen", MinorUnits: 0},
+		{Code: util.USD, NumericCode: "840", Name: "US Dollar", MinorUnits: 2, Enabled: true},
+	}, nil
+}
+
+type discardAuditRecorder struct{}
+
+func (discardAuditRecorder) CreateAuditLog(ctx context.Context, arg db.CreateAuditLogParams) (db.AuditLog, error) {
+	return db.AuditLog{}, nil
+}
+
+func (discardAuditRecorder) CompleteAuditLog(ctx context.Context, arg db.CompleteAuditLogParams) (db.AuditLog, error) {
+	return db.AuditLog{}, nil
+}
+
 func TestMain(m *testing.M) {
 	gin.SetMode(gin.TestMode)
 	os.Exit(m.Run())
 }
+
+// testResolver resolves every host to a public address.
+type testResolver struct{}
+
+func (testResolver) LookupIPAddr(ctx context.Context, host string) ([]net.IPAddr, error) {
+	return []net.IPAddr{{IP: net.ParseIP("93.184.216.34")}}, nil
+}
@@ -0,0 +1,53 @@
+package api
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/crackz/simple-bank/money"
+)
+
+// The database's nullable values are converted from and to pointers at the
+// edge of the API, so responses omit values that aren't set rather than show
+// how they're stored.
+
+func int64Pointer(v sql.NullInt64) *int64 {
+	if !v.Valid {
+		return nil
+	}
+
+	return &v.Int64
+}
+
+func int32Pointer(v sql.NullInt32) *int32 {
+	if !v.Valid {
+		return nil
+	}
+
+	return &v.Int32
+}
+
+func stringPointer(v sql.NullString) *string {
+	if !v.Valid {
+		return nil
+	}
+
+	return &v.String
+}
+
+func timePointer(v sql.NullTime) *time.Time {
+	if !v.Valid {
+		return nil
+	}
+
+	return &v.Time
+}
+
+func moneyPointer(v sql.NullInt64, currency string) *money.Money {
+	if !v.Valid {
+		return nil
+	}
+
+	m := money.New(v.Int64, currency)
+	return &m
+}
@@ -0,0 +1,50 @@
+package api
+
+import "github.com/crackz/simple-bank/pagination"
+
+const defaultPageLimit = 10
+
+type pageQuery struct {
+	Cursor string `form:"cursor"`
+	Limit  int32  `form:"limit" binding:"omitempty,min=1,max=100"`
+}
+
+func (query pageQuery) limit() int32 {
+	if query.Limit == 0 {
+		return defaultPageLimit
+	}
+
+	return query.Limit
+}
+
+// pageResponse is the envelope of the list endpoints. NextCursor is set when
+// HasMore is, and is passed back as the cursor query parameter to get the next
+// page.
+type pageResponse[T any] struct {
+	Items      []T    `json:"items"`
+	NextCursor string `json:"nextCursor,omitempty"`
+	HasMore    bool   `json:"hasMore"`
+}
+
+// newPageResponse builds the envelope from rows fetched with a limit one more
+// than the page size, the extra row telling whether there is a next page.
+func newPageResponse[Row any, Item any](server *Server, scope string, rows []Row, limit int32, position func(Row) pagination.Cursor, item func(Row) Item) (pageResponse[Item], error) {
+	response := pageResponse[Item]{Items: make([]Item, 0, len(rows))}
+
+	if int32(len(rows)) > limit {
+		rows = rows[:limit]
+		response.HasMore = true
+
+		nextCursor, err := server.cursors.Encode(scope, position(rows[len(rows)-1]))
+		if err != nil {
+			return response, err
+		}
+		response.NextCursor = nextCursor
+	}
+
+	for _, row := range rows {
+		response.Items = append(response.Items, item(row))
+	}
+
+	return response, nil
+}
@@ -0,0 +1,213 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	mockdb "github.com/crackz/simple-bank/db/mock"
+	db "github.com/crackz/simple-bank/db/sqlc"
+	"github.com/crackz/simple-bank/util"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetAccountsAPI(t *testing.T) {
+	user, _ := randomInMemoryUser(t)
+
+	createdAt := time.Now().UTC().Truncate(time.Second)
+	accounts := make([]db.Account, 3)
+	for i := range accounts {
+		accounts[i] = randomInMemoryAccount(user.Username)
+		accounts[i].ID = int64(i + 1)
+		accounts[i].CreatedAt = createdAt
+	}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	gomock.InOrder(
+		store.EXPECT().
+			ListAccountsAfter(gomock.Any(), gomock.Eq(db.ListAccountsAfterParams{
+				Owner:      user.Username,
+				LimitCount: 3,
+			})).
+			Times(1).
+			Return(accounts, nil),
+		store.EXPECT().
+			ListAccountsAfter(gomock.Any(), gomock.Eq(db.ListAccountsAfterParams{
+				Owner:          user.Username,
+				AfterCreatedAt: createdAt,
+				AfterID:        2,
+				LimitCount:     3,
+			})).
+			Times(1).
+			Return(accounts[2:], nil),
+	)
+
+	server := newTestServer(t, store)
+
+	var page pageResponse[accountResponse]
+	getPage := func(query string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		request, err := http.NewRequest(http.MethodGet, "/accounts?"+query, nil)
+		require.NoError(t, err)
+
+		addAuthorizationHeader(t, request, server.tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+		server.router.ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	recorder := getPage("limit=2")
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &page))
+	require.True(t, page.HasMore)
+	require.NotEmpty(t, page.NextCursor)
+	require.Equal(t, []accountResponse{newAccountResponse(accounts[0]), newAccountResponse(accounts[1])}, page.Items)
+
+	recorder = getPage("limit=2&cursor=" + url.QueryEscape(page.NextCursor))
+	require.Equal(t, http.StatusOK, recorder.Code)
+	page = pageResponse[accountResponse]{}
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &page))
+	require.False(t, page.HasMore)
+	require.Empty(t, page.NextCursor)
+	require.Equal(t, []accountResponse{newAccountResponse(accounts[2])}, page.Items)
+
+	require.Equal(t, http.StatusBadRequest, getPage("cursor=garbage").Code)
+	require.Equal(t, http.StatusBadRequest, getPage("limit=101").Code)
+}
+
+func TestGetAccountEntriesAPI(t *testing.T) {
+	user, _ := randomInMemoryUser(t)
+	account := randomInMemoryAccount(user.Username)
+
+	entries := []db.Entry{
+		{ID: 1, AccountID: account.ID, Amount: -100},
+		{ID: 2, AccountID: account.ID, Amount: 250},
+	}
+
+	testCases := []struct {
+		name          string
+		username      string
+		query         string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "OK",
+			username: user.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().
+					ListEntriesAfter(gomock.Any(), gomock.Eq(db.ListEntriesAfterParams{
+						AccountID:  account.ID,
+						LimitCount: defaultPageLimit + 1,
+					})).
+					Times(1).
+					Return(entries, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var page pageResponse[entryResponse]
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &page))
+				require.False(t, page.HasMore)
+				require.Equal(t, []entryResponse{
+					newEntryResponse(entries[0], account.Currency),
+					newEntryResponse(entries[1], account.Currency),
+				}, page.Items)
+			},
+		},
+		{
+			name:     "Forbidden",
+			username: "randomUser",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().ListEntriesAfter(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:     "InvalidCursor",
+			username: user.Username,
+			query:    "cursor=garbage",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().ListEntriesAfter(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/accounts/%d/entries?%s", account.ID, tc.query)
+			request, err := http.NewRequest(http.MethodGet, url, nil)
+			require.NoError(t, err)
+
+			addAuthorizationHeader(t, request, server.tokenMaker, authorizationTypeBearer, tc.username, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestGetAccountTransfersAPI(t *testing.T) {
+	user, _ := randomInMemoryUser(t)
+	account := randomInMemoryAccount(user.Username)
+	account.Currency = util.USD
+
+	rows := []db.ListTransfersAfterRow{
+		{ID: 7, FromAccountID: account.ID, ToAccountID: account.ID + 1, Amount: 1000, ToAmount: 920, ExchangeRate: "0.92", FromCurrency: util.USD, ToCurrency: util.EUR},
+	}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+	store.EXPECT().
+		ListTransfersAfter(gomock.Any(), gomock.Eq(db.ListTransfersAfterParams{
+			AccountID:  account.ID,
+			LimitCount: 6,
+		})).
+		Times(1).
+		Return(rows, nil)
+
+	server := newTestServer(t, store)
+	recorder := httptest.NewRecorder()
+
+	request, err := http.NewRequest(http.MethodGet, fmt.Sprintf("/accounts/%d/transfers?limit=5", account.ID), nil)
+	require.NoError(t, err)
+
+	addAuthorizationHeader(t, request, server.tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	var page pageResponse[transferResponse]
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &page))
+	require.Len(t, page.Items, 1)
+	require.Equal(t, "10.00", page.Items[0].Amount.String())
+	require.Equal(t, util.EUR, page.Items[0].ToAmount.Currency)
+	require.Equal(t, "9.20", page.Items[0].ToAmount.String())
+}
@@ -0,0 +1,319 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	db "github.com/crackz/simple-bank/db/sqlc"
+	"github.com/crackz/simple-bank/money"
+	"github.com/crackz/simple-bank/pain"
+	"github.com/crackz/simple-bank/risk"
+	"github.com/crackz/simple-bank/token"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// maxPaymentFileSize is the largest pain.001 file accepted, enough for
+// pain.MaxTransactions transactions.
+const maxPaymentFileSize = 2 << 20
+
+type importPaymentsQuery struct {
+	DryRun bool `form:"dryRun"`
+}
+
+// paymentRejection is why a credit transfer of a payment file is rejected, as
+// an ISO 20022 status reason code.
+type paymentRejection struct {
+	reason string
+	err    error
+}
+
+func (rejection *paymentRejection) Error() string {
+	return rejection.err.Error()
+}
+
+func rejectPayment(reason string, format string, a ...interface{}) error {
+	return &paymentRejection{reason: reason, err: fmt.Errorf(format, a...)}
+}
+
+// paymentInstruction is a credit transfer that passed validation, with the
+// position of its status in the report.
+type paymentInstruction struct {
+	payment     int
+	transaction int
+	arg         db.TransferTxParams
+}
+
+// importPayments makes the credit transfers of a pain.001.001.03 file sent as
+// the request body, and answers with a pain.002.001.03 report of the status of
+// each transfer. Every transfer is checked against the caller's accounts and
+// the enabled currencies before any is made, and the ones that pass are made
+// one at a time through the risk rules, so some may be made and others
+// rejected. With dryRun, the transfers are only checked. A file is keyed by
+// its Idempotency-Key or else by its message id, so a file sent again isn't
+// paid twice.
+func (server *Server) importPayments(ctx *gin.Context) {
+	var query importPaymentsQuery
+
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	initiation, err := pain.Parse(http.MaxBytesReader(ctx.Writer, ctx.Request.Body, maxPaymentFileSize))
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			ctx.JSON(http.StatusRequestEntityTooLarge, errorResponse(fmt.Errorf("payment file must be at most %d bytes", maxPaymentFileSize)))
+			return
+		}
+
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	idempotency, err := idempotencyParams(ctx, authPayload.Username, initiation)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	if idempotency == nil {
+		idempotency, err = messageIdempotencyParams(ctx, authPayload.Username, initiation)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+			return
+		}
+	}
+
+	report := pain.NewStatusReport(strings.ReplaceAll(uuid.NewString(), "-", ""), time.Now(), initiation)
+	validator := paymentValidator{
+		server:      server,
+		owner:       authPayload.Username,
+		accounts:    make(map[int64]db.Account),
+		endToEndIDs: make(map[string]bool),
+	}
+
+	var instructions []paymentInstruction
+	for i, payment := range initiation.Payments {
+		for j, transfer := range payment.Transactions {
+			status := pain.TransactionStatus{
+				OriginalInstructionID: transfer.InstructionID,
+				OriginalEndToEndID:    transfer.EndToEndID,
+				Status:                pain.StatusAccepted,
+			}
+
+			arg, err := validator.check(ctx, payment, transfer)
+			var rejection *paymentRejection
+			switch {
+			case errors.As(err, &rejection):
+				status.Status = pain.StatusRejected
+				status.Reason = rejection.reason
+				status.Information = rejection.Error()
+			case err != nil:
+				ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+				return
+			default:
+				instructions = append(instructions, paymentInstruction{payment: i, transaction: j, arg: arg})
+			}
+
+			report.Payments[i].Transactions = append(report.Payments[i].Transactions, status)
+		}
+	}
+
+	if !query.DryRun {
+		for _, instruction := range instructions {
+			status := &report.Payments[instruction.payment].Transactions[instruction.transaction]
+			server.makePaymentTransfer(ctx, authPayload.Username, idempotency, instruction.arg, status)
+		}
+		setAuditAfter(ctx, report)
+	}
+
+	ctx.Status(http.StatusOK)
+	ctx.Header("Content-Type", "application/xml; charset=utf-8")
+	if err := pain.WriteStatusReport(ctx.Writer, report); err != nil {
+		log.Printf("Couldn't Write Payment Status Report %s : %s", report.MessageID, err)
+	}
+}
+
+// paymentValidator checks the credit transfers of a payment file, loading
+// each account once.
+type paymentValidator struct {
+	server      *Server
+	owner       string
+	accounts    map[int64]db.Account
+	endToEndIDs map[string]bool
+}
+
+// check returns the transfer to make for a credit transfer of payment, or a
+// *paymentRejection if it must not be made.
+func (validator *paymentValidator) check(ctx context.Context, payment pain.Payment, transfer pain.CreditTransfer) (db.TransferTxParams, error) {
+	if validator.endToEndIDs[transfer.EndToEndID] {
+		return db.TransferTxParams{}, rejectPayment(pain.ReasonDuplicate, "end to end id %s was already used in this file", transfer.EndToEndID)
+	}
+	validator.endToEndIDs[transfer.EndToEndID] = true
+
+	debtor, err := validator.account(ctx, "debtor", payment.DebtorAccount)
+	if err != nil {
+		return db.TransferTxParams{}, err
+	}
+	if !isOwner(debtor, validator.owner) {
+		return db.TransferTxParams{}, rejectPayment(pain.ReasonTransactionForbidden, "debtor account %d doesn't belong to current user", debtor.ID)
+	}
+	switch debtor.Status {
+	case db.AccountFrozen:
+		return db.TransferTxParams{}, rejectPayment(pain.ReasonBlockedAccount, "debtor account %d: %s", debtor.ID, db.ErrAccountFrozen)
+	case db.AccountClosed:
+		return db.TransferTxParams{}, rejectPayment(pain.ReasonClosedAccount, "debtor account %d: %s", debtor.ID, db.ErrAccountClosed)
+	}
+
+	currency := transfer.Amount.Currency
+	if !validator.server.currencies.IsEnabled(ctx, currency) {
+		return db.TransferTxParams{}, rejectPayment(pain.ReasonCurrencyNotAllowed, "currency %s is not supported", currency)
+	}
+	if !isValidAccountCurrency(debtor, currency) {
+		return db.TransferTxParams{}, rejectPayment(pain.ReasonInvalidCurrency, "debtor account doesn't support currency: %v", currency)
+	}
+
+	amount, err := money.Parse(transfer.Amount.Value, currency)
+	if err != nil {
+		return db.TransferTxParams{}, rejectPayment(pain.ReasonInvalidAmount, "%s", err)
+	}
+	if !amount.IsPositive() {
+		return db.TransferTxParams{}, rejectPayment(pain.ReasonInvalidAmount, "amount must be positive")
+	}
+
+	creditor, err := validator.account(ctx, "creditor", transfer.CreditorAccount)
+	if err != nil {
+		return db.TransferTxParams{}, err
+	}
+	if creditor.Status == db.AccountClosed {
+		return db.TransferTxParams{}, rejectPayment(pain.ReasonClosedAccount, "creditor account %d: %s", creditor.ID, db.ErrAccountClosed)
+	}
+	if !isValidAccountCurrency(creditor, currency) {
+		return db.TransferTxParams{}, rejectPayment(pain.ReasonInvalidCurrency, "creditor account doesn't support currency: %v", currency)
+	}
+
+	return db.TransferTxParams{
+		FromAccountID: debtor.ID,
+		ToAccountID:   creditor.ID,
+		Amount:        amount.Amount,
+	}, nil
+}
+
+// account loads the account a payment file identifies by its account id.
+func (validator *paymentValidator) account(ctx context.Context, party string, id string) (db.Account, error) {
+	if id == "" {
+		return db.Account{}, rejectPayment(pain.ReasonIncorrectAccount, "%s account must be identified by its account id", party)
+	}
+
+	accountID, err := strconv.ParseInt(id, 10, 64)
+	if err != nil || accountID < 1 {
+		return db.Account{}, rejectPayment(pain.ReasonIncorrectAccount, "%s account %q is not an account id", party, id)
+	}
+
+	if account, ok := validator.accounts[accountID]; ok {
+		return account, nil
+	}
+
+	account, err := validator.server.store.GetAccount(ctx, accountID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return db.Account{}, rejectPayment(pain.ReasonIncorrectAccount, "%s account %d not found", party, accountID)
+		}
+		return db.Account{}, err
+	}
+	validator.accounts[accountID] = account
+
+	return account, nil
+}
+
+// messageIdempotencyParams keys a payment file sent without an
+// Idempotency-Key by its message id, which the sender must keep unique. A file
+// sent again replays the transfers of the first one, and another file with the
+// same message id has its transfers rejected as duplicates.
+func messageIdempotencyParams(ctx *gin.Context, owner string, initiation pain.Initiation) (*db.IdempotencyKeyParams, error) {
+	body, err := json.Marshal(initiation)
+	if err != nil {
+		return nil, err
+	}
+
+	key := "pain.001:" + initiation.MessageID
+	return db.NewIdempotencyKeyParams(owner, key, ctx.Request.Method+" "+ctx.FullPath(), body), nil
+}
+
+// makePaymentTransfer screens and makes the transfer of a validated credit
+// transfer and records the outcome in its status. Each transfer gets its own
+// idempotency key made of the file's and the end to end id, so sending the
+// file again doesn't make the transfers twice.
+func (server *Server) makePaymentTransfer(ctx context.Context, owner string, idempotency *db.IdempotencyKeyParams, arg db.TransferTxParams, status *pain.TransactionStatus) {
+	key := *idempotency
+	key.Key += ":" + status.OriginalEndToEndID
+	arg.Idempotency = &key
+
+	screened, err := server.screen(ctx, owner, &arg, true)
+	if err != nil {
+		log.Printf("Couldn't Screen Payment %s : %s", status.OriginalEndToEndID, err)
+		status.Status = pain.StatusRejected
+		status.Reason = pain.ReasonNarrative
+		status.Information = err.Error()
+		return
+	}
+
+	switch screened.Outcome {
+	case risk.Block:
+		status.Status = pain.StatusRejected
+		status.Reason = pain.ReasonTransactionForbidden
+		status.Information = db.ErrTransferBlocked.Error()
+		return
+	case risk.Review:
+		status.Status = pain.StatusPending
+		status.Information = fmt.Sprintf("held for review as risk decision %d", screened.RiskDecision.ID)
+		return
+	}
+
+	result, err := server.store.TransferTx(ctx, arg)
+	if err != nil {
+		status.Status = pain.StatusRejected
+		status.Reason = paymentRejectionReason(err)
+		status.Information = err.Error()
+		if status.Reason == pain.ReasonNarrative {
+			log.Printf("Couldn't Make Payment %s : %s", status.OriginalEndToEndID, err)
+		}
+		return
+	}
+
+	status.Status = pain.StatusSettled
+	status.TransferID = result.Transfer.ID
+}
+
+// paymentRejectionReason is the status reason code of a transfer that failed,
+// the narrative code when none fits.
+func paymentRejectionReason(err error) string {
+	switch {
+	case errors.Is(err, db.ErrInsufficientFunds):
+		return pain.ReasonInsufficientFunds
+	case errors.Is(err, db.ErrTransferLimitExceeded):
+		return pain.ReasonAmountNotAllowed
+	case errors.Is(err, db.ErrAccountNotFound):
+		return pain.ReasonIncorrectAccount
+	case errors.Is(err, db.ErrAccountFrozen):
+		return pain.ReasonBlockedAccount
+	case errors.Is(err, db.ErrAccountClosed):
+		return pain.ReasonClosedAccount
+	case errors.Is(err, db.ErrCurrencyMismatch):
+		return pain.ReasonInvalidCurrency
+	case errors.Is(err, db.ErrIdempotencyKeyConflict):
+		return pain.ReasonDuplicate
+	}
+
+	return pain.ReasonNarrative
+}
@@ -0,0 +1,275 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	mockdb "github.com/crackz/simple-bank/db/mock"
+	db "github.com/crackz/simple-bank/db/sqlc"
+	"github.com/crackz/simple-bank/pain"
+	"github.com/crackz/simple-bank/risk"
+	"github.com/crackz/simple-bank/util"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+type testCreditTransfer struct {
+	endToEndID string
+	amount     string
+	currency   string
+	creditor   string
+}
+
+// paymentFile is a pain.001.001.03 file of a single payment from debtor.
+func paymentFile(debtor string, transfers ...testCreditTransfer) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, `<?xml version="1.0" encoding="UTF-8"?>
+<Document xmlns="urn:iso:std:iso:20022:tech:xsd:pain.001.001.03"><CstmrCdtTrfInitn>
+<GrpHdr><MsgId>MSG-1</MsgId><CreDtTm>2024-03-01T09:30:00</CreDtTm><NbOfTxs>%d</NbOfTxs></GrpHdr>
+<PmtInf><PmtInfId>PMT-1</PmtInfId><PmtMtd>TRF</PmtMtd><DbtrAcct><Id><Othr><Id>%s</Id></Othr></Id></DbtrAcct>`, len(transfers), debtor)
+	for _, transfer := range transfers {
+		fmt.Fprintf(&b, `<CdtTrfTxInf><PmtId><EndToEndId>%s</EndToEndId></PmtId><Amt><InstdAmt Ccy="%s">%s</InstdAmt></Amt><CdtrAcct><Id><Othr><Id>%s</Id></Othr></Id></CdtrAcct></CdtTrfTxInf>`,
+			transfer.endToEndID, transfer.currency, transfer.amount, transfer.creditor)
+	}
+	b.WriteString(`</PmtInf></CstmrCdtTrfInitn></Document>`)
+
+	return b.String()
+}
+
+type testStatusReport struct {
+	GroupStatus  string `xml:"CstmrPmtStsRpt>OrgnlGrpInfAndSts>GrpSts"`
+	Transactions []struct {
+		EndToEndID string `xml:"OrgnlEndToEndId"`
+		Status     string `xml:"TxSts"`
+		Reason     string `xml:"StsRsnInf>Rsn>Cd"`
+		TransferID string `xml:"AcctSvcrRef"`
+	} `xml:"CstmrPmtStsRpt>OrgnlPmtInfAndSts>TxInfAndSts"`
+}
+
+func requireStatusReport(t *testing.T, recorder *httptest.ResponseRecorder, groupStatus string, statuses ...string) testStatusReport {
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.Contains(t, recorder.Header().Get("Content-Type"), "application/xml")
+
+	var report testStatusReport
+	require.NoError(t, xml.Unmarshal(recorder.Body.Bytes(), &report))
+	require.Equal(t, groupStatus, report.GroupStatus)
+
+	got := make([]string, 0, len(report.Transactions))
+	for _, transaction := range report.Transactions {
+		got = append(got, strings.TrimSpace(transaction.Status+" "+transaction.Reason))
+	}
+	require.Equal(t, statuses, got)
+
+	return report
+}
+
+func TestImportPaymentsAPI(t *testing.T) {
+	user, _ := randomInMemoryUser(t)
+	other, _ := randomInMemoryUser(t)
+
+	debtor := db.Account{ID: 1, Owner: user.Username, Currency: util.USD, Balance: 10000, Status: db.AccountActive}
+	creditor := db.Account{ID: 2, Owner: other.Username, Currency: util.USD, Status: db.AccountActive}
+	otherAccount := db.Account{ID: 3, Owner: other.Username, Currency: util.USD, Status: db.AccountActive}
+
+	valid := testCreditTransfer{endToEndID: "E2E-1", amount: "12.50", currency: util.USD, creditor: "2"}
+
+	testCases := []struct {
+		name          string
+		file          string
+		query         string
+		setupRequest  func(request *http.Request)
+		rules         risk.Rules
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:  "DryRun",
+			query: "dryRun=true",
+			file: paymentFile("1",
+				valid,
+				testCreditTransfer{endToEndID: "E2E-2", amount: "1", currency: "JPY", creditor: "2"},
+				testCreditTransfer{endToEndID: "E2E-3", amount: "1.001", currency: util.USD, creditor: "2"},
+				testCreditTransfer{endToEndID: "E2E-4", amount: "1", currency: util.USD, creditor: "DE89370400440532013000"},
+				testCreditTransfer{endToEndID: "E2E-5", amount: "1", currency: util.USD, creditor: "4"},
+				testCreditTransfer{endToEndID: "E2E-1", amount: "1", currency: util.USD, creditor: "2"},
+			),
+			buildStubs: func(store *mockdb.MockStore) {
+				// each account is loaded once
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(debtor.ID)).Times(1).Return(debtor, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(creditor.ID)).Times(1).Return(creditor, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(int64(4))).Times(1).Return(db.Account{}, sql.ErrNoRows)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				requireStatusReport(t, recorder, pain.StatusPartiallyAccepted,
+					"ACTC", "RJCT AM03", "RJCT AM12", "RJCT AC01", "RJCT AC01", "RJCT AM05")
+			},
+		},
+		{
+			name: "Execute",
+			file: paymentFile("1",
+				valid,
+				testCreditTransfer{endToEndID: "E2E-2", amount: "100", currency: util.USD, creditor: "2"},
+			),
+			setupRequest: func(request *http.Request) {
+				request.Header.Set(idempotencyKeyHeaderName, "batch-1")
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(debtor.ID)).Times(1).Return(debtor, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(creditor.ID)).Times(1).Return(creditor, nil)
+				gomock.InOrder(
+					store.EXPECT().
+						TransferTx(gomock.Any(), gomock.Any()).
+						Times(1).
+						DoAndReturn(func(_ interface{}, arg db.TransferTxParams) (db.TransferTxResult, error) {
+							require.Equal(t, debtor.ID, arg.FromAccountID)
+							require.Equal(t, creditor.ID, arg.ToAccountID)
+							require.Equal(t, int64(1250), arg.Amount)
+							// each transfer has its own idempotency key
+							require.Equal(t, "batch-1:E2E-1", arg.Idempotency.Key)
+							return db.TransferTxResult{Transfer: db.Transfer{ID: 7}}, nil
+						}),
+					store.EXPECT().
+						TransferTx(gomock.Any(), gomock.Any()).
+						Times(1).
+						Return(db.TransferTxResult{}, db.ErrInsufficientFunds),
+				)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				report := requireStatusReport(t, recorder, pain.StatusPartiallyAccepted, "ACSC", "RJCT AM04")
+				require.Equal(t, "7", report.Transactions[0].TransferID)
+			},
+		},
+		{
+			name: "KeyedByMessageID",
+			file: paymentFile("1", valid),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(debtor.ID)).Times(1).Return(debtor, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(creditor.ID)).Times(1).Return(creditor, nil)
+				store.EXPECT().
+					TransferTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ interface{}, arg db.TransferTxParams) (db.TransferTxResult, error) {
+						require.Equal(t, user.Username, arg.Idempotency.Owner)
+						require.Equal(t, "pain.001:MSG-1:E2E-1", arg.Idempotency.Key)
+						return db.TransferTxResult{Transfer: db.Transfer{ID: 7}}, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				requireStatusReport(t, recorder, pain.StatusSettled, "ACSC")
+			},
+		},
+		{
+			name: "MessageIDReused",
+			file: paymentFile("1", valid),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(debtor.ID)).Times(1).Return(debtor, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(creditor.ID)).Times(1).Return(creditor, nil)
+				store.EXPECT().
+					TransferTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.TransferTxResult{}, db.ErrIdempotencyKeyConflict)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				requireStatusReport(t, recorder, pain.StatusRejected, "RJCT AM05")
+			},
+		},
+		{
+			name: "HeldForReview",
+			file: paymentFile("1", valid),
+			rules: risk.Rules{
+				VelocityCount:   1,
+				VelocityWindow:  time.Minute,
+				VelocityOutcome: risk.Review,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(debtor.ID)).Times(1).Return(debtor, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(creditor.ID)).Times(1).Return(creditor, nil)
+				store.EXPECT().
+					CheckTransferRisk(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(risk.Review, db.RiskDecision{ID: 3, Status: db.RiskPending}, nil)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				requireStatusReport(t, recorder, pain.StatusPending, "PDNG")
+			},
+		},
+		{
+			name: "DebtorNotOwned",
+			file: paymentFile("3", valid),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(otherAccount.ID)).Times(1).Return(otherAccount, nil)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				requireStatusReport(t, recorder, pain.StatusRejected, "RJCT AG01")
+			},
+		},
+		{
+			name: "InvalidDocument",
+			file: strings.Replace(paymentFile("1", valid), "<NbOfTxs>1", "<NbOfTxs>2", 1),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "TooLarge",
+			file: strings.Replace(paymentFile("1", valid), "</Document>", strings.Repeat(" ", maxPaymentFileSize)+"</Document>", 1),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusRequestEntityTooLarge, recorder.Code)
+			},
+		},
+		{
+			name: "InternalError",
+			file: paymentFile("1", valid),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(debtor.ID)).Times(1).Return(db.Account{}, sql.ErrConnDone)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			server.riskRules = tc.rules
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodPost, "/transfers/import?"+tc.query, bytes.NewReader([]byte(tc.file)))
+			require.NoError(t, err)
+			request.Header.Set("Content-Type", "application/xml")
+			if tc.setupRequest != nil {
+				tc.setupRequest(request)
+			}
+
+			addAuthorizationHeader(t, request, server.tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
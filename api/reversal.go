@@ -0,0 +1,162 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+
+	db "github.com/crackz/simple-bank/db/sqlc"
+	"github.com/crackz/simple-bank/money"
+	"github.com/crackz/simple-bank/token"
+	"github.com/gin-gonic/gin"
+)
+
+type transferParam struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+type transferDetailsResponse struct {
+	transferResponse
+	// ReversedAmount is in the currency of the source account.
+	ReversedAmount money.Money        `json:"reversedAmount"`
+	Reversals      []transferResponse `json:"reversals"`
+}
+
+// getTransfer returns a transfer to the owner of either of its accounts along
+// with the reversals made against it.
+func (server *Server) getTransfer(ctx *gin.Context) {
+	var params transferParam
+
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	transfer, err := server.store.GetTransfer(ctx, params.ID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(db.ErrTransferNotFound))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	fromAccount, err := server.checkAccountExist(ctx, transfer.FromAccountID)
+	if err != nil {
+		return
+	}
+
+	toAccount, err := server.checkAccountExist(ctx, transfer.ToAccountID)
+	if err != nil {
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if !isOwner(fromAccount, authPayload.Username) && !isOwner(toAccount, authPayload.Username) {
+		ctx.JSON(http.StatusForbidden, errorResponse(errors.New("you are not allowed to access this transfer")))
+		return
+	}
+
+	reversals, err := server.store.ListTransferReversals(ctx, sql.NullInt64{Int64: transfer.ID, Valid: true})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	response := transferDetailsResponse{
+		transferResponse: newTransferResponse(transfer, fromAccount.Currency, toAccount.Currency),
+		ReversedAmount:   money.New(0, fromAccount.Currency),
+		Reversals:        make([]transferResponse, 0, len(reversals)),
+	}
+	for _, reversal := range reversals {
+		// reversals go the other way round
+		response.Reversals = append(response.Reversals, newTransferResponse(reversal, toAccount.Currency, fromAccount.Currency))
+
+		response.ReversedAmount, err = response.ReversedAmount.Add(money.New(reversal.ToAmount, fromAccount.Currency))
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+			return
+		}
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+type reverseTransferDto struct {
+	// Amount is a decimal amount in the currency of the transfer's source
+	// account. It defaults to whatever is left to reverse.
+	Amount string `json:"amount" binding:"omitempty,amount"`
+}
+
+// reverseTransfer refunds all or part of a transfer. Only the owner of the
+// account that was credited can send the money back.
+func (server *Server) reverseTransfer(ctx *gin.Context) {
+	var params transferParam
+	var reverseDto reverseTransferDto
+
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	if err := ctx.ShouldBindJSON(&reverseDto); err != nil {
+		ctx.JSON(http.StatusUnprocessableEntity, errorResponse(err))
+		return
+	}
+
+	transfer, err := server.store.GetTransfer(ctx, params.ID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(db.ErrTransferNotFound))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	toAccount, err := server.checkAccountExist(ctx, transfer.ToAccountID)
+	if err != nil {
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if !isOwner(toAccount, authPayload.Username) {
+		ctx.JSON(http.StatusForbidden, errorResponse(fmt.Errorf("account id: %v doesn't belong to current user", toAccount.ID)))
+		return
+	}
+
+	var amount int64
+	if reverseDto.Amount != "" {
+		fromAccount, err := server.checkAccountExist(ctx, transfer.FromAccountID)
+		if err != nil {
+			return
+		}
+
+		var ok bool
+		amount, ok = parseAmount(ctx, reverseDto.Amount, fromAccount.Currency)
+		if !ok {
+			return
+		}
+	}
+
+	idempotency, err := idempotencyParams(ctx, authPayload.Username, gin.H{"transferID": transfer.ID, "amount": reverseDto.Amount})
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	arg := db.ReverseTransferTxParams{
+		TransferID:  transfer.ID,
+		Amount:      amount,
+		Idempotency: idempotency,
+	}
+
+	result, err := server.store.ReverseTransferTx(ctx, arg)
+	if err != nil {
+		ctx.JSON(transferErrorStatus(err), errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, newTransferTxResponse(result))
+}
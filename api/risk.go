@@ -0,0 +1,231 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	db "github.com/crackz/simple-bank/db/sqlc"
+	"github.com/crackz/simple-bank/money"
+	"github.com/crackz/simple-bank/risk"
+	"github.com/crackz/simple-bank/screening"
+	"github.com/crackz/simple-bank/token"
+	"github.com/gin-gonic/gin"
+)
+
+type heldTransferResponse struct {
+	RiskDecisionID int64  `json:"riskDecisionID"`
+	Status         string `json:"status"`
+}
+
+// screenTransfer runs the risk rules on a transfer about to be made. An allowed
+// transfer carries the decision on, to be recorded with it. A transfer held for
+// review or blocked is recorded and answered here, and ok is false.
+func (server *Server) screenTransfer(ctx *gin.Context, owner string, arg *db.TransferTxParams) (ok bool) {
+	return server.answerScreening(ctx, owner, arg, true)
+}
+
+// screenImmediateTransfer screens like screenTransfer a transfer that can't wait
+// for a review because it's part of a larger request, like a batch leg, an
+// exchange or a hold. Such a transfer is blocked instead of held for review.
+func (server *Server) screenImmediateTransfer(ctx *gin.Context, owner string, arg *db.TransferTxParams) (ok bool) {
+	return server.answerScreening(ctx, owner, arg, false)
+}
+
+func (server *Server) answerScreening(ctx *gin.Context, owner string, arg *db.TransferTxParams, reviewable bool) (ok bool) {
+	result, err := server.screen(ctx, owner, arg, reviewable)
+	if err != nil {
+		// a retry of a held transfer with a different request conflicts
+		ctx.JSON(transferErrorStatus(err), errorResponse(err))
+		return false
+	}
+
+	if result.Allowed() {
+		return true
+	}
+	setAuditAfter(ctx, result.RiskDecision)
+
+	// the triggered rules are only shown to staff
+	if result.Outcome == risk.Block {
+		ctx.JSON(http.StatusForbidden, errorResponse(db.ErrTransferBlocked))
+		return false
+	}
+
+	ctx.JSON(http.StatusAccepted, heldTransferResponse{
+		RiskDecisionID: result.RiskDecision.ID,
+		Status:         result.RiskDecision.Status,
+	})
+	return false
+}
+
+// screen screens a transfer with the server's rules without answering the
+// request.
+func (server *Server) screen(ctx context.Context, owner string, arg *db.TransferTxParams, reviewable bool) (screening.Result, error) {
+	return screening.Screen(ctx, server.store, db.CheckTransferRiskParams{
+		Rules:      server.riskRules,
+		Owner:      owner,
+		Transfer:   arg,
+		Reviewable: reviewable,
+	})
+}
+
+type riskDecisionResponse struct {
+	ID            int64       `json:"id"`
+	Owner         string      `json:"owner"`
+	FromAccountID int64       `json:"fromAccountID"`
+	ToAccountID   int64       `json:"toAccountID"`
+	Amount        money.Money `json:"amount"`
+	Outcome       string      `json:"outcome"`
+	Rules         []string    `json:"rules"`
+	Status        string      `json:"status"`
+	// TransferID is only set once the transfer is made.
+	TransferID *int64 `json:"transferID,omitempty"`
+	// ReviewedBy and ReviewedAt are only set once the decision is reviewed.
+	ReviewedBy *string    `json:"reviewedBy,omitempty"`
+	ReviewedAt *time.Time `json:"reviewedAt,omitempty"`
+	CreatedAt  time.Time  `json:"createdAt"`
+}
+
+// newRiskDecisionResponse takes the currency of the source account.
+func newRiskDecisionResponse(decision db.RiskDecision, currency string) riskDecisionResponse {
+	return riskDecisionResponse{
+		ID:            decision.ID,
+		Owner:         decision.Owner,
+		FromAccountID: decision.FromAccountID,
+		ToAccountID:   decision.ToAccountID,
+		Amount:        money.New(decision.Amount, currency),
+		Outcome:       decision.Outcome,
+		Rules:         decision.Rules,
+		Status:        decision.Status,
+		TransferID:    int64Pointer(decision.TransferID),
+		ReviewedBy:    stringPointer(decision.ReviewedBy),
+		ReviewedAt:    timePointer(decision.ReviewedAt),
+		CreatedAt:     decision.CreatedAt,
+	}
+}
+
+type riskReviewResponse struct {
+	RiskDecision riskDecisionResponse `json:"riskDecision"`
+	// Transfer is only set when the transfer was approved.
+	Transfer *transferTxResponse `json:"transfer,omitempty"`
+}
+
+type getRiskReviewsQuery struct {
+	Status string `form:"status" binding:"omitempty,oneof=allowed pending approved rejected blocked"`
+	Page   int32  `form:"page" binding:"min=1"`
+	Limit  int32  `form:"limit" binding:"min=1,max=100"`
+}
+
+// getRiskReviews lists the transfers held for review, oldest first. Decisions
+// with another status can be listed with the status query parameter.
+func (server *Server) getRiskReviews(ctx *gin.Context) {
+	var query getRiskReviewsQuery
+
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if query.Status == "" {
+		query.Status = db.RiskPending
+	}
+	if query.Page == 0 {
+		query.Page = 1
+	}
+	if query.Limit == 0 {
+		query.Limit = 10
+	}
+
+	decisions, err := server.store.ListRiskDecisionsByStatus(ctx, db.ListRiskDecisionsByStatusParams{
+		Status: query.Status,
+		Limit:  query.Limit,
+		Offset: (query.Page - 1) * query.Limit,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	currencies := make(map[int64]string)
+	response := make([]riskDecisionResponse, 0, len(decisions))
+	for _, decision := range decisions {
+		currency, ok := currencies[decision.FromAccountID]
+		if !ok {
+			account, err := server.store.GetAccount(ctx, decision.FromAccountID)
+			if err != nil {
+				ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+				return
+			}
+			currency = account.Currency
+			currencies[account.ID] = currency
+		}
+
+		response = append(response, newRiskDecisionResponse(decision, currency))
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+type riskReviewParam struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+// approveRiskReview makes a transfer held for review.
+func (server *Server) approveRiskReview(ctx *gin.Context) {
+	server.reviewRiskDecision(ctx, true)
+}
+
+func (server *Server) rejectRiskReview(ctx *gin.Context) {
+	server.reviewRiskDecision(ctx, false)
+}
+
+func (server *Server) reviewRiskDecision(ctx *gin.Context, approve bool) {
+	var params riskReviewParam
+
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	setAuditTarget(ctx, auditTargetRiskDecision, params.ID)
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	result, err := server.store.ReviewRiskDecisionTx(ctx, db.ReviewRiskDecisionTxParams{
+		ID:       params.ID,
+		Approve:  approve,
+		Reviewer: authPayload.Username,
+	})
+	if err != nil {
+		ctx.JSON(riskErrorStatus(err), errorResponse(err))
+		return
+	}
+	setAuditAfter(ctx, result)
+
+	var response riskReviewResponse
+	if result.Transfer != nil {
+		transfer := newTransferTxResponse(*result.Transfer)
+		response.Transfer = &transfer
+		response.RiskDecision = newRiskDecisionResponse(result.RiskDecision, result.Transfer.FromAccount.Currency)
+	} else {
+		account, err := server.store.GetAccount(ctx, result.RiskDecision.FromAccountID)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+			return
+		}
+		response.RiskDecision = newRiskDecisionResponse(result.RiskDecision, account.Currency)
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+func riskErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, db.ErrRiskDecisionNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, db.ErrRiskDecisionNotPending):
+		return http.StatusConflict
+	}
+
+	return transferErrorStatus(err)
+}
@@ -0,0 +1,463 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	mockdb "github.com/crackz/simple-bank/db/mock"
+	db "github.com/crackz/simple-bank/db/sqlc"
+	"github.com/crackz/simple-bank/money"
+	"github.com/crackz/simple-bank/risk"
+	"github.com/crackz/simple-bank/util"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateTransferRiskScreeningAPI(t *testing.T) {
+	amount := int64(100)
+
+	user1, _ := randomInMemoryUser(t)
+	user2, _ := randomInMemoryUser(t)
+
+	account1 := randomInMemoryAccount(user1.Username)
+	account2 := randomInMemoryAccount(user2.Username)
+	account1.Currency = util.USD
+	account2.Currency = util.USD
+	account2.ID = account1.ID + 1
+
+	rules := risk.Rules{
+		VelocityCount:   3,
+		VelocityWindow:  10 * time.Minute,
+		VelocityOutcome: risk.Review,
+	}
+	transferArg := db.TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        amount,
+	}
+	checkArg := db.CheckTransferRiskParams{
+		Rules:      rules,
+		Owner:      user1.Username,
+		Transfer:   &transferArg,
+		Reviewable: true,
+	}
+
+	testCases := []struct {
+		name           string
+		idempotencyKey string
+		buildStubs     func(store *mockdb.MockStore)
+		checkResponse  func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "Allowed",
+			buildStubs: func(store *mockdb.MockStore) {
+				decision := risk.Decision{Outcome: risk.Allow, Rules: []string{}}
+
+				store.EXPECT().
+					CheckTransferRisk(gomock.Any(), gomock.Eq(checkArg)).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.CheckTransferRiskParams) (risk.Outcome, db.RiskDecision, error) {
+						arg.Transfer.Risk = &decision
+						return risk.Allow, db.RiskDecision{}, nil
+					})
+
+				arg := transferArg
+				arg.Risk = &decision
+				store.EXPECT().
+					TransferTx(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(db.TransferTxResult{FromAccount: account1, ToAccount: account2}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusCreated, recorder.Code)
+			},
+		},
+		{
+			name: "HeldForReview",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CheckTransferRisk(gomock.Any(), gomock.Eq(checkArg)).
+					Times(1).
+					Return(risk.Review, db.RiskDecision{ID: 7, Status: db.RiskPending}, nil)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusAccepted, recorder.Code)
+
+				var got heldTransferResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &got))
+				require.Equal(t, heldTransferResponse{RiskDecisionID: 7, Status: db.RiskPending}, got)
+			},
+		},
+		{
+			name: "Blocked",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CheckTransferRisk(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(risk.Block, db.RiskDecision{ID: 8, Status: db.RiskBlocked, Rules: []string{risk.RuleVelocity}}, nil)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+				require.NotContains(t, recorder.Body.String(), risk.RuleVelocity)
+			},
+		},
+		{
+			name: "ScreeningError",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CheckTransferRisk(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(risk.Outcome(""), db.RiskDecision{}, sql.ErrConnDone)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+		{
+			name:           "IdempotencyKeyIsScreened",
+			idempotencyKey: "transfer-key",
+			buildStubs: func(store *mockdb.MockStore) {
+				// the store tells a replay by its key, so the key is passed on
+				store.EXPECT().
+					CheckTransferRisk(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.CheckTransferRiskParams) (risk.Outcome, db.RiskDecision, error) {
+						require.NotNil(t, arg.Transfer.Idempotency)
+						require.Equal(t, user1.Username, arg.Transfer.Idempotency.Owner)
+						require.Equal(t, "transfer-key", arg.Transfer.Idempotency.Key)
+						return risk.Allow, db.RiskDecision{}, nil
+					})
+				store.EXPECT().
+					TransferTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.TransferTxResult{FromAccount: account1, ToAccount: account2}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusCreated, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+			store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			server.riskRules = rules
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(gin.H{
+				"fromAccountID": account1.ID,
+				"toAccountID":   account2.ID,
+				"amount":        "1.00",
+				"currency":      util.USD,
+			})
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/transfers", bytes.NewReader(data))
+			require.NoError(t, err)
+			if tc.idempotencyKey != "" {
+				request.Header.Set(idempotencyKeyHeaderName, tc.idempotencyKey)
+			}
+
+			addAuthorizationHeader(t, request, server.tokenMaker, authorizationTypeBearer, user1.Username, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestCreateBatchTransferRiskScreeningAPI(t *testing.T) {
+	user1, _ := randomInMemoryUser(t)
+	user2, _ := randomInMemoryUser(t)
+
+	fromAccount := randomInMemoryAccount(user1.Username)
+	toAccount1 := randomInMemoryAccount(user2.Username)
+	toAccount2 := randomInMemoryAccount(user2.Username)
+	fromAccount.Currency = util.USD
+	toAccount1.Currency = util.USD
+	toAccount2.Currency = util.USD
+	toAccount1.ID = fromAccount.ID + 1
+	toAccount2.ID = fromAccount.ID + 2
+
+	rules := risk.Rules{
+		NewPayeeAmount:  10,
+		NewPayeeOutcome: risk.Review,
+	}
+	allowed := risk.Decision{Outcome: risk.Allow, Rules: []string{}}
+	allow := func(_ context.Context, arg db.CheckTransferRiskParams) (risk.Outcome, db.RiskDecision, error) {
+		// batch legs can't wait for a review
+		require.False(t, arg.Reviewable)
+		arg.Transfer.Risk = &allowed
+		return risk.Allow, db.RiskDecision{}, nil
+	}
+
+	testCases := []struct {
+		name          string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "AllLegsAllowed",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CheckTransferRisk(gomock.Any(), gomock.Any()).
+					Times(2).
+					DoAndReturn(allow)
+
+				arg := db.BatchTransferTxParams{
+					Transfers: []db.TransferTxParams{
+						{FromAccountID: fromAccount.ID, ToAccountID: toAccount1.ID, Amount: 10, Risk: &allowed},
+						{FromAccountID: fromAccount.ID, ToAccountID: toAccount2.ID, Amount: 20, Risk: &allowed},
+					},
+				}
+				store.EXPECT().
+					BatchTransferTx(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(db.BatchTransferTxResult{}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusCreated, recorder.Code)
+			},
+		},
+		{
+			name: "LegBlocked",
+			buildStubs: func(store *mockdb.MockStore) {
+				gomock.InOrder(
+					store.EXPECT().
+						CheckTransferRisk(gomock.Any(), gomock.Any()).
+						Times(1).
+						DoAndReturn(allow),
+					store.EXPECT().
+						CheckTransferRisk(gomock.Any(), gomock.Any()).
+						Times(1).
+						Return(risk.Block, db.RiskDecision{ID: 9, Status: db.RiskBlocked}, nil),
+				)
+				store.EXPECT().BatchTransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+			store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(toAccount1.ID)).Times(1).Return(toAccount1, nil)
+			store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(toAccount2.ID)).Times(1).Return(toAccount2, nil)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			server.riskRules = rules
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(gin.H{
+				"fromAccountID": fromAccount.ID,
+				"currency":      util.USD,
+				"transfers": []gin.H{
+					{"toAccountID": toAccount1.ID, "amount": "0.10"},
+					{"toAccountID": toAccount2.ID, "amount": "0.20"},
+				},
+			})
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/transfers/batch", bytes.NewReader(data))
+			require.NoError(t, err)
+
+			addAuthorizationHeader(t, request, server.tokenMaker, authorizationTypeBearer, user1.Username, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestReviewRiskDecisionAPI(t *testing.T) {
+	admin, _ := randomInMemoryUser(t)
+	admin.Role = util.AdminRole
+
+	decisionID := util.RandomInt(1, 1000)
+	account1 := randomInMemoryAccount(admin.Username)
+	account2 := randomInMemoryAccount(admin.Username)
+
+	testCases := []struct {
+		name          string
+		action        string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:   "Approve",
+			action: "approve",
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.ReviewRiskDecisionTxParams{ID: decisionID, Approve: true, Reviewer: admin.Username}
+				store.EXPECT().
+					ReviewRiskDecisionTx(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(db.ReviewRiskDecisionTxResult{
+						RiskDecision: db.RiskDecision{ID: decisionID, Status: db.RiskApproved},
+						Transfer:     &db.TransferTxResult{FromAccount: account1, ToAccount: account2},
+					}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:   "Reject",
+			action: "reject",
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.ReviewRiskDecisionTxParams{ID: decisionID, Approve: false, Reviewer: admin.Username}
+				store.EXPECT().
+					ReviewRiskDecisionTx(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(db.ReviewRiskDecisionTxResult{
+						RiskDecision: db.RiskDecision{ID: decisionID, FromAccountID: account1.ID, Amount: 150, Status: db.RiskRejected},
+					}, nil)
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(account1.ID)).
+					Times(1).
+					Return(account1, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var response riskReviewResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+				require.Equal(t, money.New(150, account1.Currency), response.RiskDecision.Amount)
+				require.Nil(t, response.RiskDecision.TransferID)
+				require.Nil(t, response.Transfer)
+				require.NotContains(t, recorder.Body.String(), "requestHash")
+			},
+		},
+		{
+			name:   "NotFound",
+			action: "approve",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ReviewRiskDecisionTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.ReviewRiskDecisionTxResult{}, db.ErrRiskDecisionNotFound)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:   "AlreadyReviewed",
+			action: "reject",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ReviewRiskDecisionTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.ReviewRiskDecisionTxResult{}, db.ErrRiskDecisionNotPending)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+		{
+			name:   "InsufficientFunds",
+			action: "approve",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ReviewRiskDecisionTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.ReviewRiskDecisionTxResult{}, db.ErrInsufficientFunds)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			store.EXPECT().GetUser(gomock.Any(), gomock.Eq(admin.Username)).Times(1).Return(admin, nil)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/admin/risk-reviews/%d/%s", decisionID, tc.action)
+			request, err := http.NewRequest(http.MethodPost, url, nil)
+			require.NoError(t, err)
+
+			addAuthorizationHeader(t, request, server.tokenMaker, authorizationTypeBearer, admin.Username, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestGetRiskReviewsAPI(t *testing.T) {
+	admin, _ := randomInMemoryUser(t)
+	admin.Role = util.AdminRole
+	account := randomInMemoryAccount(admin.Username)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().GetUser(gomock.Any(), gomock.Eq(admin.Username)).Times(1).Return(admin, nil)
+	store.EXPECT().
+		ListRiskDecisionsByStatus(gomock.Any(), gomock.Eq(db.ListRiskDecisionsByStatusParams{
+			Status: db.RiskPending,
+			Limit:  5,
+			Offset: 5,
+		})).
+		Times(1).
+		Return([]db.RiskDecision{
+			{ID: 1, FromAccountID: account.ID, Amount: 250, Status: db.RiskPending},
+			{ID: 2, FromAccountID: account.ID, Amount: 300, Status: db.RiskPending},
+		}, nil)
+	store.EXPECT().
+		GetAccount(gomock.Any(), gomock.Eq(account.ID)).
+		Times(1).
+		Return(account, nil)
+
+	server := newTestServer(t, store)
+	recorder := httptest.NewRecorder()
+
+	request, err := http.NewRequest(http.MethodGet, "/admin/risk-reviews?page=2&limit=5", nil)
+	require.NoError(t, err)
+
+	addAuthorizationHeader(t, request, server.tokenMaker, authorizationTypeBearer, admin.Username, time.Minute)
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.JSONEq(t, fmt.Sprintf(`[
+		{"id":1,"owner":"","fromAccountID":%[1]d,"toAccountID":0,"amount":{"amount":"2.50","currency":%[2]q},"outcome":"","rules":null,"status":"pending","createdAt":"0001-01-01T00:00:00Z"},
+		{"id":2,"owner":"","fromAccountID":%[1]d,"toAccountID":0,"amount":{"amount":"3.00","currency":%[2]q},"outcome":"","rules":null,"status":"pending","createdAt":"0001-01-01T00:00:00Z"}
+	]`, account.ID, account.Currency), recorder.Body.String())
+}
@@ -0,0 +1,343 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"time"
+
+	db "github.com/crackz/simple-bank/db/sqlc"
+	"github.com/crackz/simple-bank/money"
+	"github.com/crackz/simple-bank/token"
+	"github.com/gin-gonic/gin"
+)
+
+type createScheduledTransferDto struct {
+	ToAccountID   int64     `json:"toAccountID" binding:"required,min=1"`
+	FromAccountID int64     `json:"fromAccountID" binding:"required,min=1"`
+	Amount        string    `json:"amount" binding:"required,amount=Currency"`
+	Currency      string    `json:"currency" binding:"required,currency"`
+	Frequency     string    `json:"frequency" binding:"required,oneof=once daily weekly monthly"`
+	StartAt       time.Time `json:"startAt" binding:"required"`
+}
+
+type scheduledTransferResponse struct {
+	ID            int64       `json:"id"`
+	Owner         string      `json:"owner"`
+	FromAccountID int64       `json:"fromAccountID"`
+	ToAccountID   int64       `json:"toAccountID"`
+	Amount        money.Money `json:"amount"`
+	Frequency     string      `json:"frequency"`
+	StartAt       time.Time   `json:"startAt"`
+	NextRunAt     time.Time   `json:"nextRunAt"`
+	Active        bool        `json:"active"`
+	CreatedAt     time.Time   `json:"createdAt"`
+}
+
+// newScheduledTransferResponse takes the currency of the source account.
+func newScheduledTransferResponse(scheduledTransfer db.ScheduledTransfer, currency string) scheduledTransferResponse {
+	return scheduledTransferResponse{
+		ID:            scheduledTransfer.ID,
+		Owner:         scheduledTransfer.Owner,
+		FromAccountID: scheduledTransfer.FromAccountID,
+		ToAccountID:   scheduledTransfer.ToAccountID,
+		Amount:        money.New(scheduledTransfer.Amount, currency),
+		Frequency:     scheduledTransfer.Frequency,
+		StartAt:       scheduledTransfer.StartAt,
+		NextRunAt:     scheduledTransfer.NextRunAt,
+		Active:        scheduledTransfer.Active,
+		CreatedAt:     scheduledTransfer.CreatedAt,
+	}
+}
+
+type scheduledTransferRunResponse struct {
+	ID                  int64     `json:"id"`
+	ScheduledTransferID int64     `json:"scheduledTransferID"`
+	ScheduledFor        time.Time `json:"scheduledFor"`
+	// TransferID is only set when the run succeeded.
+	TransferID *int64 `json:"transferID,omitempty"`
+	Status     string `json:"status"`
+	// Error is only set when the run failed.
+	Error     *string   `json:"error,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+func newScheduledTransferRunResponse(run db.ScheduledTransferRun) scheduledTransferRunResponse {
+	return scheduledTransferRunResponse{
+		ID:                  run.ID,
+		ScheduledTransferID: run.ScheduledTransferID,
+		ScheduledFor:        run.ScheduledFor,
+		TransferID:          int64Pointer(run.TransferID),
+		Status:              run.Status,
+		Error:               stringPointer(run.Error),
+		CreatedAt:           run.CreatedAt,
+	}
+}
+
+func (server *Server) createScheduledTransfer(ctx *gin.Context) {
+	var createDto createScheduledTransferDto
+
+	if err := ctx.ShouldBindJSON(&createDto); err != nil {
+		ctx.JSON(http.StatusUnprocessableEntity, errorResponse(err))
+		return
+	}
+
+	fromAccount, toAccount, ok := server.checkTransferAccounts(ctx, createDto.FromAccountID, createDto.ToAccountID, createDto.Currency)
+	if !ok {
+		return
+	}
+
+	amount, ok := parseAmount(ctx, createDto.Amount, fromAccount.Currency)
+	if !ok {
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	arg := db.CreateScheduledTransferParams{
+		Owner:         authPayload.Username,
+		FromAccountID: fromAccount.ID,
+		ToAccountID:   toAccount.ID,
+		Amount:        amount,
+		Frequency:     createDto.Frequency,
+		StartAt:       createDto.StartAt,
+	}
+
+	scheduledTransfer, err := server.store.CreateScheduledTransfer(ctx, arg)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, newScheduledTransferResponse(scheduledTransfer, fromAccount.Currency))
+}
+
+type getScheduledTransfersQuery struct {
+	Page  int32 `form:"page" binding:"min=1"`
+	Limit int32 `form:"limit" binding:"min=1,max=100"`
+}
+
+func (server *Server) getScheduledTransfers(ctx *gin.Context) {
+	var query getScheduledTransfersQuery
+
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if query.Page == 0 {
+		query.Page = 1
+	}
+	if query.Limit == 0 {
+		query.Limit = 10
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	arg := db.ListScheduledTransfersParams{
+		Owner:  authPayload.Username,
+		Limit:  query.Limit,
+		Offset: (query.Page - 1) * query.Limit,
+	}
+
+	scheduledTransfers, err := server.store.ListScheduledTransfers(ctx, arg)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	// the scheduled transfers of a user are usually made from few accounts
+	currencies := make(map[int64]string)
+	response := make([]scheduledTransferResponse, 0, len(scheduledTransfers))
+	for _, scheduledTransfer := range scheduledTransfers {
+		currency, ok := currencies[scheduledTransfer.FromAccountID]
+		if !ok {
+			account, err := server.store.GetAccount(ctx, scheduledTransfer.FromAccountID)
+			if err != nil {
+				ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+				return
+			}
+			currency = account.Currency
+			currencies[account.ID] = currency
+		}
+
+		response = append(response, newScheduledTransferResponse(scheduledTransfer, currency))
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+type scheduledTransferParam struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+func (server *Server) getScheduledTransfer(ctx *gin.Context) {
+	var params scheduledTransferParam
+
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	scheduledTransfer, fromAccount, ok := server.checkScheduledTransferOwner(ctx, params.ID)
+	if !ok {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newScheduledTransferResponse(scheduledTransfer, fromAccount.Currency))
+}
+
+type updateScheduledTransferDto struct {
+	// Amount is a decimal amount in the currency of the source account.
+	Amount    *string    `json:"amount" binding:"omitempty,amount"`
+	Frequency *string    `json:"frequency" binding:"omitempty,oneof=once daily weekly monthly"`
+	StartAt   *time.Time `json:"startAt"`
+	Active    *bool      `json:"active"`
+}
+
+func (server *Server) updateScheduledTransfer(ctx *gin.Context) {
+	var params scheduledTransferParam
+	var dto updateScheduledTransferDto
+
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	if err := ctx.ShouldBindJSON(&dto); err != nil {
+		ctx.JSON(http.StatusUnprocessableEntity, errorResponse(err))
+		return
+	}
+
+	scheduledTransfer, fromAccount, ok := server.checkScheduledTransferOwner(ctx, params.ID)
+	if !ok {
+		return
+	}
+
+	arg := db.UpdateScheduledTransferParams{ID: scheduledTransfer.ID}
+	if dto.Amount != nil {
+		amount, ok := parseAmount(ctx, *dto.Amount, fromAccount.Currency)
+		if !ok {
+			return
+		}
+		arg.Amount = sql.NullInt64{Int64: amount, Valid: true}
+	}
+	if dto.Frequency != nil {
+		arg.Frequency = sql.NullString{String: *dto.Frequency, Valid: true}
+	}
+	if dto.StartAt != nil {
+		arg.StartAt = sql.NullTime{Time: *dto.StartAt, Valid: true}
+	}
+	if dto.Active != nil {
+		arg.Active = sql.NullBool{Bool: *dto.Active, Valid: true}
+	}
+
+	updatedScheduledTransfer, err := server.store.UpdateScheduledTransferTx(ctx, arg)
+	if err != nil {
+		if errors.Is(err, db.ErrScheduledTransferCompleted) {
+			ctx.JSON(http.StatusConflict, errorResponse(err))
+		} else {
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newScheduledTransferResponse(updatedScheduledTransfer, fromAccount.Currency))
+}
+
+func (server *Server) deleteScheduledTransfer(ctx *gin.Context) {
+	var params scheduledTransferParam
+
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	scheduledTransfer, fromAccount, ok := server.checkScheduledTransferOwner(ctx, params.ID)
+	if !ok {
+		return
+	}
+
+	err := server.store.DeleteScheduledTransfer(ctx, scheduledTransfer.ID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newScheduledTransferResponse(scheduledTransfer, fromAccount.Currency))
+}
+
+type getScheduledTransferRunsQuery struct {
+	Page  int32 `form:"page" binding:"min=1"`
+	Limit int32 `form:"limit" binding:"min=1,max=100"`
+}
+
+func (server *Server) getScheduledTransferRuns(ctx *gin.Context) {
+	var params scheduledTransferParam
+	var query getScheduledTransferRunsQuery
+
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if query.Page == 0 {
+		query.Page = 1
+	}
+	if query.Limit == 0 {
+		query.Limit = 10
+	}
+
+	scheduledTransfer, _, ok := server.checkScheduledTransferOwner(ctx, params.ID)
+	if !ok {
+		return
+	}
+
+	runs, err := server.store.ListScheduledTransferRuns(ctx, db.ListScheduledTransferRunsParams{
+		ScheduledTransferID: scheduledTransfer.ID,
+		Limit:               query.Limit,
+		Offset:              (query.Page - 1) * query.Limit,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	response := make([]scheduledTransferRunResponse, 0, len(runs))
+	for _, run := range runs {
+		response = append(response, newScheduledTransferRunResponse(run))
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+// checkScheduledTransferOwner loads a scheduled transfer of the current user
+// with its source account, whose currency its amount is in. The error response
+// is already written when ok is false.
+func (server *Server) checkScheduledTransferOwner(ctx *gin.Context, id int64) (scheduledTransfer db.ScheduledTransfer, fromAccount db.Account, ok bool) {
+	scheduledTransfer, err := server.store.GetScheduledTransfer(ctx, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(errors.New("scheduled transfer not found")))
+		} else {
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		}
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if scheduledTransfer.Owner != authPayload.Username {
+		ctx.JSON(http.StatusForbidden, errorResponse(errors.New("you are not allowed to access this scheduled transfer")))
+		return
+	}
+
+	fromAccount, err = server.store.GetAccount(ctx, scheduledTransfer.FromAccountID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	return scheduledTransfer, fromAccount, true
+}
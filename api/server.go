@@ -1,38 +1,68 @@
 package api
 
 import (
+	"context"
+	"expvar"
 	"fmt"
+	"net"
 
+	"github.com/crackz/simple-bank/audit"
+	"github.com/crackz/simple-bank/currency"
 	db "github.com/crackz/simple-bank/db/sqlc"
+	"github.com/crackz/simple-bank/export"
+	"github.com/crackz/simple-bank/fx"
+	"github.com/crackz/simple-bank/pagination"
+	"github.com/crackz/simple-bank/risk"
 	"github.com/crackz/simple-bank/token"
 	"github.com/crackz/simple-bank/util"
+	"github.com/crackz/simple-bank/webhook"
 	"github.com/gin-gonic/gin"
-	"github.com/gin-gonic/gin/binding"
-	"github.com/go-playground/validator/v10"
 )
 
 type Server struct {
-	config     *util.Config
-	store      db.Store
-	router     *gin.Engine
-	tokenMaker token.Maker
+	config       *util.Config
+	store        db.Store
+	router       *gin.Engine
+	tokenMaker   token.Maker
+	rateProvider fx.RateProvider
+	riskRules    risk.Rules
+	// auditRecorder is the store outside of tests
+	auditRecorder audit.Recorder
+	// currencies is the registry of the process, shared with the gRPC server
+	currencies *currency.Registry
+	cursors    pagination.Signer
+	// resolver resolves the hosts of webhook URLs, which must be public
+	resolver webhook.Resolver
 }
 
-func NewServer(config *util.Config, store db.Store) (*Server, error) {
+func NewServer(config *util.Config, store db.Store, currencies *currency.Registry) (*Server, error) {
 	tokenMaker, err := token.NewJWTMaker(config.JwtSecret)
 	if err != nil {
 		return nil, fmt.Errorf("couldn't create token maker: %w", err)
 	}
 
-	server := &Server{
-		config:     config,
-		store:      store,
-		tokenMaker: tokenMaker,
+	rateProvider, err := newRateProvider(config)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't create rate provider: %w", err)
 	}
-	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
-		v.RegisterValidation("currency", validateCurrency)
+
+	riskRules, err := risk.NewRules(config)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't load risk rules: %w", err)
 	}
 
+	server := &Server{
+		config:        config,
+		store:         store,
+		tokenMaker:    tokenMaker,
+		rateProvider:  rateProvider,
+		riskRules:     riskRules,
+		auditRecorder: store,
+		currencies:    currencies,
+		cursors:       pagination.NewSigner(config.JwtSecret),
+		resolver:      net.DefaultResolver,
+	}
+	registerValidations(currencies)
 	server.setupRouter()
 
 	return server, nil
@@ -40,6 +70,10 @@ func NewServer(config *util.Config, store db.Store) (*Server, error) {
 
 func (server *Server) setupRouter() {
 	router := gin.Default()
+	// handlers pass their *gin.Context to the store, which needs the audit
+	// entry the request's context carries
+	router.ContextWithFallback = true
+	router.Use(server.auditMiddleware())
 
 	// User Endpoints
 	router.POST("/users/register", server.registerUser)
@@ -47,20 +81,98 @@ func (server *Server) setupRouter() {
 
 	authRoutes := router.Group("/").Use(authMiddleware(server.tokenMaker))
 
+	// Currency Endpoints
+	authRoutes.GET("/currencies", server.getCurrencies)
+
 	// Accounts Endpoints
 	authRoutes.GET("/accounts", server.getAccounts)
 	authRoutes.POST("/accounts", server.createAccount)
 	authRoutes.GET("/accounts/:accountID", server.getAccount)
-	authRoutes.PATCH("/accounts/:accountID", server.updateAccount)
-	authRoutes.DELETE("/accounts/:accountID", server.deleteAccount)
+	authRoutes.POST("/accounts/:accountID/close", server.closeAccount)
+	authRoutes.GET("/accounts/:accountID/statement", server.getAccountStatement)
+	authRoutes.GET("/accounts/:accountID/statement.csv", server.exportAccountStatement(export.CSV))
+	authRoutes.GET("/accounts/:accountID/statement.ofx", server.exportAccountStatement(export.OFX))
+	authRoutes.GET("/accounts/:accountID/statement.xml", server.exportAccountStatement(export.Camt053))
+	authRoutes.GET("/accounts/:accountID/interest", server.getAccountInterest)
+	authRoutes.GET("/accounts/:accountID/entries", server.getAccountEntries)
+	authRoutes.GET("/accounts/:accountID/transfers", server.getAccountTransfers)
 
 	// Transfer Endpoints
+	authRoutes.GET("/transfers", server.getTransfers)
 	authRoutes.POST("/transfers", server.createTransfer)
+	authRoutes.POST("/transfers/batch", server.createBatchTransfer)
+	authRoutes.POST("/transfers/import", server.importPayments)
+	authRoutes.POST("/transfers/preview", server.previewTransferFee)
+	authRoutes.GET("/transfers/limits", server.getTransferLimits)
+	authRoutes.POST("/transfers/exchange", server.createExchangeTransfer)
+	authRoutes.GET("/transfers/:id", server.getTransfer)
+	authRoutes.POST("/transfers/:id/reverse", server.reverseTransfer)
+
+	// Hold Endpoints
+	authRoutes.POST("/holds", server.createHold)
+	authRoutes.GET("/holds/:id", server.getHold)
+	authRoutes.POST("/holds/:id/capture", server.captureHold)
+	authRoutes.POST("/holds/:id/void", server.voidHold)
+
+	// FX Endpoints
+	authRoutes.POST("/fx/quotes", server.createFxQuote)
+
+	// Scheduled Transfer Endpoints
+	authRoutes.GET("/transfers/scheduled", server.getScheduledTransfers)
+	authRoutes.POST("/transfers/scheduled", server.createScheduledTransfer)
+	authRoutes.GET("/transfers/scheduled/:id", server.getScheduledTransfer)
+	authRoutes.PATCH("/transfers/scheduled/:id", server.updateScheduledTransfer)
+	authRoutes.DELETE("/transfers/scheduled/:id", server.deleteScheduledTransfer)
+	authRoutes.GET("/transfers/scheduled/:id/runs", server.getScheduledTransferRuns)
+
+	// Webhook Endpoints
+	authRoutes.GET("/webhooks", server.getWebhooks)
+	authRoutes.POST("/webhooks", server.createWebhook)
+	authRoutes.GET("/webhooks/:id", server.getWebhook)
+	authRoutes.PATCH("/webhooks/:id", server.updateWebhook)
+	authRoutes.DELETE("/webhooks/:id", server.deleteWebhook)
+	authRoutes.GET("/webhooks/:id/deliveries", server.getWebhookDeliveries)
+	authRoutes.GET("/webhooks/:id/attempts", server.getWebhookAttempts)
+
+	adminRoutes := router.Group("/admin").Use(authMiddleware(server.tokenMaker), adminMiddleware(server.store))
+
+	// Admin Endpoints
+	adminRoutes.GET("/reconciliation", server.getReconciliationReport)
+	adminRoutes.POST("/accounts/:accountID/freeze", server.freezeAccount)
+	adminRoutes.POST("/accounts/:accountID/unfreeze", server.unfreezeAccount)
+	adminRoutes.GET("/audit-log", server.getAuditLog)
+	adminRoutes.GET("/interest-plans", server.getInterestPlans)
+	adminRoutes.POST("/interest-plans", server.createInterestPlan)
+	adminRoutes.PUT("/accounts/:accountID/interest-plan", server.assignInterestPlan)
+	adminRoutes.GET("/fee-schedules", server.getFeeSchedules)
+	adminRoutes.POST("/fee-schedules", server.createFeeSchedule)
+	adminRoutes.DELETE("/fee-schedules/:id", server.deactivateFeeSchedule)
+	adminRoutes.GET("/users/:username/transfer-limits", server.getUserTransferLimits)
+	adminRoutes.PUT("/users/:username/transfer-limits/:currency", server.setUserTransferLimit)
+	adminRoutes.GET("/risk-reviews", server.getRiskReviews)
+	adminRoutes.POST("/risk-reviews/:id/approve", server.approveRiskReview)
+	adminRoutes.POST("/risk-reviews/:id/reject", server.rejectRiskReview)
+	adminRoutes.GET("/currencies", server.getAllCurrencies)
+	adminRoutes.POST("/currencies/:code/enable", server.enableCurrency)
+	adminRoutes.POST("/currencies/:code/disable", server.disableCurrency)
+	adminRoutes.GET("/metrics", gin.WrapH(expvar.Handler()))
 
 	server.router = router
 }
 
+func newRateProvider(config *util.Config) (fx.RateProvider, error) {
+	if config.FXRatesFile != "" {
+		return fx.NewFileRateProvider(config.FXRatesFile)
+	}
+
+	return fx.NewStaticRateProvider(fx.DefaultRates)
+}
+
 func (server *Server) Start(address string) error {
+	if err := server.currencies.Load(context.Background()); err != nil {
+		return err
+	}
+
 	return server.router.Run(address)
 }
 
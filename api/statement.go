@@ -0,0 +1,180 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	db "github.com/crackz/simple-bank/db/sqlc"
+	"github.com/crackz/simple-bank/export"
+	"github.com/crackz/simple-bank/money"
+	"github.com/gin-gonic/gin"
+)
+
+const defaultStatementPeriod = 30 * 24 * time.Hour
+
+type statementEntryResponse struct {
+	entryResponse
+	CounterpartyAccountID *int64 `json:"counterpartyAccountID,omitempty"`
+	// Balance is the running balance of the account after the entry.
+	Balance money.Money `json:"balance"`
+}
+
+type accountStatementResponse struct {
+	AccountID      int64                    `json:"accountID"`
+	From           time.Time                `json:"from"`
+	To             time.Time                `json:"to"`
+	OpeningBalance money.Money              `json:"openingBalance"`
+	ClosingBalance money.Money              `json:"closingBalance"`
+	Entries        []statementEntryResponse `json:"entries"`
+}
+
+func newAccountStatementResponse(statement db.AccountStatement, currency string) accountStatementResponse {
+	response := accountStatementResponse{
+		AccountID:      statement.AccountID,
+		From:           statement.From,
+		To:             statement.To,
+		OpeningBalance: money.New(statement.OpeningBalance, currency),
+		ClosingBalance: money.New(statement.ClosingBalance, currency),
+		Entries:        make([]statementEntryResponse, 0, len(statement.Entries)),
+	}
+	for _, entry := range statement.Entries {
+		response.Entries = append(response.Entries, statementEntryResponse{
+			entryResponse:         newEntryResponse(entry.Entry, currency),
+			CounterpartyAccountID: int64Pointer(entry.CounterpartyAccountID),
+			Balance:               money.New(entry.Balance, currency),
+		})
+	}
+
+	return response
+}
+
+type getAccountStatementQuery struct {
+	From *time.Time `form:"from"`
+	To   *time.Time `form:"to"`
+}
+
+// getAccountStatement returns the entries of an account between from and to
+// with the opening, running and closing balances. The period defaults to the
+// last 30 days.
+func (server *Server) getAccountStatement(ctx *gin.Context) {
+	var params getAccountParam
+	var query getAccountStatementQuery
+
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	arg, err := statementParams(params.AccountID, query)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	account, ok := server.checkAccountOwner(ctx, params.AccountID)
+	if !ok {
+		return
+	}
+
+	statement, err := server.store.GetAccountStatement(ctx, arg)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newAccountStatementResponse(statement, account.Currency))
+}
+
+// exportAccountStatement serves the same statement as getAccountStatement as
+// a file in format. The entries are written out as they are read, so the
+// response can't turn into an error once it has started; a failure halfway
+// only cuts the file short.
+func (server *Server) exportAccountStatement(format export.Format) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var params getAccountParam
+		var query getAccountStatementQuery
+
+		if err := ctx.ShouldBindUri(&params); err != nil {
+			ctx.JSON(http.StatusBadRequest, errorResponse(err))
+			return
+		}
+		if err := ctx.ShouldBindQuery(&query); err != nil {
+			ctx.JSON(http.StatusBadRequest, errorResponse(err))
+			return
+		}
+
+		arg, err := statementParams(params.AccountID, query)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, errorResponse(err))
+			return
+		}
+
+		account, ok := server.checkAccountOwner(ctx, params.AccountID)
+		if !ok {
+			return
+		}
+
+		writer, err := export.NewWriter(format, ctx.Writer)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+			return
+		}
+
+		begin := func(statement db.AccountStatement) error {
+			ctx.Header("Content-Type", format.ContentType())
+			ctx.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="statement-%d.%s"`, account.ID, format))
+			ctx.Status(http.StatusOK)
+
+			return writer.WriteHeader(export.Statement{
+				Account:        account,
+				From:           statement.From,
+				To:             statement.To,
+				OpeningBalance: statement.OpeningBalance,
+				ClosingBalance: statement.ClosingBalance,
+				CreatedAt:      time.Now(),
+			})
+		}
+
+		err = server.store.StreamAccountStatement(ctx, arg, begin, writer.WriteEntry)
+		if err == nil {
+			err = writer.Close()
+		}
+		if err != nil {
+			if !ctx.Writer.Written() {
+				ctx.Writer.Header().Del("Content-Type")
+				ctx.Writer.Header().Del("Content-Disposition")
+				ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+				return
+			}
+			log.Printf("Couldn't Export Statement Of Account %d : %s", account.ID, err)
+		}
+	}
+}
+
+func statementParams(accountID int64, query getAccountStatementQuery) (db.AccountStatementParams, error) {
+	arg := db.AccountStatementParams{
+		AccountID: accountID,
+		To:        time.Now(),
+	}
+	if query.To != nil {
+		arg.To = *query.To
+	}
+
+	arg.From = arg.To.Add(-defaultStatementPeriod)
+	if query.From != nil {
+		arg.From = *query.From
+	}
+
+	if !arg.From.Before(arg.To) {
+		return arg, errors.New("from must be before to")
+	}
+
+	return arg, nil
+}
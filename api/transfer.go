@@ -1,10 +1,15 @@
 package api
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
+	"time"
 
 	db "github.com/crackz/simple-bank/db/sqlc"
+	"github.com/crackz/simple-bank/fx"
+	"github.com/crackz/simple-bank/money"
+	"github.com/crackz/simple-bank/pagination"
 	"github.com/crackz/simple-bank/token"
 	"github.com/gin-gonic/gin"
 )
@@ -12,10 +17,99 @@ import (
 type createTransferDto struct {
 	ToAccountID   int64  `json:"toAccountID" binding:"required,min=1"`
 	FromAccountID int64  `json:"fromAccountID" binding:"required,min=1"`
-	Amount        int64  `json:"amount" binding:"required,min=0,gt=0"`
+	Amount        string `json:"amount" binding:"required,amount=Currency"`
 	Currency      string `json:"currency" binding:"required,currency"`
 }
 
+type transferResponse struct {
+	ID            int64       `json:"id"`
+	FromAccountID int64       `json:"fromAccountID"`
+	ToAccountID   int64       `json:"toAccountID"`
+	Amount        money.Money `json:"amount"`
+	// ToAmount is what the destination account was credited, in its currency.
+	ToAmount     money.Money `json:"toAmount"`
+	ExchangeRate string      `json:"exchangeRate"`
+	// ReversalOf is only set on a reversal, to the transfer it reverses.
+	ReversalOf *int64    `json:"reversalOf,omitempty"`
+	CreatedAt  time.Time `json:"createdAt"`
+}
+
+func newTransferResponse(transfer db.Transfer, fromCurrency string, toCurrency string) transferResponse {
+	return transferResponse{
+		ID:            transfer.ID,
+		FromAccountID: transfer.FromAccountID,
+		ToAccountID:   transfer.ToAccountID,
+		Amount:        money.New(transfer.Amount, fromCurrency),
+		ToAmount:      money.New(transfer.ToAmount, toCurrency),
+		ExchangeRate:  transfer.ExchangeRate,
+		ReversalOf:    int64Pointer(transfer.ReversalOf),
+		CreatedAt:     transfer.CreatedAt,
+	}
+}
+
+type entryResponse struct {
+	ID        int64       `json:"id"`
+	AccountID int64       `json:"accountID"`
+	Amount    money.Money `json:"amount"`
+	// TransferID is only missing on entries not made by a transfer.
+	TransferID *int64    `json:"transferID,omitempty"`
+	CreatedAt  time.Time `json:"createdAt"`
+}
+
+func newEntryResponse(entry db.Entry, currency string) entryResponse {
+	return entryResponse{
+		ID:         entry.ID,
+		AccountID:  entry.AccountID,
+		Amount:     money.New(entry.Amount, currency),
+		TransferID: int64Pointer(entry.TransferID),
+		CreatedAt:  entry.CreatedAt,
+	}
+}
+
+type transferFeeResponse struct {
+	FeeTransferID int64       `json:"feeTransferID"`
+	FeeScheduleID int64       `json:"feeScheduleID"`
+	Amount        money.Money `json:"amount"`
+}
+
+type transferTxResponse struct {
+	Transfer    transferResponse     `json:"transfer"`
+	FromAccount accountResponse      `json:"fromAccount"`
+	ToAccount   accountResponse      `json:"toAccount"`
+	FromEntry   entryResponse        `json:"fromEntry"`
+	ToEntry     entryResponse        `json:"toEntry"`
+	Fee         *transferFeeResponse `json:"fee,omitempty"`
+	FeeRefund   *transferResponse    `json:"feeRefund,omitempty"`
+}
+
+func newTransferTxResponse(result db.TransferTxResult) transferTxResponse {
+	fromCurrency := result.FromAccount.Currency
+	toCurrency := result.ToAccount.Currency
+
+	response := transferTxResponse{
+		Transfer:    newTransferResponse(result.Transfer, fromCurrency, toCurrency),
+		FromAccount: newAccountResponse(result.FromAccount),
+		ToAccount:   newAccountResponse(result.ToAccount),
+		FromEntry:   newEntryResponse(result.FromEntry, fromCurrency),
+		ToEntry:     newEntryResponse(result.ToEntry, toCurrency),
+	}
+	if result.Fee != nil {
+		// the fee is paid from the source account
+		response.Fee = &transferFeeResponse{
+			FeeTransferID: result.Fee.FeeTransferID,
+			FeeScheduleID: result.Fee.FeeScheduleID,
+			Amount:        money.New(result.Fee.Amount, fromCurrency),
+		}
+	}
+	if result.FeeRefund != nil {
+		// the fee is refunded to the account the reversal credits
+		feeRefund := newTransferResponse(*result.FeeRefund, toCurrency, toCurrency)
+		response.FeeRefund = &feeRefund
+	}
+
+	return response
+}
+
 func (server *Server) createTransfer(ctx *gin.Context) {
 	var createDto createTransferDto
 
@@ -24,12 +118,134 @@ func (server *Server) createTransfer(ctx *gin.Context) {
 		return
 	}
 
-	fromAccount, err := server.checkAccountExist(ctx, createDto.FromAccountID)
+	setAuditTarget(ctx, auditTargetAccount, createDto.FromAccountID)
+
+	fromAccount, toAccount, ok := server.checkTransferAccounts(ctx, createDto.FromAccountID, createDto.ToAccountID, createDto.Currency)
+	if !ok {
+		return
+	}
+	setAuditBefore(ctx, gin.H{"fromAccount": fromAccount, "toAccount": toAccount})
+
+	amount, ok := parseAmount(ctx, createDto.Amount, fromAccount.Currency)
+	if !ok {
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	idempotency, err := idempotencyParams(ctx, authPayload.Username, createDto)
 	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	arg := db.TransferTxParams{
+		FromAccountID: fromAccount.ID,
+		ToAccountID:   toAccount.ID,
+		Amount:        amount,
+		Idempotency:   idempotency,
+	}
+	if !server.screenTransfer(ctx, authPayload.Username, &arg) {
+		return
+	}
+
+	transfer, err := server.store.TransferTx(ctx, arg)
+	if err != nil {
+		ctx.JSON(transferErrorStatus(err), errorResponse(err))
+		return
+	}
+	setAuditAfter(ctx, transfer)
+
+	ctx.JSON(http.StatusCreated, newTransferTxResponse(transfer))
+}
+
+// checkTransferAccounts loads both accounts of a transfer and checks that the
+// current user owns the source account and that both accounts hold currency.
+// The error response is already written when ok is false.
+func (server *Server) checkTransferAccounts(ctx *gin.Context, fromAccountID int64, toAccountID int64, currency string) (fromAccount db.Account, toAccount db.Account, ok bool) {
+	fromAccount, err := server.checkAccountExist(ctx, fromAccountID)
+	if err != nil {
+		return
+	}
+
+	toAccount, err = server.checkAccountExist(ctx, toAccountID)
+	if err != nil {
+		return
+	}
+
+	if !isValidAccountCurrency(fromAccount, currency) {
+		ctx.JSON(http.StatusBadRequest, errorResponse(fmt.Errorf("from account doesn't support currency: %v", fromAccount.Currency)))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if !isOwner(fromAccount, authPayload.Username) {
+		ctx.JSON(http.StatusForbidden, errorResponse(fmt.Errorf("account id: %v doesn't belong to current user", fromAccountID)))
+		return
+	}
+
+	if !isValidAccountCurrency(toAccount, currency) {
+		ctx.JSON(http.StatusBadRequest, errorResponse(fmt.Errorf("to account doesn't support currency: %v", fromAccount.Currency)))
+		return
+	}
+
+	return fromAccount, toAccount, true
+}
+
+func transferErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, db.ErrAccountNotFound), errors.Is(err, db.ErrTransferNotFound), errors.Is(err, db.ErrHoldNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, db.ErrCurrencyMismatch):
+		return http.StatusBadRequest
+	case errors.Is(err, db.ErrInsufficientFunds), errors.Is(err, db.ErrTransferLimitExceeded):
+		return http.StatusUnprocessableEntity
+	case errors.Is(err, db.ErrIdempotencyKeyConflict):
+		return http.StatusConflict
+	case errors.Is(err, db.ErrQuoteNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, db.ErrQuoteExpired), errors.Is(err, db.ErrQuoteUsed):
+		return http.StatusConflict
+	case errors.Is(err, db.ErrAmountTooLow), errors.Is(err, fx.ErrAmountTooHigh):
+		return http.StatusUnprocessableEntity
+	case errors.Is(err, db.ErrReversalExceedsTransfer), errors.Is(err, db.ErrCannotReverseReversal), errors.Is(err, db.ErrCannotReverseFee):
+		return http.StatusUnprocessableEntity
+	case errors.Is(err, db.ErrHoldNotActive), errors.Is(err, db.ErrHoldExpired):
+		return http.StatusConflict
+	case errors.Is(err, db.ErrCaptureExceedsHold):
+		return http.StatusUnprocessableEntity
+	case errors.Is(err, db.ErrAccountFrozen), errors.Is(err, db.ErrAccountClosed), errors.Is(err, db.ErrInvalidStatusTransition):
+		return http.StatusConflict
+	case errors.Is(err, db.ErrAccountNotEmpty), errors.Is(err, db.ErrAccountHasHolds), errors.Is(err, db.ErrInvalidSweepAccount):
+		return http.StatusUnprocessableEntity
+	}
+
+	return http.StatusInternalServerError
+}
+
+type batchTransferLegDto struct {
+	ToAccountID int64  `json:"toAccountID" binding:"required,min=1"`
+	Amount      string `json:"amount" binding:"required,amount"`
+}
+
+type createBatchTransferDto struct {
+	FromAccountID int64                 `json:"fromAccountID" binding:"required,min=1"`
+	Currency      string                `json:"currency" binding:"required,currency"`
+	Transfers     []batchTransferLegDto `json:"transfers" binding:"required,min=1,max=100,dive"`
+}
+
+type batchTransferResponse struct {
+	Transfers []transferTxResponse `json:"transfers"`
+}
+
+func (server *Server) createBatchTransfer(ctx *gin.Context) {
+	var createDto createBatchTransferDto
+
+	if err := ctx.ShouldBindJSON(&createDto); err != nil {
+		ctx.JSON(http.StatusUnprocessableEntity, errorResponse(err))
 		return
 	}
 
-	toAccount, err := server.checkAccountExist(ctx, createDto.ToAccountID)
+	fromAccount, err := server.checkAccountExist(ctx, createDto.FromAccountID)
 	if err != nil {
 		return
 	}
@@ -45,22 +261,187 @@ func (server *Server) createTransfer(ctx *gin.Context) {
 		return
 	}
 
-	if !isValidAccountCurrency(toAccount, createDto.Currency) {
-		ctx.JSON(http.StatusBadRequest, errorResponse(fmt.Errorf("to account doesn't support currency: %v", fromAccount.Currency)))
+	arg := db.BatchTransferTxParams{
+		Transfers: make([]db.TransferTxParams, 0, len(createDto.Transfers)),
+	}
+	for i, leg := range createDto.Transfers {
+		toAccount, err := server.checkAccountExist(ctx, leg.ToAccountID)
+		if err != nil {
+			return
+		}
+
+		if !isValidAccountCurrency(toAccount, createDto.Currency) {
+			ctx.JSON(http.StatusBadRequest, errorResponse(fmt.Errorf("transfer %d: to account doesn't support currency: %v", i, createDto.Currency)))
+			return
+		}
+
+		amount, ok := parseAmount(ctx, leg.Amount, createDto.Currency)
+		if !ok {
+			return
+		}
+
+		arg.Transfers = append(arg.Transfers, db.TransferTxParams{
+			FromAccountID: fromAccount.ID,
+			ToAccountID:   toAccount.ID,
+			Amount:        amount,
+		})
+	}
+
+	arg.Idempotency, err = idempotencyParams(ctx, authPayload.Username, createDto)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
 		return
 	}
 
-	arg := db.TransferTxParams{
+	for i := range arg.Transfers {
+		// a replayed batch isn't screened again, which is told by its key
+		leg := arg.Transfers[i]
+		leg.Idempotency = arg.Idempotency
+		if !server.screenImmediateTransfer(ctx, authPayload.Username, &leg) {
+			return
+		}
+		arg.Transfers[i].Risk = leg.Risk
+	}
+
+	result, err := server.store.BatchTransferTx(ctx, arg)
+	if err != nil {
+		ctx.JSON(transferErrorStatus(err), errorResponse(err))
+		return
+	}
+
+	response := batchTransferResponse{
+		Transfers: make([]transferTxResponse, 0, len(result.Transfers)),
+	}
+	for _, transfer := range result.Transfers {
+		response.Transfers = append(response.Transfers, newTransferTxResponse(transfer))
+	}
+
+	ctx.JSON(http.StatusCreated, response)
+}
+
+type createExchangeTransferDto struct {
+	FromAccountID int64 `json:"fromAccountID" binding:"required,min=1"`
+	ToAccountID   int64 `json:"toAccountID" binding:"required,min=1"`
+	// Amount is a decimal amount in the source account's currency.
+	Amount  string `json:"amount" binding:"required,amount"`
+	QuoteID int64  `json:"quoteID" binding:"required,min=1"`
+}
+
+// createExchangeTransfer moves money between accounts of different currencies
+// at the rate locked by a quote from POST /fx/quotes.
+func (server *Server) createExchangeTransfer(ctx *gin.Context) {
+	var createDto createExchangeTransferDto
+
+	if err := ctx.ShouldBindJSON(&createDto); err != nil {
+		ctx.JSON(http.StatusUnprocessableEntity, errorResponse(err))
+		return
+	}
+
+	fromAccount, err := server.checkAccountExist(ctx, createDto.FromAccountID)
+	if err != nil {
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if !isOwner(fromAccount, authPayload.Username) {
+		ctx.JSON(http.StatusForbidden, errorResponse(fmt.Errorf("account id: %v doesn't belong to current user", createDto.FromAccountID)))
+		return
+	}
+
+	amount, ok := parseAmount(ctx, createDto.Amount, fromAccount.Currency)
+	if !ok {
+		return
+	}
+
+	arg := db.ExchangeTransferTxParams{
 		FromAccountID: fromAccount.ID,
-		ToAccountID:   toAccount.ID,
-		Amount:        createDto.Amount,
+		ToAccountID:   createDto.ToAccountID,
+		Amount:        amount,
+		QuoteID:       createDto.QuoteID,
+		Owner:         authPayload.Username,
 	}
 
-	transfer, err := server.store.TransferTx(ctx, arg)
+	screenArg := db.TransferTxParams{
+		FromAccountID: arg.FromAccountID,
+		ToAccountID:   arg.ToAccountID,
+		Amount:        arg.Amount,
+	}
+	if !server.screenImmediateTransfer(ctx, authPayload.Username, &screenArg) {
+		return
+	}
+	arg.Risk = screenArg.Risk
+
+	transfer, err := server.store.ExchangeTransferTx(ctx, arg)
+	if err != nil {
+		ctx.JSON(transferErrorStatus(err), errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, newTransferTxResponse(transfer))
+}
+
+// getAccountTransfers lists the transfers from or to one of the current user's
+// accounts, oldest first, a page at a time.
+func (server *Server) getAccountTransfers(ctx *gin.Context) {
+	var params getAccountParam
+	var query pageQuery
+
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	scope := fmt.Sprintf("transfers:%d", params.AccountID)
+	cursor, err := server.cursors.Decode(scope, query.Cursor)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	account, ok := server.checkAccountOwner(ctx, params.AccountID)
+	if !ok {
+		return
+	}
+
+	transfers, err := server.store.ListTransfersAfter(ctx, db.ListTransfersAfterParams{
+		AccountID:      account.ID,
+		AfterCreatedAt: cursor.CreatedAt,
+		AfterID:        cursor.ID,
+		LimitCount:     query.limit() + 1,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	response, err := newPageResponse(server, scope, transfers, query.limit(), transferRowCursor, newTransferRowResponse)
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
 		return
 	}
 
-	ctx.JSON(http.StatusCreated, transfer)
+	ctx.JSON(http.StatusOK, response)
+}
+
+func transferRowCursor(row db.ListTransfersAfterRow) pagination.Cursor {
+	return pagination.Cursor{CreatedAt: row.CreatedAt, ID: row.ID}
+}
+
+func newTransferRowResponse(row db.ListTransfersAfterRow) transferResponse {
+	transfer := db.Transfer{
+		ID:            row.ID,
+		FromAccountID: row.FromAccountID,
+		ToAccountID:   row.ToAccountID,
+		Amount:        row.Amount,
+		CreatedAt:     row.CreatedAt,
+		ToAmount:      row.ToAmount,
+		ExchangeRate:  row.ExchangeRate,
+		ReversalOf:    row.ReversalOf,
+	}
+
+	return newTransferResponse(transfer, row.FromCurrency, row.ToCurrency)
 }
@@ -0,0 +1,139 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	db "github.com/crackz/simple-bank/db/sqlc"
+	"github.com/crackz/simple-bank/money"
+	"github.com/crackz/simple-bank/pagination"
+	"github.com/crackz/simple-bank/token"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	directionIncoming = "incoming"
+	directionOutgoing = "outgoing"
+)
+
+// transferFilter narrows down the transfers of the current user. Amounts are
+// decimals in Currency, compared against whichever side of the transfer is in
+// that currency.
+type transferFilter struct {
+	AccountID int64      `form:"accountID" json:"accountID,omitempty" binding:"omitempty,min=1"`
+	Direction string     `form:"direction" json:"direction,omitempty" binding:"omitempty,oneof=incoming outgoing"`
+	Currency  string     `form:"currency" json:"currency,omitempty" binding:"required_with=MinAmount MaxAmount,omitempty,len=3,uppercase"`
+	MinAmount string     `form:"minAmount" json:"minAmount,omitempty" binding:"omitempty,amount=Currency"`
+	MaxAmount string     `form:"maxAmount" json:"maxAmount,omitempty" binding:"omitempty,amount=Currency"`
+	From      *time.Time `form:"from" json:"from,omitempty"`
+	To        *time.Time `form:"to" json:"to,omitempty"`
+}
+
+type listTransfersQuery struct {
+	pageQuery
+	transferFilter
+}
+
+// getTransfers lists the transfers from or to any of the current user's
+// accounts, oldest first, a page at a time. A transfer between two of the
+// user's accounts is both incoming and outgoing.
+func (server *Server) getTransfers(ctx *gin.Context) {
+	var query listTransfersQuery
+
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	// a cursor is only good for the filters it was made with
+	filter, err := json.Marshal(query.transferFilter)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	scope := "transfers:" + authPayload.Username + ":" + string(filter)
+
+	cursor, err := server.cursors.Decode(scope, query.Cursor)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	arg, err := listUserTransfersParams(authPayload.Username, query.transferFilter)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	arg.AfterCreatedAt = cursor.CreatedAt
+	arg.AfterID = cursor.ID
+	arg.LimitCount = query.limit() + 1
+
+	if query.AccountID != 0 {
+		if _, ok := server.checkAccountOwner(ctx, query.AccountID); !ok {
+			return
+		}
+	}
+
+	transfers, err := server.store.ListUserTransfers(ctx, arg)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	response, err := newPageResponse(server, scope, transfers, query.limit(), userTransferCursor, newUserTransferResponse)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+func listUserTransfersParams(owner string, filter transferFilter) (db.ListUserTransfersParams, error) {
+	arg := db.ListUserTransfersParams{
+		Owner:    owner,
+		Outgoing: filter.Direction != directionIncoming,
+		Incoming: filter.Direction != directionOutgoing,
+	}
+
+	if filter.AccountID != 0 {
+		arg.AccountID = sql.NullInt64{Int64: filter.AccountID, Valid: true}
+	}
+	if filter.Currency != "" {
+		arg.Currency = sql.NullString{String: filter.Currency, Valid: true}
+	}
+	if filter.MinAmount != "" {
+		minAmount, err := money.Parse(filter.MinAmount, filter.Currency)
+		if err != nil {
+			return arg, err
+		}
+		arg.MinAmount = sql.NullInt64{Int64: minAmount.Amount, Valid: true}
+	}
+	if filter.MaxAmount != "" {
+		maxAmount, err := money.Parse(filter.MaxAmount, filter.Currency)
+		if err != nil {
+			return arg, err
+		}
+		arg.MaxAmount = sql.NullInt64{Int64: maxAmount.Amount, Valid: true}
+	}
+	if filter.From != nil {
+		arg.FromTime = sql.NullTime{Time: *filter.From, Valid: true}
+	}
+	if filter.To != nil {
+		arg.ToTime = sql.NullTime{Time: *filter.To, Valid: true}
+	}
+
+	return arg, nil
+}
+
+func userTransferCursor(row db.ListUserTransfersRow) pagination.Cursor {
+	return pagination.Cursor{CreatedAt: row.CreatedAt, ID: row.ID}
+}
+
+func newUserTransferResponse(row db.ListUserTransfersRow) transferResponse {
+	return newTransferRowResponse(db.ListTransfersAfterRow(row))
+}
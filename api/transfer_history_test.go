@@ -0,0 +1,220 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	mockdb "github.com/crackz/simple-bank/db/mock"
+	db "github.com/crackz/simple-bank/db/sqlc"
+	"github.com/crackz/simple-bank/util"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetTransfersAPI(t *testing.T) {
+	user, _ := randomInMemoryUser(t)
+	account := randomInMemoryAccount(user.Username)
+
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+
+	rows := []db.ListUserTransfersRow{
+		{ID: 1, FromAccountID: account.ID, ToAccountID: account.ID + 1, Amount: 1500, ToAmount: 1500, FromCurrency: util.USD, ToCurrency: util.USD},
+		{ID: 2, FromAccountID: account.ID + 1, ToAccountID: account.ID, Amount: 2500, ToAmount: 2500, FromCurrency: util.USD, ToCurrency: util.USD},
+	}
+
+	testCases := []struct {
+		name          string
+		query         url.Values
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:  "OK",
+			query: url.Values{},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListUserTransfers(gomock.Any(), gomock.Eq(db.ListUserTransfersParams{
+						Owner:      user.Username,
+						Outgoing:   true,
+						Incoming:   true,
+						LimitCount: defaultPageLimit + 1,
+					})).
+					Times(1).
+					Return(rows, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var page pageResponse[transferResponse]
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &page))
+				require.False(t, page.HasMore)
+				require.Len(t, page.Items, 2)
+				require.Equal(t, "15.00", page.Items[0].Amount.String())
+			},
+		},
+		{
+			name: "Filters",
+			query: url.Values{
+				"accountID": {"1"},
+				"direction": {"outgoing"},
+				"currency":  {util.USD},
+				"minAmount": {"10"},
+				"maxAmount": {"20.5"},
+				"from":      {from.Format(time.RFC3339)},
+				"to":        {to.Format(time.RFC3339)},
+				"limit":     {"1"},
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				owned := randomInMemoryAccount(user.Username)
+				owned.ID = 1
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(int64(1))).Times(1).Return(owned, nil)
+				store.EXPECT().
+					ListUserTransfers(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ interface{}, arg db.ListUserTransfersParams) ([]db.ListUserTransfersRow, error) {
+						require.True(t, arg.Outgoing)
+						require.False(t, arg.Incoming)
+						require.Equal(t, sql.NullInt64{Int64: 1, Valid: true}, arg.AccountID)
+						require.Equal(t, sql.NullString{String: util.USD, Valid: true}, arg.Currency)
+						require.Equal(t, sql.NullInt64{Int64: 1000, Valid: true}, arg.MinAmount)
+						require.Equal(t, sql.NullInt64{Int64: 2050, Valid: true}, arg.MaxAmount)
+						require.True(t, arg.FromTime.Valid)
+						require.True(t, from.Equal(arg.FromTime.Time))
+						require.True(t, arg.ToTime.Valid)
+						require.True(t, to.Equal(arg.ToTime.Time))
+						require.Equal(t, int32(2), arg.LimitCount)
+						return rows, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var page pageResponse[transferResponse]
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &page))
+				require.True(t, page.HasMore)
+				require.NotEmpty(t, page.NextCursor)
+				require.Len(t, page.Items, 1)
+			},
+		},
+		{
+			name:  "AccountForbidden",
+			query: url.Values{"accountID": {"1"}},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(int64(1))).Times(1).Return(randomInMemoryAccount("randomUser"), nil)
+				store.EXPECT().ListUserTransfers(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:  "AmountWithoutCurrency",
+			query: url.Values{"minAmount": {"10"}},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListUserTransfers(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:  "TooManyDecimals",
+			query: url.Values{"currency": {util.USD}, "minAmount": {"10.001"}},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListUserTransfers(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:  "InvalidDirection",
+			query: url.Values{"direction": {"sideways"}},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListUserTransfers(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:  "InternalError",
+			query: url.Values{},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListUserTransfers(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodGet, "/transfers?"+tc.query.Encode(), nil)
+			require.NoError(t, err)
+
+			addAuthorizationHeader(t, request, server.tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestGetTransfersCursorFilters(t *testing.T) {
+	user, _ := randomInMemoryUser(t)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().
+		ListUserTransfers(gomock.Any(), gomock.Any()).
+		Times(1).
+		Return([]db.ListUserTransfersRow{
+			{ID: 1, Amount: 100, ToAmount: 100, FromCurrency: util.USD, ToCurrency: util.USD},
+			{ID: 2, Amount: 100, ToAmount: 100, FromCurrency: util.USD, ToCurrency: util.USD},
+		}, nil)
+
+	server := newTestServer(t, store)
+	getPage := func(query url.Values) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		request, err := http.NewRequest(http.MethodGet, "/transfers?"+query.Encode(), nil)
+		require.NoError(t, err)
+
+		addAuthorizationHeader(t, request, server.tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+		server.router.ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	recorder := getPage(url.Values{"direction": {"incoming"}, "limit": {"1"}})
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	var page pageResponse[transferResponse]
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &page))
+	require.True(t, page.HasMore)
+
+	// the cursor can't be used with other filters
+	recorder = getPage(url.Values{"direction": {"outgoing"}, "limit": {"1"}, "cursor": {page.NextCursor}})
+	require.Equal(t, http.StatusBadRequest, recorder.Code)
+}
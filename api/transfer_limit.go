@@ -0,0 +1,165 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	db "github.com/crackz/simple-bank/db/sqlc"
+	"github.com/crackz/simple-bank/money"
+	"github.com/crackz/simple-bank/token"
+	"github.com/gin-gonic/gin"
+	"github.com/lib/pq"
+)
+
+type transferLimitWindowResponse struct {
+	// Limit and Remaining are only set when the window has a limit.
+	Limit     *money.Money `json:"limit,omitempty"`
+	Used      money.Money  `json:"used"`
+	Remaining *money.Money `json:"remaining,omitempty"`
+}
+
+func newTransferLimitWindowResponse(window db.TransferLimitWindow, currency string) transferLimitWindowResponse {
+	return transferLimitWindowResponse{
+		Limit:     moneyPointer(window.Limit, currency),
+		Used:      money.New(window.Used, currency),
+		Remaining: moneyPointer(window.Remaining, currency),
+	}
+}
+
+type transferLimitHeadroomResponse struct {
+	Currency string `json:"currency"`
+	// PerTransaction is only set when transactions are limited.
+	PerTransaction *money.Money                `json:"perTransaction,omitempty"`
+	Daily          transferLimitWindowResponse `json:"daily"`
+	Monthly        transferLimitWindowResponse `json:"monthly"`
+}
+
+// getTransferLimits shows how much the user can still transfer in each
+// currency they have limits in.
+func (server *Server) getTransferLimits(ctx *gin.Context) {
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	headroom, err := server.store.GetTransferLimitHeadroom(ctx, authPayload.Username)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	response := make([]transferLimitHeadroomResponse, 0, len(headroom))
+	for _, currencyHeadroom := range headroom {
+		response = append(response, transferLimitHeadroomResponse{
+			Currency:       currencyHeadroom.Currency,
+			PerTransaction: moneyPointer(currencyHeadroom.PerTransaction, currencyHeadroom.Currency),
+			Daily:          newTransferLimitWindowResponse(currencyHeadroom.Daily, currencyHeadroom.Currency),
+			Monthly:        newTransferLimitWindowResponse(currencyHeadroom.Monthly, currencyHeadroom.Currency),
+		})
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+// transferLimitResponse only has the limits that are set.
+type transferLimitResponse struct {
+	Username       string       `json:"username"`
+	Currency       string       `json:"currency"`
+	PerTransaction *money.Money `json:"perTransaction,omitempty"`
+	Daily          *money.Money `json:"daily,omitempty"`
+	Monthly        *money.Money `json:"monthly,omitempty"`
+	UpdatedAt      time.Time    `json:"updatedAt"`
+}
+
+func newTransferLimitResponse(limit db.TransferLimit) transferLimitResponse {
+	return transferLimitResponse{
+		Username:       limit.Username,
+		Currency:       limit.Currency,
+		PerTransaction: moneyPointer(limit.PerTransaction, limit.Currency),
+		Daily:          moneyPointer(limit.Daily, limit.Currency),
+		Monthly:        moneyPointer(limit.Monthly, limit.Currency),
+		UpdatedAt:      limit.UpdatedAt,
+	}
+}
+
+type userTransferLimitsParam struct {
+	Username string `uri:"username" binding:"required,alphanum"`
+}
+
+func (server *Server) getUserTransferLimits(ctx *gin.Context) {
+	var params userTransferLimitsParam
+
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	limits, err := server.store.ListTransferLimits(ctx, params.Username)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	response := make([]transferLimitResponse, 0, len(limits))
+	for _, limit := range limits {
+		response = append(response, newTransferLimitResponse(limit))
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+type userTransferLimitParam struct {
+	Username string `uri:"username" binding:"required,alphanum"`
+	Currency string `uri:"currency" binding:"required,currency"`
+}
+
+// setTransferLimitDto takes decimal amounts in the currency of the limit. A
+// limit left out of the body removes it.
+type setTransferLimitDto struct {
+	PerTransaction *string `json:"perTransaction" binding:"omitempty,amount"`
+	Daily          *string `json:"daily" binding:"omitempty,amount"`
+	Monthly        *string `json:"monthly" binding:"omitempty,amount"`
+}
+
+// setUserTransferLimit replaces the transfer limits of a user in a currency.
+func (server *Server) setUserTransferLimit(ctx *gin.Context) {
+	var params userTransferLimitParam
+	var dto setTransferLimitDto
+
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	if err := ctx.ShouldBindJSON(&dto); err != nil {
+		ctx.JSON(http.StatusUnprocessableEntity, errorResponse(err))
+		return
+	}
+
+	arg := db.UpsertTransferLimitParams{
+		Username: params.Username,
+		Currency: params.Currency,
+	}
+	var ok bool
+	if arg.PerTransaction, ok = parseNullAmount(ctx, dto.PerTransaction, params.Currency); !ok {
+		return
+	}
+	if arg.Daily, ok = parseNullAmount(ctx, dto.Daily, params.Currency); !ok {
+		return
+	}
+	if arg.Monthly, ok = parseNullAmount(ctx, dto.Monthly, params.Currency); !ok {
+		return
+	}
+
+	setAuditTarget(ctx, auditTargetUser, params.Username)
+
+	limit, err := server.store.UpsertTransferLimit(ctx, arg)
+	if err != nil {
+		if pgErr, ok := err.(*pq.Error); ok && pgErr.Code.Name() == "foreign_key_violation" {
+			ctx.JSON(http.StatusNotFound, errorResponse(errors.New("user not found")))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	setAuditAfter(ctx, limit)
+
+	ctx.JSON(http.StatusOK, newTransferLimitResponse(limit))
+}
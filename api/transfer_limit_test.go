@@ -0,0 +1,224 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	mockdb "github.com/crackz/simple-bank/db/mock"
+	db "github.com/crackz/simple-bank/db/sqlc"
+	"github.com/crackz/simple-bank/money"
+	"github.com/crackz/simple-bank/util"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetTransferLimitsAPI(t *testing.T) {
+	user, _ := randomInMemoryUser(t)
+
+	headroom := []db.TransferLimitHeadroom{
+		{
+			Currency:       util.USD,
+			PerTransaction: sql.NullInt64{Int64: 500, Valid: true},
+			Daily: db.TransferLimitWindow{
+				Limit:     sql.NullInt64{Int64: 1000, Valid: true},
+				Used:      300,
+				Remaining: sql.NullInt64{Int64: 700, Valid: true},
+			},
+			Monthly: db.TransferLimitWindow{Used: 300},
+		},
+	}
+
+	testCases := []struct {
+		name          string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetTransferLimitHeadroom(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(headroom, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				// limits that aren't set are left out
+				require.JSONEq(t, `[{
+					"currency": "USD",
+					"perTransaction": {"amount": "5.00", "currency": "USD"},
+					"daily": {
+						"limit": {"amount": "10.00", "currency": "USD"},
+						"used": {"amount": "3.00", "currency": "USD"},
+						"remaining": {"amount": "7.00", "currency": "USD"}
+					},
+					"monthly": {"used": {"amount": "3.00", "currency": "USD"}}
+				}]`, recorder.Body.String())
+			},
+		},
+		{
+			name: "InternalError",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetTransferLimitHeadroom(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodGet, "/transfers/limits", nil)
+			require.NoError(t, err)
+
+			addAuthorizationHeader(t, request, server.tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestSetUserTransferLimitAPI(t *testing.T) {
+	admin, _ := randomInMemoryUser(t)
+	admin.Role = util.AdminRole
+
+	user, _ := randomInMemoryUser(t)
+
+	testCases := []struct {
+		name          string
+		currency      string
+		body          gin.H
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "OK",
+			currency: util.USD,
+			body:     gin.H{"perTransaction": "5.00", "daily": "10"},
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.UpsertTransferLimitParams{
+					Username:       user.Username,
+					Currency:       util.USD,
+					PerTransaction: sql.NullInt64{Int64: 500, Valid: true},
+					Daily:          sql.NullInt64{Int64: 1000, Valid: true},
+				}
+				store.EXPECT().
+					UpsertTransferLimit(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(db.TransferLimit{
+						Username:       arg.Username,
+						Currency:       arg.Currency,
+						PerTransaction: arg.PerTransaction,
+						Daily:          arg.Daily,
+					}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var got transferLimitResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &got))
+				require.Equal(t, money.New(500, util.USD), *got.PerTransaction)
+				require.Equal(t, money.New(1000, util.USD), *got.Daily)
+				require.Nil(t, got.Monthly)
+			},
+		},
+		{
+			name:     "UserNotFound",
+			currency: util.USD,
+			body:     gin.H{"daily": "10.00"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					UpsertTransferLimit(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.TransferLimit{}, &pq.Error{Code: "23503"})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:     "InvalidCurrency",
+			currency: "XYZ",
+			body:     gin.H{"daily": "10.00"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().UpsertTransferLimit(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:     "TooManyDecimalPlaces",
+			currency: util.USD,
+			body:     gin.H{"daily": "10.001"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().UpsertTransferLimit(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+			},
+		},
+		{
+			name:     "NegativeLimit",
+			currency: util.USD,
+			body:     gin.H{"monthly": "-1"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().UpsertTransferLimit(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			store.EXPECT().GetUser(gomock.Any(), gomock.Eq(admin.Username)).Times(1).Return(admin, nil)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			url := fmt.Sprintf("/admin/users/%s/transfer-limits/%s", user.Username, tc.currency)
+			request, err := http.NewRequest(http.MethodPut, url, bytes.NewReader(data))
+			require.NoError(t, err)
+
+			addAuthorizationHeader(t, request, server.tokenMaker, authorizationTypeBearer, admin.Username, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
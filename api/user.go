@@ -62,7 +62,10 @@ func (server *Server) registerUser(ctx *gin.Context) {
 		HashedPassword: hashedPassword,
 	}
 
-	user, err := server.store.CreateUser(ctx, arg)
+	setAuditActor(ctx, createDto.Username)
+	setAuditTarget(ctx, auditTargetUser, createDto.Username)
+
+	user, err := server.store.CreateUserTx(ctx, arg)
 	if err != nil {
 		if pgErr, ok := err.(*pq.Error); ok {
 			switch pgErr.Code.Name() {
@@ -75,6 +78,7 @@ func (server *Server) registerUser(ctx *gin.Context) {
 		return
 	}
 
+	setAuditAfter(ctx, newUserResponse(&user))
 	ctx.JSON(http.StatusCreated, newUserResponse(&user))
 }
 
@@ -96,6 +100,9 @@ func (server *Server) loginUser(ctx *gin.Context) {
 		return
 	}
 
+	setAuditActor(ctx, loginUserDto.Username)
+	setAuditTarget(ctx, auditTargetUser, loginUserDto.Username)
+
 	user, err := server.store.GetUser(ctx, loginUserDto.Username)
 	if err != nil {
 		if err == sql.ErrNoRows {
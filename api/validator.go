@@ -1,15 +1,81 @@
 package api
 
 import (
-	"github.com/crackz/simple-bank/util"
+	"context"
+	"database/sql"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/crackz/simple-bank/currency"
+	"github.com/crackz/simple-bank/money"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
 	"github.com/go-playground/validator/v10"
 )
 
-func validateCurrency(fieldLevel validator.FieldLevel) bool {
+// registerValidations registers the custom bindings with gin's validator, with
+// the currency binding checking against currencies. Gin's validator is shared
+// by the whole process, so the registry of the last server made is the one
+// checked against, and the process makes a single registry in main.
+func registerValidations(currencies *currency.Registry) {
+	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		v.RegisterValidation("currency", currencyValidation(currencies))
+		v.RegisterValidation("amount", validateAmount)
+	}
+}
+
+// currencyValidation checks that a field is an enabled currency of the registry.
+func currencyValidation(currencies *currency.Registry) validator.Func {
+	return func(fieldLevel validator.FieldLevel) bool {
+		if value, ok := fieldLevel.Field().Interface().(string); ok {
+			return currencies.IsEnabled(context.Background(), value)
+		}
+
+		return false
+	}
+}
+
+var decimalAmount = regexp.MustCompile(`^[0-9]+(\.[0-9]+)?$`)
+
+// validateAmount checks that a field is a positive decimal amount. The
+// parameter names the field holding the currency, if the struct has one, to
+// also check that the amount fits the currency's minor unit.
+func validateAmount(fieldLevel validator.FieldLevel) bool {
+	value, ok := fieldLevel.Field().Interface().(string)
+	if !ok || !decimalAmount.MatchString(value) || strings.Trim(value, "0.") == "" {
+		return false
+	}
+
+	if param := fieldLevel.Param(); param != "" {
+		currency := fieldLevel.Parent().FieldByName(param)
+		_, err := money.Parse(value, currency.String())
+		return err == nil
+	}
+
+	return true
+}
+
+// parseAmount reads a decimal amount in minor units of currency. The error
+// response is already written when ok is false.
+func parseAmount(ctx *gin.Context, amount string, currency string) (minor int64, ok bool) {
+	m, err := money.Parse(amount, currency)
+	if err != nil {
+		ctx.JSON(http.StatusUnprocessableEntity, errorResponse(err))
+		return
+	}
+
+	return m.Amount, true
+}
 
-	if value, ok := fieldLevel.Field().Interface().(string); ok {
-		return util.IsSupportedCurrency(value)
+// parseNullAmount reads an optional decimal amount like parseAmount. An omitted
+// amount isn't set.
+func parseNullAmount(ctx *gin.Context, amount *string, currency string) (minor sql.NullInt64, ok bool) {
+	if amount == nil {
+		return sql.NullInt64{}, true
 	}
 
-	return false
+	minor.Int64, ok = parseAmount(ctx, *amount, currency)
+	minor.Valid = ok
+	return
 }
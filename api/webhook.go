@@ -0,0 +1,367 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	db "github.com/crackz/simple-bank/db/sqlc"
+	"github.com/crackz/simple-bank/token"
+	"github.com/crackz/simple-bank/webhook"
+	"github.com/gin-gonic/gin"
+)
+
+type createWebhookDto struct {
+	Url        string   `json:"url" binding:"required,url"`
+	EventTypes []string `json:"eventTypes" binding:"dive,oneof=transfer.received transfer.sent account.created"`
+}
+
+// webhookResponse leaves out the signing secret, which is only returned when
+// the endpoint is registered.
+type webhookResponse struct {
+	ID         int64     `json:"id"`
+	Url        string    `json:"url"`
+	EventTypes []string  `json:"eventTypes"`
+	Active     bool      `json:"active"`
+	CreatedAt  time.Time `json:"createdAt"`
+}
+
+func newWebhookResponse(endpoint db.WebhookEndpoint) webhookResponse {
+	return webhookResponse{
+		ID:         endpoint.ID,
+		Url:        endpoint.Url,
+		EventTypes: endpoint.EventTypes,
+		Active:     endpoint.Active,
+		CreatedAt:  endpoint.CreatedAt,
+	}
+}
+
+type webhookDeliveryResponse struct {
+	ID            int64           `json:"id"`
+	EventID       int64           `json:"eventID"`
+	EventType     string          `json:"eventType"`
+	Payload       json.RawMessage `json:"payload"`
+	Status        string          `json:"status"`
+	Attempts      int32           `json:"attempts"`
+	NextAttemptAt time.Time       `json:"nextAttemptAt"`
+	// LastError is only set when the last attempt failed.
+	LastError *string `json:"lastError,omitempty"`
+	// DeliveredAt is only set once the event is delivered.
+	DeliveredAt *time.Time `json:"deliveredAt,omitempty"`
+	CreatedAt   time.Time  `json:"createdAt"`
+}
+
+func newWebhookDeliveryResponse(delivery db.WebhookDelivery) webhookDeliveryResponse {
+	return webhookDeliveryResponse{
+		ID:            delivery.ID,
+		EventID:       delivery.EventID,
+		EventType:     delivery.EventType,
+		Payload:       delivery.Payload,
+		Status:        delivery.Status,
+		Attempts:      delivery.Attempts,
+		NextAttemptAt: delivery.NextAttemptAt,
+		LastError:     stringPointer(delivery.LastError),
+		DeliveredAt:   timePointer(delivery.DeliveredAt),
+		CreatedAt:     delivery.CreatedAt,
+	}
+}
+
+type webhookAttemptResponse struct {
+	ID         int64 `json:"id"`
+	DeliveryID int64 `json:"deliveryID"`
+	Attempt    int32 `json:"attempt"`
+	// StatusCode is only set when the endpoint responded.
+	StatusCode *int32 `json:"statusCode,omitempty"`
+	// Error is only set when the attempt failed.
+	Error     *string   `json:"error,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+func newWebhookAttemptResponse(attempt db.WebhookDeliveryAttempt) webhookAttemptResponse {
+	return webhookAttemptResponse{
+		ID:         attempt.ID,
+		DeliveryID: attempt.DeliveryID,
+		Attempt:    attempt.Attempt,
+		StatusCode: int32Pointer(attempt.StatusCode),
+		Error:      stringPointer(attempt.Error),
+		CreatedAt:  attempt.CreatedAt,
+	}
+}
+
+type createWebhookResponse struct {
+	webhookResponse
+	Secret string `json:"secret"`
+}
+
+func (server *Server) createWebhook(ctx *gin.Context) {
+	var createDto createWebhookDto
+
+	if err := ctx.ShouldBindJSON(&createDto); err != nil {
+		ctx.JSON(http.StatusUnprocessableEntity, errorResponse(err))
+		return
+	}
+	if err := webhook.CheckURL(ctx, server.resolver, createDto.Url); err != nil {
+		ctx.JSON(http.StatusUnprocessableEntity, errorResponse(err))
+		return
+	}
+
+	secret, err := webhook.NewSecret()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	eventTypes := createDto.EventTypes
+	if eventTypes == nil {
+		eventTypes = []string{}
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	endpoint, err := server.store.CreateWebhookEndpoint(ctx, db.CreateWebhookEndpointParams{
+		Owner:      authPayload.Username,
+		Url:        createDto.Url,
+		Secret:     secret,
+		EventTypes: eventTypes,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, createWebhookResponse{
+		webhookResponse: newWebhookResponse(endpoint),
+		Secret:          endpoint.Secret,
+	})
+}
+
+type getWebhooksQuery struct {
+	Page  int32 `form:"page" binding:"min=1"`
+	Limit int32 `form:"limit" binding:"min=1,max=100"`
+}
+
+func (server *Server) getWebhooks(ctx *gin.Context) {
+	var query getWebhooksQuery
+
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if query.Page == 0 {
+		query.Page = 1
+	}
+	if query.Limit == 0 {
+		query.Limit = 10
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	endpoints, err := server.store.ListWebhookEndpoints(ctx, db.ListWebhookEndpointsParams{
+		Owner:  authPayload.Username,
+		Limit:  query.Limit,
+		Offset: (query.Page - 1) * query.Limit,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	response := make([]webhookResponse, len(endpoints))
+	for i, endpoint := range endpoints {
+		response[i] = newWebhookResponse(endpoint)
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+type webhookParam struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+func (server *Server) getWebhook(ctx *gin.Context) {
+	var params webhookParam
+
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	endpoint, ok := server.checkWebhookOwner(ctx, params.ID)
+	if !ok {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newWebhookResponse(endpoint))
+}
+
+type updateWebhookDto struct {
+	Url        *string  `json:"url" binding:"omitempty,url"`
+	EventTypes []string `json:"eventTypes" binding:"omitempty,dive,oneof=transfer.received transfer.sent account.created"`
+	Active     *bool    `json:"active"`
+}
+
+func (server *Server) updateWebhook(ctx *gin.Context) {
+	var params webhookParam
+	var dto updateWebhookDto
+
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	if err := ctx.ShouldBindJSON(&dto); err != nil {
+		ctx.JSON(http.StatusUnprocessableEntity, errorResponse(err))
+		return
+	}
+	if dto.Url != nil {
+		if err := webhook.CheckURL(ctx, server.resolver, *dto.Url); err != nil {
+			ctx.JSON(http.StatusUnprocessableEntity, errorResponse(err))
+			return
+		}
+	}
+
+	endpoint, ok := server.checkWebhookOwner(ctx, params.ID)
+	if !ok {
+		return
+	}
+
+	// a nil EventTypes keeps the current filter
+	arg := db.UpdateWebhookEndpointParams{ID: endpoint.ID, EventTypes: dto.EventTypes}
+	if dto.Url != nil {
+		arg.Url = sql.NullString{String: *dto.Url, Valid: true}
+	}
+	if dto.Active != nil {
+		arg.Active = sql.NullBool{Bool: *dto.Active, Valid: true}
+	}
+
+	updatedEndpoint, err := server.store.UpdateWebhookEndpoint(ctx, arg)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newWebhookResponse(updatedEndpoint))
+}
+
+func (server *Server) deleteWebhook(ctx *gin.Context) {
+	var params webhookParam
+
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	endpoint, ok := server.checkWebhookOwner(ctx, params.ID)
+	if !ok {
+		return
+	}
+
+	err := server.store.DeleteWebhookEndpoint(ctx, endpoint.ID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newWebhookResponse(endpoint))
+}
+
+type getWebhookLogQuery struct {
+	Page  int32 `form:"page" binding:"min=1"`
+	Limit int32 `form:"limit" binding:"min=1,max=100"`
+}
+
+// getWebhookDeliveries lists the deliveries of an endpoint, newest first,
+// including the dead-lettered ones.
+func (server *Server) getWebhookDeliveries(ctx *gin.Context) {
+	endpoint, query, ok := server.bindWebhookLog(ctx)
+	if !ok {
+		return
+	}
+
+	deliveries, err := server.store.ListWebhookDeliveries(ctx, db.ListWebhookDeliveriesParams{
+		WebhookID: endpoint.ID,
+		Limit:     query.Limit,
+		Offset:    (query.Page - 1) * query.Limit,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	response := make([]webhookDeliveryResponse, 0, len(deliveries))
+	for _, delivery := range deliveries {
+		response = append(response, newWebhookDeliveryResponse(delivery))
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+// getWebhookAttempts lists every attempt at delivering to an endpoint, newest
+// first.
+func (server *Server) getWebhookAttempts(ctx *gin.Context) {
+	endpoint, query, ok := server.bindWebhookLog(ctx)
+	if !ok {
+		return
+	}
+
+	attempts, err := server.store.ListWebhookDeliveryAttempts(ctx, db.ListWebhookDeliveryAttemptsParams{
+		WebhookID: endpoint.ID,
+		Limit:     query.Limit,
+		Offset:    (query.Page - 1) * query.Limit,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	response := make([]webhookAttemptResponse, 0, len(attempts))
+	for _, attempt := range attempts {
+		response = append(response, newWebhookAttemptResponse(attempt))
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+func (server *Server) bindWebhookLog(ctx *gin.Context) (db.WebhookEndpoint, getWebhookLogQuery, bool) {
+	var params webhookParam
+	var query getWebhookLogQuery
+
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return db.WebhookEndpoint{}, query, false
+	}
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return db.WebhookEndpoint{}, query, false
+	}
+
+	if query.Page == 0 {
+		query.Page = 1
+	}
+	if query.Limit == 0 {
+		query.Limit = 10
+	}
+
+	endpoint, ok := server.checkWebhookOwner(ctx, params.ID)
+	return endpoint, query, ok
+}
+
+func (server *Server) checkWebhookOwner(ctx *gin.Context, id int64) (db.WebhookEndpoint, bool) {
+	endpoint, err := server.store.GetWebhookEndpoint(ctx, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(errors.New("webhook not found")))
+		} else {
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		}
+		return endpoint, false
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if endpoint.Owner != authPayload.Username {
+		ctx.JSON(http.StatusForbidden, errorResponse(errors.New("you are not allowed to access this webhook")))
+		return endpoint, false
+	}
+
+	return endpoint, true
+}
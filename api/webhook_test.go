@@ -0,0 +1,437 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	mockdb "github.com/crackz/simple-bank/db/mock"
+	db "github.com/crackz/simple-bank/db/sqlc"
+	"github.com/crackz/simple-bank/token"
+	"github.com/crackz/simple-bank/util"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func randomInMemoryWebhook(owner string) db.WebhookEndpoint {
+	return db.WebhookEndpoint{
+		ID:         util.RandomInt(1, 1000),
+		Owner:      owner,
+		Url:        "https://example.com/hooks",
+		Secret:     "whsec_" + util.RandString(32),
+		EventTypes: []string{"transfer.received"},
+		Active:     true,
+	}
+}
+
+func TestCreateWebhookAPI(t *testing.T) {
+	user, _ := randomInMemoryUser(t)
+	endpoint := randomInMemoryWebhook(user.Username)
+
+	testCases := []struct {
+		name          string
+		body          gin.H
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "Created",
+			body: gin.H{
+				"url":        endpoint.Url,
+				"eventTypes": endpoint.EventTypes,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateWebhookEndpoint(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ interface{}, arg db.CreateWebhookEndpointParams) (db.WebhookEndpoint, error) {
+						require.Equal(t, user.Username, arg.Owner)
+						require.Equal(t, endpoint.Url, arg.Url)
+						require.Equal(t, endpoint.EventTypes, arg.EventTypes)
+						require.True(t, strings.HasPrefix(arg.Secret, "whsec_"))
+						return endpoint, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusCreated, recorder.Code)
+
+				var response createWebhookResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+				require.Equal(t, endpoint.ID, response.ID)
+				require.Equal(t, endpoint.Secret, response.Secret)
+			},
+		},
+		{
+			name: "All Event Types",
+			body: gin.H{
+				"url": endpoint.Url,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateWebhookEndpoint(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ interface{}, arg db.CreateWebhookEndpointParams) (db.WebhookEndpoint, error) {
+						require.NotNil(t, arg.EventTypes)
+						require.Empty(t, arg.EventTypes)
+						return endpoint, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusCreated, recorder.Code)
+			},
+		},
+		{
+			name: "Invalid URL Scheme",
+			body: gin.H{
+				"url": "ftp://example.com/hooks",
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateWebhookEndpoint(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+			},
+		},
+		{
+			name: "Loopback URL",
+			body: gin.H{
+				"url": "http://127.0.0.1:8080/hooks",
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateWebhookEndpoint(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+			},
+		},
+		{
+			name: "Link-Local URL",
+			body: gin.H{
+				"url": "http://169.254.169.254/latest/meta-data",
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateWebhookEndpoint(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+			},
+		},
+		{
+			name: "Invalid Event Type",
+			body: gin.H{
+				"url":        endpoint.Url,
+				"eventTypes": []string{"user.created"},
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateWebhookEndpoint(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+			},
+		},
+		{
+			name: "Internal Server Error",
+			body: gin.H{
+				"url": endpoint.Url,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateWebhookEndpoint(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.WebhookEndpoint{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/webhooks", bytes.NewReader(data))
+			require.NoError(t, err)
+
+			addAuthorizationHeader(t, request, server.tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestGetWebhookAPI(t *testing.T) {
+	user, _ := randomInMemoryUser(t)
+	otherUser, _ := randomInMemoryUser(t)
+	endpoint := randomInMemoryWebhook(user.Username)
+
+	testCases := []struct {
+		name          string
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorizationHeader(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetWebhookEndpoint(gomock.Any(), gomock.Eq(endpoint.ID)).
+					Times(1).
+					Return(endpoint, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				body, err := io.ReadAll(recorder.Body)
+				require.NoError(t, err)
+				require.NotContains(t, string(body), endpoint.Secret)
+
+				var response webhookResponse
+				require.NoError(t, json.Unmarshal(body, &response))
+				require.Equal(t, newWebhookResponse(endpoint), response)
+			},
+		},
+		{
+			name: "Forbidden",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorizationHeader(t, request, tokenMaker, authorizationTypeBearer, otherUser.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetWebhookEndpoint(gomock.Any(), gomock.Eq(endpoint.ID)).
+					Times(1).
+					Return(endpoint, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name: "Not Found",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorizationHeader(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetWebhookEndpoint(gomock.Any(), gomock.Eq(endpoint.ID)).
+					Times(1).
+					Return(db.WebhookEndpoint{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodGet, fmt.Sprintf("/webhooks/%d", endpoint.ID), nil)
+			require.NoError(t, err)
+
+			tc.setupAuth(t, request, server.tokenMaker)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestUpdateWebhookAPI(t *testing.T) {
+	user, _ := randomInMemoryUser(t)
+	endpoint := randomInMemoryWebhook(user.Username)
+
+	testCases := []struct {
+		name          string
+		body          gin.H
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "Disable",
+			body: gin.H{"active": false},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetWebhookEndpoint(gomock.Any(), gomock.Eq(endpoint.ID)).
+					Times(1).
+					Return(endpoint, nil)
+
+				updated := endpoint
+				updated.Active = false
+				store.EXPECT().
+					UpdateWebhookEndpoint(gomock.Any(), gomock.Eq(db.UpdateWebhookEndpointParams{
+						ID:     endpoint.ID,
+						Active: sql.NullBool{Bool: false, Valid: true},
+					})).
+					Times(1).
+					Return(updated, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var response webhookResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+				require.False(t, response.Active)
+			},
+		},
+		{
+			name: "Invalid URL",
+			body: gin.H{"url": "mailto:someone@example.com"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					UpdateWebhookEndpoint(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPatch, fmt.Sprintf("/webhooks/%d", endpoint.ID), bytes.NewReader(data))
+			require.NoError(t, err)
+
+			addAuthorizationHeader(t, request, server.tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestGetWebhookAttemptsAPI(t *testing.T) {
+	user, _ := randomInMemoryUser(t)
+	endpoint := randomInMemoryWebhook(user.Username)
+	attempts := []db.WebhookDeliveryAttempt{
+		{ID: 2, DeliveryID: 1, WebhookID: endpoint.ID, Attempt: 2, StatusCode: sql.NullInt32{Int32: 200, Valid: true}},
+		{ID: 1, DeliveryID: 1, WebhookID: endpoint.ID, Attempt: 1, StatusCode: sql.NullInt32{Int32: 500, Valid: true}, Error: sql.NullString{String: "endpoint responded with 500 Internal Server Error", Valid: true}},
+	}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().
+		GetWebhookEndpoint(gomock.Any(), gomock.Eq(endpoint.ID)).
+		Times(1).
+		Return(endpoint, nil)
+	store.EXPECT().
+		ListWebhookDeliveryAttempts(gomock.Any(), gomock.Eq(db.ListWebhookDeliveryAttemptsParams{
+			WebhookID: endpoint.ID,
+			Limit:     5,
+			Offset:    5,
+		})).
+		Times(1).
+		Return(attempts, nil)
+
+	server := newTestServer(t, store)
+	recorder := httptest.NewRecorder()
+
+	request, err := http.NewRequest(http.MethodGet, fmt.Sprintf("/webhooks/%d/attempts?page=2&limit=5", endpoint.ID), nil)
+	require.NoError(t, err)
+
+	addAuthorizationHeader(t, request, server.tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	require.JSONEq(t, `[
+		{"id":2,"deliveryID":1,"attempt":2,"statusCode":200,"createdAt":"0001-01-01T00:00:00Z"},
+		{"id":1,"deliveryID":1,"attempt":1,"statusCode":500,"error":"endpoint responded with 500 Internal Server Error","createdAt":"0001-01-01T00:00:00Z"}
+	]`, recorder.Body.String())
+}
+
+func TestGetWebhookDeliveriesAPI(t *testing.T) {
+	user, _ := randomInMemoryUser(t)
+	endpoint := randomInMemoryWebhook(user.Username)
+	deliveredAt := time.Date(2023, time.March, 1, 12, 0, 0, 0, time.UTC)
+	deliveries := []db.WebhookDelivery{
+		{ID: 2, WebhookID: endpoint.ID, EventID: 7, EventType: "transfer.sent", Payload: []byte(`{}`), Status: "pending", Attempts: 1, LastError: sql.NullString{String: "timeout", Valid: true}},
+		{ID: 1, WebhookID: endpoint.ID, EventID: 6, EventType: "transfer.sent", Payload: []byte(`{}`), Status: "delivered", Attempts: 1, DeliveredAt: sql.NullTime{Time: deliveredAt, Valid: true}},
+	}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().
+		GetWebhookEndpoint(gomock.Any(), gomock.Eq(endpoint.ID)).
+		Times(1).
+		Return(endpoint, nil)
+	store.EXPECT().
+		ListWebhookDeliveries(gomock.Any(), gomock.Eq(db.ListWebhookDeliveriesParams{
+			WebhookID: endpoint.ID,
+			Limit:     10,
+			Offset:    0,
+		})).
+		Times(1).
+		Return(deliveries, nil)
+
+	server := newTestServer(t, store)
+	recorder := httptest.NewRecorder()
+
+	request, err := http.NewRequest(http.MethodGet, fmt.Sprintf("/webhooks/%d/deliveries?page=1&limit=10", endpoint.ID), nil)
+	require.NoError(t, err)
+
+	addAuthorizationHeader(t, request, server.tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.JSONEq(t, `[
+		{"id":2,"eventID":7,"eventType":"transfer.sent","payload":{},"status":"pending","attempts":1,"nextAttemptAt":"0001-01-01T00:00:00Z","lastError":"timeout","createdAt":"0001-01-01T00:00:00Z"},
+		{"id":1,"eventID":6,"eventType":"transfer.sent","payload":{},"status":"delivered","attempts":1,"nextAttemptAt":"0001-01-01T00:00:00Z","deliveredAt":"2023-03-01T12:00:00Z","createdAt":"0001-01-01T00:00:00Z"}
+	]`, recorder.Body.String())
+}
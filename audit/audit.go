@@ -0,0 +1,166 @@
+package audit
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+
+	db "github.com/crackz/simple-bank/db/sqlc"
+)
+
+// Recorder writes audit log entries. The servers record through their store.
+type Recorder interface {
+	CreateAuditLog(ctx context.Context, arg db.CreateAuditLogParams) (db.AuditLog, error)
+	CompleteAuditLog(ctx context.Context, arg db.CompleteAuditLogParams) (db.AuditLog, error)
+}
+
+// Entry collects what a call knows about the change it made. The REST and
+// gRPC servers make one for every mutating call. The first store transaction
+// of the call writes it, so no change can commit without it, and it's
+// completed with how the call was answered once the call is done. The entry
+// of a call that committed nothing is only written then.
+type Entry struct {
+	// call is nil for entries of calls that aren't audited.
+	call       *Call
+	actor      string
+	targetType string
+	targetID   string
+	before     interface{}
+	after      interface{}
+
+	// written is the ID the entry was last written with and id the one it was
+	// committed with.
+	written int64
+	id      int64
+}
+
+// NewEntry returns the entry of call, which isn't answered yet.
+func NewEntry(call Call) *Entry {
+	return &Entry{call: &call}
+}
+
+// SetActor names the caller, the token's user or the user logging in.
+func (entry *Entry) SetActor(username string) {
+	entry.actor = username
+}
+
+func (entry *Entry) SetTarget(targetType string, targetID interface{}) {
+	entry.targetType = targetType
+	entry.targetID = fmt.Sprint(targetID)
+}
+
+// SetBefore snapshots the target before the change. The snapshot is encoded
+// when the entry is written, so it must not be modified afterwards.
+func (entry *Entry) SetBefore(snapshot interface{}) {
+	entry.before = snapshot
+}
+
+func (entry *Entry) SetAfter(snapshot interface{}) {
+	entry.after = snapshot
+}
+
+// Call is how a call was made and answered. Method and Route are the HTTP
+// method and route of REST calls, and "GRPC" and the full method name of gRPC
+// calls, whose status code is the gRPC status code.
+type Call struct {
+	Method     string
+	Route      string
+	StatusCode int32
+	ClientIP   string
+}
+
+// Params returns the audit log entry of call, with the snapshots encoded.
+func (entry *Entry) Params(call Call) (db.CreateAuditLogParams, error) {
+	arg := db.CreateAuditLogParams{
+		Method:     call.Method,
+		Route:      call.Route,
+		StatusCode: sql.NullInt32{Int32: call.StatusCode, Valid: true},
+		ClientIp:   call.ClientIP,
+		Actor:      sql.NullString{String: entry.actor, Valid: entry.actor != ""},
+		TargetType: sql.NullString{String: entry.targetType, Valid: entry.targetType != ""},
+		TargetID:   sql.NullString{String: entry.targetID, Valid: entry.targetID != ""},
+	}
+
+	var err error
+	if arg.Before, err = json.Marshal(entry.before); err != nil {
+		return arg, err
+	}
+	arg.After, err = json.Marshal(entry.after)
+
+	return arg, err
+}
+
+// WriteAuditLog writes the entry with q, a transaction of the call about to
+// commit, unless it was committed already. The call isn't answered yet, so
+// the entry is written without its status code and after snapshot.
+func (entry *Entry) WriteAuditLog(ctx context.Context, q *db.Queries) error {
+	if entry.call == nil || entry.id != 0 {
+		return nil
+	}
+
+	arg, err := entry.Params(*entry.call)
+	if err != nil {
+		return err
+	}
+	arg.StatusCode = sql.NullInt32{}
+	arg.After = json.RawMessage("null")
+
+	written, err := q.CreateAuditLog(ctx, arg)
+	if err != nil {
+		return err
+	}
+
+	entry.written = written.ID
+	return nil
+}
+
+func (entry *Entry) AuditLogCommitted() {
+	if entry.written != 0 {
+		entry.id = entry.written
+	}
+}
+
+// Record writes the entry of call, or completes it with how call was
+// answered if a transaction of the call committed it. The call is already
+// answered, so an entry that can't be written is only logged.
+func Record(recorder Recorder, entry *Entry, call Call) {
+	arg, err := entry.Params(call)
+	if err == nil {
+		// the call may have been cancelled, the entry must be written anyway
+		if entry.id != 0 {
+			_, err = recorder.CompleteAuditLog(context.Background(), db.CompleteAuditLogParams{
+				ID:         entry.id,
+				StatusCode: arg.StatusCode,
+				TargetType: arg.TargetType,
+				TargetID:   arg.TargetID,
+				After:      arg.After,
+			})
+		} else {
+			_, err = recorder.CreateAuditLog(context.Background(), arg)
+		}
+	}
+	if err != nil {
+		log.Printf("Couldn't Record Audit Log For %s %s : %s", call.Method, call.Route, err)
+	}
+}
+
+type entryKey struct{}
+
+// NewContext returns a copy of ctx carrying entry, which the store
+// transactions made with it write.
+func NewContext(ctx context.Context, entry *Entry) context.Context {
+	return db.WithAuditLogger(context.WithValue(ctx, entryKey{}, entry), entry)
+}
+
+// FromContext returns the entry ctx carries. Calls that aren't audited get an
+// entry that's never recorded, so they can set it all the same.
+func FromContext(ctx context.Context) *Entry {
+	entry, ok := ctx.Value(entryKey{}).(*Entry)
+	if !ok {
+		return &Entry{}
+	}
+
+	return entry
+}
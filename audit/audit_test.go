@@ -0,0 +1,102 @@
+package audit
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	db "github.com/crackz/simple-bank/db/sqlc"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParams(t *testing.T) {
+	entry := &Entry{}
+	entry.SetActor("alice")
+	entry.SetTarget("account", int64(42))
+	entry.SetBefore(map[string]int64{"balance": 10})
+	entry.SetAfter(map[string]int64{"balance": 20})
+
+	arg, err := entry.Params(Call{
+		Method:     "POST",
+		Route:      "/accounts/:id",
+		StatusCode: 200,
+		ClientIP:   "203.0.113.7",
+	})
+	require.NoError(t, err)
+	require.Equal(t, "POST", arg.Method)
+	require.Equal(t, "/accounts/:id", arg.Route)
+	require.Equal(t, sql.NullInt32{Int32: 200, Valid: true}, arg.StatusCode)
+	require.Equal(t, "203.0.113.7", arg.ClientIp)
+	require.Equal(t, "alice", arg.Actor.String)
+	require.Equal(t, "account", arg.TargetType.String)
+	require.Equal(t, "42", arg.TargetID.String)
+	require.JSONEq(t, `{"balance":10}`, string(arg.Before))
+	require.JSONEq(t, `{"balance":20}`, string(arg.After))
+}
+
+func TestParamsAnonymous(t *testing.T) {
+	arg, err := (&Entry{}).Params(Call{Method: "POST", Route: "/users/login", StatusCode: 401})
+	require.NoError(t, err)
+	require.False(t, arg.Actor.Valid)
+	require.False(t, arg.TargetType.Valid)
+	require.False(t, arg.TargetID.Valid)
+	require.Equal(t, "null", string(arg.Before))
+	require.Equal(t, "null", string(arg.After))
+}
+
+type fakeRecorder struct {
+	created   []db.CreateAuditLogParams
+	completed []db.CompleteAuditLogParams
+}
+
+func (recorder *fakeRecorder) CreateAuditLog(ctx context.Context, arg db.CreateAuditLogParams) (db.AuditLog, error) {
+	recorder.created = append(recorder.created, arg)
+	return db.AuditLog{}, nil
+}
+
+func (recorder *fakeRecorder) CompleteAuditLog(ctx context.Context, arg db.CompleteAuditLogParams) (db.AuditLog, error) {
+	recorder.completed = append(recorder.completed, arg)
+	return db.AuditLog{}, nil
+}
+
+func TestRecord(t *testing.T) {
+	call := Call{Method: "POST", Route: "/transfers", ClientIP: "203.0.113.7"}
+
+	// a call that didn't commit anything is written once it's answered
+	recorder := &fakeRecorder{}
+	entry := NewEntry(call)
+	call.StatusCode = 401
+	Record(recorder, entry, call)
+	require.Len(t, recorder.created, 1)
+	require.Empty(t, recorder.completed)
+	require.Equal(t, sql.NullInt32{Int32: 401, Valid: true}, recorder.created[0].StatusCode)
+
+	// one whose transaction wrote the entry completes it
+	recorder = &fakeRecorder{}
+	entry = NewEntry(call)
+	entry.written = 7
+	entry.AuditLogCommitted()
+	entry.SetTarget("account", int64(42))
+	entry.SetAfter(map[string]int64{"balance": 20})
+	call.StatusCode = 201
+	Record(recorder, entry, call)
+	require.Empty(t, recorder.created)
+	require.Len(t, recorder.completed, 1)
+	require.Equal(t, int64(7), recorder.completed[0].ID)
+	require.Equal(t, sql.NullInt32{Int32: 201, Valid: true}, recorder.completed[0].StatusCode)
+	require.Equal(t, "42", recorder.completed[0].TargetID.String)
+	require.JSONEq(t, `{"balance":20}`, string(recorder.completed[0].After))
+}
+
+func TestContext(t *testing.T) {
+	entry := &Entry{}
+	ctx := NewContext(context.Background(), entry)
+
+	FromContext(ctx).SetActor("alice")
+	require.Equal(t, "alice", entry.actor)
+
+	// calls that aren't audited can set an entry all the same
+	require.NotPanics(t, func() {
+		FromContext(context.Background()).SetActor("alice")
+	})
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"os"
+	"text/tabwriter"
+
+	db "github.com/crackz/simple-bank/db/sqlc"
+	"github.com/crackz/simple-bank/util"
+	_ "github.com/lib/pq"
+)
+
+// reconcile prints every ledger discrepancy found in the database and exits
+// with status 1 if there is any, so it can be used from cron or CI.
+func main() {
+	config, err := util.LoadConfig(".")
+	if err != nil {
+		log.Fatal("Couldn't load config")
+	}
+
+	conn, err := sql.Open(config.DBDriver, config.DBSource)
+	if err != nil {
+		log.Fatal("Couldn't Connect To DB : ", err)
+	}
+
+	store := db.NewStore(conn)
+	report, err := store.Reconcile(context.Background())
+	if err != nil {
+		log.Fatal("Couldn't Reconcile The Ledger : ", err)
+	}
+
+	printReport(report)
+	if !report.Balanced() {
+		os.Exit(1)
+	}
+}
+
+func printReport(report db.ReconciliationReport) {
+	fmt.Printf("Reconciliation at %s\n", report.CheckedAt.Format("2006-01-02 15:04:05 MST"))
+
+	if report.Balanced() {
+		fmt.Println("Ledger is balanced")
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+
+	if len(report.AccountDiscrepancies) > 0 {
+		fmt.Fprintf(w, "\nAccount discrepancies: %d\n", len(report.AccountDiscrepancies))
+		fmt.Fprintln(w, "ACCOUNT\tBALANCE\tENTRIES TOTAL\tDIFFERENCE")
+		for _, d := range report.AccountDiscrepancies {
+			fmt.Fprintf(w, "%d\t%d\t%d\t%d\n", d.AccountID, d.Balance, d.EntriesTotal, d.Balance-d.EntriesTotal)
+		}
+	}
+
+	if len(report.TransferDiscrepancies) > 0 {
+		fmt.Fprintf(w, "\nTransfer discrepancies: %d\n", len(report.TransferDiscrepancies))
+		fmt.Fprintln(w, "TRANSFER\tFROM\tTO\tAMOUNT\tTO AMOUNT\tDEBITS\tCREDITS\tENTRIES")
+		for _, d := range report.TransferDiscrepancies {
+			fmt.Fprintf(w, "%d\t%d\t%d\t%d\t%d\t%d\t%d\t%d\n", d.TransferID, d.FromAccountID, d.ToAccountID, d.Amount, d.ToAmount, d.DebitEntries, d.CreditEntries, d.TotalEntries)
+		}
+	}
+
+	w.Flush()
+}
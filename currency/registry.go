@@ -0,0 +1,130 @@
+package currency
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	db "github.com/crackz/simple-bank/db/sqlc"
+	"github.com/crackz/simple-bank/money"
+)
+
+// DefaultTTL is how long the currencies are cached when no ttl is given.
+const DefaultTTL = time.Minute
+
+var ErrUnknownCurrency = errors.New("unknown currency")
+
+// Store is what the registry loads currencies from, the db store outside of
+// tests.
+type Store interface {
+	ListCurrencies(ctx context.Context) ([]db.Currency, error)
+}
+
+// Registry caches the currencies table so looking up a currency on every
+// request doesn't need a query. The table is reloaded once the cache is older
+// than ttl, DefaultTTL if it's not positive, or on the next lookup after
+// Invalidate. The minor units of every loaded currency are registered with the
+// money package.
+type Registry struct {
+	store Store
+	ttl   time.Duration
+
+	mu         sync.Mutex
+	loadedAt   time.Time
+	currencies []db.Currency
+	byCode     map[string]db.Currency
+}
+
+func NewRegistry(store Store, ttl time.Duration) *Registry {
+	if ttl <= 0 {
+		ttl = DefaultTTL
+	}
+
+	return &Registry{store: store, ttl: ttl}
+}
+
+// Get returns a currency, enabled or not.
+func (registry *Registry) Get(ctx context.Context, code string) (db.Currency, error) {
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+
+	if err := registry.load(ctx); err != nil {
+		return db.Currency{}, err
+	}
+
+	currency, ok := registry.byCode[code]
+	if !ok {
+		return db.Currency{}, fmt.Errorf("%w: %s", ErrUnknownCurrency, code)
+	}
+
+	return currency, nil
+}
+
+// IsEnabled reports whether a currency can be used. It's false when the
+// currencies couldn't be loaded.
+func (registry *Registry) IsEnabled(ctx context.Context, code string) bool {
+	currency, err := registry.Get(ctx, code)
+	return err == nil && currency.Enabled
+}
+
+// Enabled returns the enabled currencies ordered by code.
+func (registry *Registry) Enabled(ctx context.Context) ([]db.Currency, error) {
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+
+	if err := registry.load(ctx); err != nil {
+		return nil, err
+	}
+
+	enabled := []db.Currency{}
+	for _, currency := range registry.currencies {
+		if currency.Enabled {
+			enabled = append(enabled, currency)
+		}
+	}
+
+	return enabled, nil
+}
+
+// Load loads the currencies unless the cache is still fresh. Calling it at
+// startup makes the minor units of every currency known to the money package
+// before the first request.
+func (registry *Registry) Load(ctx context.Context) error {
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+
+	return registry.load(ctx)
+}
+
+// Invalidate makes the next lookup reload the currencies, e.g. after one was
+// enabled or disabled.
+func (registry *Registry) Invalidate() {
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+
+	registry.loadedAt = time.Time{}
+}
+
+func (registry *Registry) load(ctx context.Context) error {
+	if !registry.loadedAt.IsZero() && time.Since(registry.loadedAt) < registry.ttl {
+		return nil
+	}
+
+	currencies, err := registry.store.ListCurrencies(ctx)
+	if err != nil {
+		return fmt.Errorf("couldn't load currencies: %w", err)
+	}
+
+	byCode := make(map[string]db.Currency, len(currencies))
+	for _, currency := range currencies {
+		byCode[currency.Code] = currency
+		money.SetExponent(currency.Code, int(currency.MinorUnits))
+	}
+
+	registry.currencies = currencies
+	registry.byCode = byCode
+	registry.loadedAt = time.Now()
+	return nil
+}
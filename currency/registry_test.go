@@ -0,0 +1,87 @@
+package currency
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	db "github.com/crackz/simple-bank/db/sqlc"
+	"github.com/crackz/simple-bank/money"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeStore struct {
+	currencies []db.Currency
+	err        error
+	calls      int
+}
+
+func (store *fakeStore) ListCurrencies(ctx context.Context) ([]db.Currency, error) {
+	store.calls++
+	return store.currencies, store.err
+}
+
+func TestRegistry(t *testing.T) {
+	store := &fakeStore{currencies: []db.Currency{
+		{Code: "BHD", MinorUnits: 3, Enabled: true},
+		{Code: "JPY", MinorUnits: 0},
+		{Code: "USD", MinorUnits: 2, Enabled: true},
+	}}
+	registry := NewRegistry(store, time.Hour)
+	ctx := context.Background()
+
+	require.True(t, registry.IsEnabled(ctx, "USD"))
+	require.False(t, registry.IsEnabled(ctx, "JPY"))
+	require.False(t, registry.IsEnabled(ctx, "XXX"))
+
+	currency, err := registry.Get(ctx, "JPY")
+	require.NoError(t, err)
+	require.Equal(t, "JPY", currency.Code)
+
+	_, err = registry.Get(ctx, "XXX")
+	require.ErrorIs(t, err, ErrUnknownCurrency)
+
+	enabled, err := registry.Enabled(ctx)
+	require.NoError(t, err)
+	require.Len(t, enabled, 2)
+	require.Equal(t, "BHD", enabled[0].Code)
+
+	// the cache is fresh, so the store was only read once
+	require.Equal(t, 1, store.calls)
+
+	// the minor units are known to the money package
+	m, err := money.Parse("1.234", "BHD")
+	require.NoError(t, err)
+	require.Equal(t, int64(1234), m.Amount)
+
+	store.currencies[1].Enabled = true
+	require.False(t, registry.IsEnabled(ctx, "JPY"))
+
+	registry.Invalidate()
+	require.True(t, registry.IsEnabled(ctx, "JPY"))
+	require.Equal(t, 2, store.calls)
+}
+
+func TestRegistryExpires(t *testing.T) {
+	store := &fakeStore{currencies: []db.Currency{{Code: "USD", MinorUnits: 2, Enabled: true}}}
+	registry := NewRegistry(store, time.Nanosecond)
+	ctx := context.Background()
+
+	require.NoError(t, registry.Load(ctx))
+	time.Sleep(time.Millisecond)
+	require.NoError(t, registry.Load(ctx))
+	require.Equal(t, 2, store.calls)
+}
+
+func TestRegistryLoadError(t *testing.T) {
+	storeErr := errors.New("connection refused")
+	registry := NewRegistry(&fakeStore{err: storeErr}, time.Hour)
+	ctx := context.Background()
+
+	require.ErrorIs(t, registry.Load(ctx), storeErr)
+	require.False(t, registry.IsEnabled(ctx, "USD"))
+
+	_, err := registry.Enabled(ctx)
+	require.ErrorIs(t, err, storeErr)
+}
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS "fx_quotes";
+ALTER TABLE IF EXISTS "transfers" DROP COLUMN IF EXISTS "exchange_rate";
+ALTER TABLE IF EXISTS "transfers" DROP COLUMN IF EXISTS "to_amount";
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS "account_holds";
+ALTER TABLE IF EXISTS "accounts" DROP COLUMN IF EXISTS "available_balance";
+ALTER TABLE IF EXISTS "accounts" DROP COLUMN IF EXISTS "held_balance";
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS "owner_currency_key";
+ALTER TABLE IF EXISTS "accounts" ADD CONSTRAINT "owner_currency_key" UNIQUE ("owner", "currency");
+ALTER TABLE IF EXISTS "accounts" DROP COLUMN IF EXISTS "status";
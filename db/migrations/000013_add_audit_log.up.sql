@@ -0,0 +1,37 @@
+CREATE TABLE "audit_log" (
+  "id" bigserial PRIMARY KEY,
+  "actor" varchar,
+  "method" varchar NOT NULL,
+  "route" varchar NOT NULL,
+  "status_code" int NOT NULL,
+  "target_type" varchar,
+  "target_id" varchar,
+  "before" jsonb NOT NULL DEFAULT 'null',
+  "after" jsonb NOT NULL DEFAULT 'null',
+  "client_ip" varchar NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "audit_log" ("actor");
+
+CREATE INDEX ON "audit_log" ("target_type", "target_id");
+
+CREATE INDEX ON "audit_log" ("created_at");
+
+COMMENT ON COLUMN "audit_log"."actor" IS 'username of the caller, null if unknown';
+
+COMMENT ON COLUMN "audit_log"."route" IS 'route pattern, e.g. /accounts/:accountID';
+
+CREATE FUNCTION "audit_log_append_only"() RETURNS trigger AS $$
+BEGIN
+  RAISE EXCEPTION 'audit_log is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER "audit_log_no_update_or_delete"
+BEFORE UPDATE OR DELETE ON "audit_log"
+FOR EACH ROW EXECUTE FUNCTION "audit_log_append_only"();
+
+CREATE TRIGGER "audit_log_no_truncate"
+BEFORE TRUNCATE ON "audit_log"
+FOR EACH STATEMENT EXECUTE FUNCTION "audit_log_append_only"();
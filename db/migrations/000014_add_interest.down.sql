@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS "interest_postings";
+DROP TABLE IF EXISTS "interest_accruals";
+DROP TABLE IF EXISTS "account_interest";
+DROP TABLE IF EXISTS "interest_plans";
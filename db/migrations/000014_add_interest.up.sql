@@ -0,0 +1,70 @@
+CREATE TABLE "interest_plans" (
+  "id" bigserial PRIMARY KEY,
+  "name" varchar NOT NULL,
+  "currency" varchar NOT NULL,
+  "annual_rate" numeric NOT NULL,
+  "day_count" varchar NOT NULL,
+  "expense_account_id" bigint NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "account_interest" (
+  "account_id" bigint PRIMARY KEY,
+  "plan_id" bigint NOT NULL,
+  "accrued" numeric NOT NULL DEFAULT 0,
+  "accrued_through" date NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "interest_accruals" (
+  "id" bigserial PRIMARY KEY,
+  "account_id" bigint NOT NULL,
+  "plan_id" bigint NOT NULL,
+  "accrual_date" date NOT NULL,
+  "balance" bigint NOT NULL,
+  "annual_rate" numeric NOT NULL,
+  "day_count" varchar NOT NULL,
+  "amount" numeric NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "interest_postings" (
+  "id" bigserial PRIMARY KEY,
+  "account_id" bigint NOT NULL,
+  "period_end" date NOT NULL,
+  "amount" bigint NOT NULL,
+  "transfer_id" bigint NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "account_interest" ("accrued_through");
+
+CREATE UNIQUE INDEX ON "interest_accruals" ("account_id", "accrual_date");
+
+CREATE UNIQUE INDEX ON "interest_postings" ("account_id", "period_end");
+
+COMMENT ON COLUMN "interest_plans"."annual_rate" IS 'yearly rate as a fraction, e.g. 0.025 for 2.5%';
+
+COMMENT ON COLUMN "interest_plans"."day_count" IS 'ACT/365 or 30/360';
+
+COMMENT ON COLUMN "interest_plans"."expense_account_id" IS 'bank-owned account the interest is paid from';
+
+COMMENT ON COLUMN "account_interest"."accrued" IS 'interest accrued but not posted yet, in fractions of minor units';
+
+COMMENT ON COLUMN "account_interest"."accrued_through" IS 'last day interest was accrued for';
+
+COMMENT ON COLUMN "interest_accruals"."balance" IS 'end-of-day balance the interest was computed on';
+
+ALTER TABLE "interest_plans" ADD FOREIGN KEY ("expense_account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "account_interest" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "account_interest" ADD FOREIGN KEY ("plan_id") REFERENCES "interest_plans" ("id");
+
+ALTER TABLE "interest_accruals" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "interest_accruals" ADD FOREIGN KEY ("plan_id") REFERENCES "interest_plans" ("id");
+
+ALTER TABLE "interest_postings" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "interest_postings" ADD FOREIGN KEY ("transfer_id") REFERENCES "transfers" ("id");
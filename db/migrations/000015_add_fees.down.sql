@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS "transfer_fees";
+DROP TABLE IF EXISTS "fee_schedules";
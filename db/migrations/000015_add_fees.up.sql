@@ -0,0 +1,37 @@
+CREATE TABLE "fee_schedules" (
+  "id" bigserial PRIMARY KEY,
+  "currency" varchar NOT NULL,
+  "flat_amount" bigint NOT NULL DEFAULT 0,
+  "percentage" numeric NOT NULL DEFAULT 0,
+  "min_amount" bigint NOT NULL DEFAULT 0,
+  "max_amount" bigint,
+  "revenue_account_id" bigint NOT NULL,
+  "active" boolean NOT NULL DEFAULT true,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "transfer_fees" (
+  "transfer_id" bigint PRIMARY KEY,
+  "fee_transfer_id" bigint UNIQUE NOT NULL,
+  "fee_schedule_id" bigint NOT NULL,
+  "amount" bigint NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE UNIQUE INDEX ON "fee_schedules" ("currency") WHERE "active";
+
+COMMENT ON COLUMN "fee_schedules"."percentage" IS 'fraction of the amount, e.g. 0.005 for 0.5%';
+
+COMMENT ON COLUMN "fee_schedules"."max_amount" IS 'no cap when null';
+
+COMMENT ON COLUMN "fee_schedules"."revenue_account_id" IS 'bank-owned account the fees are paid to';
+
+COMMENT ON COLUMN "transfer_fees"."fee_transfer_id" IS 'transfer paying the fee to the revenue account';
+
+ALTER TABLE "fee_schedules" ADD FOREIGN KEY ("revenue_account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "transfer_fees" ADD FOREIGN KEY ("transfer_id") REFERENCES "transfers" ("id");
+
+ALTER TABLE "transfer_fees" ADD FOREIGN KEY ("fee_transfer_id") REFERENCES "transfers" ("id");
+
+ALTER TABLE "transfer_fees" ADD FOREIGN KEY ("fee_schedule_id") REFERENCES "fee_schedules" ("id");
@@ -0,0 +1,19 @@
+CREATE TABLE "transfer_limits" (
+  "username" varchar NOT NULL,
+  "currency" varchar NOT NULL,
+  "per_transaction" bigint,
+  "daily" bigint,
+  "monthly" bigint,
+  "updated_at" timestamptz NOT NULL DEFAULT (now()),
+  PRIMARY KEY ("username", "currency")
+);
+
+CREATE INDEX ON "transfers" ("from_account_id", "created_at");
+
+COMMENT ON COLUMN "transfer_limits"."per_transaction" IS 'no limit when null';
+
+COMMENT ON COLUMN "transfer_limits"."daily" IS 'limit over the last 24 hours, no limit when null';
+
+COMMENT ON COLUMN "transfer_limits"."monthly" IS 'limit over the last 30 days, no limit when null';
+
+ALTER TABLE "transfer_limits" ADD FOREIGN KEY ("username") REFERENCES "users" ("username");
@@ -0,0 +1,34 @@
+CREATE TABLE "risk_decisions" (
+  "id" bigserial PRIMARY KEY,
+  "owner" varchar NOT NULL,
+  "from_account_id" bigint NOT NULL,
+  "to_account_id" bigint NOT NULL,
+  "amount" bigint NOT NULL,
+  "outcome" varchar NOT NULL,
+  "rules" varchar[] NOT NULL,
+  "status" varchar NOT NULL,
+  "transfer_id" bigint UNIQUE,
+  "reviewed_by" varchar,
+  "reviewed_at" timestamptz,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "risk_decisions" ("status", "id");
+
+COMMENT ON COLUMN "risk_decisions"."outcome" IS 'allow, review or block';
+
+COMMENT ON COLUMN "risk_decisions"."rules" IS 'rules the transfer triggered';
+
+COMMENT ON COLUMN "risk_decisions"."status" IS 'allowed, pending, approved, rejected or blocked';
+
+COMMENT ON COLUMN "risk_decisions"."transfer_id" IS 'set once the transfer is made';
+
+ALTER TABLE "risk_decisions" ADD FOREIGN KEY ("owner") REFERENCES "users" ("username");
+
+ALTER TABLE "risk_decisions" ADD FOREIGN KEY ("from_account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "risk_decisions" ADD FOREIGN KEY ("to_account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "risk_decisions" ADD FOREIGN KEY ("transfer_id") REFERENCES "transfers" ("id");
+
+ALTER TABLE "risk_decisions" ADD FOREIGN KEY ("reviewed_by") REFERENCES "users" ("username");
@@ -0,0 +1,15 @@
+ALTER TABLE "accounts" DROP CONSTRAINT IF EXISTS "accounts_currency_fkey";
+ALTER TABLE "fee_schedules" DROP CONSTRAINT IF EXISTS "fee_schedules_currency_fkey";
+ALTER TABLE "interest_plans" DROP CONSTRAINT IF EXISTS "interest_plans_currency_fkey";
+ALTER TABLE "transfer_limits" DROP CONSTRAINT IF EXISTS "transfer_limits_currency_fkey";
+ALTER TABLE "fx_quotes" DROP CONSTRAINT IF EXISTS "fx_quotes_from_currency_fkey";
+ALTER TABLE "fx_quotes" DROP CONSTRAINT IF EXISTS "fx_quotes_to_currency_fkey";
+
+UPDATE "accounts" SET "currency" = 'EURO' WHERE "currency" = 'EUR';
+UPDATE "fee_schedules" SET "currency" = 'EURO' WHERE "currency" = 'EUR';
+UPDATE "interest_plans" SET "currency" = 'EURO' WHERE "currency" = 'EUR';
+UPDATE "transfer_limits" SET "currency" = 'EURO' WHERE "currency" = 'EUR';
+UPDATE "fx_quotes" SET "from_currency" = 'EURO' WHERE "from_currency" = 'EUR';
+UPDATE "fx_quotes" SET "to_currency" = 'EURO' WHERE "to_currency" = 'EUR';
+
+DROP TABLE IF EXISTS "currencies";
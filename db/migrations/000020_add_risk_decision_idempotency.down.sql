@@ -0,0 +1,5 @@
+ALTER TABLE IF EXISTS "risk_decisions" DROP CONSTRAINT IF EXISTS "risk_decisions_owner_idempotency_key_key";
+
+ALTER TABLE IF EXISTS "risk_decisions" DROP COLUMN IF EXISTS "request_hash";
+
+ALTER TABLE IF EXISTS "risk_decisions" DROP COLUMN IF EXISTS "idempotency_key";
@@ -0,0 +1,7 @@
+-- a transfer held for review keeps the key it was sent with, so retrying it
+-- returns the decision and approving it stores the result under the key
+ALTER TABLE "risk_decisions" ADD COLUMN "idempotency_key" varchar;
+
+ALTER TABLE "risk_decisions" ADD COLUMN "request_hash" varchar;
+
+ALTER TABLE "risk_decisions" ADD CONSTRAINT "risk_decisions_owner_idempotency_key_key" UNIQUE ("owner", "idempotency_key");
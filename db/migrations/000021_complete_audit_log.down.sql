@@ -0,0 +1,9 @@
+CREATE OR REPLACE FUNCTION "audit_log_append_only"() RETURNS trigger AS $$
+BEGIN
+  RAISE EXCEPTION 'audit_log is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+COMMENT ON COLUMN "audit_log"."status_code" IS NULL;
+
+ALTER TABLE IF EXISTS "audit_log" ALTER COLUMN "status_code" SET NOT NULL;
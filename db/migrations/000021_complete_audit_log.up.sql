@@ -0,0 +1,30 @@
+-- an entry is written in the transaction of the change a call makes, before
+-- the call is answered, and completed once with the response
+ALTER TABLE "audit_log" ALTER COLUMN "status_code" DROP NOT NULL;
+
+COMMENT ON COLUMN "audit_log"."status_code" IS 'null until the call is answered';
+
+CREATE OR REPLACE FUNCTION "audit_log_append_only"() RETURNS trigger AS $$
+BEGIN
+  -- an unanswered entry may only be given its status code, after snapshot and
+  -- missing target
+  IF TG_OP = 'UPDATE' THEN
+    IF OLD.status_code IS NULL AND NEW.status_code IS NOT NULL
+      AND NEW.id = OLD.id
+      AND NEW.actor IS NOT DISTINCT FROM OLD.actor
+      AND NEW.method = OLD.method
+      AND NEW.route = OLD.route
+      AND (OLD.target_type IS NULL OR NEW.target_type = OLD.target_type)
+      AND (OLD.target_id IS NULL OR NEW.target_id = OLD.target_id)
+      AND NEW.before = OLD.before
+      AND OLD.after = 'null'
+      AND NEW.client_ip = OLD.client_ip
+      AND NEW.created_at = OLD.created_at
+    THEN
+      RETURN NEW;
+    END IF;
+  END IF;
+
+  RAISE EXCEPTION 'audit_log is append-only';
+END;
+$$ LANGUAGE plpgsql;
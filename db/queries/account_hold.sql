@@ -0,0 +1,31 @@
+-- name: CreateAccountHold :one
+INSERT INTO account_holds (
+  account_id,
+  to_account_id,
+  amount,
+  expires_at
+) VALUES (
+  $1, $2, $3, $4
+) RETURNING *;
+
+-- name: GetAccountHold :one
+SELECT * FROM account_holds
+WHERE id = $1 LIMIT 1;
+
+-- name: GetAccountHoldForUpdate :one
+SELECT * FROM account_holds
+WHERE id = $1 LIMIT 1
+FOR NO KEY UPDATE;
+
+-- name: ClaimExpiredAccountHolds :many
+SELECT * FROM account_holds
+WHERE status = 'active' AND expires_at <= now()
+ORDER BY expires_at
+LIMIT $1
+FOR NO KEY UPDATE SKIP LOCKED;
+
+-- name: ResolveAccountHold :one
+UPDATE account_holds
+SET status = $2, transfer_id = $3, resolved_at = now()
+WHERE id = $1
+RETURNING *;
@@ -0,0 +1,36 @@
+-- name: CreateAuditLog :one
+INSERT INTO audit_log (
+  actor,
+  method,
+  route,
+  status_code,
+  target_type,
+  target_id,
+  before,
+  after,
+  client_ip
+) VALUES (
+  $1, $2, $3, $4, $5, $6, $7, $8, $9
+) RETURNING *;
+
+-- name: CompleteAuditLog :one
+UPDATE audit_log
+SET
+  status_code = $2,
+  target_type = COALESCE(target_type, $3),
+  target_id = COALESCE(target_id, $4),
+  after = $5
+WHERE id = $1
+RETURNING *;
+
+-- name: ListAuditLogs :many
+SELECT * FROM audit_log
+WHERE (sqlc.narg(actor)::varchar IS NULL OR actor = sqlc.narg(actor))
+  AND (sqlc.narg(target_type)::varchar IS NULL OR target_type = sqlc.narg(target_type))
+  AND (sqlc.narg(target_id)::varchar IS NULL OR target_id = sqlc.narg(target_id))
+  AND (sqlc.narg(route)::varchar IS NULL OR route = sqlc.narg(route))
+  AND (sqlc.narg(from_time)::timestamptz IS NULL OR created_at >= sqlc.narg(from_time))
+  AND (sqlc.narg(to_time)::timestamptz IS NULL OR created_at < sqlc.narg(to_time))
+ORDER BY id DESC
+LIMIT sqlc.arg(limit_count)
+OFFSET sqlc.arg(offset_count);
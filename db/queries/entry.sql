@@ -9,10 +9,57 @@ ORDER BY id
 LIMIT $2
 OFFSET $3;
 
+-- name: ListEntriesAfter :many
+SELECT * FROM entries
+WHERE
+  account_id = sqlc.arg(account_id) AND
+  (created_at, id) > (sqlc.arg(after_created_at)::timestamptz, sqlc.arg(after_id)::bigint)
+ORDER BY created_at, id
+LIMIT sqlc.arg(limit_count);
+
 
 -- name: CreateEntry :one
 INSERT INTO entries (
-  account_id, amount
+  account_id, amount, transfer_id
 ) VALUES (
-  $1, $2
+  $1, $2, $3
 ) RETURNING *;
+
+-- name: GetAccountBalanceBefore :one
+SELECT COALESCE(SUM(amount), 0)::bigint AS balance FROM entries
+WHERE account_id = $1 AND created_at < $2;
+
+-- name: ListAccountStatementEntries :many
+SELECT
+  e.id,
+  e.account_id,
+  e.amount,
+  e.created_at,
+  e.transfer_id,
+  t.from_account_id,
+  t.to_account_id
+FROM entries e
+LEFT JOIN transfers t ON t.id = e.transfer_id
+WHERE
+  e.account_id = sqlc.arg(account_id) AND
+  e.created_at >= sqlc.arg(from_time) AND
+  e.created_at < sqlc.arg(to_time)
+ORDER BY e.created_at, e.id;
+
+-- name: ListAccountStatementEntriesAfter :many
+SELECT
+  e.id,
+  e.account_id,
+  e.amount,
+  e.created_at,
+  e.transfer_id,
+  t.from_account_id,
+  t.to_account_id
+FROM entries e
+LEFT JOIN transfers t ON t.id = e.transfer_id
+WHERE
+  e.account_id = sqlc.arg(account_id) AND
+  e.created_at < sqlc.arg(to_time) AND
+  (e.created_at, e.id) > (sqlc.arg(after_created_at)::timestamptz, sqlc.arg(after_id)::bigint)
+ORDER BY e.created_at, e.id
+LIMIT sqlc.arg(limit_count);
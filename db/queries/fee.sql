@@ -0,0 +1,59 @@
+-- name: CreateFeeSchedule :one
+INSERT INTO fee_schedules (
+  currency,
+  flat_amount,
+  percentage,
+  min_amount,
+  max_amount,
+  revenue_account_id
+) VALUES (
+  $1, $2, $3, $4, $5, $6
+) RETURNING *;
+
+-- name: GetFeeSchedule :one
+SELECT * FROM fee_schedules
+WHERE id = $1 LIMIT 1;
+
+-- name: GetActiveFeeScheduleForAccount :one
+SELECT fs.* FROM fee_schedules fs
+JOIN accounts a ON a.currency = fs.currency
+WHERE a.id = $1 AND fs.active
+LIMIT 1;
+
+-- name: ListFeeSchedules :many
+SELECT * FROM fee_schedules
+ORDER BY id
+LIMIT $1
+OFFSET $2;
+
+-- name: DeactivateFeeSchedules :many
+UPDATE fee_schedules
+SET active = false
+WHERE currency = $1 AND active
+RETURNING *;
+
+-- name: DeactivateFeeSchedule :one
+UPDATE fee_schedules
+SET active = false
+WHERE id = $1
+RETURNING *;
+
+-- name: CreateTransferFee :one
+INSERT INTO transfer_fees (
+  transfer_id,
+  fee_transfer_id,
+  fee_schedule_id,
+  amount
+) VALUES (
+  $1, $2, $3, $4
+) RETURNING *;
+
+-- name: GetTransferFee :one
+SELECT * FROM transfer_fees
+WHERE transfer_id = $1 LIMIT 1;
+
+-- name: IsFeeTransfer :one
+SELECT EXISTS (
+  SELECT 1 FROM transfer_fees
+  WHERE fee_transfer_id = $1
+);
@@ -0,0 +1,25 @@
+-- name: CreateFxQuote :one
+INSERT INTO fx_quotes (
+  owner,
+  from_currency,
+  to_currency,
+  rate,
+  expires_at
+) VALUES (
+  $1, $2, $3, $4, $5
+) RETURNING *;
+
+-- name: GetFxQuote :one
+SELECT * FROM fx_quotes
+WHERE id = $1 LIMIT 1;
+
+-- name: GetFxQuoteForUpdate :one
+SELECT * FROM fx_quotes
+WHERE id = $1 LIMIT 1
+FOR NO KEY UPDATE;
+
+-- name: MarkFxQuoteUsed :one
+UPDATE fx_quotes
+SET used_at = now()
+WHERE id = $1
+RETURNING *;
@@ -0,0 +1,13 @@
+-- name: GetIdempotencyKey :one
+SELECT * FROM idempotency_keys
+WHERE owner = $1 AND key = $2 LIMIT 1;
+
+-- name: CreateIdempotencyKey :one
+INSERT INTO idempotency_keys (
+  owner,
+  key,
+  request_hash,
+  response
+) VALUES (
+  $1, $2, $3, $4
+) RETURNING *;
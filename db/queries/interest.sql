@@ -0,0 +1,102 @@
+-- name: CreateInterestPlan :one
+INSERT INTO interest_plans (
+  name,
+  currency,
+  annual_rate,
+  day_count,
+  expense_account_id
+) VALUES (
+  $1, $2, $3, $4, $5
+) RETURNING *;
+
+-- name: GetInterestPlan :one
+SELECT * FROM interest_plans
+WHERE id = $1 LIMIT 1;
+
+-- name: ListInterestPlans :many
+SELECT * FROM interest_plans
+ORDER BY id
+LIMIT $1
+OFFSET $2;
+
+-- name: GetAccountInterest :one
+SELECT * FROM account_interest
+WHERE account_id = $1 LIMIT 1;
+
+-- name: GetAccountInterestForUpdate :one
+SELECT * FROM account_interest
+WHERE account_id = $1 LIMIT 1
+FOR NO KEY UPDATE;
+
+-- name: AssignInterestPlan :one
+INSERT INTO account_interest (
+  account_id,
+  plan_id,
+  accrued_through
+) VALUES (
+  $1, $2, $3
+)
+ON CONFLICT (account_id) DO UPDATE SET plan_id = EXCLUDED.plan_id
+RETURNING *;
+
+-- name: ListDueAccountInterest :many
+SELECT ai.account_id FROM account_interest ai
+JOIN accounts a ON a.id = ai.account_id
+WHERE
+  ai.accrued_through < sqlc.arg(through) AND
+  ai.account_id > sqlc.arg(after_account_id) AND
+  a.status <> 'closed'
+ORDER BY ai.account_id
+LIMIT sqlc.arg(batch_size);
+
+-- name: UpdateAccountInterestAccrued :one
+UPDATE account_interest
+SET
+  accrued = $2,
+  accrued_through = $3
+WHERE account_id = $1
+RETURNING *;
+
+-- name: GetAccountBalanceAt :one
+SELECT (a.balance - COALESCE(SUM(e.amount), 0))::bigint AS balance
+FROM accounts a
+LEFT JOIN entries e ON e.account_id = a.id AND e.created_at >= sqlc.arg(at)
+WHERE a.id = sqlc.arg(account_id)
+GROUP BY a.id;
+
+-- name: CreateInterestAccrual :one
+INSERT INTO interest_accruals (
+  account_id,
+  plan_id,
+  accrual_date,
+  balance,
+  annual_rate,
+  day_count,
+  amount
+) VALUES (
+  $1, $2, $3, $4, $5, $6, $7
+) RETURNING *;
+
+-- name: ListInterestAccruals :many
+SELECT * FROM interest_accruals
+WHERE account_id = $1
+ORDER BY accrual_date DESC
+LIMIT $2
+OFFSET $3;
+
+-- name: CreateInterestPosting :one
+INSERT INTO interest_postings (
+  account_id,
+  period_end,
+  amount,
+  transfer_id
+) VALUES (
+  $1, $2, $3, $4
+) RETURNING *;
+
+-- name: ListInterestPostings :many
+SELECT * FROM interest_postings
+WHERE account_id = $1
+ORDER BY period_end DESC
+LIMIT $2
+OFFSET $3;
@@ -0,0 +1,29 @@
+-- name: ListAccountBalanceDiscrepancies :many
+SELECT
+  a.id AS account_id,
+  a.balance,
+  COALESCE(SUM(e.amount), 0)::bigint AS entries_total
+FROM accounts a
+LEFT JOIN entries e ON e.account_id = a.id
+GROUP BY a.id
+HAVING a.balance <> COALESCE(SUM(e.amount), 0)
+ORDER BY a.id;
+
+-- name: ListUnbalancedTransfers :many
+SELECT
+  t.id AS transfer_id,
+  t.from_account_id,
+  t.to_account_id,
+  t.amount,
+  t.to_amount,
+  COUNT(e.id) FILTER (WHERE e.account_id = t.from_account_id AND e.amount = -t.amount)::int AS debit_entries,
+  COUNT(e.id) FILTER (WHERE e.account_id = t.to_account_id AND e.amount = t.to_amount)::int AS credit_entries,
+  COUNT(e.id)::int AS total_entries
+FROM transfers t
+LEFT JOIN entries e ON e.transfer_id = t.id
+GROUP BY t.id
+HAVING
+  COUNT(e.id) FILTER (WHERE e.account_id = t.from_account_id AND e.amount = -t.amount) <> 1 OR
+  COUNT(e.id) FILTER (WHERE e.account_id = t.to_account_id AND e.amount = t.to_amount) <> 1 OR
+  COUNT(e.id) <> 2
+ORDER BY t.id;
@@ -0,0 +1,73 @@
+-- name: CreateRiskDecision :one
+INSERT INTO risk_decisions (
+  owner,
+  from_account_id,
+  to_account_id,
+  amount,
+  outcome,
+  rules,
+  status,
+  transfer_id,
+  idempotency_key,
+  request_hash
+) VALUES (
+  $1, $2, $3, $4, $5, $6, $7, $8, $9, $10
+)
+ON CONFLICT ON CONSTRAINT risk_decisions_owner_idempotency_key_key DO NOTHING
+RETURNING *;
+
+-- name: GetRiskDecision :one
+SELECT * FROM risk_decisions
+WHERE id = $1 LIMIT 1;
+
+-- name: GetRiskDecisionForUpdate :one
+SELECT * FROM risk_decisions
+WHERE id = $1 LIMIT 1
+FOR NO KEY UPDATE;
+
+-- name: GetRiskDecisionByTransfer :one
+SELECT * FROM risk_decisions
+WHERE transfer_id = $1 LIMIT 1;
+
+-- name: GetRiskDecisionByIdempotencyKey :one
+SELECT * FROM risk_decisions
+WHERE owner = $1 AND idempotency_key = $2 LIMIT 1;
+
+-- name: ListRiskDecisionsByStatus :many
+SELECT * FROM risk_decisions
+WHERE status = $1
+ORDER BY id
+LIMIT $2
+OFFSET $3;
+
+-- name: UpdateRiskDecisionReview :one
+UPDATE risk_decisions
+SET status = $2,
+    transfer_id = $3,
+    reviewed_by = $4,
+    reviewed_at = now()
+WHERE id = $1
+RETURNING *;
+
+-- name: GetTransferRiskFacts :one
+WITH sent AS (
+  SELECT t.to_account_id, t.amount, t.created_at
+  FROM transfers t
+  JOIN accounts a ON a.id = t.from_account_id
+  WHERE a.owner = sqlc.arg(username)
+    AND t.reversal_of IS NULL
+    AND NOT EXISTS (
+      SELECT 1 FROM transfer_fees tf
+      WHERE tf.fee_transfer_id = t.id
+    )
+)
+SELECT
+  u.password_changed_at,
+  (SELECT COUNT(*) FROM sent WHERE sent.created_at > sqlc.arg(velocity_since)) AS recent_transfers,
+  (SELECT COUNT(*) FROM sent WHERE sent.to_account_id = sqlc.arg(to_account_id)) AS payee_transfers,
+  (SELECT COUNT(*) FROM sent
+   WHERE sent.created_at > sqlc.arg(round_since)
+     AND sent.amount % NULLIF(sqlc.arg(round_unit)::bigint, 0) = 0) AS round_transfers,
+  (SELECT COUNT(*) FROM sent WHERE sent.created_at > u.password_changed_at) AS transfers_since_password_change
+FROM users u
+WHERE u.username = sqlc.arg(username);
@@ -0,0 +1,80 @@
+-- name: CreateScheduledTransfer :one
+INSERT INTO scheduled_transfers (
+  owner,
+  from_account_id,
+  to_account_id,
+  amount,
+  frequency,
+  start_at,
+  next_run_at
+) VALUES (
+  $1, $2, $3, $4, $5, $6, $6
+) RETURNING *;
+
+-- name: GetScheduledTransfer :one
+SELECT * FROM scheduled_transfers
+WHERE id = $1 LIMIT 1;
+
+-- name: GetScheduledTransferForUpdate :one
+SELECT * FROM scheduled_transfers
+WHERE id = $1 LIMIT 1
+FOR NO KEY UPDATE;
+
+-- name: ListScheduledTransfers :many
+SELECT * FROM scheduled_transfers
+WHERE owner = $1
+ORDER BY id
+LIMIT $2
+OFFSET $3;
+
+-- name: UpdateScheduledTransfer :one
+UPDATE scheduled_transfers
+SET
+  amount = COALESCE(sqlc.narg(amount), amount),
+  frequency = COALESCE(sqlc.narg(frequency), frequency),
+  start_at = COALESCE(sqlc.narg(start_at), start_at),
+  next_run_at = COALESCE(sqlc.narg(next_run_at), sqlc.narg(start_at), next_run_at),
+  active = COALESCE(sqlc.narg(active), active)
+WHERE id = sqlc.arg(id)
+RETURNING *;
+
+-- name: DeleteScheduledTransfer :exec
+DELETE FROM scheduled_transfers
+WHERE id = $1;
+
+-- name: ClaimDueScheduledTransfers :many
+SELECT * FROM scheduled_transfers
+WHERE active AND next_run_at <= now()
+ORDER BY next_run_at
+LIMIT $1
+FOR UPDATE SKIP LOCKED;
+
+-- name: UpdateScheduledTransferNextRun :one
+UPDATE scheduled_transfers
+SET next_run_at = $2, active = $3
+WHERE id = $1
+RETURNING *;
+
+-- name: CreateScheduledTransferRun :one
+INSERT INTO scheduled_transfer_runs (
+  scheduled_transfer_id,
+  scheduled_for,
+  transfer_id,
+  status,
+  error
+) VALUES (
+  $1, $2, $3, $4, $5
+) RETURNING *;
+
+-- name: HasScheduledTransferRuns :one
+SELECT EXISTS (
+  SELECT 1 FROM scheduled_transfer_runs
+  WHERE scheduled_transfer_id = $1
+);
+
+-- name: ListScheduledTransferRuns :many
+SELECT * FROM scheduled_transfer_runs
+WHERE scheduled_transfer_id = $1
+ORDER BY id DESC
+LIMIT $2
+OFFSET $3;
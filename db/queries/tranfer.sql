@@ -5,19 +5,93 @@ WHERE id = $1 LIMIT 1;
 
 -- name: ListTransfers :many
 SELECT * FROM transfers
-WHERE 
-    from_account_id = $1 OR
-    to_account_id = $2
+WHERE
+    from_account_id = sqlc.arg(account_id) OR
+    to_account_id = sqlc.arg(account_id)
 ORDER BY id
-LIMIT $3
-OFFSET $4;
+LIMIT sqlc.arg(limit_count)
+OFFSET sqlc.arg(offset_count);
+
+-- name: ListTransfersAfter :many
+SELECT
+  t.*,
+  fa.currency AS from_currency,
+  ta.currency AS to_currency
+FROM transfers t
+JOIN accounts fa ON fa.id = t.from_account_id
+JOIN accounts ta ON ta.id = t.to_account_id
+WHERE
+  (t.from_account_id = sqlc.arg(account_id) OR t.to_account_id = sqlc.arg(account_id)) AND
+  (t.created_at, t.id) > (sqlc.arg(after_created_at)::timestamptz, sqlc.arg(after_id)::bigint)
+ORDER BY t.created_at, t.id
+LIMIT sqlc.arg(limit_count);
+
+-- name: ListUserTransfers :many
+SELECT
+  t.*,
+  fa.currency AS from_currency,
+  ta.currency AS to_currency
+FROM transfers t
+JOIN accounts fa ON fa.id = t.from_account_id
+JOIN accounts ta ON ta.id = t.to_account_id
+WHERE
+  (
+    (sqlc.arg(outgoing)::boolean AND fa.owner = sqlc.arg(owner) AND (sqlc.narg(account_id)::bigint IS NULL OR fa.id = sqlc.narg(account_id))) OR
+    (sqlc.arg(incoming)::boolean AND ta.owner = sqlc.arg(owner) AND (sqlc.narg(account_id)::bigint IS NULL OR ta.id = sqlc.narg(account_id)))
+  )
+  AND (sqlc.narg(currency)::varchar IS NULL OR fa.currency = sqlc.narg(currency) OR ta.currency = sqlc.narg(currency))
+  AND (sqlc.narg(min_amount)::bigint IS NULL OR (CASE WHEN fa.currency = sqlc.narg(currency) THEN t.amount ELSE t.to_amount END) >= sqlc.narg(min_amount))
+  AND (sqlc.narg(max_amount)::bigint IS NULL OR (CASE WHEN fa.currency = sqlc.narg(currency) THEN t.amount ELSE t.to_amount END) <= sqlc.narg(max_amount))
+  AND (sqlc.narg(from_time)::timestamptz IS NULL OR t.created_at >= sqlc.narg(from_time))
+  AND (sqlc.narg(to_time)::timestamptz IS NULL OR t.created_at < sqlc.narg(to_time))
+  AND (t.created_at, t.id) > (sqlc.arg(after_created_at)::timestamptz, sqlc.arg(after_id)::bigint)
+ORDER BY t.created_at, t.id
+LIMIT sqlc.arg(limit_count);
 
 -- name: CreateTransfer :one
 INSERT INTO transfers (
   from_account_id, 
   to_account_id, 
-  amount
+  amount,
+  to_amount
+) VALUES (
+  $1, $2, $3, $3
+) RETURNING *;
+
+-- name: CreateExchangeTransfer :one
+INSERT INTO transfers (
+  from_account_id,
+  to_account_id,
+  amount,
+  to_amount,
+  exchange_rate
 ) VALUES (
-  $1, $2, $3
+  $1, $2, $3, $4, $5
 ) RETURNING *;
 
+
+-- name: GetTransferForUpdate :one
+SELECT * FROM transfers
+WHERE id = $1 LIMIT 1
+FOR NO KEY UPDATE;
+
+-- name: CreateReversalTransfer :one
+INSERT INTO transfers (
+  from_account_id,
+  to_account_id,
+  amount,
+  to_amount,
+  exchange_rate,
+  reversal_of
+) VALUES (
+  $1, $2, $3, $4, $5, $6
+) RETURNING *;
+
+-- name: ListTransferReversals :many
+SELECT * FROM transfers
+WHERE reversal_of = $1
+ORDER BY id;
+
+-- name: GetReversedAmount :one
+SELECT COALESCE(SUM(to_amount), 0)::bigint AS reversed_amount FROM transfers
+WHERE reversal_of = $1;
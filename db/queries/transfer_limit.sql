@@ -0,0 +1,41 @@
+-- name: UpsertTransferLimit :one
+INSERT INTO transfer_limits (
+  username,
+  currency,
+  per_transaction,
+  daily,
+  monthly
+) VALUES (
+  $1, $2, $3, $4, $5
+)
+ON CONFLICT (username, currency) DO UPDATE
+SET per_transaction = EXCLUDED.per_transaction,
+    daily = EXCLUDED.daily,
+    monthly = EXCLUDED.monthly,
+    updated_at = now()
+RETURNING *;
+
+-- name: GetTransferLimitForUpdate :one
+SELECT * FROM transfer_limits
+WHERE username = $1 AND currency = $2 LIMIT 1
+FOR NO KEY UPDATE;
+
+-- name: ListTransferLimits :many
+SELECT * FROM transfer_limits
+WHERE username = $1
+ORDER BY currency;
+
+-- name: GetTransferredAmounts :one
+SELECT
+  COALESCE(SUM(t.amount) FILTER (WHERE t.created_at > sqlc.arg(day_start)), 0)::bigint AS daily,
+  COALESCE(SUM(t.amount), 0)::bigint AS monthly
+FROM transfers t
+JOIN accounts a ON a.id = t.from_account_id
+WHERE a.owner = sqlc.arg(owner)
+  AND a.currency = sqlc.arg(currency)
+  AND t.created_at > sqlc.arg(month_start)
+  AND t.reversal_of IS NULL
+  AND NOT EXISTS (
+    SELECT 1 FROM transfer_fees tf
+    WHERE tf.fee_transfer_id = t.id
+  );
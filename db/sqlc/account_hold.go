@@ -0,0 +1,262 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/crackz/simple-bank/risk"
+)
+
+const (
+	HoldActive   = "active"
+	HoldCaptured = "captured"
+	HoldVoided   = "voided"
+	HoldExpired  = "expired"
+)
+
+var (
+	ErrHoldNotFound       = errors.New("hold not found")
+	ErrHoldNotActive      = errors.New("hold is no longer active")
+	ErrHoldExpired        = errors.New("hold has expired")
+	ErrCaptureExceedsHold = errors.New("capture amount exceeds the held amount")
+)
+
+type CreateHoldTxParams struct {
+	AccountID   int64     `json:"accountId"`
+	ToAccountID int64     `json:"toAccountId"`
+	Amount      int64     `json:"amount"`
+	ExpiresAt   time.Time `json:"expiresAt"`
+
+	Idempotency *IdempotencyKeyParams `json:"-"`
+}
+
+type HoldTxResult struct {
+	Hold    AccountHold `json:"hold"`
+	Account Account     `json:"account"`
+	// Transfer is only set when the hold was captured.
+	Transfer *TransferTxResult `json:"transfer,omitempty"`
+}
+
+// CreateHoldTx reserves funds of an account for a later payment to another
+// account. Held funds stay in the balance but no longer count towards the
+// available balance. The hold only reserves the amount, but the available
+// balance must also cover the fee a capture of the whole hold would be charged
+// now, and the hold must fit the transfer limits of the account's owner, so a
+// hold that could never be captured is refused upfront.
+func (store *SQLStore) CreateHoldTx(ctx context.Context, arg CreateHoldTxParams) (HoldTxResult, error) {
+	var result HoldTxResult
+
+	err := store.execIdempotentTx(ctx, arg.Idempotency, &result, func(q *Queries) error {
+		_, fee, err := transferFee(ctx, q, arg.AccountID, arg.Amount)
+		if err != nil {
+			return err
+		}
+
+		accounts, err := lockAccounts(ctx, q, arg.AccountID, arg.ToAccountID)
+		if err != nil {
+			return err
+		}
+
+		err = checkTransfer(accounts[arg.AccountID], accounts[arg.ToAccountID], arg.Amount+fee)
+		if err != nil {
+			return err
+		}
+		if err := checkTransferLimit(ctx, q, accounts[arg.AccountID], arg.Amount); err != nil {
+			return err
+		}
+
+		result.Hold, err = q.CreateAccountHold(ctx, CreateAccountHoldParams{
+			AccountID:   arg.AccountID,
+			ToAccountID: arg.ToAccountID,
+			Amount:      arg.Amount,
+			ExpiresAt:   arg.ExpiresAt,
+		})
+		if err != nil {
+			return err
+		}
+
+		result.Account, err = q.AddAccountHeldBalance(ctx, AddAccountHeldBalanceParams{
+			ID:     arg.AccountID,
+			Amount: arg.Amount,
+		})
+		return err
+	})
+
+	return result, err
+}
+
+type CaptureHoldTxParams struct {
+	HoldID int64 `json:"holdId"`
+	// Amount defaults to the whole hold. The rest of a partial capture is
+	// released.
+	Amount int64 `json:"amount"`
+
+	Idempotency *IdempotencyKeyParams `json:"-"`
+	// Risk is the screening decision that allowed the capture. It's recorded
+	// with the transfer.
+	Risk *risk.Decision `json:"-"`
+}
+
+// CaptureHoldTx releases an active hold and transfers the captured amount to
+// the account the hold was made for, charging the fee of a transfer of that
+// amount. Only captured amounts count towards the transfer limits, so they're
+// checked again here: holds that each fit when made may not all fit together.
+func (store *SQLStore) CaptureHoldTx(ctx context.Context, arg CaptureHoldTxParams) (HoldTxResult, error) {
+	var result HoldTxResult
+
+	err := store.execIdempotentTx(ctx, arg.Idempotency, &result, func(q *Queries) error {
+		hold, err := lockActiveHold(ctx, q, arg.HoldID)
+		if err != nil {
+			return err
+		}
+
+		if time.Now().After(hold.ExpiresAt) {
+			return ErrHoldExpired
+		}
+
+		amount := arg.Amount
+		if amount == 0 {
+			amount = hold.Amount
+		}
+		if amount < 0 || amount > hold.Amount {
+			return ErrCaptureExceedsHold
+		}
+
+		schedule, fee, err := transferFee(ctx, q, hold.AccountID, amount)
+		if err != nil {
+			return err
+		}
+
+		ids := []int64{hold.AccountID, hold.ToAccountID}
+		if fee > 0 {
+			ids = append(ids, schedule.RevenueAccountID)
+		}
+		accounts, err := lockAccounts(ctx, q, ids...)
+		if err != nil {
+			return err
+		}
+
+		accounts[hold.AccountID], err = releaseHold(ctx, q, hold)
+		if err != nil {
+			return err
+		}
+
+		transferResult, err := chargedTransfer(ctx, q, accounts, TransferTxParams{
+			FromAccountID: hold.AccountID,
+			ToAccountID:   hold.ToAccountID,
+			Amount:        amount,
+			Risk:          arg.Risk,
+		}, schedule, fee)
+		if err != nil {
+			return err
+		}
+
+		result.Hold, err = q.ResolveAccountHold(ctx, ResolveAccountHoldParams{
+			ID:         hold.ID,
+			Status:     HoldCaptured,
+			TransferID: sql.NullInt64{Int64: transferResult.Transfer.ID, Valid: true},
+		})
+		result.Account = transferResult.FromAccount
+		result.Transfer = &transferResult
+		return err
+	})
+
+	return result, err
+}
+
+// VoidHoldTx releases an active hold without moving any money.
+func (store *SQLStore) VoidHoldTx(ctx context.Context, holdID int64) (HoldTxResult, error) {
+	var result HoldTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		hold, err := lockActiveHold(ctx, q, holdID)
+		if err != nil {
+			return err
+		}
+
+		result.Account, err = releaseHold(ctx, q, hold)
+		if err != nil {
+			return err
+		}
+
+		result.Hold, err = q.ResolveAccountHold(ctx, ResolveAccountHoldParams{
+			ID:     hold.ID,
+			Status: HoldVoided,
+		})
+		return err
+	})
+
+	return result, err
+}
+
+// ExpireHolds releases up to limit active holds that are past their expiry.
+// Like RunDueScheduledTransfers, the holds are claimed with SKIP LOCKED so
+// several workers can expire holds at the same time. Their accounts are
+// locked in ascending order, like transfers lock theirs, before any hold is
+// released.
+func (store *SQLStore) ExpireHolds(ctx context.Context, limit int32) ([]AccountHold, error) {
+	var expired []AccountHold
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		holds, err := q.ClaimExpiredAccountHolds(ctx, limit)
+		if err != nil {
+			return err
+		}
+
+		accountIDs := make([]int64, 0, len(holds))
+		for _, hold := range holds {
+			accountIDs = append(accountIDs, hold.AccountID)
+		}
+		if _, err := lockAccounts(ctx, q, accountIDs...); err != nil {
+			return err
+		}
+
+		expired = make([]AccountHold, 0, len(holds))
+		for _, hold := range holds {
+			if _, err := releaseHold(ctx, q, hold); err != nil {
+				return fmt.Errorf("hold %d: %w", hold.ID, err)
+			}
+
+			hold, err = q.ResolveAccountHold(ctx, ResolveAccountHoldParams{
+				ID:     hold.ID,
+				Status: HoldExpired,
+			})
+			if err != nil {
+				return fmt.Errorf("hold %d: %w", hold.ID, err)
+			}
+			expired = append(expired, hold)
+		}
+
+		return nil
+	})
+
+	return expired, err
+}
+
+// lockActiveHold locks a hold before its account, which is the order every
+// hold transaction uses.
+func lockActiveHold(ctx context.Context, q *Queries, id int64) (AccountHold, error) {
+	hold, err := q.GetAccountHoldForUpdate(ctx, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return hold, ErrHoldNotFound
+		}
+		return hold, err
+	}
+
+	if hold.Status != HoldActive {
+		return hold, ErrHoldNotActive
+	}
+
+	return hold, nil
+}
+
+func releaseHold(ctx context.Context, q *Queries, hold AccountHold) (Account, error) {
+	return q.AddAccountHeldBalance(ctx, AddAccountHeldBalanceParams{
+		ID:     hold.AccountID,
+		Amount: -hold.Amount,
+	})
+}
@@ -0,0 +1,143 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomHold(t *testing.T, store Store, fromAccount Account, toAccount Account, amount int64, expiresAt time.Time) HoldTxResult {
+	result, err := store.CreateHoldTx(context.Background(), CreateHoldTxParams{
+		AccountID:   fromAccount.ID,
+		ToAccountID: toAccount.ID,
+		Amount:      amount,
+		ExpiresAt:   expiresAt,
+	})
+	require.NoError(t, err)
+	require.NotZero(t, result.Hold.ID)
+	require.Equal(t, HoldActive, result.Hold.Status)
+	require.Equal(t, amount, result.Hold.Amount)
+
+	return result
+}
+
+func TestCreateHoldTx(t *testing.T) {
+	store := NewStore(testDb)
+
+	fromAccount := createRandomAccount(t)
+	toAccount := createRandomAccountWithCurrency(t, fromAccount.Currency)
+
+	result := createRandomHold(t, store, fromAccount, toAccount, fromAccount.Balance-10, time.Now().Add(time.Hour))
+	require.Equal(t, fromAccount.Balance, result.Account.Balance)
+	require.Equal(t, fromAccount.Balance-10, result.Account.HeldBalance)
+	require.Equal(t, int64(10), result.Account.AvailableBalance)
+
+	// held funds can't be spent by a transfer or another hold
+	_, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: fromAccount.ID,
+		ToAccountID:   toAccount.ID,
+		Amount:        11,
+	})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+
+	_, err = store.CreateHoldTx(context.Background(), CreateHoldTxParams{
+		AccountID:   fromAccount.ID,
+		ToAccountID: toAccount.ID,
+		Amount:      11,
+		ExpiresAt:   time.Now().Add(time.Hour),
+	})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+}
+
+func TestCaptureHoldTx(t *testing.T) {
+	store := NewStore(testDb)
+
+	fromAccount := createRandomAccount(t)
+	toAccount := createRandomAccountWithCurrency(t, fromAccount.Currency)
+	hold := createRandomHold(t, store, fromAccount, toAccount, 50, time.Now().Add(time.Hour)).Hold
+
+	_, err := store.CaptureHoldTx(context.Background(), CaptureHoldTxParams{HoldID: hold.ID, Amount: 51})
+	require.ErrorIs(t, err, ErrCaptureExceedsHold)
+
+	result, err := store.CaptureHoldTx(context.Background(), CaptureHoldTxParams{HoldID: hold.ID, Amount: 30})
+	require.NoError(t, err)
+	require.Equal(t, HoldCaptured, result.Hold.Status)
+	require.True(t, result.Hold.TransferID.Valid)
+	require.True(t, result.Hold.ResolvedAt.Valid)
+	require.NotNil(t, result.Transfer)
+	require.Equal(t, result.Hold.TransferID.Int64, result.Transfer.Transfer.ID)
+	require.Equal(t, int64(30), result.Transfer.Transfer.Amount)
+
+	// the uncaptured rest is released
+	require.Equal(t, fromAccount.Balance-30, result.Account.Balance)
+	require.Zero(t, result.Account.HeldBalance)
+	require.Equal(t, toAccount.Balance+30, result.Transfer.ToAccount.Balance)
+
+	_, err = store.CaptureHoldTx(context.Background(), CaptureHoldTxParams{HoldID: hold.ID})
+	require.ErrorIs(t, err, ErrHoldNotActive)
+}
+
+func TestCaptureExpiredHoldTx(t *testing.T) {
+	store := NewStore(testDb)
+
+	fromAccount := createRandomAccount(t)
+	toAccount := createRandomAccountWithCurrency(t, fromAccount.Currency)
+	hold := createRandomHold(t, store, fromAccount, toAccount, 10, time.Now().Add(-time.Second)).Hold
+
+	_, err := store.CaptureHoldTx(context.Background(), CaptureHoldTxParams{HoldID: hold.ID})
+	require.ErrorIs(t, err, ErrHoldExpired)
+}
+
+func TestVoidHoldTx(t *testing.T) {
+	store := NewStore(testDb)
+
+	fromAccount := createRandomAccount(t)
+	toAccount := createRandomAccountWithCurrency(t, fromAccount.Currency)
+	hold := createRandomHold(t, store, fromAccount, toAccount, 10, time.Now().Add(time.Hour)).Hold
+
+	result, err := store.VoidHoldTx(context.Background(), hold.ID)
+	require.NoError(t, err)
+	require.Equal(t, HoldVoided, result.Hold.Status)
+	require.False(t, result.Hold.TransferID.Valid)
+	require.Nil(t, result.Transfer)
+	require.Equal(t, fromAccount.Balance, result.Account.Balance)
+	require.Equal(t, fromAccount.Balance, result.Account.AvailableBalance)
+
+	_, err = store.VoidHoldTx(context.Background(), hold.ID)
+	require.ErrorIs(t, err, ErrHoldNotActive)
+
+	_, err = store.VoidHoldTx(context.Background(), -1)
+	require.ErrorIs(t, err, ErrHoldNotFound)
+}
+
+func TestExpireHolds(t *testing.T) {
+	store := NewStore(testDb)
+
+	fromAccount := createRandomAccount(t)
+	toAccount := createRandomAccountWithCurrency(t, fromAccount.Currency)
+	expired := createRandomHold(t, store, fromAccount, toAccount, 10, time.Now().Add(-time.Second)).Hold
+	active := createRandomHold(t, store, fromAccount, toAccount, 20, time.Now().Add(time.Hour)).Hold
+
+	// other tests leave expired holds behind as well
+	for {
+		holds, err := store.ExpireHolds(context.Background(), 100)
+		require.NoError(t, err)
+		if len(holds) < 100 {
+			break
+		}
+	}
+
+	hold, err := testQueries.GetAccountHold(context.Background(), expired.ID)
+	require.NoError(t, err)
+	require.Equal(t, HoldExpired, hold.Status)
+
+	hold, err = testQueries.GetAccountHold(context.Background(), active.ID)
+	require.NoError(t, err)
+	require.Equal(t, HoldActive, hold.Status)
+
+	account, err := testQueries.GetAccount(context.Background(), fromAccount.ID)
+	require.NoError(t, err)
+	require.Equal(t, active.Amount, account.HeldBalance)
+}
@@ -0,0 +1,172 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+const (
+	AccountActive = "active"
+	AccountFrozen = "frozen"
+	AccountClosed = "closed"
+)
+
+var (
+	ErrAccountFrozen           = errors.New("account is frozen")
+	ErrAccountClosed           = errors.New("account is closed")
+	ErrAccountNotEmpty         = errors.New("account balance must be zero or swept to another account")
+	ErrAccountHasHolds         = errors.New("account has active holds")
+	ErrInvalidSweepAccount     = errors.New("sweep account must be another account of the same owner")
+	ErrInvalidStatusTransition = errors.New("invalid account status transition")
+)
+
+// checkAccountsStatus refuses debits from frozen or closed accounts and credits
+// to closed ones. Frozen accounts can still receive money.
+func checkAccountsStatus(fromAccount Account, toAccount Account) error {
+	switch fromAccount.Status {
+	case AccountFrozen:
+		return fmt.Errorf("account %d: %w", fromAccount.ID, ErrAccountFrozen)
+	case AccountClosed:
+		return fmt.Errorf("account %d: %w", fromAccount.ID, ErrAccountClosed)
+	}
+
+	if toAccount.Status == AccountClosed {
+		return fmt.Errorf("account %d: %w", toAccount.ID, ErrAccountClosed)
+	}
+
+	return nil
+}
+
+// accountStatusTransitions lists the transitions UpdateAccountStatusTx allows.
+// Closing goes through CloseAccountTx since the balance has to be settled
+// first, and a closed account can't be reopened.
+var accountStatusTransitions = map[string][]string{
+	AccountActive: {AccountFrozen},
+	AccountFrozen: {AccountActive},
+}
+
+func (store *SQLStore) UpdateAccountStatusTx(ctx context.Context, accountID int64, status string) (Account, error) {
+	var account Account
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		current, err := lockAccount(ctx, q, accountID)
+		if err != nil {
+			return err
+		}
+
+		if current.Status == AccountClosed {
+			return ErrAccountClosed
+		}
+
+		if !canTransitionAccountStatus(current.Status, status) {
+			return fmt.Errorf("%w from %s to %s", ErrInvalidStatusTransition, current.Status, status)
+		}
+
+		account, err = q.UpdateAccountStatus(ctx, UpdateAccountStatusParams{
+			ID:     accountID,
+			Status: status,
+		})
+		return err
+	})
+
+	return account, err
+}
+
+func canTransitionAccountStatus(from string, to string) bool {
+	for _, status := range accountStatusTransitions[from] {
+		if status == to {
+			return true
+		}
+	}
+
+	return false
+}
+
+type CloseAccountTxParams struct {
+	AccountID int64 `json:"accountId"`
+	// SweepToAccountID receives the remaining balance. It's only required when
+	// the balance isn't zero.
+	SweepToAccountID int64 `json:"sweepToAccountId"`
+	// SweepRate converts the remaining balance when the sweep account holds
+	// another currency.
+	SweepRate string `json:"sweepRate"`
+}
+
+type CloseAccountTxResult struct {
+	Account Account           `json:"account"`
+	Sweep   *TransferTxResult `json:"sweep,omitempty"`
+}
+
+// CloseAccountTx closes an active account. A remaining balance is first moved
+// to another account of the same owner so no money is left behind.
+func (store *SQLStore) CloseAccountTx(ctx context.Context, arg CloseAccountTxParams) (CloseAccountTxResult, error) {
+	var result CloseAccountTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		result = CloseAccountTxResult{}
+
+		ids := []int64{arg.AccountID}
+		if arg.SweepToAccountID != 0 {
+			ids = append(ids, arg.SweepToAccountID)
+		}
+
+		accounts, err := lockAccounts(ctx, q, ids...)
+		if err != nil {
+			return err
+		}
+
+		account := accounts[arg.AccountID]
+		switch {
+		case account.Status == AccountClosed:
+			return ErrAccountClosed
+		case account.Status == AccountFrozen:
+			return ErrAccountFrozen
+		case account.HeldBalance > 0:
+			return ErrAccountHasHolds
+		case account.Balance < 0:
+			return ErrAccountNotEmpty
+		}
+
+		if account.Balance > 0 {
+			if arg.SweepToAccountID == 0 {
+				return ErrAccountNotEmpty
+			}
+
+			sweepAccount := accounts[arg.SweepToAccountID]
+			if sweepAccount.ID == account.ID || sweepAccount.Owner != account.Owner {
+				return ErrInvalidSweepAccount
+			}
+
+			if err := checkAccountsStatus(account, sweepAccount); err != nil {
+				return err
+			}
+
+			var sweep TransferTxResult
+			if account.Currency == sweepAccount.Currency {
+				sweep, err = transfer(ctx, q, TransferTxParams{
+					FromAccountID: account.ID,
+					ToAccountID:   sweepAccount.ID,
+					Amount:        account.Balance,
+				})
+			} else {
+				if arg.SweepRate == "" {
+					return ErrCurrencyMismatch
+				}
+				sweep, err = exchange(ctx, q, account, sweepAccount, account.Balance, arg.SweepRate)
+			}
+			if err != nil {
+				return err
+			}
+			result.Sweep = &sweep
+		}
+
+		result.Account, err = q.UpdateAccountStatus(ctx, UpdateAccountStatusParams{
+			ID:     account.ID,
+			Status: AccountClosed,
+		})
+		return err
+	})
+
+	return result, err
+}
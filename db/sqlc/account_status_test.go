@@ -0,0 +1,129 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/crackz/simple-bank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomAccountForOwner(t *testing.T, owner string, currency string) Account {
+	account, err := testQueries.CreateAccount(context.Background(), CreateAccountParams{
+		Owner:    owner,
+		Balance:  0,
+		Currency: currency,
+	})
+	require.NoError(t, err)
+
+	return account
+}
+
+func TestFrozenAccountTransfers(t *testing.T) {
+	store := NewStore(testDb)
+
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccountWithCurrency(t, account1.Currency)
+
+	frozen, err := store.UpdateAccountStatusTx(context.Background(), account1.ID, AccountFrozen)
+	require.NoError(t, err)
+	require.Equal(t, AccountFrozen, frozen.Status)
+
+	// a frozen account can't be debited but can still be credited
+	_, err = store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        10,
+	})
+	require.ErrorIs(t, err, ErrAccountFrozen)
+
+	_, err = store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account2.ID,
+		ToAccountID:   account1.ID,
+		Amount:        10,
+	})
+	require.NoError(t, err)
+
+	_, err = store.UpdateAccountStatusTx(context.Background(), account1.ID, AccountFrozen)
+	require.ErrorIs(t, err, ErrInvalidStatusTransition)
+
+	_, err = store.CloseAccountTx(context.Background(), CloseAccountTxParams{AccountID: account1.ID})
+	require.ErrorIs(t, err, ErrAccountFrozen)
+
+	unfrozen, err := store.UpdateAccountStatusTx(context.Background(), account1.ID, AccountActive)
+	require.NoError(t, err)
+	require.Equal(t, AccountActive, unfrozen.Status)
+
+	_, err = store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        10,
+	})
+	require.NoError(t, err)
+}
+
+func TestCloseAccountTx(t *testing.T) {
+	store := NewStore(testDb)
+
+	user := createRandomUser(t)
+	account := createRandomAccountForOwner(t, user.Username, util.USD)
+
+	result, err := store.CloseAccountTx(context.Background(), CloseAccountTxParams{AccountID: account.ID})
+	require.NoError(t, err)
+	require.Equal(t, AccountClosed, result.Account.Status)
+	require.Nil(t, result.Sweep)
+
+	_, err = store.CloseAccountTx(context.Background(), CloseAccountTxParams{AccountID: account.ID})
+	require.ErrorIs(t, err, ErrAccountClosed)
+
+	_, err = store.UpdateAccountStatusTx(context.Background(), account.ID, AccountActive)
+	require.ErrorIs(t, err, ErrAccountClosed)
+
+	// a closed account can't receive money either
+	other := createRandomAccountWithCurrency(t, util.USD)
+	_, err = store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: other.ID,
+		ToAccountID:   account.ID,
+		Amount:        10,
+	})
+	require.ErrorIs(t, err, ErrAccountClosed)
+
+	// the currency can be opened again once the old account is closed
+	createRandomAccountForOwner(t, user.Username, util.USD)
+}
+
+func TestCloseAccountTxWithSweep(t *testing.T) {
+	store := NewStore(testDb)
+
+	account := createRandomAccountWithCurrency(t, util.USD)
+	sweepAccount := createRandomAccountForOwner(t, account.Owner, util.CAD)
+	otherAccount := createRandomAccountWithCurrency(t, util.CAD)
+
+	_, err := store.CloseAccountTx(context.Background(), CloseAccountTxParams{AccountID: account.ID})
+	require.ErrorIs(t, err, ErrAccountNotEmpty)
+
+	_, err = store.CloseAccountTx(context.Background(), CloseAccountTxParams{
+		AccountID:        account.ID,
+		SweepToAccountID: otherAccount.ID,
+		SweepRate:        "2",
+	})
+	require.ErrorIs(t, err, ErrInvalidSweepAccount)
+
+	_, err = store.CloseAccountTx(context.Background(), CloseAccountTxParams{
+		AccountID:        account.ID,
+		SweepToAccountID: sweepAccount.ID,
+	})
+	require.ErrorIs(t, err, ErrCurrencyMismatch)
+
+	result, err := store.CloseAccountTx(context.Background(), CloseAccountTxParams{
+		AccountID:        account.ID,
+		SweepToAccountID: sweepAccount.ID,
+		SweepRate:        "2",
+	})
+	require.NoError(t, err)
+	require.Equal(t, AccountClosed, result.Account.Status)
+	require.Zero(t, result.Account.Balance)
+	require.NotNil(t, result.Sweep)
+	require.Equal(t, account.Balance, result.Sweep.Transfer.Amount)
+	require.Equal(t, 2*account.Balance, result.Sweep.ToAccount.Balance)
+}
@@ -2,7 +2,6 @@ package db
 
 import (
 	"context"
-	"database/sql"
 	"testing"
 
 	"github.com/crackz/simple-bank/util"
@@ -10,11 +9,15 @@ import (
 )
 
 func createRandomAccount(t *testing.T) Account {
+	return createRandomAccountWithCurrency(t, util.RandomCurrency())
+}
+
+func createRandomAccountWithCurrency(t *testing.T, currency string) Account {
 	user := createRandomUser(t)
 	arg := CreateAccountParams{
 		Owner:    user.Username,
 		Balance:  util.RandBalance(),
-		Currency: util.RandomCurrency(),
+		Currency: currency,
 	}
 
 	account, err := testQueries.CreateAccount(context.Background(), arg)
@@ -66,16 +69,18 @@ func TestUpdateAccount(t *testing.T) {
 
 }
 
-func TestDeleteAccount(t *testing.T) {
+func TestUpdateAccountStatus(t *testing.T) {
 	account := createRandomAccount(t)
+	require.Equal(t, AccountActive, account.Status)
 
-	err := testQueries.DeleteAccount(context.Background(), account.ID)
+	updatedAccount, err := testQueries.UpdateAccountStatus(context.Background(), UpdateAccountStatusParams{
+		ID:     account.ID,
+		Status: AccountFrozen,
+	})
 	require.NoError(t, err)
-
-	foundAccount, err := testQueries.GetAccount(context.Background(), account.ID)
-	require.Error(t, err)
-	require.EqualError(t, err, sql.ErrNoRows.Error())
-	require.Empty(t, foundAccount)
+	require.Equal(t, account.ID, updatedAccount.ID)
+	require.Equal(t, AccountFrozen, updatedAccount.Status)
+	require.Equal(t, account.Balance, updatedAccount.Balance)
 }
 
 func TestListAccounts(t *testing.T) {
@@ -99,3 +104,37 @@ func TestListAccounts(t *testing.T) {
 		require.Equal(t, lastAccount.Owner, account.Owner)
 	}
 }
+
+func TestListAccountsAfter(t *testing.T) {
+	user := createRandomUser(t)
+
+	var accounts []Account
+	for _, currency := range []string{util.USD, util.EUR, util.CAD} {
+		account, err := testQueries.CreateAccount(context.Background(), CreateAccountParams{
+			Owner:    user.Username,
+			Currency: currency,
+		})
+		require.NoError(t, err)
+		accounts = append(accounts, account)
+	}
+
+	firstPage, err := testQueries.ListAccountsAfter(context.Background(), ListAccountsAfterParams{
+		Owner:      user.Username,
+		LimitCount: 2,
+	})
+	require.NoError(t, err)
+	require.Len(t, firstPage, 2)
+	require.Equal(t, accounts[0].ID, firstPage[0].ID)
+	require.Equal(t, accounts[1].ID, firstPage[1].ID)
+
+	last := firstPage[len(firstPage)-1]
+	secondPage, err := testQueries.ListAccountsAfter(context.Background(), ListAccountsAfterParams{
+		Owner:          user.Username,
+		AfterCreatedAt: last.CreatedAt,
+		AfterID:        last.ID,
+		LimitCount:     2,
+	})
+	require.NoError(t, err)
+	require.Len(t, secondPage, 1)
+	require.Equal(t, accounts[2].ID, secondPage[0].ID)
+}
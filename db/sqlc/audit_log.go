@@ -0,0 +1,142 @@
+package db
+
+import "context"
+
+// AuditLogger writes the audit log entry of a call in the transactions the
+// call makes through the store, so none of its changes can commit without
+// the entry.
+type AuditLogger interface {
+	// WriteAuditLog writes the entry with q, a transaction about to commit,
+	// unless an earlier transaction of the call committed it already.
+	WriteAuditLog(ctx context.Context, q *Queries) error
+	// AuditLogCommitted is called once a transaction WriteAuditLog was called
+	// with has committed.
+	AuditLogCommitted()
+}
+
+type auditLoggerKey struct{}
+
+// WithAuditLogger returns a copy of ctx whose store transactions write the
+// entry of logger.
+func WithAuditLogger(ctx context.Context, logger AuditLogger) context.Context {
+	return context.WithValue(ctx, auditLoggerKey{}, logger)
+}
+
+func auditLoggerFromContext(ctx context.Context) (AuditLogger, bool) {
+	logger, ok := ctx.Value(auditLoggerKey{}).(AuditLogger)
+	return logger, ok
+}
+
+// execAuditedQuery runs fn, a change made with a single statement, in a
+// transaction of its own when ctx carries an audit logger, for the entry to
+// be written with it.
+func (store *SQLStore) execAuditedQuery(ctx context.Context, fn func(*Queries) error) error {
+	if _, ok := auditLoggerFromContext(ctx); !ok {
+		return fn(store.Queries)
+	}
+
+	return store.execTx(ctx, fn)
+}
+
+// The changes below are made with a single statement but called by the
+// servers on behalf of audited calls.
+
+func (store *SQLStore) CreateFxQuote(ctx context.Context, arg CreateFxQuoteParams) (FxQuote, error) {
+	var quote FxQuote
+	err := store.execAuditedQuery(ctx, func(q *Queries) error {
+		var err error
+		quote, err = q.CreateFxQuote(ctx, arg)
+		return err
+	})
+
+	return quote, err
+}
+
+func (store *SQLStore) CreateInterestPlan(ctx context.Context, arg CreateInterestPlanParams) (InterestPlan, error) {
+	var plan InterestPlan
+	err := store.execAuditedQuery(ctx, func(q *Queries) error {
+		var err error
+		plan, err = q.CreateInterestPlan(ctx, arg)
+		return err
+	})
+
+	return plan, err
+}
+
+func (store *SQLStore) CreateScheduledTransfer(ctx context.Context, arg CreateScheduledTransferParams) (ScheduledTransfer, error) {
+	var scheduledTransfer ScheduledTransfer
+	err := store.execAuditedQuery(ctx, func(q *Queries) error {
+		var err error
+		scheduledTransfer, err = q.CreateScheduledTransfer(ctx, arg)
+		return err
+	})
+
+	return scheduledTransfer, err
+}
+
+func (store *SQLStore) DeleteScheduledTransfer(ctx context.Context, id int64) error {
+	return store.execAuditedQuery(ctx, func(q *Queries) error {
+		return q.DeleteScheduledTransfer(ctx, id)
+	})
+}
+
+func (store *SQLStore) CreateWebhookEndpoint(ctx context.Context, arg CreateWebhookEndpointParams) (WebhookEndpoint, error) {
+	var endpoint WebhookEndpoint
+	err := store.execAuditedQuery(ctx, func(q *Queries) error {
+		var err error
+		endpoint, err = q.CreateWebhookEndpoint(ctx, arg)
+		return err
+	})
+
+	return endpoint, err
+}
+
+func (store *SQLStore) UpdateWebhookEndpoint(ctx context.Context, arg UpdateWebhookEndpointParams) (WebhookEndpoint, error) {
+	var endpoint WebhookEndpoint
+	err := store.execAuditedQuery(ctx, func(q *Queries) error {
+		var err error
+		endpoint, err = q.UpdateWebhookEndpoint(ctx, arg)
+		return err
+	})
+
+	return endpoint, err
+}
+
+func (store *SQLStore) DeleteWebhookEndpoint(ctx context.Context, id int64) error {
+	return store.execAuditedQuery(ctx, func(q *Queries) error {
+		return q.DeleteWebhookEndpoint(ctx, id)
+	})
+}
+
+func (store *SQLStore) DeactivateFeeSchedule(ctx context.Context, id int64) (FeeSchedule, error) {
+	var schedule FeeSchedule
+	err := store.execAuditedQuery(ctx, func(q *Queries) error {
+		var err error
+		schedule, err = q.DeactivateFeeSchedule(ctx, id)
+		return err
+	})
+
+	return schedule, err
+}
+
+func (store *SQLStore) UpdateCurrencyEnabled(ctx context.Context, arg UpdateCurrencyEnabledParams) (Currency, error) {
+	var currency Currency
+	err := store.execAuditedQuery(ctx, func(q *Queries) error {
+		var err error
+		currency, err = q.UpdateCurrencyEnabled(ctx, arg)
+		return err
+	})
+
+	return currency, err
+}
+
+func (store *SQLStore) UpsertTransferLimit(ctx context.Context, arg UpsertTransferLimitParams) (TransferLimit, error) {
+	var limit TransferLimit
+	err := store.execAuditedQuery(ctx, func(q *Queries) error {
+		var err error
+		limit, err = q.UpsertTransferLimit(ctx, arg)
+		return err
+	})
+
+	return limit, err
+}
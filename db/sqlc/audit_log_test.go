@@ -0,0 +1,177 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/crackz/simple-bank/interest"
+	"github.com/crackz/simple-bank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuditLog(t *testing.T) {
+	actor := util.RandOwner()
+	start := time.Now().Add(-time.Second)
+
+	arg := CreateAuditLogParams{
+		Actor:      sql.NullString{String: actor, Valid: true},
+		Method:     "PATCH",
+		Route:      "/accounts/:accountID",
+		StatusCode: sql.NullInt32{Int32: 201, Valid: true},
+		TargetType: sql.NullString{String: "account", Valid: true},
+		TargetID:   sql.NullString{String: "1", Valid: true},
+		Before:     json.RawMessage(`{"balance":10}`),
+		After:      json.RawMessage(`{"balance":20}`),
+		ClientIp:   "203.0.113.7",
+	}
+
+	entry, err := testQueries.CreateAuditLog(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, arg.Actor, entry.Actor)
+	require.JSONEq(t, string(arg.Before), string(entry.Before))
+	require.JSONEq(t, string(arg.After), string(entry.After))
+
+	loginArg := arg
+	loginArg.Method = "POST"
+	loginArg.Route = "/users/login"
+	loginArg.StatusCode = sql.NullInt32{Int32: 401, Valid: true}
+	loginArg.TargetType = sql.NullString{String: "user", Valid: true}
+	loginArg.TargetID = sql.NullString{String: actor, Valid: true}
+	loginArg.Before = json.RawMessage(`null`)
+	loginArg.After = json.RawMessage(`null`)
+	login, err := testQueries.CreateAuditLog(context.Background(), loginArg)
+	require.NoError(t, err)
+
+	entries, err := testQueries.ListAuditLogs(context.Background(), ListAuditLogsParams{
+		Actor:      arg.Actor,
+		FromTime:   sql.NullTime{Time: start, Valid: true},
+		LimitCount: 10,
+	})
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	require.Equal(t, login.ID, entries[0].ID)
+	require.Equal(t, entry.ID, entries[1].ID)
+
+	entries, err = testQueries.ListAuditLogs(context.Background(), ListAuditLogsParams{
+		Actor:      arg.Actor,
+		Route:      sql.NullString{String: "/users/login", Valid: true},
+		LimitCount: 10,
+	})
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	require.Equal(t, login.ID, entries[0].ID)
+
+	entries, err = testQueries.ListAuditLogs(context.Background(), ListAuditLogsParams{
+		Actor:      arg.Actor,
+		ToTime:     sql.NullTime{Time: start, Valid: true},
+		LimitCount: 10,
+	})
+	require.NoError(t, err)
+	require.Empty(t, entries)
+
+	// the log is append-only
+	_, err = testDb.Exec("UPDATE audit_log SET status_code = 200 WHERE id = $1", entry.ID)
+	require.ErrorContains(t, err, "append-only")
+
+	_, err = testDb.Exec("DELETE FROM audit_log WHERE id = $1", entry.ID)
+	require.ErrorContains(t, err, "append-only")
+}
+
+func TestCompleteAuditLog(t *testing.T) {
+	entry, err := testQueries.CreateAuditLog(context.Background(), CreateAuditLogParams{
+		Method:   "POST",
+		Route:    "/accounts",
+		Before:   json.RawMessage(`null`),
+		After:    json.RawMessage(`null`),
+		ClientIp: "203.0.113.7",
+	})
+	require.NoError(t, err)
+	require.False(t, entry.StatusCode.Valid)
+
+	arg := CompleteAuditLogParams{
+		ID:         entry.ID,
+		StatusCode: sql.NullInt32{Int32: 201, Valid: true},
+		TargetType: sql.NullString{String: "account", Valid: true},
+		TargetID:   sql.NullString{String: "1", Valid: true},
+		After:      json.RawMessage(`{"id":1}`),
+	}
+	completed, err := testQueries.CompleteAuditLog(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, arg.StatusCode, completed.StatusCode)
+	require.Equal(t, arg.TargetID, completed.TargetID)
+	require.JSONEq(t, string(arg.After), string(completed.After))
+
+	// an answered entry can't change anymore
+	arg.StatusCode = sql.NullInt32{Int32: 500, Valid: true}
+	_, err = testQueries.CompleteAuditLog(context.Background(), arg)
+	require.ErrorContains(t, err, "append-only")
+}
+
+type testAuditLogger struct {
+	written   []int64
+	committed int
+}
+
+func (logger *testAuditLogger) WriteAuditLog(ctx context.Context, q *Queries) error {
+	entry, err := q.CreateAuditLog(ctx, CreateAuditLogParams{
+		Method: "POST",
+		Route:  "/test",
+		Before: json.RawMessage(`null`),
+		After:  json.RawMessage(`null`),
+	})
+	if err != nil {
+		return err
+	}
+
+	logger.written = append(logger.written, entry.ID)
+	return nil
+}
+
+func (logger *testAuditLogger) AuditLogCommitted() {
+	logger.committed++
+}
+
+func TestTxWritesAuditLog(t *testing.T) {
+	store := NewStore(testDb)
+
+	fromAccount := createRandomAccount(t)
+	toAccount := createRandomAccountWithCurrency(t, fromAccount.Currency)
+
+	logger := &testAuditLogger{}
+	ctx := WithAuditLogger(context.Background(), logger)
+
+	_, err := store.TransferTx(ctx, TransferTxParams{
+		FromAccountID: fromAccount.ID,
+		ToAccountID:   toAccount.ID,
+		Amount:        10,
+	})
+	require.NoError(t, err)
+	require.Len(t, logger.written, 1)
+	require.Equal(t, 1, logger.committed)
+
+	// a transaction that fails doesn't write the entry
+	_, err = store.TransferTx(ctx, TransferTxParams{
+		FromAccountID: fromAccount.ID,
+		ToAccountID:   toAccount.ID,
+		Amount:        fromAccount.Balance + 1,
+	})
+	require.Error(t, err)
+	require.Len(t, logger.written, 1)
+	require.Equal(t, 1, logger.committed)
+
+	// changes made with a single statement get a transaction of their own
+	expenseAccount := createRandomAccountWithCurrency(t, fromAccount.Currency)
+	_, err = store.CreateInterestPlan(ctx, CreateInterestPlanParams{
+		Name:             "savings",
+		Currency:         fromAccount.Currency,
+		AnnualRate:       "0.0365",
+		DayCount:         interest.ACT365,
+		ExpenseAccountID: expenseAccount.ID,
+	})
+	require.NoError(t, err)
+	require.Len(t, logger.written, 2)
+	require.Equal(t, 2, logger.committed)
+}
@@ -0,0 +1,64 @@
+package db
+
+import (
+	"context"
+	"fmt"
+)
+
+type BatchTransferTxParams struct {
+	Transfers []TransferTxParams `json:"transfers"`
+
+	Idempotency *IdempotencyKeyParams `json:"-"`
+}
+
+type BatchTransferTxResult struct {
+	Transfers []TransferTxResult `json:"transfers"`
+}
+
+// BatchTransferTx executes all transfers in a single transaction, so either all
+// of them are applied or none is. Each transfer is charged its fee and counts
+// towards the transfer limits like a transfer of TransferTx. Every involved
+// account, including the revenue accounts fees are paid to, is locked up
+// front and each transfer is checked against the balances left by the
+// previous ones.
+func (store *SQLStore) BatchTransferTx(ctx context.Context, arg BatchTransferTxParams) (BatchTransferTxResult, error) {
+	var result BatchTransferTxResult
+
+	err := store.execIdempotentTx(ctx, arg.Idempotency, &result, func(q *Queries) error {
+		schedules := make([]FeeSchedule, len(arg.Transfers))
+		fees := make([]int64, len(arg.Transfers))
+		ids := make([]int64, 0, 3*len(arg.Transfers))
+		for i, t := range arg.Transfers {
+			var err error
+
+			schedules[i], fees[i], err = transferFee(ctx, q, t.FromAccountID, t.Amount)
+			if err != nil {
+				return fmt.Errorf("transfer %d: %w", i, err)
+			}
+
+			ids = append(ids, t.FromAccountID, t.ToAccountID)
+			if fees[i] > 0 {
+				ids = append(ids, schedules[i].RevenueAccountID)
+			}
+		}
+
+		accounts, err := lockAccounts(ctx, q, ids...)
+		if err != nil {
+			return err
+		}
+
+		result.Transfers = make([]TransferTxResult, 0, len(arg.Transfers))
+		for i, t := range arg.Transfers {
+			transferResult, err := chargedTransfer(ctx, q, accounts, t, schedules[i], fees[i])
+			if err != nil {
+				return fmt.Errorf("transfer %d: %w", i, err)
+			}
+
+			result.Transfers = append(result.Transfers, transferResult)
+		}
+
+		return nil
+	})
+
+	return result, err
+}
@@ -0,0 +1,98 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBatchTransferTx(t *testing.T) {
+	store := NewStore(testDb)
+
+	fromAccount := createRandomAccount(t)
+	toAccount1 := createRandomAccountWithCurrency(t, fromAccount.Currency)
+	toAccount2 := createRandomAccountWithCurrency(t, fromAccount.Currency)
+
+	arg := BatchTransferTxParams{
+		Transfers: []TransferTxParams{
+			{FromAccountID: fromAccount.ID, ToAccountID: toAccount1.ID, Amount: 10},
+			{FromAccountID: fromAccount.ID, ToAccountID: toAccount2.ID, Amount: 20},
+			{FromAccountID: fromAccount.ID, ToAccountID: toAccount1.ID, Amount: 30},
+		},
+	}
+
+	result, err := store.BatchTransferTx(context.Background(), arg)
+	require.NoError(t, err)
+	require.Len(t, result.Transfers, len(arg.Transfers))
+
+	for i, transferResult := range result.Transfers {
+		require.NotZero(t, transferResult.Transfer.ID)
+		require.Equal(t, arg.Transfers[i].ToAccountID, transferResult.Transfer.ToAccountID)
+		require.Equal(t, arg.Transfers[i].Amount, transferResult.Transfer.Amount)
+		require.Equal(t, -arg.Transfers[i].Amount, transferResult.FromEntry.Amount)
+		require.Equal(t, arg.Transfers[i].ToAccountID, transferResult.ToEntry.AccountID)
+	}
+
+	require.Equal(t, fromAccount.Balance-60, result.Transfers[2].FromAccount.Balance)
+	require.Equal(t, toAccount1.Balance+40, result.Transfers[2].ToAccount.Balance)
+	require.Equal(t, toAccount2.Balance+20, result.Transfers[1].ToAccount.Balance)
+}
+
+func TestBatchTransferTxRollback(t *testing.T) {
+	store := NewStore(testDb)
+
+	fromAccount := createRandomAccount(t)
+	toAccount1 := createRandomAccountWithCurrency(t, fromAccount.Currency)
+	toAccount2 := createRandomAccountWithCurrency(t, fromAccount.Currency)
+
+	// each leg fits in the balance on its own but not both together
+	_, err := store.BatchTransferTx(context.Background(), BatchTransferTxParams{
+		Transfers: []TransferTxParams{
+			{FromAccountID: fromAccount.ID, ToAccountID: toAccount1.ID, Amount: fromAccount.Balance},
+			{FromAccountID: fromAccount.ID, ToAccountID: toAccount2.ID, Amount: 1},
+		},
+	})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+
+	updatedFromAccount, err := store.GetAccount(context.Background(), fromAccount.ID)
+	require.NoError(t, err)
+	require.Equal(t, fromAccount.Balance, updatedFromAccount.Balance)
+
+	updatedToAccount1, err := store.GetAccount(context.Background(), toAccount1.ID)
+	require.NoError(t, err)
+	require.Equal(t, toAccount1.Balance, updatedToAccount1.Balance)
+}
+
+func TestBatchTransferTxDeadlock(t *testing.T) {
+	store := NewStore(testDb)
+
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccountWithCurrency(t, account1.Currency)
+	account3 := createRandomAccountWithCurrency(t, account1.Currency)
+
+	n := 10
+	errs := make(chan error)
+
+	for i := 0; i < n; i++ {
+		accounts := []Account{account1, account2, account3}
+		from := accounts[i%3]
+
+		go func() {
+			_, err := store.BatchTransferTx(context.Background(), BatchTransferTxParams{
+				Transfers: []TransferTxParams{
+					{FromAccountID: from.ID, ToAccountID: accounts[0].ID, Amount: 1},
+					{FromAccountID: from.ID, ToAccountID: accounts[1].ID, Amount: 1},
+					{FromAccountID: from.ID, ToAccountID: accounts[2].ID, Amount: 1},
+				},
+			})
+
+			errs <- err
+		}()
+	}
+
+	for i := 0; i < n; i++ {
+		err := <-errs
+		require.NoError(t, err)
+	}
+}
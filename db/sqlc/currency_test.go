@@ -0,0 +1,60 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/crackz/simple-bank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListCurrencies(t *testing.T) {
+	currencies, err := testQueries.ListCurrencies(context.Background())
+	require.NoError(t, err)
+	require.NotEmpty(t, currencies)
+
+	byCode := make(map[string]Currency, len(currencies))
+	for _, currency := range currencies {
+		byCode[currency.Code] = currency
+	}
+
+	for _, code := range []string{util.USD, util.CAD, util.EUR} {
+		require.True(t, byCode[code].Enabled, code)
+		require.Equal(t, int32(2), byCode[code].MinorUnits, code)
+	}
+	require.Equal(t, int32(0), byCode["JPY"].MinorUnits)
+	require.NotContains(t, byCode, "EURO")
+}
+
+func TestUpdateCurrencyEnabled(t *testing.T) {
+	currency, err := testQueries.GetCurrency(context.Background(), "CHF")
+	require.NoError(t, err)
+
+	updated, err := testQueries.UpdateCurrencyEnabled(context.Background(), UpdateCurrencyEnabledParams{
+		Code:    currency.Code,
+		Enabled: !currency.Enabled,
+	})
+	require.NoError(t, err)
+	require.Equal(t, !currency.Enabled, updated.Enabled)
+	require.True(t, updated.UpdatedAt.After(currency.UpdatedAt))
+
+	_, err = testQueries.UpdateCurrencyEnabled(context.Background(), UpdateCurrencyEnabledParams{
+		Code:    currency.Code,
+		Enabled: currency.Enabled,
+	})
+	require.NoError(t, err)
+
+	_, err = testQueries.UpdateCurrencyEnabled(context.Background(), UpdateCurrencyEnabledParams{Code: "XXX"})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestCreateAccountUnknownCurrency(t *testing.T) {
+	user := createRandomUser(t)
+
+	_, err := testQueries.CreateAccount(context.Background(), CreateAccountParams{
+		Owner:    user.Username,
+		Currency: "EURO",
+	})
+	require.Error(t, err)
+}
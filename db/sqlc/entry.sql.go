@@ -7,35 +7,56 @@ package db
 
 import (
 	"context"
+	"database/sql"
+	"time"
 )
 
 const createEntry = `-- name: CreateEntry :one
 INSERT INTO entries (
-  account_id, amount
+  account_id, amount, transfer_id
 ) VALUES (
-  $1, $2
-) RETURNING id, account_id, amount, created_at
+  $1, $2, $3
+) RETURNING id, account_id, amount, created_at, transfer_id
 `
 
 type CreateEntryParams struct {
-	AccountID int64 `json:"accountID"`
-	Amount    int64 `json:"amount"`
+	AccountID  int64         `json:"accountID"`
+	Amount     int64         `json:"amount"`
+	TransferID sql.NullInt64 `json:"transferID"`
 }
 
 func (q *Queries) CreateEntry(ctx context.Context, arg CreateEntryParams) (Entry, error) {
-	row := q.db.QueryRowContext(ctx, createEntry, arg.AccountID, arg.Amount)
+	row := q.db.QueryRowContext(ctx, createEntry, arg.AccountID, arg.Amount, arg.TransferID)
 	var i Entry
 	err := row.Scan(
 		&i.ID,
 		&i.AccountID,
 		&i.Amount,
 		&i.CreatedAt,
+		&i.TransferID,
 	)
 	return i, err
 }
 
+const getAccountBalanceBefore = `-- name: GetAccountBalanceBefore :one
+SELECT COALESCE(SUM(amount), 0)::bigint AS balance FROM entries
+WHERE account_id = $1 AND created_at < $2
+`
+
+type GetAccountBalanceBeforeParams struct {
+	AccountID int64     `json:"accountID"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+func (q *Queries) GetAccountBalanceBefore(ctx context.Context, arg GetAccountBalanceBeforeParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, getAccountBalanceBefore, arg.AccountID, arg.CreatedAt)
+	var balance int64
+	err := row.Scan(&balance)
+	return balance, err
+}
+
 const getEntry = `-- name: GetEntry :one
-SELECT id, account_id, amount, created_at FROM entries
+SELECT id, account_id, amount, created_at, transfer_id FROM entries
 WHERE id = $1 LIMIT 1
 `
 
@@ -47,12 +68,152 @@ func (q *Queries) GetEntry(ctx context.Context, id int64) (Entry, error) {
 		&i.AccountID,
 		&i.Amount,
 		&i.CreatedAt,
+		&i.TransferID,
 	)
 	return i, err
 }
 
+const listAccountStatementEntries = `-- name: ListAccountStatementEntries :many
+SELECT
+  e.id,
+  e.account_id,
+  e.amount,
+  e.created_at,
+  e.transfer_id,
+  t.from_account_id,
+  t.to_account_id
+FROM entries e
+LEFT JOIN transfers t ON t.id = e.transfer_id
+WHERE
+  e.account_id = $1 AND
+  e.created_at >= $2 AND
+  e.created_at < $3
+ORDER BY e.created_at, e.id
+`
+
+type ListAccountStatementEntriesParams struct {
+	AccountID int64     `json:"accountID"`
+	FromTime  time.Time `json:"fromTime"`
+	ToTime    time.Time `json:"toTime"`
+}
+
+type ListAccountStatementEntriesRow struct {
+	ID            int64         `json:"id"`
+	AccountID     int64         `json:"accountID"`
+	Amount        int64         `json:"amount"`
+	CreatedAt     time.Time     `json:"createdAt"`
+	TransferID    sql.NullInt64 `json:"transferID"`
+	FromAccountID sql.NullInt64 `json:"fromAccountID"`
+	ToAccountID   sql.NullInt64 `json:"toAccountID"`
+}
+
+func (q *Queries) ListAccountStatementEntries(ctx context.Context, arg ListAccountStatementEntriesParams) ([]ListAccountStatementEntriesRow, error) {
+	rows, err := q.db.QueryContext(ctx, listAccountStatementEntries, arg.AccountID, arg.FromTime, arg.ToTime)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListAccountStatementEntriesRow{}
+	for rows.Next() {
+		var i ListAccountStatementEntriesRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.AccountID,
+			&i.Amount,
+			&i.CreatedAt,
+			&i.TransferID,
+			&i.FromAccountID,
+			&i.ToAccountID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listAccountStatementEntriesAfter = `-- name: ListAccountStatementEntriesAfter :many
+SELECT
+  e.id,
+  e.account_id,
+  e.amount,
+  e.created_at,
+  e.transfer_id,
+  t.from_account_id,
+  t.to_account_id
+FROM entries e
+LEFT JOIN transfers t ON t.id = e.transfer_id
+WHERE
+  e.account_id = $1 AND
+  e.created_at < $2 AND
+  (e.created_at, e.id) > ($3::timestamptz, $4::bigint)
+ORDER BY e.created_at, e.id
+LIMIT $5
+`
+
+type ListAccountStatementEntriesAfterParams struct {
+	AccountID      int64     `json:"accountID"`
+	ToTime         time.Time `json:"toTime"`
+	AfterCreatedAt time.Time `json:"afterCreatedAt"`
+	AfterID        int64     `json:"afterID"`
+	LimitCount     int32     `json:"limitCount"`
+}
+
+type ListAccountStatementEntriesAfterRow struct {
+	ID            int64         `json:"id"`
+	AccountID     int64         `json:"accountID"`
+	Amount        int64         `json:"amount"`
+	CreatedAt     time.Time     `json:"createdAt"`
+	TransferID    sql.NullInt64 `json:"transferID"`
+	FromAccountID sql.NullInt64 `json:"fromAccountID"`
+	ToAccountID   sql.NullInt64 `json:"toAccountID"`
+}
+
+func (q *Queries) ListAccountStatementEntriesAfter(ctx context.Context, arg ListAccountStatementEntriesAfterParams) ([]ListAccountStatementEntriesAfterRow, error) {
+	rows, err := q.db.QueryContext(ctx, listAccountStatementEntriesAfter,
+		arg.AccountID,
+		arg.ToTime,
+		arg.AfterCreatedAt,
+		arg.AfterID,
+		arg.LimitCount,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListAccountStatementEntriesAfterRow{}
+	for rows.Next() {
+		var i ListAccountStatementEntriesAfterRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.AccountID,
+			&i.Amount,
+			&i.CreatedAt,
+			&i.TransferID,
+			&i.FromAccountID,
+			&i.ToAccountID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listEntries = `-- name: ListEntries :many
-SELECT id, account_id, amount, created_at FROM entries
+SELECT id, account_id, amount, created_at, transfer_id FROM entries
 WHERE account_id = $1
 ORDER BY id
 LIMIT $2
@@ -79,6 +240,57 @@ func (q *Queries) ListEntries(ctx context.Context, arg ListEntriesParams) ([]Ent
 			&i.AccountID,
 			&i.Amount,
 			&i.CreatedAt,
+			&i.TransferID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listEntriesAfter = `-- name: ListEntriesAfter :many
+SELECT id, account_id, amount, created_at, transfer_id FROM entries
+WHERE
+  account_id = $1 AND
+  (created_at, id) > ($2::timestamptz, $3::bigint)
+ORDER BY created_at, id
+LIMIT $4
+`
+
+type ListEntriesAfterParams struct {
+	AccountID      int64     `json:"accountID"`
+	AfterCreatedAt time.Time `json:"afterCreatedAt"`
+	AfterID        int64     `json:"afterID"`
+	LimitCount     int32     `json:"limitCount"`
+}
+
+func (q *Queries) ListEntriesAfter(ctx context.Context, arg ListEntriesAfterParams) ([]Entry, error) {
+	rows, err := q.db.QueryContext(ctx, listEntriesAfter,
+		arg.AccountID,
+		arg.AfterCreatedAt,
+		arg.AfterID,
+		arg.LimitCount,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Entry{}
+	for rows.Next() {
+		var i Entry
+		if err := rows.Scan(
+			&i.ID,
+			&i.AccountID,
+			&i.Amount,
+			&i.CreatedAt,
+			&i.TransferID,
 		); err != nil {
 			return nil, err
 		}
@@ -64,3 +64,35 @@ func TestListEntries(t *testing.T) {
 	}
 
 }
+
+func TestListEntriesAfter(t *testing.T) {
+	account := createRandomAccount(t)
+
+	var entries []Entry
+	for i := 0; i < 5; i++ {
+		entries = append(entries, createRandomEntry(t, &account))
+	}
+
+	arg := ListEntriesAfterParams{
+		AccountID:  account.ID,
+		LimitCount: 3,
+	}
+
+	var listed []Entry
+	for {
+		page, err := testQueries.ListEntriesAfter(context.Background(), arg)
+		require.NoError(t, err)
+		if len(page) == 0 {
+			break
+		}
+
+		listed = append(listed, page...)
+		arg.AfterCreatedAt = page[len(page)-1].CreatedAt
+		arg.AfterID = page[len(page)-1].ID
+	}
+
+	require.Len(t, listed, len(entries))
+	for i := range entries {
+		require.Equal(t, entries[i].ID, listed[i].ID)
+	}
+}
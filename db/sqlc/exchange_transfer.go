@@ -0,0 +1,147 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/crackz/simple-bank/fx"
+	"github.com/crackz/simple-bank/risk"
+)
+
+var (
+	ErrQuoteNotFound = errors.New("exchange quote not found")
+	ErrQuoteExpired  = errors.New("exchange quote has expired")
+	ErrQuoteUsed     = errors.New("exchange quote was already used")
+	ErrAmountTooLow  = errors.New("amount is too low to be converted")
+)
+
+type ExchangeTransferTxParams struct {
+	FromAccountID int64  `json:"fromAccountId"`
+	ToAccountID   int64  `json:"toAccountId"`
+	Amount        int64  `json:"amount"`
+	QuoteID       int64  `json:"quoteId"`
+	Owner         string `json:"owner"`
+
+	// Risk is the screening decision that allowed the exchange. It's recorded
+	// with the transfer.
+	Risk *risk.Decision `json:"-"`
+}
+
+// ExchangeTransferTx moves money between accounts of different currencies at
+// the rate locked by an unexpired quote of the owner. The quote is consumed in
+// the same transaction, and the applied rate and both amounts are recorded on
+// the transfer. The source account is charged the fee of its currency's
+// schedule on the amount, like a transfer of TransferTx.
+func (store *SQLStore) ExchangeTransferTx(ctx context.Context, arg ExchangeTransferTxParams) (TransferTxResult, error) {
+	var result TransferTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		schedule, fee, err := transferFee(ctx, q, arg.FromAccountID, arg.Amount)
+		if err != nil {
+			return err
+		}
+
+		ids := []int64{arg.FromAccountID, arg.ToAccountID}
+		if fee > 0 {
+			ids = append(ids, schedule.RevenueAccountID)
+		}
+		accounts, err := lockAccounts(ctx, q, ids...)
+		if err != nil {
+			return err
+		}
+		fromAccount := accounts[arg.FromAccountID]
+		toAccount := accounts[arg.ToAccountID]
+
+		quote, err := q.GetFxQuoteForUpdate(ctx, arg.QuoteID)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return ErrQuoteNotFound
+			}
+			return err
+		}
+
+		switch {
+		case quote.Owner != arg.Owner:
+			return ErrQuoteNotFound
+		case quote.UsedAt.Valid:
+			return ErrQuoteUsed
+		case time.Now().After(quote.ExpiresAt):
+			return ErrQuoteExpired
+		case quote.FromCurrency != fromAccount.Currency || quote.ToCurrency != toAccount.Currency:
+			return ErrCurrencyMismatch
+		case fromAccount.AvailableBalance < arg.Amount+fee:
+			return ErrInsufficientFunds
+		}
+
+		if err := checkAccountsStatus(fromAccount, toAccount); err != nil {
+			return err
+		}
+		if fee > 0 {
+			if err := checkAccountsStatus(fromAccount, accounts[schedule.RevenueAccountID]); err != nil {
+				return err
+			}
+		}
+		if err := checkTransferLimit(ctx, q, fromAccount, arg.Amount); err != nil {
+			return err
+		}
+
+		result, err = exchange(ctx, q, fromAccount, toAccount, arg.Amount, quote.Rate)
+		if err != nil {
+			return err
+		}
+
+		if fee > 0 {
+			if err := chargeFee(ctx, q, schedule, accounts, &result, fee); err != nil {
+				return err
+			}
+		}
+
+		if arg.Risk != nil {
+			_, err = recordRiskDecision(ctx, q, fromAccount.Owner, TransferTxParams{
+				FromAccountID: arg.FromAccountID,
+				ToAccountID:   arg.ToAccountID,
+				Amount:        arg.Amount,
+			}, *arg.Risk, result.Transfer.ID)
+			if err != nil {
+				return err
+			}
+		}
+
+		_, err = q.MarkFxQuoteUsed(ctx, quote.ID)
+		return err
+	})
+
+	return result, err
+}
+
+// exchange converts amount at rate and writes the transfer with its entries.
+// Both accounts must already be locked by the caller.
+func exchange(ctx context.Context, q *Queries, fromAccount Account, toAccount Account, amount int64, rateValue string) (TransferTxResult, error) {
+	rate, err := fx.ParseRate(fromAccount.Currency, toAccount.Currency, rateValue)
+	if err != nil {
+		return TransferTxResult{}, err
+	}
+
+	toAmount, err := rate.Convert(amount)
+	if err != nil {
+		return TransferTxResult{}, err
+	}
+	if toAmount <= 0 {
+		return TransferTxResult{}, ErrAmountTooLow
+	}
+
+	createdTransfer, err := q.CreateExchangeTransfer(ctx, CreateExchangeTransferParams{
+		FromAccountID: fromAccount.ID,
+		ToAccountID:   toAccount.ID,
+		Amount:        amount,
+		ToAmount:      toAmount,
+		ExchangeRate:  rateValue,
+	})
+	if err != nil {
+		return TransferTxResult{}, err
+	}
+
+	return postTransfer(ctx, q, createdTransfer)
+}
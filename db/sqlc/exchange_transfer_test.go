@@ -0,0 +1,185 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/crackz/simple-bank/money"
+	"github.com/crackz/simple-bank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomFxQuote(t *testing.T, owner string, fromCurrency string, toCurrency string, expiresAt time.Time) FxQuote {
+	arg := CreateFxQuoteParams{
+		Owner:        owner,
+		FromCurrency: fromCurrency,
+		ToCurrency:   toCurrency,
+		Rate:         "1.3500000000",
+		ExpiresAt:    expiresAt,
+	}
+
+	quote, err := testQueries.CreateFxQuote(context.Background(), arg)
+	require.NoError(t, err)
+	require.NotZero(t, quote.ID)
+	require.Equal(t, arg.Owner, quote.Owner)
+	require.Equal(t, arg.FromCurrency, quote.FromCurrency)
+	require.Equal(t, arg.ToCurrency, quote.ToCurrency)
+	require.False(t, quote.UsedAt.Valid)
+
+	return quote
+}
+
+func TestExchangeTransferTx(t *testing.T) {
+	store := NewStore(testDb)
+
+	fromAccount := createRandomAccountWithCurrency(t, util.USD)
+	toAccount := createRandomAccountWithCurrency(t, util.CAD)
+	quote := createRandomFxQuote(t, fromAccount.Owner, util.USD, util.CAD, time.Now().Add(time.Minute))
+
+	arg := ExchangeTransferTxParams{
+		FromAccountID: fromAccount.ID,
+		ToAccountID:   toAccount.ID,
+		Amount:        100,
+		QuoteID:       quote.ID,
+		Owner:         fromAccount.Owner,
+	}
+
+	result, err := store.ExchangeTransferTx(context.Background(), arg)
+	require.NoError(t, err)
+
+	require.Equal(t, int64(100), result.Transfer.Amount)
+	require.Equal(t, int64(135), result.Transfer.ToAmount)
+	require.Equal(t, quote.Rate, result.Transfer.ExchangeRate)
+	require.Equal(t, int64(-100), result.FromEntry.Amount)
+	require.Equal(t, int64(135), result.ToEntry.Amount)
+	require.Equal(t, fromAccount.Balance-100, result.FromAccount.Balance)
+	require.Equal(t, toAccount.Balance+135, result.ToAccount.Balance)
+
+	usedQuote, err := testQueries.GetFxQuote(context.Background(), quote.ID)
+	require.NoError(t, err)
+	require.True(t, usedQuote.UsedAt.Valid)
+
+	// a quote can only be used once
+	_, err = store.ExchangeTransferTx(context.Background(), arg)
+	require.ErrorIs(t, err, ErrQuoteUsed)
+}
+
+func TestExchangeTransferTxExponents(t *testing.T) {
+	store := NewStore(testDb)
+	// the currency registry registers the exponents outside of tests
+	money.SetExponent("JPY", 0)
+
+	usdAccount := createRandomAccountWithCurrency(t, util.USD)
+	jpyAccount := createRandomAccountWithCurrency(t, "JPY")
+
+	quote, err := testQueries.CreateFxQuote(context.Background(), CreateFxQuoteParams{
+		Owner:        usdAccount.Owner,
+		FromCurrency: util.USD,
+		ToCurrency:   "JPY",
+		Rate:         "150.0000000000",
+		ExpiresAt:    time.Now().Add(time.Minute),
+	})
+	require.NoError(t, err)
+
+	// 1.00 USD buys 150 JPY, not 15000
+	result, err := store.ExchangeTransferTx(context.Background(), ExchangeTransferTxParams{
+		FromAccountID: usdAccount.ID,
+		ToAccountID:   jpyAccount.ID,
+		Amount:        100,
+		QuoteID:       quote.ID,
+		Owner:         usdAccount.Owner,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(150), result.Transfer.ToAmount)
+	require.Equal(t, jpyAccount.Balance+150, result.ToAccount.Balance)
+
+	quote, err = testQueries.CreateFxQuote(context.Background(), CreateFxQuoteParams{
+		Owner:        jpyAccount.Owner,
+		FromCurrency: "JPY",
+		ToCurrency:   util.USD,
+		Rate:         "0.0066666667",
+		ExpiresAt:    time.Now().Add(time.Minute),
+	})
+	require.NoError(t, err)
+
+	// 150 JPY buy 1.00 USD, not 0.01
+	result, err = store.ExchangeTransferTx(context.Background(), ExchangeTransferTxParams{
+		FromAccountID: jpyAccount.ID,
+		ToAccountID:   usdAccount.ID,
+		Amount:        150,
+		QuoteID:       quote.ID,
+		Owner:         jpyAccount.Owner,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(100), result.Transfer.ToAmount)
+}
+
+func TestExchangeTransferTxRejected(t *testing.T) {
+	store := NewStore(testDb)
+
+	fromAccount := createRandomAccountWithCurrency(t, util.USD)
+	toAccount := createRandomAccountWithCurrency(t, util.CAD)
+	otherAccount := createRandomAccountWithCurrency(t, util.EUR)
+
+	testCases := []struct {
+		name          string
+		quote         FxQuote
+		toAccountID   int64
+		owner         string
+		amount        int64
+		expectedError error
+	}{
+		{
+			name:          "Expired",
+			quote:         createRandomFxQuote(t, fromAccount.Owner, util.USD, util.CAD, time.Now().Add(-time.Second)),
+			toAccountID:   toAccount.ID,
+			owner:         fromAccount.Owner,
+			amount:        10,
+			expectedError: ErrQuoteExpired,
+		},
+		{
+			name:          "OtherOwner",
+			quote:         createRandomFxQuote(t, otherAccount.Owner, util.USD, util.CAD, time.Now().Add(time.Minute)),
+			toAccountID:   toAccount.ID,
+			owner:         fromAccount.Owner,
+			amount:        10,
+			expectedError: ErrQuoteNotFound,
+		},
+		{
+			name:          "CurrencyMismatch",
+			quote:         createRandomFxQuote(t, fromAccount.Owner, util.USD, util.CAD, time.Now().Add(time.Minute)),
+			toAccountID:   otherAccount.ID,
+			owner:         fromAccount.Owner,
+			amount:        10,
+			expectedError: ErrCurrencyMismatch,
+		},
+		{
+			name:          "InsufficientFunds",
+			quote:         createRandomFxQuote(t, fromAccount.Owner, util.USD, util.CAD, time.Now().Add(time.Minute)),
+			toAccountID:   toAccount.ID,
+			owner:         fromAccount.Owner,
+			amount:        fromAccount.Balance + 1,
+			expectedError: ErrInsufficientFunds,
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := store.ExchangeTransferTx(context.Background(), ExchangeTransferTxParams{
+				FromAccountID: fromAccount.ID,
+				ToAccountID:   tc.toAccountID,
+				Amount:        tc.amount,
+				QuoteID:       tc.quote.ID,
+				Owner:         tc.owner,
+			})
+			require.ErrorIs(t, err, tc.expectedError)
+		})
+	}
+
+	account, err := testQueries.GetAccount(context.Background(), fromAccount.ID)
+	require.NoError(t, err)
+	require.Equal(t, fromAccount.Balance, account.Balance)
+}
@@ -0,0 +1,157 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+var ErrInvalidFeeSchedule = errors.New("fee schedule amounts must not be negative and the minimum must not exceed the maximum")
+
+// CreateFeeScheduleTx makes a new schedule the active one of its currency,
+// replacing the one that was active so far. Fees go to the revenue account,
+// which must hold the schedule's currency.
+func (store *SQLStore) CreateFeeScheduleTx(ctx context.Context, arg CreateFeeScheduleParams) (FeeSchedule, error) {
+	var schedule FeeSchedule
+
+	percentage, ok := new(big.Rat).SetString(arg.Percentage)
+	switch {
+	case !ok, percentage.Sign() < 0, arg.FlatAmount < 0, arg.MinAmount < 0:
+		return schedule, ErrInvalidFeeSchedule
+	case arg.MaxAmount.Valid && arg.MaxAmount.Int64 < arg.MinAmount:
+		return schedule, ErrInvalidFeeSchedule
+	}
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		revenueAccount, err := q.GetAccount(ctx, arg.RevenueAccountID)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return ErrAccountNotFound
+			}
+			return err
+		}
+		if revenueAccount.Currency != arg.Currency {
+			return ErrCurrencyMismatch
+		}
+
+		if _, err := q.DeactivateFeeSchedules(ctx, arg.Currency); err != nil {
+			return err
+		}
+
+		schedule, err = q.CreateFeeSchedule(ctx, arg)
+		return err
+	})
+
+	return schedule, err
+}
+
+// computeFee returns the fee the schedule charges on amount: the flat amount
+// plus the percentage of amount rounded half up to a minor unit, raised to the
+// minimum and capped at the maximum.
+func computeFee(schedule FeeSchedule, amount int64) (int64, error) {
+	percentage, ok := new(big.Rat).SetString(schedule.Percentage)
+	if !ok || percentage.Sign() < 0 {
+		return 0, fmt.Errorf("fee schedule %d: %w", schedule.ID, ErrInvalidFeeSchedule)
+	}
+
+	variable := new(big.Rat).Mul(big.NewRat(amount, 1), percentage)
+	variable.Add(variable, big.NewRat(1, 2))
+	rounded := new(big.Int).Quo(variable.Num(), variable.Denom())
+	if !rounded.IsInt64() {
+		return 0, fmt.Errorf("fee schedule %d: %w", schedule.ID, ErrInvalidFeeSchedule)
+	}
+
+	fee := schedule.FlatAmount + rounded.Int64()
+	if fee < schedule.MinAmount {
+		fee = schedule.MinAmount
+	}
+	if schedule.MaxAmount.Valid && fee > schedule.MaxAmount.Int64 {
+		fee = schedule.MaxAmount.Int64
+	}
+
+	return fee, nil
+}
+
+// transferFee returns the active fee schedule of the source account's currency
+// and the fee it charges on amount. The fee is zero when the currency has no
+// active schedule and for transfers from the revenue account itself.
+func transferFee(ctx context.Context, q *Queries, fromAccountID int64, amount int64) (FeeSchedule, int64, error) {
+	schedule, err := q.GetActiveFeeScheduleForAccount(ctx, fromAccountID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return schedule, 0, nil
+		}
+		return schedule, 0, err
+	}
+	if schedule.RevenueAccountID == fromAccountID {
+		return schedule, 0, nil
+	}
+
+	fee, err := computeFee(schedule, amount)
+	return schedule, fee, err
+}
+
+// chargeFee pays the fee of a transfer to the schedule's revenue account with
+// a transfer of its own and links it to the charged transfer. The revenue
+// account must already be locked by the caller, and accounts is updated with
+// the new balances.
+func chargeFee(ctx context.Context, q *Queries, schedule FeeSchedule, accounts map[int64]Account, result *TransferTxResult, fee int64) error {
+	feeResult, err := transfer(ctx, q, TransferTxParams{
+		FromAccountID: result.Transfer.FromAccountID,
+		ToAccountID:   schedule.RevenueAccountID,
+		Amount:        fee,
+	})
+	if err != nil {
+		return err
+	}
+
+	accounts[feeResult.FromAccount.ID] = feeResult.FromAccount
+	accounts[feeResult.ToAccount.ID] = feeResult.ToAccount
+	result.FromAccount = feeResult.FromAccount
+	if result.ToAccount.ID == schedule.RevenueAccountID {
+		result.ToAccount = feeResult.ToAccount
+	}
+
+	transferFee, err := q.CreateTransferFee(ctx, CreateTransferFeeParams{
+		TransferID:    result.Transfer.ID,
+		FeeTransferID: feeResult.Transfer.ID,
+		FeeScheduleID: schedule.ID,
+		Amount:        fee,
+	})
+	if err != nil {
+		return err
+	}
+
+	result.Fee = &transferFee
+	return nil
+}
+
+type TransferFeePreview struct {
+	Amount int64 `json:"amount"`
+	Fee    int64 `json:"fee"`
+	// Total is what the source account is debited.
+	Total         int64         `json:"total"`
+	FeeScheduleID sql.NullInt64 `json:"feeScheduleID"`
+}
+
+// PreviewTransferFee returns the fee a transfer of amount from the account
+// would be charged if it was made now.
+func (store *SQLStore) PreviewTransferFee(ctx context.Context, fromAccountID int64, amount int64) (TransferFeePreview, error) {
+	schedule, fee, err := transferFee(ctx, store.Queries, fromAccountID, amount)
+	if err != nil {
+		return TransferFeePreview{}, err
+	}
+
+	preview := TransferFeePreview{
+		Amount: amount,
+		Fee:    fee,
+		Total:  amount + fee,
+	}
+	if fee > 0 {
+		preview.FeeScheduleID = sql.NullInt64{Int64: schedule.ID, Valid: true}
+	}
+
+	return preview, nil
+}
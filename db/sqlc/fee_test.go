@@ -0,0 +1,261 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/crackz/simple-bank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomFeeSchedule(t *testing.T, currency string, arg CreateFeeScheduleParams) FeeSchedule {
+	revenueAccount := createRandomAccountWithCurrency(t, currency)
+
+	arg.Currency = currency
+	arg.RevenueAccountID = revenueAccount.ID
+
+	schedule, err := NewStore(testDb).CreateFeeScheduleTx(context.Background(), arg)
+	require.NoError(t, err)
+	require.True(t, schedule.Active)
+
+	// other transfer tests of the currency expect no fee
+	t.Cleanup(func() {
+		_, err := testQueries.DeactivateFeeSchedule(context.Background(), schedule.ID)
+		require.NoError(t, err)
+	})
+
+	return schedule
+}
+
+func TestComputeFee(t *testing.T) {
+	testCases := []struct {
+		name     string
+		schedule FeeSchedule
+		amount   int64
+		fee      int64
+	}{
+		{
+			name:     "Flat",
+			schedule: FeeSchedule{FlatAmount: 30, Percentage: "0"},
+			amount:   1000,
+			fee:      30,
+		},
+		{
+			name:     "PercentageRoundsHalfUp",
+			schedule: FeeSchedule{Percentage: "0.005"},
+			amount:   1100,
+			fee:      6,
+		},
+		{
+			name:     "PercentageRoundsDown",
+			schedule: FeeSchedule{Percentage: "0.005"},
+			amount:   1099,
+			fee:      5,
+		},
+		{
+			name:     "FlatAndPercentage",
+			schedule: FeeSchedule{FlatAmount: 10, Percentage: "0.01"},
+			amount:   1000,
+			fee:      20,
+		},
+		{
+			name:     "Minimum",
+			schedule: FeeSchedule{Percentage: "0.01", MinAmount: 25},
+			amount:   1000,
+			fee:      25,
+		},
+		{
+			name:     "Maximum",
+			schedule: FeeSchedule{Percentage: "0.01", MaxAmount: sql.NullInt64{Int64: 50, Valid: true}},
+			amount:   100000,
+			fee:      50,
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			fee, err := computeFee(tc.schedule, tc.amount)
+			require.NoError(t, err)
+			require.Equal(t, tc.fee, fee)
+		})
+	}
+}
+
+func TestCreateFeeScheduleTx(t *testing.T) {
+	store := NewStore(testDb)
+
+	account := createRandomAccount(t)
+	first := createRandomFeeSchedule(t, account.Currency, CreateFeeScheduleParams{FlatAmount: 10, Percentage: "0"})
+	second := createRandomFeeSchedule(t, account.Currency, CreateFeeScheduleParams{FlatAmount: 20, Percentage: "0"})
+
+	schedule, err := store.GetFeeSchedule(context.Background(), first.ID)
+	require.NoError(t, err)
+	require.False(t, schedule.Active)
+
+	active, err := store.GetActiveFeeScheduleForAccount(context.Background(), account.ID)
+	require.NoError(t, err)
+	require.Equal(t, second.ID, active.ID)
+
+	_, err = store.CreateFeeScheduleTx(context.Background(), CreateFeeScheduleParams{
+		Currency:         account.Currency,
+		Percentage:       "0",
+		MinAmount:        50,
+		MaxAmount:        sql.NullInt64{Int64: 10, Valid: true},
+		RevenueAccountID: account.ID,
+	})
+	require.ErrorIs(t, err, ErrInvalidFeeSchedule)
+}
+
+func TestTransferTxWithFee(t *testing.T) {
+	store := NewStore(testDb)
+
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccountWithCurrency(t, account1.Currency)
+	schedule := createRandomFeeSchedule(t, account1.Currency, CreateFeeScheduleParams{
+		FlatAmount: 5,
+		Percentage: "0.01",
+	})
+
+	amount := int64(100)
+	fee := int64(6)
+
+	preview, err := store.PreviewTransferFee(context.Background(), account1.ID, amount)
+	require.NoError(t, err)
+	require.Equal(t, fee, preview.Fee)
+	require.Equal(t, amount+fee, preview.Total)
+	require.Equal(t, schedule.ID, preview.FeeScheduleID.Int64)
+
+	result, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        amount,
+	})
+	require.NoError(t, err)
+	require.NotNil(t, result.Fee)
+	require.Equal(t, fee, result.Fee.Amount)
+	require.Equal(t, schedule.ID, result.Fee.FeeScheduleID)
+	require.Equal(t, account1.Balance-amount-fee, result.FromAccount.Balance)
+	require.Equal(t, account2.Balance+amount, result.ToAccount.Balance)
+
+	transferFee, err := store.GetTransferFee(context.Background(), result.Transfer.ID)
+	require.NoError(t, err)
+	require.Equal(t, *result.Fee, transferFee)
+
+	feeTransfer, err := store.GetTransfer(context.Background(), transferFee.FeeTransferID)
+	require.NoError(t, err)
+	require.Equal(t, account1.ID, feeTransfer.FromAccountID)
+	require.Equal(t, schedule.RevenueAccountID, feeTransfer.ToAccountID)
+	require.Equal(t, fee, feeTransfer.Amount)
+
+	revenueAccount, err := store.GetAccount(context.Background(), schedule.RevenueAccountID)
+	require.NoError(t, err)
+
+	// transfers from the revenue account aren't charged
+	_, err = store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: revenueAccount.ID,
+		ToAccountID:   account2.ID,
+		Amount:        1,
+	})
+	require.NoError(t, err)
+
+	updatedRevenueAccount, err := store.GetAccount(context.Background(), revenueAccount.ID)
+	require.NoError(t, err)
+	require.Equal(t, revenueAccount.Balance-1, updatedRevenueAccount.Balance)
+}
+
+func TestTransferTxWithFeeInsufficientFunds(t *testing.T) {
+	store := NewStore(testDb)
+
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccountWithCurrency(t, account1.Currency)
+	createRandomFeeSchedule(t, account1.Currency, CreateFeeScheduleParams{FlatAmount: 1, Percentage: "0"})
+
+	// the balance covers the amount but not the fee on top of it
+	_, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        account1.Balance,
+	})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+
+	updatedAccount1, err := store.GetAccount(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Equal(t, account1.Balance, updatedAccount1.Balance)
+}
+
+func TestBatchTransferTxWithFee(t *testing.T) {
+	store := NewStore(testDb)
+
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccountWithCurrency(t, account1.Currency)
+	account3 := createRandomAccountWithCurrency(t, account1.Currency)
+	schedule := createRandomFeeSchedule(t, account1.Currency, CreateFeeScheduleParams{FlatAmount: 3, Percentage: "0"})
+
+	revenueAccount, err := store.GetAccount(context.Background(), schedule.RevenueAccountID)
+	require.NoError(t, err)
+
+	result, err := store.BatchTransferTx(context.Background(), BatchTransferTxParams{
+		Transfers: []TransferTxParams{
+			{FromAccountID: account1.ID, ToAccountID: account2.ID, Amount: 10},
+			{FromAccountID: account1.ID, ToAccountID: account3.ID, Amount: 20},
+		},
+	})
+	require.NoError(t, err)
+	require.Len(t, result.Transfers, 2)
+
+	// every leg is charged
+	for _, transfer := range result.Transfers {
+		require.NotNil(t, transfer.Fee)
+		require.Equal(t, int64(3), transfer.Fee.Amount)
+		require.Equal(t, schedule.ID, transfer.Fee.FeeScheduleID)
+	}
+	require.Equal(t, account1.Balance-36, result.Transfers[1].FromAccount.Balance)
+
+	updatedRevenueAccount, err := store.GetAccount(context.Background(), revenueAccount.ID)
+	require.NoError(t, err)
+	require.Equal(t, revenueAccount.Balance+6, updatedRevenueAccount.Balance)
+}
+
+func TestCaptureHoldTxWithFee(t *testing.T) {
+	store := NewStore(testDb)
+
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccountWithCurrency(t, account1.Currency)
+	hold := createRandomHold(t, store, account1, account2, 50, time.Now().Add(time.Hour)).Hold
+	schedule := createRandomFeeSchedule(t, account1.Currency, CreateFeeScheduleParams{FlatAmount: 4, Percentage: "0"})
+
+	result, err := store.CaptureHoldTx(context.Background(), CaptureHoldTxParams{HoldID: hold.ID})
+	require.NoError(t, err)
+	require.NotNil(t, result.Transfer.Fee)
+	require.Equal(t, int64(4), result.Transfer.Fee.Amount)
+	require.Equal(t, schedule.ID, result.Transfer.Fee.FeeScheduleID)
+	require.Equal(t, account1.Balance-54, result.Account.Balance)
+	require.Zero(t, result.Account.HeldBalance)
+}
+
+func TestExchangeTransferTxWithFee(t *testing.T) {
+	store := NewStore(testDb)
+
+	fromAccount := createRandomAccountWithCurrency(t, util.USD)
+	toAccount := createRandomAccountWithCurrency(t, util.CAD)
+	quote := createRandomFxQuote(t, fromAccount.Owner, util.USD, util.CAD, time.Now().Add(time.Minute))
+	schedule := createRandomFeeSchedule(t, util.USD, CreateFeeScheduleParams{FlatAmount: 2, Percentage: "0"})
+
+	result, err := store.ExchangeTransferTx(context.Background(), ExchangeTransferTxParams{
+		FromAccountID: fromAccount.ID,
+		ToAccountID:   toAccount.ID,
+		Amount:        100,
+		QuoteID:       quote.ID,
+		Owner:         fromAccount.Owner,
+	})
+	require.NoError(t, err)
+	require.NotNil(t, result.Fee)
+	require.Equal(t, int64(2), result.Fee.Amount)
+	require.Equal(t, schedule.ID, result.Fee.FeeScheduleID)
+	require.Equal(t, fromAccount.Balance-102, result.FromAccount.Balance)
+	require.Equal(t, toAccount.Balance+135, result.ToAccount.Balance)
+}
@@ -0,0 +1,109 @@
+package db
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+
+	"github.com/lib/pq"
+)
+
+var ErrIdempotencyKeyConflict = errors.New("idempotency key was already used for a different request")
+
+const (
+	idempotencyKeysPkey = "idempotency_keys_pkey"
+	// MaxIdempotencyKeyLength is the longest key clients may send.
+	MaxIdempotencyKeyLength = 255
+)
+
+type IdempotencyKeyParams struct {
+	Owner       string
+	Key         string
+	RequestHash string
+}
+
+// NewIdempotencyKeyParams returns the params of a request made with key. The
+// request is hashed from the route it was made on and its encoded body, so
+// reusing a key for a different request can be detected.
+func NewIdempotencyKeyParams(owner string, key string, route string, body []byte) *IdempotencyKeyParams {
+	hash := sha256.New()
+	hash.Write([]byte(route + "\n"))
+	hash.Write(body)
+
+	return &IdempotencyKeyParams{
+		Owner:       owner,
+		Key:         key,
+		RequestHash: hex.EncodeToString(hash.Sum(nil)),
+	}
+}
+
+// execIdempotentTx runs fn in a transaction and stores the JSON encoded result
+// under the idempotency key in that same transaction. If the key was already
+// used for the same request, the stored result is decoded into result and fn
+// isn't run again.
+func (store *SQLStore) execIdempotentTx(ctx context.Context, idempotency *IdempotencyKeyParams, result interface{}, fn func(*Queries) error) error {
+	if idempotency == nil {
+		return store.execTx(ctx, fn)
+	}
+
+	replayed, err := replayIdempotentResult(ctx, store.Queries, idempotency, result)
+	if err != nil || replayed {
+		return err
+	}
+
+	err = store.execTx(ctx, func(q *Queries) error {
+		if err := fn(q); err != nil {
+			return err
+		}
+
+		return storeIdempotentResult(ctx, q, idempotency, result)
+	})
+
+	// A concurrent request with the same key committed first, so ours was
+	// rolled back and the committed result is the one to return.
+	if pqErr, ok := err.(*pq.Error); ok && pqErr.Constraint == idempotencyKeysPkey {
+		_, err = replayIdempotentResult(ctx, store.Queries, idempotency, result)
+	}
+
+	return err
+}
+
+// storeIdempotentResult stores the JSON encoded result under the idempotency
+// key, for requests made again with the key to be answered with.
+func storeIdempotentResult(ctx context.Context, q *Queries, idempotency *IdempotencyKeyParams, result interface{}) error {
+	response, err := json.Marshal(result)
+	if err != nil {
+		return err
+	}
+
+	_, err = q.CreateIdempotencyKey(ctx, CreateIdempotencyKeyParams{
+		Owner:       idempotency.Owner,
+		Key:         idempotency.Key,
+		RequestHash: idempotency.RequestHash,
+		Response:    response,
+	})
+	return err
+}
+
+func replayIdempotentResult(ctx context.Context, q *Queries, idempotency *IdempotencyKeyParams, result interface{}) (bool, error) {
+	stored, err := q.GetIdempotencyKey(ctx, GetIdempotencyKeyParams{
+		Owner: idempotency.Owner,
+		Key:   idempotency.Key,
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return false, nil
+		}
+
+		return false, err
+	}
+
+	if stored.RequestHash != idempotency.RequestHash {
+		return false, ErrIdempotencyKeyConflict
+	}
+
+	return true, json.Unmarshal(stored.Response, result)
+}
@@ -0,0 +1,212 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/crackz/simple-bank/interest"
+)
+
+var (
+	ErrInterestPlanNotFound   = errors.New("interest plan not found")
+	ErrInterestNotAssigned    = errors.New("account has no interest plan")
+	ErrInterestExpenseAccount = errors.New("an interest expense account can't earn interest")
+)
+
+type AssignInterestPlanTxParams struct {
+	AccountID int64 `json:"accountId"`
+	PlanID    int64 `json:"planId"`
+}
+
+// AssignInterestPlanTx puts an account on an interest plan, starting with the
+// current day. Moving an account to another plan keeps what it accrued so far,
+// and days already accrued keep the rate they were accrued at.
+func (store *SQLStore) AssignInterestPlanTx(ctx context.Context, arg AssignInterestPlanTxParams) (AccountInterest, error) {
+	var accountInterest AccountInterest
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		account, err := lockAccount(ctx, q, arg.AccountID)
+		if err != nil {
+			return err
+		}
+		if account.Status == AccountClosed {
+			return fmt.Errorf("account %d: %w", account.ID, ErrAccountClosed)
+		}
+
+		plan, err := q.GetInterestPlan(ctx, arg.PlanID)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return ErrInterestPlanNotFound
+			}
+			return err
+		}
+
+		switch {
+		case plan.Currency != account.Currency:
+			return ErrCurrencyMismatch
+		case plan.ExpenseAccountID == account.ID:
+			return ErrInterestExpenseAccount
+		}
+
+		accountInterest, err = q.AssignInterestPlan(ctx, AssignInterestPlanParams{
+			AccountID:      account.ID,
+			PlanID:         plan.ID,
+			AccruedThrough: interest.Date(time.Now()).AddDate(0, 0, -1),
+		})
+		return err
+	})
+
+	return accountInterest, err
+}
+
+type AccrueInterestTxParams struct {
+	AccountID int64 `json:"accountId"`
+	// Through is the last day to accrue interest for. It must have ended, so
+	// its end-of-day balance is final.
+	Through time.Time `json:"through"`
+}
+
+type AccrueInterestTxResult struct {
+	AccountInterest AccountInterest   `json:"accountInterest"`
+	Accruals        []InterestAccrual `json:"accruals"`
+	Postings        []InterestPosting `json:"postings"`
+}
+
+// AccrueInterestTx accrues the interest of an account for every day after the
+// last accrued one up to Through, on each day's end-of-day balance, and posts
+// it after the last day of every month. Only whole minor units are posted; the
+// fraction left over is carried over to the next month. Posting transfers the
+// interest from the plan's expense account, which is allowed to go negative
+// since that's what an expense account does.
+func (store *SQLStore) AccrueInterestTx(ctx context.Context, arg AccrueInterestTxParams) (AccrueInterestTxResult, error) {
+	var result AccrueInterestTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		result = AccrueInterestTxResult{}
+
+		accountInterest, err := q.GetAccountInterestForUpdate(ctx, arg.AccountID)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return ErrInterestNotAssigned
+			}
+			return err
+		}
+		result.AccountInterest = accountInterest
+
+		through := interest.Date(arg.Through)
+		if !accountInterest.AccruedThrough.Before(through) {
+			return nil
+		}
+
+		plan, err := q.GetInterestPlan(ctx, accountInterest.PlanID)
+		if err != nil {
+			return err
+		}
+		rate, err := interest.ParseRate(plan.AnnualRate)
+		if err != nil {
+			return fmt.Errorf("interest plan %d: %w", plan.ID, err)
+		}
+		accrued, err := interest.ParseAccrued(accountInterest.Accrued)
+		if err != nil {
+			return err
+		}
+
+		day := interest.Date(accountInterest.AccruedThrough)
+		for day.Before(through) {
+			day = day.AddDate(0, 0, 1)
+
+			balance, err := q.GetAccountBalanceAt(ctx, GetAccountBalanceAtParams{
+				At:        day.AddDate(0, 0, 1),
+				AccountID: arg.AccountID,
+			})
+			if err != nil {
+				return err
+			}
+
+			amount, err := interest.DailyAccrual(balance, rate, plan.DayCount, day)
+			if err != nil {
+				return fmt.Errorf("interest plan %d: %w", plan.ID, err)
+			}
+
+			accrual, err := q.CreateInterestAccrual(ctx, CreateInterestAccrualParams{
+				AccountID:   arg.AccountID,
+				PlanID:      plan.ID,
+				AccrualDate: day,
+				Balance:     balance,
+				AnnualRate:  plan.AnnualRate,
+				DayCount:    plan.DayCount,
+				Amount:      interest.FormatAccrued(amount),
+			})
+			if err != nil {
+				return err
+			}
+			result.Accruals = append(result.Accruals, accrual)
+			accrued.Add(accrued, amount)
+
+			if !interest.IsMonthEnd(day) {
+				continue
+			}
+
+			posted, rest, err := interest.Split(accrued)
+			if err != nil {
+				return err
+			}
+			if posted == 0 {
+				continue
+			}
+
+			posting, err := postInterest(ctx, q, plan, arg.AccountID, day, posted)
+			if err != nil {
+				return err
+			}
+			result.Postings = append(result.Postings, posting)
+			accrued = rest
+		}
+
+		result.AccountInterest, err = q.UpdateAccountInterestAccrued(ctx, UpdateAccountInterestAccruedParams{
+			AccountID:      arg.AccountID,
+			Accrued:        interest.FormatAccrued(accrued),
+			AccruedThrough: through,
+		})
+		return err
+	})
+
+	return result, err
+}
+
+// postInterest pays amount of interest for the period ending on periodEnd from
+// the plan's expense account. The expense account's balance isn't checked.
+func postInterest(ctx context.Context, q *Queries, plan InterestPlan, accountID int64, periodEnd time.Time, amount int64) (InterestPosting, error) {
+	accounts, err := lockAccounts(ctx, q, plan.ExpenseAccountID, accountID)
+	if err != nil {
+		return InterestPosting{}, err
+	}
+	expenseAccount := accounts[plan.ExpenseAccountID]
+	account := accounts[accountID]
+
+	if err := checkAccountsStatus(expenseAccount, account); err != nil {
+		return InterestPosting{}, err
+	}
+	if expenseAccount.Currency != account.Currency {
+		return InterestPosting{}, ErrCurrencyMismatch
+	}
+
+	result, err := transfer(ctx, q, TransferTxParams{
+		FromAccountID: expenseAccount.ID,
+		ToAccountID:   account.ID,
+		Amount:        amount,
+	})
+	if err != nil {
+		return InterestPosting{}, err
+	}
+
+	return q.CreateInterestPosting(ctx, CreateInterestPostingParams{
+		AccountID:  accountID,
+		PeriodEnd:  periodEnd,
+		Amount:     amount,
+		TransferID: result.Transfer.ID,
+	})
+}
@@ -0,0 +1,142 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/crackz/simple-bank/interest"
+	"github.com/crackz/simple-bank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomInterestPlan(t *testing.T, currency string, dayCount string) InterestPlan {
+	expenseAccount := createRandomAccountWithCurrency(t, currency)
+
+	plan, err := testQueries.CreateInterestPlan(context.Background(), CreateInterestPlanParams{
+		Name:             "savings",
+		Currency:         currency,
+		AnnualRate:       "0.0365",
+		DayCount:         dayCount,
+		ExpenseAccountID: expenseAccount.ID,
+	})
+	require.NoError(t, err)
+	require.Equal(t, expenseAccount.ID, plan.ExpenseAccountID)
+
+	return plan
+}
+
+func TestAssignInterestPlanTx(t *testing.T) {
+	store := NewStore(testDb)
+
+	account := createRandomAccountWithCurrency(t, util.USD)
+	plan := createRandomInterestPlan(t, account.Currency, interest.ACT365)
+
+	accountInterest, err := store.AssignInterestPlanTx(context.Background(), AssignInterestPlanTxParams{
+		AccountID: account.ID,
+		PlanID:    plan.ID,
+	})
+	require.NoError(t, err)
+	require.Equal(t, plan.ID, accountInterest.PlanID)
+	require.Equal(t, interest.Date(time.Now()).AddDate(0, 0, -1), interest.Date(accountInterest.AccruedThrough))
+
+	_, err = store.AssignInterestPlanTx(context.Background(), AssignInterestPlanTxParams{
+		AccountID: plan.ExpenseAccountID,
+		PlanID:    plan.ID,
+	})
+	require.ErrorIs(t, err, ErrInterestExpenseAccount)
+
+	otherPlan := createRandomInterestPlan(t, util.CAD, interest.ACT365)
+	_, err = store.AssignInterestPlanTx(context.Background(), AssignInterestPlanTxParams{
+		AccountID: account.ID,
+		PlanID:    otherPlan.ID,
+	})
+	require.ErrorIs(t, err, ErrCurrencyMismatch)
+}
+
+func TestAccrueInterestTx(t *testing.T) {
+	store := NewStore(testDb)
+
+	account := createRandomAccount(t)
+	plan := createRandomInterestPlan(t, account.Currency, interest.ACT365)
+
+	_, err := store.AssignInterestPlanTx(context.Background(), AssignInterestPlanTxParams{
+		AccountID: account.ID,
+		PlanID:    plan.ID,
+	})
+	require.NoError(t, err)
+
+	// start accruing from a month end with a fraction carried over, so the
+	// first month end posted is the next one
+	start := interest.Date(time.Now()).AddDate(0, -2, 0)
+	start = time.Date(start.Year(), start.Month(), 0, 0, 0, 0, 0, time.UTC)
+	_, err = testQueries.UpdateAccountInterestAccrued(context.Background(), UpdateAccountInterestAccruedParams{
+		AccountID:      account.ID,
+		Accrued:        "0.5000000000",
+		AccruedThrough: start,
+	})
+	require.NoError(t, err)
+
+	through := time.Date(start.Year(), start.Month()+2, 0, 0, 0, 0, 0, time.UTC)
+
+	result, err := store.AccrueInterestTx(context.Background(), AccrueInterestTxParams{
+		AccountID: account.ID,
+		Through:   through,
+	})
+	require.NoError(t, err)
+	require.Len(t, result.Accruals, through.Day())
+	require.Equal(t, through, interest.Date(result.AccountInterest.AccruedThrough))
+
+	total, err := interest.ParseAccrued("0.5")
+	require.NoError(t, err)
+	for _, accrual := range result.Accruals {
+		require.Equal(t, account.Balance, accrual.Balance)
+
+		amount, err := interest.ParseAccrued(accrual.Amount)
+		require.NoError(t, err)
+		total.Add(total, amount)
+	}
+
+	posted, rest, err := interest.Split(total)
+	require.NoError(t, err)
+
+	if posted == 0 {
+		require.Empty(t, result.Postings)
+	} else {
+		require.Len(t, result.Postings, 1)
+		require.Equal(t, posted, result.Postings[0].Amount)
+		require.Equal(t, through, interest.Date(result.Postings[0].PeriodEnd))
+
+		transfer, err := store.GetTransfer(context.Background(), result.Postings[0].TransferID)
+		require.NoError(t, err)
+		require.Equal(t, plan.ExpenseAccountID, transfer.FromAccountID)
+		require.Equal(t, account.ID, transfer.ToAccountID)
+		require.Equal(t, posted, transfer.Amount)
+
+		updatedAccount, err := store.GetAccount(context.Background(), account.ID)
+		require.NoError(t, err)
+		require.Equal(t, account.Balance+posted, updatedAccount.Balance)
+	}
+	require.Equal(t, interest.FormatAccrued(rest), result.AccountInterest.Accrued)
+
+	// accruing again through the same day does nothing
+	again, err := store.AccrueInterestTx(context.Background(), AccrueInterestTxParams{
+		AccountID: account.ID,
+		Through:   through,
+	})
+	require.NoError(t, err)
+	require.Empty(t, again.Accruals)
+	require.Equal(t, result.AccountInterest.Accrued, again.AccountInterest.Accrued)
+}
+
+func TestAccrueInterestTxNotAssigned(t *testing.T) {
+	store := NewStore(testDb)
+
+	account := createRandomAccount(t)
+
+	_, err := store.AccrueInterestTx(context.Background(), AccrueInterestTxParams{
+		AccountID: account.ID,
+		Through:   time.Now(),
+	})
+	require.ErrorIs(t, err, ErrInterestNotAssigned)
+}
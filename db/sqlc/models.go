@@ -5,6 +5,8 @@
 package db
 
 import (
+	"database/sql"
+	"encoding/json"
 	"time"
 )
 
@@ -14,16 +16,204 @@ type Account struct {
 	Balance   int64     `json:"balance"`
 	Currency  string    `json:"currency"`
 	CreatedAt time.Time `json:"createdAt"`
+	// sum of the active holds on the account
+	HeldBalance int64 `json:"heldBalance"`
+	// balance that is not reserved by holds
+	AvailableBalance int64 `json:"availableBalance"`
+	// active, frozen or closed
+	Status string `json:"status"`
+}
+
+type AccountHold struct {
+	ID          int64 `json:"id"`
+	AccountID   int64 `json:"accountID"`
+	ToAccountID int64 `json:"toAccountID"`
+	// must be positive
+	Amount int64 `json:"amount"`
+	// active, captured, voided or expired
+	Status     string        `json:"status"`
+	ExpiresAt  time.Time     `json:"expiresAt"`
+	TransferID sql.NullInt64 `json:"transferID"`
+	ResolvedAt sql.NullTime  `json:"resolvedAt"`
+	CreatedAt  time.Time     `json:"createdAt"`
+}
+
+type AccountInterest struct {
+	AccountID int64 `json:"accountID"`
+	PlanID    int64 `json:"planID"`
+	// interest accrued but not posted yet, in fractions of minor units
+	Accrued string `json:"accrued"`
+	// last day interest was accrued for
+	AccruedThrough time.Time `json:"accruedThrough"`
+	CreatedAt      time.Time `json:"createdAt"`
+}
+
+type AuditLog struct {
+	ID int64 `json:"id"`
+	// username of the caller, null if unknown
+	Actor  sql.NullString `json:"actor"`
+	Method string         `json:"method"`
+	// route pattern, e.g. /accounts/:accountID
+	Route string `json:"route"`
+	// null until the call is answered
+	StatusCode sql.NullInt32   `json:"statusCode"`
+	TargetType sql.NullString  `json:"targetType"`
+	TargetID   sql.NullString  `json:"targetID"`
+	Before     json.RawMessage `json:"before"`
+	After      json.RawMessage `json:"after"`
+	ClientIp   string          `json:"clientIp"`
+	CreatedAt  time.Time       `json:"createdAt"`
+}
+
+type Currency struct {
+	// ISO 4217 alphabetic code
+	Code        string `json:"code"`
+	NumericCode string `json:"numericCode"`
+	Name        string `json:"name"`
+	// decimal places of the minor unit, e.g. 2 for cents
+	MinorUnits int32 `json:"minorUnits"`
+	// only enabled currencies are accepted by the API
+	Enabled   bool      `json:"enabled"`
+	UpdatedAt time.Time `json:"updatedAt"`
 }
 
 type Entry struct {
 	ID        int64 `json:"id"`
 	AccountID int64 `json:"accountID"`
 	// can be negative or positive
-	Amount    int64     `json:"amount"`
+	Amount     int64         `json:"amount"`
+	CreatedAt  time.Time     `json:"createdAt"`
+	TransferID sql.NullInt64 `json:"transferID"`
+}
+
+type FeeSchedule struct {
+	ID         int64  `json:"id"`
+	Currency   string `json:"currency"`
+	FlatAmount int64  `json:"flatAmount"`
+	// fraction of the amount, e.g. 0.005 for 0.5%
+	Percentage string `json:"percentage"`
+	MinAmount  int64  `json:"minAmount"`
+	// no cap when null
+	MaxAmount sql.NullInt64 `json:"maxAmount"`
+	// bank-owned account the fees are paid to
+	RevenueAccountID int64     `json:"revenueAccountID"`
+	Active           bool      `json:"active"`
+	CreatedAt        time.Time `json:"createdAt"`
+}
+
+type FxQuote struct {
+	ID           int64        `json:"id"`
+	Owner        string       `json:"owner"`
+	FromCurrency string       `json:"fromCurrency"`
+	ToCurrency   string       `json:"toCurrency"`
+	Rate         string       `json:"rate"`
+	ExpiresAt    time.Time    `json:"expiresAt"`
+	UsedAt       sql.NullTime `json:"usedAt"`
+	CreatedAt    time.Time    `json:"createdAt"`
+}
+
+type IdempotencyKey struct {
+	Owner       string          `json:"owner"`
+	Key         string          `json:"key"`
+	RequestHash string          `json:"requestHash"`
+	Response    json.RawMessage `json:"response"`
+	CreatedAt   time.Time       `json:"createdAt"`
+}
+
+type InterestAccrual struct {
+	ID          int64     `json:"id"`
+	AccountID   int64     `json:"accountID"`
+	PlanID      int64     `json:"planID"`
+	AccrualDate time.Time `json:"accrualDate"`
+	// end-of-day balance the interest was computed on
+	Balance    int64     `json:"balance"`
+	AnnualRate string    `json:"annualRate"`
+	DayCount   string    `json:"dayCount"`
+	Amount     string    `json:"amount"`
+	CreatedAt  time.Time `json:"createdAt"`
+}
+
+type InterestPlan struct {
+	ID       int64  `json:"id"`
+	Name     string `json:"name"`
+	Currency string `json:"currency"`
+	// yearly rate as a fraction, e.g. 0.025 for 2.5%
+	AnnualRate string `json:"annualRate"`
+	// ACT/365 or 30/360
+	DayCount string `json:"dayCount"`
+	// bank-owned account the interest is paid from
+	ExpenseAccountID int64     `json:"expenseAccountID"`
+	CreatedAt        time.Time `json:"createdAt"`
+}
+
+type InterestPosting struct {
+	ID         int64     `json:"id"`
+	AccountID  int64     `json:"accountID"`
+	PeriodEnd  time.Time `json:"periodEnd"`
+	Amount     int64     `json:"amount"`
+	TransferID int64     `json:"transferID"`
+	CreatedAt  time.Time `json:"createdAt"`
+}
+
+type Outbox struct {
+	ID int64 `json:"id"`
+	// account, user or transfer
+	AggregateType string `json:"aggregateType"`
+	AggregateID   string `json:"aggregateID"`
+	// e.g. account.created
+	EventType   string          `json:"eventType"`
+	Payload     json.RawMessage `json:"payload"`
+	PublishedAt sql.NullTime    `json:"publishedAt"`
+	CreatedAt   time.Time       `json:"createdAt"`
+}
+
+type RiskDecision struct {
+	ID            int64  `json:"id"`
+	Owner         string `json:"owner"`
+	FromAccountID int64  `json:"fromAccountID"`
+	ToAccountID   int64  `json:"toAccountID"`
+	Amount        int64  `json:"amount"`
+	// allow, review or block
+	Outcome string `json:"outcome"`
+	// rules the transfer triggered
+	Rules []string `json:"rules"`
+	// allowed, pending, approved, rejected or blocked
+	Status string `json:"status"`
+	// set once the transfer is made
+	TransferID     sql.NullInt64  `json:"transferID"`
+	ReviewedBy     sql.NullString `json:"reviewedBy"`
+	ReviewedAt     sql.NullTime   `json:"reviewedAt"`
+	CreatedAt      time.Time      `json:"createdAt"`
+	IdempotencyKey sql.NullString `json:"idempotencyKey"`
+	RequestHash    sql.NullString `json:"requestHash"`
+}
+
+type ScheduledTransfer struct {
+	ID            int64  `json:"id"`
+	Owner         string `json:"owner"`
+	FromAccountID int64  `json:"fromAccountID"`
+	ToAccountID   int64  `json:"toAccountID"`
+	// must be positive
+	Amount int64 `json:"amount"`
+	// once, daily, weekly or monthly
+	Frequency string    `json:"frequency"`
+	StartAt   time.Time `json:"startAt"`
+	NextRunAt time.Time `json:"nextRunAt"`
+	Active    bool      `json:"active"`
 	CreatedAt time.Time `json:"createdAt"`
 }
 
+type ScheduledTransferRun struct {
+	ID                  int64         `json:"id"`
+	ScheduledTransferID int64         `json:"scheduledTransferID"`
+	ScheduledFor        time.Time     `json:"scheduledFor"`
+	TransferID          sql.NullInt64 `json:"transferID"`
+	// succeeded or failed
+	Status    string         `json:"status"`
+	Error     sql.NullString `json:"error"`
+	CreatedAt time.Time      `json:"createdAt"`
+}
+
 type Transfer struct {
 	ID            int64 `json:"id"`
 	FromAccountID int64 `json:"fromAccountID"`
@@ -31,6 +221,33 @@ type Transfer struct {
 	// must be positive
 	Amount    int64     `json:"amount"`
 	CreatedAt time.Time `json:"createdAt"`
+	// amount credited in the destination account currency
+	ToAmount int64 `json:"toAmount"`
+	// units of the destination currency per unit of the source currency
+	ExchangeRate string `json:"exchangeRate"`
+	// the transfer this one reverses, if any
+	ReversalOf sql.NullInt64 `json:"reversalOf"`
+}
+
+type TransferFee struct {
+	TransferID int64 `json:"transferID"`
+	// transfer paying the fee to the revenue account
+	FeeTransferID int64     `json:"feeTransferID"`
+	FeeScheduleID int64     `json:"feeScheduleID"`
+	Amount        int64     `json:"amount"`
+	CreatedAt     time.Time `json:"createdAt"`
+}
+
+type TransferLimit struct {
+	Username string `json:"username"`
+	Currency string `json:"currency"`
+	// no limit when null
+	PerTransaction sql.NullInt64 `json:"perTransaction"`
+	// limit over the last 24 hours, no limit when null
+	Daily sql.NullInt64 `json:"daily"`
+	// limit over the last 30 days, no limit when null
+	Monthly   sql.NullInt64 `json:"monthly"`
+	UpdatedAt time.Time     `json:"updatedAt"`
 }
 
 type User struct {
@@ -40,4 +257,42 @@ type User struct {
 	Email             string    `json:"email"`
 	PasswordChangedAt time.Time `json:"passwordChangedAt"`
 	CreatedAt         time.Time `json:"createdAt"`
+	Role              string    `json:"role"`
+}
+
+type WebhookDelivery struct {
+	ID        int64           `json:"id"`
+	WebhookID int64           `json:"webhookID"`
+	EventID   int64           `json:"eventID"`
+	EventType string          `json:"eventType"`
+	Payload   json.RawMessage `json:"payload"`
+	// pending, delivered or dead
+	Status        string         `json:"status"`
+	Attempts      int32          `json:"attempts"`
+	NextAttemptAt time.Time      `json:"nextAttemptAt"`
+	LastError     sql.NullString `json:"lastError"`
+	DeliveredAt   sql.NullTime   `json:"deliveredAt"`
+	CreatedAt     time.Time      `json:"createdAt"`
+}
+
+type WebhookDeliveryAttempt struct {
+	ID         int64 `json:"id"`
+	DeliveryID int64 `json:"deliveryID"`
+	WebhookID  int64 `json:"webhookID"`
+	Attempt    int32 `json:"attempt"`
+	// HTTP status of the response, if one was received
+	StatusCode sql.NullInt32  `json:"statusCode"`
+	Error      sql.NullString `json:"error"`
+	CreatedAt  time.Time      `json:"createdAt"`
+}
+
+type WebhookEndpoint struct {
+	ID     int64  `json:"id"`
+	Owner  string `json:"owner"`
+	Url    string `json:"url"`
+	Secret string `json:"secret"`
+	// event types to deliver, all of them when empty
+	EventTypes []string  `json:"eventTypes"`
+	Active     bool      `json:"active"`
+	CreatedAt  time.Time `json:"createdAt"`
 }
@@ -0,0 +1,152 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"time"
+)
+
+const (
+	AggregateAccount  = "account"
+	AggregateUser     = "user"
+	AggregateTransfer = "transfer"
+)
+
+const (
+	EventAccountCreated  = "account.created"
+	EventUserCreated     = "user.created"
+	EventTransferCreated = "transfer.created"
+)
+
+type AccountCreatedEvent struct {
+	AccountID int64     `json:"accountID"`
+	Owner     string    `json:"owner"`
+	Currency  string    `json:"currency"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// UserCreatedEvent leaves out the hashed password, which must never leave the
+// database.
+type UserCreatedEvent struct {
+	Username  string    `json:"username"`
+	FullName  string    `json:"fullName"`
+	Email     string    `json:"email"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+type TransferCreatedEvent struct {
+	TransferID    int64     `json:"transferID"`
+	FromAccountID int64     `json:"fromAccountID"`
+	ToAccountID   int64     `json:"toAccountID"`
+	Amount        int64     `json:"amount"`
+	ToAmount      int64     `json:"toAmount"`
+	ExchangeRate  string    `json:"exchangeRate"`
+	ReversalOf    *int64    `json:"reversalOf,omitempty"`
+	CreatedAt     time.Time `json:"createdAt"`
+}
+
+// recordEvent writes an event to the outbox in the caller's transaction, so it
+// is published if and only if the change it describes is committed.
+func recordEvent(ctx context.Context, q *Queries, aggregateType string, aggregateID string, eventType string, payload interface{}) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	_, err = q.CreateOutboxEvent(ctx, CreateOutboxEventParams{
+		AggregateType: aggregateType,
+		AggregateID:   aggregateID,
+		EventType:     eventType,
+		Payload:       data,
+	})
+	return err
+}
+
+func recordAccountCreated(ctx context.Context, q *Queries, account Account) error {
+	return recordEvent(ctx, q, AggregateAccount, strconv.FormatInt(account.ID, 10), EventAccountCreated, AccountCreatedEvent{
+		AccountID: account.ID,
+		Owner:     account.Owner,
+		Currency:  account.Currency,
+		CreatedAt: account.CreatedAt,
+	})
+}
+
+func recordTransferCreated(ctx context.Context, q *Queries, transfer Transfer) error {
+	event := TransferCreatedEvent{
+		TransferID:    transfer.ID,
+		FromAccountID: transfer.FromAccountID,
+		ToAccountID:   transfer.ToAccountID,
+		Amount:        transfer.Amount,
+		ToAmount:      transfer.ToAmount,
+		ExchangeRate:  transfer.ExchangeRate,
+		CreatedAt:     transfer.CreatedAt,
+	}
+	if transfer.ReversalOf.Valid {
+		event.ReversalOf = &transfer.ReversalOf.Int64
+	}
+
+	return recordEvent(ctx, q, AggregateTransfer, strconv.FormatInt(transfer.ID, 10), EventTransferCreated, event)
+}
+
+// CreateUserTx creates the user and records its user.created event.
+func (store *SQLStore) CreateUserTx(ctx context.Context, arg CreateUserParams) (User, error) {
+	var user User
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+
+		user, err = q.CreateUser(ctx, arg)
+		if err != nil {
+			return err
+		}
+
+		return recordEvent(ctx, q, AggregateUser, user.Username, EventUserCreated, UserCreatedEvent{
+			Username:  user.Username,
+			FullName:  user.FullName,
+			Email:     user.Email,
+			CreatedAt: user.CreatedAt,
+		})
+	})
+
+	return user, err
+}
+
+// RelayOutboxEvents hands up to limit unpublished events to publish in the
+// order they were recorded and marks the published ones. The claimed rows stay
+// locked until then, so concurrent relays take turns instead of publishing
+// out of order. It stops at the first event publish fails on, keeping that
+// event and everything after it for the next call, and returns how many were
+// published. Delivery is at least once: an event whose mark is lost, e.g. to
+// a crash before commit, is published again.
+func (store *SQLStore) RelayOutboxEvents(ctx context.Context, limit int32, publish func(context.Context, Outbox) error) (int, error) {
+	var published int
+	var publishErr error
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		published, publishErr = 0, nil
+
+		events, err := q.ClaimUnpublishedOutboxEvents(ctx, limit)
+		if err != nil {
+			return err
+		}
+
+		for _, event := range events {
+			if publishErr = publish(ctx, event); publishErr != nil {
+				return nil
+			}
+
+			if err := q.MarkOutboxEventPublished(ctx, event.ID); err != nil {
+				return err
+			}
+			published++
+		}
+
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return published, publishErr
+}
@@ -0,0 +1,143 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"testing"
+
+	"github.com/crackz/simple-bank/util"
+	"github.com/stretchr/testify/require"
+)
+
+// relayAll publishes every pending event, including the ones other tests
+// recorded, and returns them in publish order.
+func relayAll(t *testing.T, store Store) []Outbox {
+	var events []Outbox
+
+	for {
+		published, err := store.RelayOutboxEvents(context.Background(), 100, func(_ context.Context, event Outbox) error {
+			events = append(events, event)
+			return nil
+		})
+		require.NoError(t, err)
+
+		if published == 0 {
+			return events
+		}
+	}
+}
+
+func findEvents(events []Outbox, aggregateType string, aggregateID string) []Outbox {
+	var found []Outbox
+	for _, event := range events {
+		if event.AggregateType == aggregateType && event.AggregateID == aggregateID {
+			found = append(found, event)
+		}
+	}
+
+	return found
+}
+
+func TestOutboxEvents(t *testing.T) {
+	store := NewStore(testDb)
+
+	hashedPassword, err := util.HashPassword(util.RandString(6))
+	require.NoError(t, err)
+
+	user, err := store.CreateUserTx(context.Background(), CreateUserParams{
+		Username:       util.RandOwner(),
+		HashedPassword: hashedPassword,
+		FullName:       util.RandString(8),
+		Email:          util.RandomEmail(),
+	})
+	require.NoError(t, err)
+
+	account, err := store.CreateAccountTx(context.Background(), CreateAccountTxParams{
+		CreateAccountParams: CreateAccountParams{
+			Owner:    user.Username,
+			Balance:  100,
+			Currency: util.RandomCurrency(),
+		},
+	})
+	require.NoError(t, err)
+
+	toAccount := createRandomAccountWithCurrency(t, account.Currency)
+	result, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account.ID,
+		ToAccountID:   toAccount.ID,
+		Amount:        10,
+	})
+	require.NoError(t, err)
+
+	events := relayAll(t, store)
+
+	userEvents := findEvents(events, AggregateUser, user.Username)
+	require.Len(t, userEvents, 1)
+	require.Equal(t, EventUserCreated, userEvents[0].EventType)
+	require.NotContains(t, string(userEvents[0].Payload), hashedPassword)
+
+	accountEvents := findEvents(events, AggregateAccount, strconv.FormatInt(account.ID, 10))
+	require.Len(t, accountEvents, 1)
+	require.Equal(t, EventAccountCreated, accountEvents[0].EventType)
+	require.Greater(t, accountEvents[0].ID, userEvents[0].ID)
+
+	transferEvents := findEvents(events, AggregateTransfer, strconv.FormatInt(result.Transfer.ID, 10))
+	require.Len(t, transferEvents, 1)
+	require.Equal(t, EventTransferCreated, transferEvents[0].EventType)
+
+	var transferCreated TransferCreatedEvent
+	require.NoError(t, json.Unmarshal(transferEvents[0].Payload, &transferCreated))
+	require.Equal(t, result.Transfer.ID, transferCreated.TransferID)
+	require.Equal(t, account.ID, transferCreated.FromAccountID)
+	require.Equal(t, toAccount.ID, transferCreated.ToAccountID)
+	require.Equal(t, int64(10), transferCreated.Amount)
+
+	// published events aren't relayed again
+	require.Empty(t, findEvents(relayAll(t, store), AggregateUser, user.Username))
+}
+
+func TestRelayOutboxEventsPublishFailure(t *testing.T) {
+	store := NewStore(testDb)
+	relayAll(t, store)
+
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccountWithCurrency(t, account1.Currency)
+	_, err := store.CreateAccountTx(context.Background(), CreateAccountTxParams{
+		CreateAccountParams: CreateAccountParams{
+			Owner:    createRandomUser(t).Username,
+			Currency: util.RandomCurrency(),
+		},
+	})
+	require.NoError(t, err)
+
+	_, err = store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        10,
+	})
+	require.NoError(t, err)
+
+	// the first event goes through and the failed one stays pending together
+	// with everything after it
+	publishErr := errors.New("sink unavailable")
+	var attempted []Outbox
+	published, err := store.RelayOutboxEvents(context.Background(), 100, func(_ context.Context, event Outbox) error {
+		attempted = append(attempted, event)
+		if len(attempted) == 2 {
+			return publishErr
+		}
+		return nil
+	})
+	require.ErrorIs(t, err, publishErr)
+	require.Equal(t, 1, published)
+	require.GreaterOrEqual(t, len(attempted), 2)
+
+	retried := relayAll(t, store)
+	require.NotEmpty(t, retried)
+	require.Equal(t, attempted[1].ID, retried[0].ID)
+	for _, event := range retried {
+		require.NotEqual(t, attempted[0].ID, event.ID)
+	}
+}
@@ -6,24 +6,116 @@ package db
 
 import (
 	"context"
+	"database/sql"
 )
 
 type Querier interface {
 	AddAccountBalance(ctx context.Context, arg AddAccountBalanceParams) (Account, error)
+	AddAccountHeldBalance(ctx context.Context, arg AddAccountHeldBalanceParams) (Account, error)
+	AssignInterestPlan(ctx context.Context, arg AssignInterestPlanParams) (AccountInterest, error)
+	ClaimDueScheduledTransfers(ctx context.Context, limit int32) ([]ScheduledTransfer, error)
+	ClaimDueWebhookDeliveries(ctx context.Context, arg ClaimDueWebhookDeliveriesParams) ([]WebhookDelivery, error)
+	ClaimExpiredAccountHolds(ctx context.Context, limit int32) ([]AccountHold, error)
+	ClaimUnpublishedOutboxEvents(ctx context.Context, limit int32) ([]Outbox, error)
+	CompleteAuditLog(ctx context.Context, arg CompleteAuditLogParams) (AuditLog, error)
 	CreateAccount(ctx context.Context, arg CreateAccountParams) (Account, error)
+	CreateAccountHold(ctx context.Context, arg CreateAccountHoldParams) (AccountHold, error)
+	CreateAuditLog(ctx context.Context, arg CreateAuditLogParams) (AuditLog, error)
 	CreateEntry(ctx context.Context, arg CreateEntryParams) (Entry, error)
+	CreateExchangeTransfer(ctx context.Context, arg CreateExchangeTransferParams) (Transfer, error)
+	CreateFeeSchedule(ctx context.Context, arg CreateFeeScheduleParams) (FeeSchedule, error)
+	CreateFxQuote(ctx context.Context, arg CreateFxQuoteParams) (FxQuote, error)
+	CreateIdempotencyKey(ctx context.Context, arg CreateIdempotencyKeyParams) (IdempotencyKey, error)
+	CreateInterestAccrual(ctx context.Context, arg CreateInterestAccrualParams) (InterestAccrual, error)
+	CreateInterestPlan(ctx context.Context, arg CreateInterestPlanParams) (InterestPlan, error)
+	CreateInterestPosting(ctx context.Context, arg CreateInterestPostingParams) (InterestPosting, error)
+	CreateOutboxEvent(ctx context.Context, arg CreateOutboxEventParams) (Outbox, error)
+	CreateReversalTransfer(ctx context.Context, arg CreateReversalTransferParams) (Transfer, error)
+	CreateRiskDecision(ctx context.Context, arg CreateRiskDecisionParams) (RiskDecision, error)
+	CreateScheduledTransfer(ctx context.Context, arg CreateScheduledTransferParams) (ScheduledTransfer, error)
+	CreateScheduledTransferRun(ctx context.Context, arg CreateScheduledTransferRunParams) (ScheduledTransferRun, error)
 	CreateTransfer(ctx context.Context, arg CreateTransferParams) (Transfer, error)
+	CreateTransferFee(ctx context.Context, arg CreateTransferFeeParams) (TransferFee, error)
 	CreateUser(ctx context.Context, arg CreateUserParams) (User, error)
-	DeleteAccount(ctx context.Context, id int64) error
+	CreateWebhookDeliveryAttempt(ctx context.Context, arg CreateWebhookDeliveryAttemptParams) (WebhookDeliveryAttempt, error)
+	CreateWebhookEndpoint(ctx context.Context, arg CreateWebhookEndpointParams) (WebhookEndpoint, error)
+	DeactivateFeeSchedule(ctx context.Context, id int64) (FeeSchedule, error)
+	DeactivateFeeSchedules(ctx context.Context, currency string) ([]FeeSchedule, error)
+	DeleteScheduledTransfer(ctx context.Context, id int64) error
+	DeleteWebhookEndpoint(ctx context.Context, id int64) error
+	EnqueueWebhookDeliveries(ctx context.Context, arg EnqueueWebhookDeliveriesParams) (int64, error)
 	GetAccount(ctx context.Context, id int64) (Account, error)
+	GetAccountBalanceAt(ctx context.Context, arg GetAccountBalanceAtParams) (int64, error)
+	GetAccountBalanceBefore(ctx context.Context, arg GetAccountBalanceBeforeParams) (int64, error)
 	GetAccountForUpdate(ctx context.Context, id int64) (Account, error)
+	GetAccountHold(ctx context.Context, id int64) (AccountHold, error)
+	GetAccountHoldForUpdate(ctx context.Context, id int64) (AccountHold, error)
+	GetAccountInterest(ctx context.Context, accountID int64) (AccountInterest, error)
+	GetAccountInterestForUpdate(ctx context.Context, accountID int64) (AccountInterest, error)
+	GetActiveFeeScheduleForAccount(ctx context.Context, id int64) (FeeSchedule, error)
+	GetCurrency(ctx context.Context, code string) (Currency, error)
 	GetEntry(ctx context.Context, id int64) (Entry, error)
+	GetFeeSchedule(ctx context.Context, id int64) (FeeSchedule, error)
+	GetFxQuote(ctx context.Context, id int64) (FxQuote, error)
+	GetFxQuoteForUpdate(ctx context.Context, id int64) (FxQuote, error)
+	GetIdempotencyKey(ctx context.Context, arg GetIdempotencyKeyParams) (IdempotencyKey, error)
+	GetInterestPlan(ctx context.Context, id int64) (InterestPlan, error)
+	GetReversedAmount(ctx context.Context, reversalOf sql.NullInt64) (int64, error)
+	GetRiskDecision(ctx context.Context, id int64) (RiskDecision, error)
+	GetRiskDecisionByIdempotencyKey(ctx context.Context, arg GetRiskDecisionByIdempotencyKeyParams) (RiskDecision, error)
+	GetRiskDecisionByTransfer(ctx context.Context, transferID sql.NullInt64) (RiskDecision, error)
+	GetRiskDecisionForUpdate(ctx context.Context, id int64) (RiskDecision, error)
+	GetScheduledTransfer(ctx context.Context, id int64) (ScheduledTransfer, error)
+	GetScheduledTransferForUpdate(ctx context.Context, id int64) (ScheduledTransfer, error)
 	GetTransfer(ctx context.Context, id int64) (Transfer, error)
+	GetTransferFee(ctx context.Context, transferID int64) (TransferFee, error)
+	GetTransferForUpdate(ctx context.Context, id int64) (Transfer, error)
+	GetTransferLimitForUpdate(ctx context.Context, arg GetTransferLimitForUpdateParams) (TransferLimit, error)
+	GetTransferRiskFacts(ctx context.Context, arg GetTransferRiskFactsParams) (GetTransferRiskFactsRow, error)
+	GetTransferredAmounts(ctx context.Context, arg GetTransferredAmountsParams) (GetTransferredAmountsRow, error)
 	GetUser(ctx context.Context, username string) (User, error)
+	GetWebhookEndpoint(ctx context.Context, id int64) (WebhookEndpoint, error)
+	HasScheduledTransferRuns(ctx context.Context, scheduledTransferID int64) (bool, error)
+	IsFeeTransfer(ctx context.Context, feeTransferID int64) (bool, error)
+	ListAccountBalanceDiscrepancies(ctx context.Context) ([]ListAccountBalanceDiscrepanciesRow, error)
+	ListAccountStatementEntries(ctx context.Context, arg ListAccountStatementEntriesParams) ([]ListAccountStatementEntriesRow, error)
+	ListAccountStatementEntriesAfter(ctx context.Context, arg ListAccountStatementEntriesAfterParams) ([]ListAccountStatementEntriesAfterRow, error)
 	ListAccounts(ctx context.Context, arg ListAccountsParams) ([]Account, error)
+	ListAccountsAfter(ctx context.Context, arg ListAccountsAfterParams) ([]Account, error)
+	ListAuditLogs(ctx context.Context, arg ListAuditLogsParams) ([]AuditLog, error)
+	ListCurrencies(ctx context.Context) ([]Currency, error)
+	ListDueAccountInterest(ctx context.Context, arg ListDueAccountInterestParams) ([]int64, error)
 	ListEntries(ctx context.Context, arg ListEntriesParams) ([]Entry, error)
+	ListEntriesAfter(ctx context.Context, arg ListEntriesAfterParams) ([]Entry, error)
+	ListFeeSchedules(ctx context.Context, arg ListFeeSchedulesParams) ([]FeeSchedule, error)
+	ListInterestAccruals(ctx context.Context, arg ListInterestAccrualsParams) ([]InterestAccrual, error)
+	ListInterestPlans(ctx context.Context, arg ListInterestPlansParams) ([]InterestPlan, error)
+	ListInterestPostings(ctx context.Context, arg ListInterestPostingsParams) ([]InterestPosting, error)
+	ListRiskDecisionsByStatus(ctx context.Context, arg ListRiskDecisionsByStatusParams) ([]RiskDecision, error)
+	ListScheduledTransferRuns(ctx context.Context, arg ListScheduledTransferRunsParams) ([]ScheduledTransferRun, error)
+	ListScheduledTransfers(ctx context.Context, arg ListScheduledTransfersParams) ([]ScheduledTransfer, error)
+	ListTransferLimits(ctx context.Context, username string) ([]TransferLimit, error)
+	ListTransferReversals(ctx context.Context, reversalOf sql.NullInt64) ([]Transfer, error)
 	ListTransfers(ctx context.Context, arg ListTransfersParams) ([]Transfer, error)
+	ListTransfersAfter(ctx context.Context, arg ListTransfersAfterParams) ([]ListTransfersAfterRow, error)
+	ListUnbalancedTransfers(ctx context.Context) ([]ListUnbalancedTransfersRow, error)
+	ListUserTransfers(ctx context.Context, arg ListUserTransfersParams) ([]ListUserTransfersRow, error)
+	ListWebhookDeliveries(ctx context.Context, arg ListWebhookDeliveriesParams) ([]WebhookDelivery, error)
+	ListWebhookDeliveryAttempts(ctx context.Context, arg ListWebhookDeliveryAttemptsParams) ([]WebhookDeliveryAttempt, error)
+	ListWebhookEndpoints(ctx context.Context, arg ListWebhookEndpointsParams) ([]WebhookEndpoint, error)
+	MarkFxQuoteUsed(ctx context.Context, id int64) (FxQuote, error)
+	MarkOutboxEventPublished(ctx context.Context, id int64) error
+	ResolveAccountHold(ctx context.Context, arg ResolveAccountHoldParams) (AccountHold, error)
 	UpdateAccount(ctx context.Context, arg UpdateAccountParams) (Account, error)
+	UpdateAccountInterestAccrued(ctx context.Context, arg UpdateAccountInterestAccruedParams) (AccountInterest, error)
+	UpdateAccountStatus(ctx context.Context, arg UpdateAccountStatusParams) (Account, error)
+	UpdateCurrencyEnabled(ctx context.Context, arg UpdateCurrencyEnabledParams) (Currency, error)
+	UpdateRiskDecisionReview(ctx context.Context, arg UpdateRiskDecisionReviewParams) (RiskDecision, error)
+	UpdateScheduledTransfer(ctx context.Context, arg UpdateScheduledTransferParams) (ScheduledTransfer, error)
+	UpdateScheduledTransferNextRun(ctx context.Context, arg UpdateScheduledTransferNextRunParams) (ScheduledTransfer, error)
+	UpdateWebhookDeliveryAttempt(ctx context.Context, arg UpdateWebhookDeliveryAttemptParams) (WebhookDelivery, error)
+	UpdateWebhookEndpoint(ctx context.Context, arg UpdateWebhookEndpointParams) (WebhookEndpoint, error)
+	UpsertTransferLimit(ctx context.Context, arg UpsertTransferLimitParams) (TransferLimit, error)
 }
 
 var _ Querier = (*Queries)(nil)
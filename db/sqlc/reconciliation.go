@@ -0,0 +1,44 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+type ReconciliationReport struct {
+	CheckedAt             time.Time                            `json:"checkedAt"`
+	AccountDiscrepancies  []ListAccountBalanceDiscrepanciesRow `json:"accountDiscrepancies"`
+	TransferDiscrepancies []ListUnbalancedTransfersRow         `json:"transferDiscrepancies"`
+}
+
+func (report ReconciliationReport) Balanced() bool {
+	return len(report.AccountDiscrepancies) == 0 && len(report.TransferDiscrepancies) == 0
+}
+
+// Reconcile compares every account's balance with the sum of its entries and
+// checks that every transfer has exactly one matching debit and credit entry.
+// Both checks read the same snapshot so concurrent transfers can't show up as
+// false discrepancies.
+func (store *SQLStore) Reconcile(ctx context.Context) (ReconciliationReport, error) {
+	var report ReconciliationReport
+
+	err := store.execTxWithOptions(ctx, snapshotTxOptions, func(q *Queries) error {
+		var err error
+		report.CheckedAt = time.Now()
+
+		report.AccountDiscrepancies, err = q.ListAccountBalanceDiscrepancies(ctx)
+		if err != nil {
+			return fmt.Errorf("couldn't reconcile account balances: %w", err)
+		}
+
+		report.TransferDiscrepancies, err = q.ListUnbalancedTransfers(ctx)
+		if err != nil {
+			return fmt.Errorf("couldn't reconcile transfers: %w", err)
+		}
+
+		return nil
+	})
+
+	return report, err
+}
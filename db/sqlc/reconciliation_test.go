@@ -0,0 +1,47 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReconcile(t *testing.T) {
+	store := NewStore(testDb)
+
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccountWithCurrency(t, account1.Currency)
+
+	result, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        10,
+	})
+	require.NoError(t, err)
+
+	// a transfer written without its entries must be reported
+	orphanTransfer := createRandomTransfer(t, &account1, &account2)
+
+	report, err := store.Reconcile(context.Background())
+	require.NoError(t, err)
+	require.False(t, report.Balanced())
+	require.NotZero(t, report.CheckedAt)
+
+	// random accounts are opened with a balance that has no backing entries
+	discrepancies := make(map[int64]ListAccountBalanceDiscrepanciesRow)
+	for _, d := range report.AccountDiscrepancies {
+		discrepancies[d.AccountID] = d
+	}
+	require.Contains(t, discrepancies, account1.ID)
+	require.Equal(t, account1.Balance-10, discrepancies[account1.ID].Balance)
+	require.Equal(t, int64(-10), discrepancies[account1.ID].EntriesTotal)
+
+	unbalancedTransfers := make(map[int64]ListUnbalancedTransfersRow)
+	for _, d := range report.TransferDiscrepancies {
+		unbalancedTransfers[d.TransferID] = d
+	}
+	require.NotContains(t, unbalancedTransfers, result.Transfer.ID)
+	require.Contains(t, unbalancedTransfers, orphanTransfer.ID)
+	require.Zero(t, unbalancedTransfers[orphanTransfer.ID].TotalEntries)
+}
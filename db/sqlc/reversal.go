@@ -0,0 +1,193 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"math/big"
+
+	"github.com/crackz/simple-bank/fx"
+)
+
+var (
+	ErrTransferNotFound        = errors.New("transfer not found")
+	ErrReversalExceedsTransfer = errors.New("reversal amount exceeds the amount left to reverse")
+	ErrCannotReverseReversal   = errors.New("a reversal can't be reversed")
+	ErrCannotReverseFee        = errors.New("a fee can only be refunded by reversing the transfer it was charged for")
+)
+
+type ReverseTransferTxParams struct {
+	TransferID int64 `json:"transferId"`
+	// Amount is in the currency of the original transfer's source account. Zero
+	// reverses whatever is left of the transfer.
+	Amount int64 `json:"amount"`
+
+	Idempotency *IdempotencyKeyParams `json:"-"`
+}
+
+// ReverseTransferTx sends money of a transfer back to its source account as a
+// new transfer linked to the original one. The original transfer is locked so
+// concurrent reversals of it are serialized, and the sum of all its reversals
+// can never exceed its amount. The matching part of the fee charged for the
+// original transfer, if any, is refunded to its source account in the same
+// transaction.
+func (store *SQLStore) ReverseTransferTx(ctx context.Context, arg ReverseTransferTxParams) (TransferTxResult, error) {
+	var result TransferTxResult
+
+	err := store.execIdempotentTx(ctx, arg.Idempotency, &result, func(q *Queries) error {
+		original, err := q.GetTransferForUpdate(ctx, arg.TransferID)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return ErrTransferNotFound
+			}
+			return err
+		}
+
+		if original.ReversalOf.Valid {
+			return ErrCannotReverseReversal
+		}
+
+		isFee, err := q.IsFeeTransfer(ctx, original.ID)
+		if err != nil {
+			return err
+		}
+		if isFee {
+			return ErrCannotReverseFee
+		}
+
+		reversalOf := sql.NullInt64{Int64: original.ID, Valid: true}
+		reversed, err := q.GetReversedAmount(ctx, reversalOf)
+		if err != nil {
+			return err
+		}
+
+		amount := arg.Amount
+		if amount == 0 {
+			amount = original.Amount - reversed
+		}
+		if amount <= 0 || reversed+amount > original.Amount {
+			return ErrReversalExceedsTransfer
+		}
+
+		// The debit is what the original credit was worth for the reversed part.
+		// It's computed on the running total so partial reversals of a converted
+		// transfer add up to exactly its credited amount once fully reversed.
+		debit := proportion(reversed+amount, original.ToAmount, original.Amount) -
+			proportion(reversed, original.ToAmount, original.Amount)
+		if debit <= 0 {
+			return ErrAmountTooLow
+		}
+
+		accountIDs := []int64{original.FromAccountID, original.ToAccountID}
+		var feeTransfer *Transfer
+		fee, err := q.GetTransferFee(ctx, original.ID)
+		switch {
+		case err == nil:
+			transfer, err := q.GetTransfer(ctx, fee.FeeTransferID)
+			if err != nil {
+				return err
+			}
+			feeTransfer = &transfer
+			accountIDs = append(accountIDs, transfer.ToAccountID)
+		case err != sql.ErrNoRows:
+			return err
+		}
+
+		accounts, err := lockAccounts(ctx, q, accountIDs...)
+		if err != nil {
+			return err
+		}
+
+		fromAccount := accounts[original.FromAccountID]
+		toAccount := accounts[original.ToAccountID]
+		if toAccount.AvailableBalance < debit {
+			return ErrInsufficientFunds
+		}
+
+		if err := checkAccountsStatus(toAccount, fromAccount); err != nil {
+			return err
+		}
+
+		rate, err := fx.ParseRate(fromAccount.Currency, toAccount.Currency, original.ExchangeRate)
+		if err != nil {
+			return err
+		}
+
+		reversal, err := q.CreateReversalTransfer(ctx, CreateReversalTransferParams{
+			FromAccountID: original.ToAccountID,
+			ToAccountID:   original.FromAccountID,
+			Amount:        debit,
+			ToAmount:      amount,
+			ExchangeRate:  rate.Inverse().String(),
+			ReversalOf:    reversalOf,
+		})
+		if err != nil {
+			return err
+		}
+
+		result, err = postTransfer(ctx, q, reversal)
+		if err != nil {
+			return err
+		}
+		if feeTransfer == nil {
+			return nil
+		}
+
+		accounts[result.FromAccount.ID] = result.FromAccount
+		accounts[result.ToAccount.ID] = result.ToAccount
+		refund := proportion(reversed+amount, fee.Amount, original.Amount) -
+			proportion(reversed, fee.Amount, original.Amount)
+		return refundFee(ctx, q, *feeTransfer, refund, accounts, &result)
+	})
+
+	return result, err
+}
+
+// refundFee sends refund of a fee back from the revenue account as a reversal
+// of the fee transfer. The refund is computed like the debit of the reversal,
+// so a transfer reversed in full gets exactly its fee back.
+func refundFee(ctx context.Context, q *Queries, feeTransfer Transfer, refund int64, accounts map[int64]Account, result *TransferTxResult) error {
+	if refund <= 0 {
+		return nil
+	}
+
+	revenueAccount := accounts[feeTransfer.ToAccountID]
+	if revenueAccount.AvailableBalance < refund {
+		return ErrInsufficientFunds
+	}
+
+	if err := checkAccountsStatus(revenueAccount, accounts[feeTransfer.FromAccountID]); err != nil {
+		return err
+	}
+
+	reversal, err := q.CreateReversalTransfer(ctx, CreateReversalTransferParams{
+		FromAccountID: feeTransfer.ToAccountID,
+		ToAccountID:   feeTransfer.FromAccountID,
+		Amount:        refund,
+		ToAmount:      refund,
+		ExchangeRate:  feeTransfer.ExchangeRate,
+		ReversalOf:    sql.NullInt64{Int64: feeTransfer.ID, Valid: true},
+	})
+	if err != nil {
+		return err
+	}
+
+	refundResult, err := postTransfer(ctx, q, reversal)
+	if err != nil {
+		return err
+	}
+
+	// the refund goes to the account the reversal credits
+	result.ToAccount = refundResult.ToAccount
+	if result.FromAccount.ID == revenueAccount.ID {
+		result.FromAccount = refundResult.FromAccount
+	}
+	result.FeeRefund = &refundResult.Transfer
+	return nil
+}
+
+// proportion returns amount * numerator / denominator rounded down.
+func proportion(amount int64, numerator int64, denominator int64) int64 {
+	value := new(big.Int).Mul(big.NewInt(amount), big.NewInt(numerator))
+	return value.Quo(value, big.NewInt(denominator)).Int64()
+}
@@ -0,0 +1,160 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/crackz/simple-bank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReverseTransferTx(t *testing.T) {
+	store := NewStore(testDb)
+
+	fromAccount := createRandomAccount(t)
+	toAccount := createRandomAccountWithCurrency(t, fromAccount.Currency)
+
+	original, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: fromAccount.ID,
+		ToAccountID:   toAccount.ID,
+		Amount:        100,
+	})
+	require.NoError(t, err)
+
+	partial, err := store.ReverseTransferTx(context.Background(), ReverseTransferTxParams{
+		TransferID: original.Transfer.ID,
+		Amount:     40,
+	})
+	require.NoError(t, err)
+	require.Equal(t, sql.NullInt64{Int64: original.Transfer.ID, Valid: true}, partial.Transfer.ReversalOf)
+	require.Equal(t, toAccount.ID, partial.Transfer.FromAccountID)
+	require.Equal(t, fromAccount.ID, partial.Transfer.ToAccountID)
+	require.Equal(t, int64(40), partial.Transfer.Amount)
+	require.Equal(t, int64(-40), partial.FromEntry.Amount)
+	require.Equal(t, int64(40), partial.ToEntry.Amount)
+	require.Equal(t, fromAccount.Balance-60, partial.ToAccount.Balance)
+	require.Equal(t, toAccount.Balance+60, partial.FromAccount.Balance)
+
+	_, err = store.ReverseTransferTx(context.Background(), ReverseTransferTxParams{
+		TransferID: original.Transfer.ID,
+		Amount:     61,
+	})
+	require.ErrorIs(t, err, ErrReversalExceedsTransfer)
+
+	// a zero amount reverses the rest
+	rest, err := store.ReverseTransferTx(context.Background(), ReverseTransferTxParams{
+		TransferID: original.Transfer.ID,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(60), rest.Transfer.Amount)
+	require.Equal(t, fromAccount.Balance, rest.ToAccount.Balance)
+	require.Equal(t, toAccount.Balance, rest.FromAccount.Balance)
+
+	_, err = store.ReverseTransferTx(context.Background(), ReverseTransferTxParams{
+		TransferID: original.Transfer.ID,
+	})
+	require.ErrorIs(t, err, ErrReversalExceedsTransfer)
+
+	_, err = store.ReverseTransferTx(context.Background(), ReverseTransferTxParams{
+		TransferID: rest.Transfer.ID,
+	})
+	require.ErrorIs(t, err, ErrCannotReverseReversal)
+
+	reversals, err := testQueries.ListTransferReversals(context.Background(), sql.NullInt64{Int64: original.Transfer.ID, Valid: true})
+	require.NoError(t, err)
+	require.Len(t, reversals, 2)
+	require.Equal(t, partial.Transfer.ID, reversals[0].ID)
+	require.Equal(t, rest.Transfer.ID, reversals[1].ID)
+}
+
+func TestReverseTransferTxRefundsFee(t *testing.T) {
+	store := NewStore(testDb)
+
+	fromAccount := createRandomAccount(t)
+	toAccount := createRandomAccountWithCurrency(t, fromAccount.Currency)
+	schedule := createRandomFeeSchedule(t, fromAccount.Currency, CreateFeeScheduleParams{
+		FlatAmount: 5,
+		Percentage: "0.01",
+	})
+
+	original, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: fromAccount.ID,
+		ToAccountID:   toAccount.ID,
+		Amount:        100,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(6), original.Fee.Amount)
+
+	revenueAccount, err := store.GetAccount(context.Background(), schedule.RevenueAccountID)
+	require.NoError(t, err)
+
+	_, err = store.ReverseTransferTx(context.Background(), ReverseTransferTxParams{
+		TransferID: original.Fee.FeeTransferID,
+	})
+	require.ErrorIs(t, err, ErrCannotReverseFee)
+
+	partial, err := store.ReverseTransferTx(context.Background(), ReverseTransferTxParams{
+		TransferID: original.Transfer.ID,
+		Amount:     50,
+	})
+	require.NoError(t, err)
+	require.NotNil(t, partial.FeeRefund)
+	require.Equal(t, schedule.RevenueAccountID, partial.FeeRefund.FromAccountID)
+	require.Equal(t, fromAccount.ID, partial.FeeRefund.ToAccountID)
+	require.Equal(t, int64(3), partial.FeeRefund.Amount)
+	require.Equal(t, sql.NullInt64{Int64: original.Fee.FeeTransferID, Valid: true}, partial.FeeRefund.ReversalOf)
+	require.Equal(t, fromAccount.Balance-106+50+3, partial.ToAccount.Balance)
+
+	rest, err := store.ReverseTransferTx(context.Background(), ReverseTransferTxParams{
+		TransferID: original.Transfer.ID,
+	})
+	require.NoError(t, err)
+	require.NotNil(t, rest.FeeRefund)
+	require.Equal(t, int64(3), rest.FeeRefund.Amount)
+	require.Equal(t, fromAccount.Balance, rest.ToAccount.Balance)
+
+	updatedRevenueAccount, err := store.GetAccount(context.Background(), revenueAccount.ID)
+	require.NoError(t, err)
+	require.Equal(t, revenueAccount.Balance-6, updatedRevenueAccount.Balance)
+}
+
+func TestReverseExchangeTransferTx(t *testing.T) {
+	store := NewStore(testDb)
+
+	fromAccount := createRandomAccountWithCurrency(t, util.USD)
+	toAccount := createRandomAccountWithCurrency(t, util.CAD)
+	quote := createRandomFxQuote(t, fromAccount.Owner, util.USD, util.CAD, time.Now().Add(time.Minute))
+
+	original, err := store.ExchangeTransferTx(context.Background(), ExchangeTransferTxParams{
+		FromAccountID: fromAccount.ID,
+		ToAccountID:   toAccount.ID,
+		Amount:        3,
+		QuoteID:       quote.ID,
+		Owner:         fromAccount.Owner,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(4), original.Transfer.ToAmount)
+
+	var debited int64
+	for i := 0; i < 3; i++ {
+		reversal, err := store.ReverseTransferTx(context.Background(), ReverseTransferTxParams{
+			TransferID: original.Transfer.ID,
+			Amount:     1,
+		})
+		require.NoError(t, err)
+		require.Equal(t, int64(1), reversal.Transfer.ToAmount)
+		debited += reversal.Transfer.Amount
+	}
+
+	// partial reversals add up to exactly what was credited
+	require.Equal(t, original.Transfer.ToAmount, debited)
+}
+
+func TestReverseTransferTxNotFound(t *testing.T) {
+	store := NewStore(testDb)
+
+	_, err := store.ReverseTransferTx(context.Background(), ReverseTransferTxParams{TransferID: -1})
+	require.ErrorIs(t, err, ErrTransferNotFound)
+}
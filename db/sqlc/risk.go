@@ -0,0 +1,279 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/crackz/simple-bank/risk"
+	"github.com/lib/pq"
+)
+
+var (
+	ErrRiskDecisionNotFound   = errors.New("risk decision not found")
+	ErrRiskDecisionNotPending = errors.New("risk decision was already reviewed")
+	ErrTransferBlocked        = errors.New("transfer was blocked by risk screening")
+)
+
+// Statuses of a risk decision. Transfers allowed by screening are recorded as
+// allowed, transfers held for review are pending until approved or rejected,
+// and blocked transfers stay blocked.
+const (
+	RiskAllowed  = "allowed"
+	RiskPending  = "pending"
+	RiskApproved = "approved"
+	RiskRejected = "rejected"
+	RiskBlocked  = "blocked"
+)
+
+type screenTransferParams struct {
+	Rules       risk.Rules `json:"-"`
+	Owner       string     `json:"owner"`
+	ToAccountID int64      `json:"toAccountId"`
+	Amount      int64      `json:"amount"`
+}
+
+// screenTransfer evaluates the rules on a transfer the owner is about to make,
+// using the owner's transfers so far.
+func screenTransfer(ctx context.Context, q *Queries, arg screenTransferParams) (risk.Decision, error) {
+	now := time.Now()
+
+	facts, err := q.GetTransferRiskFacts(ctx, GetTransferRiskFactsParams{
+		Username:      arg.Owner,
+		VelocitySince: now.Add(-arg.Rules.VelocityWindow),
+		ToAccountID:   arg.ToAccountID,
+		RoundSince:    now.Add(-arg.Rules.RoundAmountWindow),
+		RoundUnit:     arg.Rules.RoundAmountUnit,
+	})
+	if err != nil {
+		return risk.Decision{}, err
+	}
+
+	return arg.Rules.Evaluate(arg.Amount, risk.Facts{
+		RecentTransfers:              facts.RecentTransfers,
+		PayeeTransfers:               facts.PayeeTransfers,
+		RoundTransfers:               facts.RoundTransfers,
+		PasswordChangedAt:            facts.PasswordChangedAt,
+		TransfersSincePasswordChange: facts.TransfersSincePasswordChange,
+	}, now), nil
+}
+
+// RiskDecisionStatus returns the status a decision is recorded with.
+func RiskDecisionStatus(outcome risk.Outcome) string {
+	switch outcome {
+	case risk.Review:
+		return RiskPending
+	case risk.Block:
+		return RiskBlocked
+	}
+
+	return RiskAllowed
+}
+
+// recordRiskDecision records the decision screening took on a transfer.
+// transferID is zero when the transfer wasn't made. Such a transfer keeps the
+// idempotency key it was sent with, if any, so retrying it finds the decision
+// and approving it stores the result under the key.
+func recordRiskDecision(ctx context.Context, q *Queries, owner string, arg TransferTxParams, decision risk.Decision, transferID int64) (RiskDecision, error) {
+	params := CreateRiskDecisionParams{
+		Owner:         owner,
+		FromAccountID: arg.FromAccountID,
+		ToAccountID:   arg.ToAccountID,
+		Amount:        arg.Amount,
+		Outcome:       string(decision.Outcome),
+		Rules:         decision.Rules,
+		Status:        RiskDecisionStatus(decision.Outcome),
+		TransferID:    sql.NullInt64{Int64: transferID, Valid: transferID != 0},
+	}
+	if transferID == 0 && arg.Idempotency != nil {
+		params.IdempotencyKey = sql.NullString{String: arg.Idempotency.Key, Valid: true}
+		params.RequestHash = sql.NullString{String: arg.Idempotency.RequestHash, Valid: true}
+	}
+
+	return q.CreateRiskDecision(ctx, params)
+}
+
+// heldRiskDecision returns the decision recorded for a transfer that was sent
+// with the idempotency key but not made, with the outcome it was answered
+// with.
+func heldRiskDecision(ctx context.Context, q *Queries, idempotency *IdempotencyKeyParams) (risk.Outcome, RiskDecision, error) {
+	decision, err := q.GetRiskDecisionByIdempotencyKey(ctx, GetRiskDecisionByIdempotencyKeyParams{
+		Owner:          idempotency.Owner,
+		IdempotencyKey: sql.NullString{String: idempotency.Key, Valid: true},
+	})
+	if err != nil {
+		return "", RiskDecision{}, err
+	}
+	if decision.RequestHash.String != idempotency.RequestHash {
+		return "", RiskDecision{}, ErrIdempotencyKeyConflict
+	}
+
+	if decision.Status == RiskPending {
+		return risk.Review, decision, nil
+	}
+	// an approved transfer was made, so it's replayed before getting here
+	return risk.Block, decision, nil
+}
+
+type CheckTransferRiskParams struct {
+	Rules risk.Rules
+	Owner string
+	// Transfer is the transfer about to be made. When it's allowed, the
+	// decision is set as its Risk, to be recorded with it.
+	Transfer *TransferTxParams
+	// Reviewable is false for transfers that can't wait for a review because
+	// they're part of a larger request, like batch legs, exchanges and holds.
+	// They're blocked instead of held for review.
+	Reviewable bool
+}
+
+// CheckTransferRisk screens a transfer the owner is about to make with the
+// rules, if any are enabled. The risk decision is only recorded here, and
+// returned, when the transfer isn't allowed; an allowed transfer records it
+// when it's made. A transfer whose idempotency key was already used isn't
+// screened again: making it returns the stored result of the first request,
+// and a transfer that wasn't made returns the decision it was held or blocked
+// by.
+func (store *SQLStore) CheckTransferRisk(ctx context.Context, arg CheckTransferRiskParams) (risk.Outcome, RiskDecision, error) {
+	var outcome risk.Outcome
+	var riskDecision RiskDecision
+	err := store.execAuditedQuery(ctx, func(q *Queries) error {
+		var err error
+		outcome, riskDecision, err = checkTransferRisk(ctx, q, arg)
+		return err
+	})
+
+	return outcome, riskDecision, err
+}
+
+// checkTransferRisk screens a transfer as described by CheckTransferRisk with
+// q, which may be the caller's transaction.
+func checkTransferRisk(ctx context.Context, q *Queries, arg CheckTransferRiskParams) (risk.Outcome, RiskDecision, error) {
+	if !arg.Rules.Enabled() {
+		return risk.Allow, RiskDecision{}, nil
+	}
+
+	idempotency := arg.Transfer.Idempotency
+	if idempotency != nil {
+		_, err := q.GetIdempotencyKey(ctx, GetIdempotencyKeyParams{
+			Owner: idempotency.Owner,
+			Key:   idempotency.Key,
+		})
+		if err != sql.ErrNoRows {
+			return risk.Allow, RiskDecision{}, err
+		}
+
+		outcome, riskDecision, err := heldRiskDecision(ctx, q, idempotency)
+		if err != sql.ErrNoRows {
+			return outcome, riskDecision, err
+		}
+	}
+
+	decision, err := screenTransfer(ctx, q, screenTransferParams{
+		Rules:       arg.Rules,
+		Owner:       arg.Owner,
+		ToAccountID: arg.Transfer.ToAccountID,
+		Amount:      arg.Transfer.Amount,
+	})
+	if err != nil {
+		return "", RiskDecision{}, err
+	}
+
+	if decision.Outcome == risk.Allow {
+		arg.Transfer.Risk = &decision
+		return risk.Allow, RiskDecision{}, nil
+	}
+	if decision.Outcome == risk.Review && !arg.Reviewable {
+		decision.Outcome = risk.Block
+	}
+
+	riskDecision, err := recordRiskDecision(ctx, q, arg.Owner, *arg.Transfer, decision, 0)
+	if err == sql.ErrNoRows {
+		// a concurrent retry recorded its decision first
+		return heldRiskDecision(ctx, q, idempotency)
+	}
+	if err != nil {
+		return "", RiskDecision{}, err
+	}
+
+	return decision.Outcome, riskDecision, nil
+}
+
+type ReviewRiskDecisionTxParams struct {
+	ID       int64  `json:"id"`
+	Approve  bool   `json:"approve"`
+	Reviewer string `json:"reviewer"`
+}
+
+type ReviewRiskDecisionTxResult struct {
+	RiskDecision RiskDecision `json:"riskDecision"`
+	// Transfer is only set when the transfer was approved.
+	Transfer *TransferTxResult `json:"transfer,omitempty"`
+}
+
+// ReviewRiskDecisionTx approves or rejects a transfer held for review. An
+// approved transfer is made in the same transaction, with the checks, fee and
+// limits of any other transfer; if it fails the decision stays pending. The
+// transfer is stored under the idempotency key it was sent with, if any, so
+// retrying the held request returns it instead of paying again.
+func (store *SQLStore) ReviewRiskDecisionTx(ctx context.Context, arg ReviewRiskDecisionTxParams) (ReviewRiskDecisionTxResult, error) {
+	var result ReviewRiskDecisionTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		result = ReviewRiskDecisionTxResult{}
+
+		decision, err := q.GetRiskDecisionForUpdate(ctx, arg.ID)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return ErrRiskDecisionNotFound
+			}
+			return err
+		}
+		if decision.Status != RiskPending {
+			return ErrRiskDecisionNotPending
+		}
+
+		update := UpdateRiskDecisionReviewParams{
+			ID:         decision.ID,
+			Status:     RiskRejected,
+			ReviewedBy: sql.NullString{String: arg.Reviewer, Valid: true},
+		}
+		if arg.Approve {
+			transfer, err := transferTx(ctx, q, TransferTxParams{
+				FromAccountID: decision.FromAccountID,
+				ToAccountID:   decision.ToAccountID,
+				Amount:        decision.Amount,
+			})
+			if err != nil {
+				return err
+			}
+			result.Transfer = &transfer
+
+			// retries of the held request replay the transfer instead of
+			// making it again
+			if decision.IdempotencyKey.Valid {
+				err = storeIdempotentResult(ctx, q, &IdempotencyKeyParams{
+					Owner:       decision.Owner,
+					Key:         decision.IdempotencyKey.String,
+					RequestHash: decision.RequestHash.String,
+				}, transfer)
+				// a retry was made once the transfer wasn't screened anymore
+				if pqErr, ok := err.(*pq.Error); ok && pqErr.Constraint == idempotencyKeysPkey {
+					return ErrIdempotencyKeyConflict
+				}
+				if err != nil {
+					return err
+				}
+			}
+
+			update.Status = RiskApproved
+			update.TransferID = sql.NullInt64{Int64: transfer.Transfer.ID, Valid: true}
+		}
+
+		result.RiskDecision, err = q.UpdateRiskDecisionReview(ctx, update)
+		return err
+	})
+
+	return result, err
+}
@@ -0,0 +1,309 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/crackz/simple-bank/risk"
+	"github.com/crackz/simple-bank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScreenTransfer(t *testing.T) {
+	store := NewStore(testDb)
+
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccountWithCurrency(t, account1.Currency)
+	account3 := createRandomAccountWithCurrency(t, account1.Currency)
+
+	for i := 0; i < 2; i++ {
+		_, err := store.TransferTx(context.Background(), TransferTxParams{
+			FromAccountID: account1.ID,
+			ToAccountID:   account2.ID,
+			Amount:        10,
+		})
+		require.NoError(t, err)
+	}
+
+	rules := risk.Rules{
+		VelocityCount:      2,
+		VelocityWindow:     time.Minute,
+		VelocityOutcome:    risk.Review,
+		NewPayeeAmount:     50,
+		NewPayeeOutcome:    risk.Block,
+		RoundAmountUnit:    10,
+		RoundAmountCount:   3,
+		RoundAmountWindow:  time.Hour,
+		RoundAmountOutcome: risk.Allow,
+	}
+
+	decision, err := screenTransfer(context.Background(), testQueries, screenTransferParams{
+		Rules:       rules,
+		Owner:       account1.Owner,
+		ToAccountID: account2.ID,
+		Amount:      50,
+	})
+	require.NoError(t, err)
+	require.Equal(t, risk.Review, decision.Outcome)
+	require.Equal(t, []string{risk.RuleVelocity, risk.RuleRoundAmount}, decision.Rules)
+
+	decision, err = screenTransfer(context.Background(), testQueries, screenTransferParams{
+		Rules:       rules,
+		Owner:       account1.Owner,
+		ToAccountID: account3.ID,
+		Amount:      51,
+	})
+	require.NoError(t, err)
+	require.Equal(t, risk.Block, decision.Outcome)
+	require.Equal(t, []string{risk.RuleVelocity, risk.RuleNewPayeeLargeAmount}, decision.Rules)
+}
+
+func TestCheckTransferRisk(t *testing.T) {
+	store := NewStore(testDb)
+
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccountWithCurrency(t, account1.Currency)
+	account3 := createRandomAccountWithCurrency(t, account1.Currency)
+
+	rules := risk.Rules{
+		NewPayeeAmount:  10,
+		NewPayeeOutcome: risk.Review,
+	}
+	arg := TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        5,
+		Idempotency: &IdempotencyKeyParams{
+			Owner:       account1.Owner,
+			Key:         util.RandString(12),
+			RequestHash: util.RandString(32),
+		},
+	}
+
+	outcome, _, err := store.CheckTransferRisk(context.Background(), CheckTransferRiskParams{
+		Rules:      rules,
+		Owner:      account1.Owner,
+		Transfer:   &arg,
+		Reviewable: true,
+	})
+	require.NoError(t, err)
+	require.Equal(t, risk.Allow, outcome)
+	require.NotNil(t, arg.Risk)
+
+	_, err = store.TransferTx(context.Background(), arg)
+	require.NoError(t, err)
+
+	// a replay isn't screened again, whatever the rules say now
+	replay := arg
+	replay.ToAccountID = account3.ID
+	replay.Amount = 50
+	replay.Risk = nil
+	outcome, _, err = store.CheckTransferRisk(context.Background(), CheckTransferRiskParams{
+		Rules:    rules,
+		Owner:    account1.Owner,
+		Transfer: &replay,
+	})
+	require.NoError(t, err)
+	require.Equal(t, risk.Allow, outcome)
+	require.Nil(t, replay.Risk)
+
+	arg.ToAccountID = account3.ID
+	arg.Amount = 50
+	arg.Idempotency = nil
+	outcome, decision, err := store.CheckTransferRisk(context.Background(), CheckTransferRiskParams{
+		Rules:      rules,
+		Owner:      account1.Owner,
+		Transfer:   &arg,
+		Reviewable: true,
+	})
+	require.NoError(t, err)
+	require.Equal(t, risk.Review, outcome)
+	require.Equal(t, RiskPending, decision.Status)
+
+	// a transfer that can't wait for a review is blocked instead
+	outcome, decision, err = store.CheckTransferRisk(context.Background(), CheckTransferRiskParams{
+		Rules:    rules,
+		Owner:    account1.Owner,
+		Transfer: &arg,
+	})
+	require.NoError(t, err)
+	require.Equal(t, risk.Block, outcome)
+	require.Equal(t, RiskBlocked, decision.Status)
+}
+
+func TestCheckTransferRiskHeldRetry(t *testing.T) {
+	store := NewStore(testDb)
+
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccountWithCurrency(t, account1.Currency)
+	reviewer := createRandomUser(t)
+
+	rules := risk.Rules{
+		NewPayeeAmount:  10,
+		NewPayeeOutcome: risk.Review,
+	}
+	arg := TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        10,
+		Idempotency: &IdempotencyKeyParams{
+			Owner:       account1.Owner,
+			Key:         util.RandString(12),
+			RequestHash: util.RandString(32),
+		},
+	}
+	check := func(arg TransferTxParams) (risk.Outcome, RiskDecision, error) {
+		return store.CheckTransferRisk(context.Background(), CheckTransferRiskParams{
+			Rules:      rules,
+			Owner:      account1.Owner,
+			Transfer:   &arg,
+			Reviewable: true,
+		})
+	}
+
+	outcome, held, err := check(arg)
+	require.NoError(t, err)
+	require.Equal(t, risk.Review, outcome)
+	require.Equal(t, arg.Idempotency.Key, held.IdempotencyKey.String)
+
+	// retrying returns the decision instead of holding the transfer again
+	outcome, retried, err := check(arg)
+	require.NoError(t, err)
+	require.Equal(t, risk.Review, outcome)
+	require.Equal(t, held.ID, retried.ID)
+
+	conflicting := arg
+	conflicting.Idempotency = &IdempotencyKeyParams{
+		Owner:       arg.Idempotency.Owner,
+		Key:         arg.Idempotency.Key,
+		RequestHash: util.RandString(32),
+	}
+	_, _, err = check(conflicting)
+	require.ErrorIs(t, err, ErrIdempotencyKeyConflict)
+
+	approved, err := store.ReviewRiskDecisionTx(context.Background(), ReviewRiskDecisionTxParams{
+		ID:       held.ID,
+		Approve:  true,
+		Reviewer: reviewer.Username,
+	})
+	require.NoError(t, err)
+	require.NotNil(t, approved.Transfer)
+
+	// once approved, retrying replays the transfer instead of paying again
+	outcome, _, err = check(arg)
+	require.NoError(t, err)
+	require.Equal(t, risk.Allow, outcome)
+
+	result, err := store.TransferTx(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, approved.Transfer.Transfer.ID, result.Transfer.ID)
+
+	updatedAccount1, err := store.GetAccount(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Equal(t, account1.Balance-arg.Amount, updatedAccount1.Balance)
+}
+
+func TestTransferTxRecordsRiskDecision(t *testing.T) {
+	store := NewStore(testDb)
+
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccountWithCurrency(t, account1.Currency)
+
+	result, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        10,
+		Risk:          &risk.Decision{Outcome: risk.Allow, Rules: []string{risk.RuleRoundAmount}},
+	})
+	require.NoError(t, err)
+
+	decision, err := store.GetRiskDecisionByTransfer(context.Background(), sql.NullInt64{Int64: result.Transfer.ID, Valid: true})
+	require.NoError(t, err)
+	require.Equal(t, account1.Owner, decision.Owner)
+	require.Equal(t, RiskAllowed, decision.Status)
+	require.Equal(t, string(risk.Allow), decision.Outcome)
+	require.Equal(t, []string{risk.RuleRoundAmount}, decision.Rules)
+}
+
+func TestReviewRiskDecisionTx(t *testing.T) {
+	store := NewStore(testDb)
+
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccountWithCurrency(t, account1.Currency)
+	reviewer := createRandomUser(t)
+
+	arg := TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        10,
+	}
+	held := risk.Decision{Outcome: risk.Review, Rules: []string{risk.RuleVelocity}}
+
+	decision, err := recordRiskDecision(context.Background(), testQueries, account1.Owner, arg, held, 0)
+	require.NoError(t, err)
+	require.Equal(t, RiskPending, decision.Status)
+	require.False(t, decision.TransferID.Valid)
+
+	result, err := store.ReviewRiskDecisionTx(context.Background(), ReviewRiskDecisionTxParams{
+		ID:       decision.ID,
+		Approve:  true,
+		Reviewer: reviewer.Username,
+	})
+	require.NoError(t, err)
+	require.Equal(t, RiskApproved, result.RiskDecision.Status)
+	require.Equal(t, reviewer.Username, result.RiskDecision.ReviewedBy.String)
+	require.True(t, result.RiskDecision.ReviewedAt.Valid)
+	require.NotNil(t, result.Transfer)
+	require.Equal(t, result.Transfer.Transfer.ID, result.RiskDecision.TransferID.Int64)
+	require.Equal(t, account1.Balance-arg.Amount, result.Transfer.FromAccount.Balance)
+
+	_, err = store.ReviewRiskDecisionTx(context.Background(), ReviewRiskDecisionTxParams{
+		ID:       decision.ID,
+		Reviewer: reviewer.Username,
+	})
+	require.ErrorIs(t, err, ErrRiskDecisionNotPending)
+
+	rejected, err := recordRiskDecision(context.Background(), testQueries, account1.Owner, arg, held, 0)
+	require.NoError(t, err)
+
+	result, err = store.ReviewRiskDecisionTx(context.Background(), ReviewRiskDecisionTxParams{
+		ID:       rejected.ID,
+		Reviewer: reviewer.Username,
+	})
+	require.NoError(t, err)
+	require.Equal(t, RiskRejected, result.RiskDecision.Status)
+	require.Nil(t, result.Transfer)
+
+	updatedAccount1, err := store.GetAccount(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Equal(t, account1.Balance-arg.Amount, updatedAccount1.Balance)
+}
+
+func TestReviewRiskDecisionTxFailedTransfer(t *testing.T) {
+	store := NewStore(testDb)
+
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccountWithCurrency(t, account1.Currency)
+	reviewer := createRandomUser(t)
+
+	decision, err := recordRiskDecision(context.Background(), testQueries, account1.Owner, TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        account1.Balance + 1,
+	}, risk.Decision{Outcome: risk.Review, Rules: []string{risk.RuleNewPayeeLargeAmount}}, 0)
+	require.NoError(t, err)
+
+	_, err = store.ReviewRiskDecisionTx(context.Background(), ReviewRiskDecisionTxParams{
+		ID:       decision.ID,
+		Approve:  true,
+		Reviewer: reviewer.Username,
+	})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+
+	// a transfer that couldn't be made can still be rejected
+	decision, err = store.GetRiskDecision(context.Background(), decision.ID)
+	require.NoError(t, err)
+	require.Equal(t, RiskPending, decision.Status)
+}
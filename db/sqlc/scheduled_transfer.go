@@ -0,0 +1,272 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/crackz/simple-bank/risk"
+)
+
+const (
+	FrequencyOnce    = "once"
+	FrequencyDaily   = "daily"
+	FrequencyWeekly  = "weekly"
+	FrequencyMonthly = "monthly"
+)
+
+const (
+	ScheduledRunSucceeded = "succeeded"
+	ScheduledRunFailed    = "failed"
+)
+
+var ErrScheduledTransferCompleted = errors.New("scheduled transfer already ran and doesn't repeat")
+
+// UpdateScheduledTransferTx changes a scheduled transfer. A paused schedule
+// that's reactivated is due next at its first occurrence from now, so the runs
+// missed while it was paused are skipped like the ones missed while no worker
+// was running. A schedule that doesn't repeat can't be reactivated once it ran.
+func (store *SQLStore) UpdateScheduledTransferTx(ctx context.Context, arg UpdateScheduledTransferParams) (ScheduledTransfer, error) {
+	var scheduled ScheduledTransfer
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+
+		scheduled, err = q.GetScheduledTransferForUpdate(ctx, arg.ID)
+		if err != nil {
+			return err
+		}
+
+		if arg.Active.Valid && arg.Active.Bool && !scheduled.Active {
+			arg.NextRunAt, err = reactivatedNextRun(ctx, q, scheduled, arg)
+			if err != nil {
+				return err
+			}
+		}
+
+		scheduled, err = q.UpdateScheduledTransfer(ctx, arg)
+		return err
+	})
+
+	return scheduled, err
+}
+
+func reactivatedNextRun(ctx context.Context, q *Queries, scheduled ScheduledTransfer, arg UpdateScheduledTransferParams) (sql.NullTime, error) {
+	frequency := scheduled.Frequency
+	if arg.Frequency.Valid {
+		frequency = arg.Frequency.String
+	}
+	start := scheduled.StartAt
+	if arg.StartAt.Valid {
+		start = arg.StartAt.Time
+	}
+
+	if frequency == FrequencyOnce {
+		ran, err := q.HasScheduledTransferRuns(ctx, scheduled.ID)
+		if err != nil {
+			return sql.NullTime{}, err
+		}
+		if ran {
+			return sql.NullTime{}, ErrScheduledTransferCompleted
+		}
+	}
+
+	now := time.Now()
+	if start.After(now) {
+		return sql.NullTime{Time: start, Valid: true}, nil
+	}
+
+	// a transfer that doesn't repeat and never ran is due right away
+	nextRunAt, active := nextScheduledRun(start, frequency, now)
+	if !active {
+		nextRunAt = now
+	}
+
+	return sql.NullTime{Time: nextRunAt, Valid: true}, nil
+}
+
+type RunDueScheduledTransfersParams struct {
+	Limit int32
+	// Rules screen every run like a transfer made by the schedule's owner. A
+	// run can't wait for a review, so one that would be held is blocked.
+	Rules risk.Rules
+}
+
+// RunDueScheduledTransfers claims up to Limit due scheduled transfers and makes
+// the transfer of each of them once it passed risk screening. The transfers are
+// made in the claiming transaction, so a run is committed together with its
+// transfer or not at all. The claimed rows stay locked until then and SKIP
+// LOCKED lets other replicas claim different rows at the same time. Every run
+// also stores its result under an idempotency key derived from the schedule
+// and its due time, so a run that was already paid is replayed instead of
+// paying twice.
+func (store *SQLStore) RunDueScheduledTransfers(ctx context.Context, arg RunDueScheduledTransfersParams) ([]ScheduledTransferRun, error) {
+	var runs []ScheduledTransferRun
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		due, err := q.ClaimDueScheduledTransfers(ctx, arg.Limit)
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		runs = make([]ScheduledTransferRun, 0, len(due))
+		for _, scheduled := range due {
+			run, err := runScheduledTransfer(ctx, q, scheduled, arg.Rules, now)
+			if err != nil {
+				return fmt.Errorf("scheduled transfer %d: %w", scheduled.ID, err)
+			}
+			runs = append(runs, run)
+		}
+
+		return nil
+	})
+
+	return runs, err
+}
+
+func runScheduledTransfer(ctx context.Context, q *Queries, scheduled ScheduledTransfer, rules risk.Rules, now time.Time) (ScheduledTransferRun, error) {
+	arg := CreateScheduledTransferRunParams{
+		ScheduledTransferID: scheduled.ID,
+		ScheduledFor:        scheduled.NextRunAt,
+		Status:              ScheduledRunSucceeded,
+	}
+
+	transferArg := TransferTxParams{
+		FromAccountID: scheduled.FromAccountID,
+		ToAccountID:   scheduled.ToAccountID,
+		Amount:        scheduled.Amount,
+		Idempotency: &IdempotencyKeyParams{
+			Owner:       scheduled.Owner,
+			Key:         fmt.Sprintf("scheduled-transfer:%d:%d", scheduled.ID, scheduled.NextRunAt.Unix()),
+			RequestHash: fmt.Sprintf("%d:%d:%d", scheduled.FromAccountID, scheduled.ToAccountID, scheduled.Amount),
+		},
+	}
+
+	// the decision of a blocked run is recorded with the run
+	outcome, _, err := checkTransferRisk(ctx, q, CheckTransferRiskParams{
+		Rules:    rules,
+		Owner:    scheduled.Owner,
+		Transfer: &transferArg,
+	})
+	if err != nil {
+		return ScheduledTransferRun{}, err
+	}
+
+	var result TransferTxResult
+	if outcome == risk.Allow {
+		result, err = scheduledTransfer(ctx, q, transferArg)
+	} else {
+		err = ErrTransferBlocked
+	}
+
+	switch {
+	case err == nil:
+		arg.TransferID = sql.NullInt64{Int64: result.Transfer.ID, Valid: true}
+	case isTransferRejection(err):
+		// The run is recorded as failed and the schedule moves on, the same way
+		// a standing order that can't be covered is skipped for that period.
+		arg.Status = ScheduledRunFailed
+		arg.Error = sql.NullString{String: err.Error(), Valid: true}
+	default:
+		// Anything else is likely transient, so the schedule is left due and
+		// retried on the next tick.
+		return ScheduledTransferRun{}, err
+	}
+
+	run, err := q.CreateScheduledTransferRun(ctx, arg)
+	if err != nil {
+		return run, err
+	}
+
+	nextRunAt, active := nextScheduledRun(scheduled.StartAt, scheduled.Frequency, now)
+	if !active {
+		nextRunAt = scheduled.NextRunAt
+	}
+
+	_, err = q.UpdateScheduledTransferNextRun(ctx, UpdateScheduledTransferNextRunParams{
+		ID:        scheduled.ID,
+		NextRunAt: nextRunAt,
+		Active:    active,
+	})
+
+	return run, err
+}
+
+// scheduledTransfer makes the transfer of a run like TransferTx, but within the
+// claiming transaction. The transfer is only written once it's been checked,
+// so a rejected run leaves the transaction usable for the next one.
+func scheduledTransfer(ctx context.Context, q *Queries, arg TransferTxParams) (TransferTxResult, error) {
+	var result TransferTxResult
+
+	replayed, err := replayIdempotentResult(ctx, q, arg.Idempotency, &result)
+	if err != nil || replayed {
+		return result, err
+	}
+
+	result, err = transferTx(ctx, q, arg)
+	if err != nil {
+		return result, err
+	}
+
+	return result, storeIdempotentResult(ctx, q, arg.Idempotency, result)
+}
+
+func isTransferRejection(err error) bool {
+	return errors.Is(err, ErrInsufficientFunds) ||
+		errors.Is(err, ErrTransferLimitExceeded) ||
+		errors.Is(err, ErrAccountNotFound) ||
+		errors.Is(err, ErrCurrencyMismatch) ||
+		errors.Is(err, ErrAccountFrozen) ||
+		errors.Is(err, ErrAccountClosed) ||
+		errors.Is(err, ErrIdempotencyKeyConflict) ||
+		errors.Is(err, ErrTransferBlocked)
+}
+
+// nextScheduledRun returns the first occurrence of a schedule strictly after
+// the given time and false if the schedule doesn't repeat. Occurrences are
+// counted from start so monthly schedules keep their day of month, clamped to
+// the last day of shorter months, and runs missed while no worker was running
+// are skipped rather than executed in a burst.
+func nextScheduledRun(start time.Time, frequency string, after time.Time) (time.Time, bool) {
+	var occurrence func(n int) time.Time
+	var estimate int
+
+	switch frequency {
+	case FrequencyDaily:
+		occurrence = func(n int) time.Time { return start.AddDate(0, 0, n) }
+		estimate = int(after.Sub(start) / (24 * time.Hour))
+	case FrequencyWeekly:
+		occurrence = func(n int) time.Time { return start.AddDate(0, 0, 7*n) }
+		estimate = int(after.Sub(start) / (7 * 24 * time.Hour))
+	case FrequencyMonthly:
+		occurrence = func(n int) time.Time { return addMonthsClamped(start, n) }
+		estimate = (after.Year()-start.Year())*12 + int(after.Month()-start.Month())
+	default:
+		return time.Time{}, false
+	}
+
+	n := estimate - 1
+	if n < 1 {
+		n = 1
+	}
+	for !occurrence(n).After(after) {
+		n++
+	}
+
+	return occurrence(n), true
+}
+
+func addMonthsClamped(t time.Time, months int) time.Time {
+	year, month, day := t.Date()
+	hour, min, sec := t.Clock()
+
+	lastDay := time.Date(year, month+time.Month(months)+1, 0, 0, 0, 0, 0, t.Location()).Day()
+	if day > lastDay {
+		day = lastDay
+	}
+
+	return time.Date(year, month+time.Month(months), day, hour, min, sec, t.Nanosecond(), t.Location())
+}
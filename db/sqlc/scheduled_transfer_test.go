@@ -0,0 +1,357 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/crackz/simple-bank/risk"
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomScheduledTransfer(t *testing.T, fromAccount Account, toAccount Account, frequency string, startAt time.Time) ScheduledTransfer {
+	arg := CreateScheduledTransferParams{
+		Owner:         fromAccount.Owner,
+		FromAccountID: fromAccount.ID,
+		ToAccountID:   toAccount.ID,
+		Amount:        10,
+		Frequency:     frequency,
+		StartAt:       startAt,
+	}
+
+	scheduledTransfer, err := testQueries.CreateScheduledTransfer(context.Background(), arg)
+	require.NoError(t, err)
+	require.NotZero(t, scheduledTransfer.ID)
+	require.Equal(t, arg.Owner, scheduledTransfer.Owner)
+	require.Equal(t, arg.Frequency, scheduledTransfer.Frequency)
+	require.WithinDuration(t, arg.StartAt, scheduledTransfer.StartAt, time.Second)
+	require.WithinDuration(t, arg.StartAt, scheduledTransfer.NextRunAt, time.Second)
+	require.True(t, scheduledTransfer.Active)
+
+	return scheduledTransfer
+}
+
+func TestRunDueScheduledTransfers(t *testing.T) {
+	store := NewStore(testDb)
+
+	fromAccount := createRandomAccount(t)
+	toAccount := createRandomAccountWithCurrency(t, fromAccount.Currency)
+
+	startAt := time.Now().Add(-time.Hour)
+	monthly := createRandomScheduledTransfer(t, fromAccount, toAccount, FrequencyMonthly, startAt)
+	once := createRandomScheduledTransfer(t, fromAccount, toAccount, FrequencyOnce, startAt)
+
+	// drain every due schedule, including ones left by other tests
+	for {
+		runs, err := store.RunDueScheduledTransfers(context.Background(), RunDueScheduledTransfersParams{Limit: 100})
+		require.NoError(t, err)
+		if len(runs) == 0 {
+			break
+		}
+	}
+
+	for _, scheduledTransfer := range []ScheduledTransfer{monthly, once} {
+		runs, err := store.ListScheduledTransferRuns(context.Background(), ListScheduledTransferRunsParams{
+			ScheduledTransferID: scheduledTransfer.ID,
+			Limit:               10,
+		})
+		require.NoError(t, err)
+		require.Len(t, runs, 1)
+		require.Equal(t, ScheduledRunSucceeded, runs[0].Status)
+		require.True(t, runs[0].TransferID.Valid)
+	}
+
+	updatedMonthly, err := store.GetScheduledTransfer(context.Background(), monthly.ID)
+	require.NoError(t, err)
+	require.True(t, updatedMonthly.Active)
+	require.True(t, updatedMonthly.NextRunAt.After(time.Now()))
+
+	updatedOnce, err := store.GetScheduledTransfer(context.Background(), once.ID)
+	require.NoError(t, err)
+	require.False(t, updatedOnce.Active)
+
+	updatedFromAccount, err := store.GetAccount(context.Background(), fromAccount.ID)
+	require.NoError(t, err)
+	require.Equal(t, fromAccount.Balance-20, updatedFromAccount.Balance)
+}
+
+func TestRunDueScheduledTransfersReplayed(t *testing.T) {
+	store := NewStore(testDb)
+
+	fromAccount := createRandomAccount(t)
+	toAccount := createRandomAccountWithCurrency(t, fromAccount.Currency)
+	scheduledTransfer := createRandomScheduledTransfer(t, fromAccount, toAccount, FrequencyOnce, time.Now().Add(-time.Hour))
+
+	// the run was already paid under its idempotency key
+	paid, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: fromAccount.ID,
+		ToAccountID:   toAccount.ID,
+		Amount:        scheduledTransfer.Amount,
+		Idempotency: &IdempotencyKeyParams{
+			Owner:       scheduledTransfer.Owner,
+			Key:         fmt.Sprintf("scheduled-transfer:%d:%d", scheduledTransfer.ID, scheduledTransfer.NextRunAt.Unix()),
+			RequestHash: fmt.Sprintf("%d:%d:%d", fromAccount.ID, toAccount.ID, scheduledTransfer.Amount),
+		},
+	})
+	require.NoError(t, err)
+
+	for {
+		runs, err := store.RunDueScheduledTransfers(context.Background(), RunDueScheduledTransfersParams{Limit: 100})
+		require.NoError(t, err)
+		if len(runs) == 0 {
+			break
+		}
+	}
+
+	runs, err := store.ListScheduledTransferRuns(context.Background(), ListScheduledTransferRunsParams{
+		ScheduledTransferID: scheduledTransfer.ID,
+		Limit:               10,
+	})
+	require.NoError(t, err)
+	require.Len(t, runs, 1)
+	require.Equal(t, ScheduledRunSucceeded, runs[0].Status)
+	require.Equal(t, paid.Transfer.ID, runs[0].TransferID.Int64)
+
+	updatedFromAccount, err := store.GetAccount(context.Background(), fromAccount.ID)
+	require.NoError(t, err)
+	require.Equal(t, fromAccount.Balance-scheduledTransfer.Amount, updatedFromAccount.Balance)
+}
+
+func TestRunDueScheduledTransfersInsufficientFunds(t *testing.T) {
+	store := NewStore(testDb)
+
+	fromAccount := createRandomAccount(t)
+	toAccount := createRandomAccountWithCurrency(t, fromAccount.Currency)
+
+	scheduledTransfer, err := testQueries.CreateScheduledTransfer(context.Background(), CreateScheduledTransferParams{
+		Owner:         fromAccount.Owner,
+		FromAccountID: fromAccount.ID,
+		ToAccountID:   toAccount.ID,
+		Amount:        fromAccount.Balance + 1,
+		Frequency:     FrequencyDaily,
+		StartAt:       time.Now().Add(-time.Minute),
+	})
+	require.NoError(t, err)
+
+	for {
+		runs, err := store.RunDueScheduledTransfers(context.Background(), RunDueScheduledTransfersParams{Limit: 100})
+		require.NoError(t, err)
+		if len(runs) == 0 {
+			break
+		}
+	}
+
+	runs, err := store.ListScheduledTransferRuns(context.Background(), ListScheduledTransferRunsParams{
+		ScheduledTransferID: scheduledTransfer.ID,
+		Limit:               10,
+	})
+	require.NoError(t, err)
+	require.Len(t, runs, 1)
+	require.Equal(t, ScheduledRunFailed, runs[0].Status)
+	require.False(t, runs[0].TransferID.Valid)
+	require.Contains(t, runs[0].Error.String, ErrInsufficientFunds.Error())
+}
+
+func TestRunDueScheduledTransfersLimitExceeded(t *testing.T) {
+	store := NewStore(testDb)
+
+	limitedAccount := createRandomAccount(t)
+	toAccount := createRandomAccountWithCurrency(t, limitedAccount.Currency)
+	setTransferLimit(t, limitedAccount, sql.NullInt64{Int64: 5, Valid: true}, sql.NullInt64{}, sql.NullInt64{})
+
+	fromAccount := createRandomAccountWithCurrency(t, limitedAccount.Currency)
+
+	startAt := time.Now().Add(-time.Minute)
+	limited := createRandomScheduledTransfer(t, limitedAccount, toAccount, FrequencyDaily, startAt)
+	other := createRandomScheduledTransfer(t, fromAccount, toAccount, FrequencyDaily, startAt)
+
+	// a schedule over the limit doesn't hold up the others
+	for {
+		runs, err := store.RunDueScheduledTransfers(context.Background(), RunDueScheduledTransfersParams{Limit: 100})
+		require.NoError(t, err)
+		if len(runs) == 0 {
+			break
+		}
+	}
+
+	runs, err := store.ListScheduledTransferRuns(context.Background(), ListScheduledTransferRunsParams{
+		ScheduledTransferID: limited.ID,
+		Limit:               10,
+	})
+	require.NoError(t, err)
+	require.Len(t, runs, 1)
+	require.Equal(t, ScheduledRunFailed, runs[0].Status)
+	require.Contains(t, runs[0].Error.String, ErrTransferLimitExceeded.Error())
+
+	runs, err = store.ListScheduledTransferRuns(context.Background(), ListScheduledTransferRunsParams{
+		ScheduledTransferID: other.ID,
+		Limit:               10,
+	})
+	require.NoError(t, err)
+	require.Len(t, runs, 1)
+	require.Equal(t, ScheduledRunSucceeded, runs[0].Status)
+
+	updatedLimited, err := store.GetScheduledTransfer(context.Background(), limited.ID)
+	require.NoError(t, err)
+	require.True(t, updatedLimited.NextRunAt.After(time.Now()))
+}
+
+func TestNextScheduledRun(t *testing.T) {
+	start := time.Date(2023, time.January, 31, 9, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name      string
+		frequency string
+		after     time.Time
+		want      time.Time
+		repeats   bool
+	}{
+		{
+			name:      "Once",
+			frequency: FrequencyOnce,
+			after:     start,
+			repeats:   false,
+		},
+		{
+			name:      "Daily",
+			frequency: FrequencyDaily,
+			after:     start,
+			want:      time.Date(2023, time.February, 1, 9, 0, 0, 0, time.UTC),
+			repeats:   true,
+		},
+		{
+			name:      "Daily Skips Missed Runs",
+			frequency: FrequencyDaily,
+			after:     time.Date(2023, time.March, 10, 12, 0, 0, 0, time.UTC),
+			want:      time.Date(2023, time.March, 11, 9, 0, 0, 0, time.UTC),
+			repeats:   true,
+		},
+		{
+			name:      "Weekly",
+			frequency: FrequencyWeekly,
+			after:     start,
+			want:      time.Date(2023, time.February, 7, 9, 0, 0, 0, time.UTC),
+			repeats:   true,
+		},
+		{
+			name:      "Monthly Clamps To Month End",
+			frequency: FrequencyMonthly,
+			after:     start,
+			want:      time.Date(2023, time.February, 28, 9, 0, 0, 0, time.UTC),
+			repeats:   true,
+		},
+		{
+			name:      "Monthly Keeps Day Of Month",
+			frequency: FrequencyMonthly,
+			after:     time.Date(2023, time.February, 28, 9, 0, 0, 0, time.UTC),
+			want:      time.Date(2023, time.March, 31, 9, 0, 0, 0, time.UTC),
+			repeats:   true,
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			got, repeats := nextScheduledRun(start, tc.frequency, tc.after)
+			require.Equal(t, tc.repeats, repeats)
+			if tc.repeats {
+				require.Equal(t, tc.want, got)
+			}
+		})
+	}
+}
+
+func TestRunDueScheduledTransfersBlocked(t *testing.T) {
+	store := NewStore(testDb)
+
+	fromAccount := createRandomAccount(t)
+	toAccount := createRandomAccountWithCurrency(t, fromAccount.Currency)
+	scheduledTransfer := createRandomScheduledTransfer(t, fromAccount, toAccount, FrequencyDaily, time.Now().Add(-time.Minute))
+
+	// a run that would be held for review is blocked, since it can't wait
+	rules := risk.Rules{
+		NewPayeeAmount:  scheduledTransfer.Amount,
+		NewPayeeOutcome: risk.Review,
+	}
+	for {
+		runs, err := store.RunDueScheduledTransfers(context.Background(), RunDueScheduledTransfersParams{Limit: 100, Rules: rules})
+		require.NoError(t, err)
+		if len(runs) == 0 {
+			break
+		}
+	}
+
+	runs, err := store.ListScheduledTransferRuns(context.Background(), ListScheduledTransferRunsParams{
+		ScheduledTransferID: scheduledTransfer.ID,
+		Limit:               10,
+	})
+	require.NoError(t, err)
+	require.Len(t, runs, 1)
+	require.Equal(t, ScheduledRunFailed, runs[0].Status)
+	require.False(t, runs[0].TransferID.Valid)
+	require.Contains(t, runs[0].Error.String, ErrTransferBlocked.Error())
+
+	updatedScheduledTransfer, err := store.GetScheduledTransfer(context.Background(), scheduledTransfer.ID)
+	require.NoError(t, err)
+	require.True(t, updatedScheduledTransfer.NextRunAt.After(time.Now()))
+
+	updatedFromAccount, err := store.GetAccount(context.Background(), fromAccount.ID)
+	require.NoError(t, err)
+	require.Equal(t, fromAccount.Balance, updatedFromAccount.Balance)
+}
+
+func TestUpdateScheduledTransferTxReactivate(t *testing.T) {
+	store := NewStore(testDb)
+
+	fromAccount := createRandomAccount(t)
+	toAccount := createRandomAccountWithCurrency(t, fromAccount.Currency)
+
+	startAt := time.Now().AddDate(0, 0, -10)
+	daily := createRandomScheduledTransfer(t, fromAccount, toAccount, FrequencyDaily, startAt)
+
+	paused, err := store.UpdateScheduledTransferTx(context.Background(), UpdateScheduledTransferParams{
+		ID:     daily.ID,
+		Active: sql.NullBool{Bool: false, Valid: true},
+	})
+	require.NoError(t, err)
+	require.False(t, paused.Active)
+	require.True(t, paused.NextRunAt.Before(time.Now()))
+
+	reactivated, err := store.UpdateScheduledTransferTx(context.Background(), UpdateScheduledTransferParams{
+		ID:     daily.ID,
+		Active: sql.NullBool{Bool: true, Valid: true},
+	})
+	require.NoError(t, err)
+	require.True(t, reactivated.Active)
+	require.True(t, reactivated.NextRunAt.After(time.Now()))
+	require.WithinDuration(t, time.Now().Add(24*time.Hour), reactivated.NextRunAt, 24*time.Hour)
+}
+
+func TestUpdateScheduledTransferTxReactivateCompleted(t *testing.T) {
+	store := NewStore(testDb)
+
+	fromAccount := createRandomAccount(t)
+	toAccount := createRandomAccountWithCurrency(t, fromAccount.Currency)
+	once := createRandomScheduledTransfer(t, fromAccount, toAccount, FrequencyOnce, time.Now().Add(-time.Hour))
+
+	for {
+		runs, err := store.RunDueScheduledTransfers(context.Background(), RunDueScheduledTransfersParams{Limit: 100})
+		require.NoError(t, err)
+		if len(runs) == 0 {
+			break
+		}
+	}
+
+	_, err := store.UpdateScheduledTransferTx(context.Background(), UpdateScheduledTransferParams{
+		ID:     once.ID,
+		Active: sql.NullBool{Bool: true, Valid: true},
+	})
+	require.ErrorIs(t, err, ErrScheduledTransferCompleted)
+
+	completed, err := store.GetScheduledTransfer(context.Background(), once.ID)
+	require.NoError(t, err)
+	require.False(t, completed.Active)
+}
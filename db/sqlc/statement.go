@@ -0,0 +1,175 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+type AccountStatementParams struct {
+	AccountID int64     `json:"accountId"`
+	From      time.Time `json:"from"`
+	To        time.Time `json:"to"`
+}
+
+type AccountStatement struct {
+	AccountID      int64            `json:"accountID"`
+	From           time.Time        `json:"from"`
+	To             time.Time        `json:"to"`
+	OpeningBalance int64            `json:"openingBalance"`
+	ClosingBalance int64            `json:"closingBalance"`
+	Entries        []StatementEntry `json:"entries"`
+}
+
+type StatementEntry struct {
+	Entry
+	// CounterpartyAccountID is the other account of the entry's transfer.
+	CounterpartyAccountID sql.NullInt64 `json:"counterpartyAccountID"`
+	// Balance is the running balance of the account after the entry.
+	Balance int64 `json:"balance"`
+}
+
+// GetAccountStatement lists the entries of an account created in [From, To)
+// with the balance after each of them. The opening balance and the entries
+// are read from the same snapshot so concurrent transfers can't make them
+// disagree.
+func (store *SQLStore) GetAccountStatement(ctx context.Context, arg AccountStatementParams) (AccountStatement, error) {
+	statement := AccountStatement{
+		AccountID: arg.AccountID,
+		From:      arg.From,
+		To:        arg.To,
+	}
+
+	err := store.execTxWithOptions(ctx, snapshotTxOptions, func(q *Queries) error {
+		var err error
+
+		statement.OpeningBalance, err = q.GetAccountBalanceBefore(ctx, GetAccountBalanceBeforeParams{
+			AccountID: arg.AccountID,
+			CreatedAt: arg.From,
+		})
+		if err != nil {
+			return fmt.Errorf("couldn't get opening balance: %w", err)
+		}
+
+		rows, err := q.ListAccountStatementEntries(ctx, ListAccountStatementEntriesParams{
+			AccountID: arg.AccountID,
+			FromTime:  arg.From,
+			ToTime:    arg.To,
+		})
+		if err != nil {
+			return fmt.Errorf("couldn't list statement entries: %w", err)
+		}
+
+		balance := statement.OpeningBalance
+		statement.Entries = make([]StatementEntry, 0, len(rows))
+		for _, row := range rows {
+			balance += row.Amount
+			statement.Entries = append(statement.Entries, newStatementEntry(row, balance))
+		}
+		statement.ClosingBalance = balance
+
+		return nil
+	})
+
+	return statement, err
+}
+
+// statementPageSize is how many entries StreamAccountStatement reads per
+// query.
+const statementPageSize = 500
+
+// StreamAccountStatement reads the same statement as GetAccountStatement but
+// hands the entries to entry one at a time instead of collecting them, so
+// statements of any length can be written out. begin gets the statement
+// without its entries before the first one.
+//
+// The balances are read from one snapshot, then the entries are paged in by
+// (created_at, id) without holding a transaction open while the client reads.
+// Entries are never updated or deleted, so the pages add up to the closing
+// balance unless a transfer dated before To commits in between.
+func (store *SQLStore) StreamAccountStatement(ctx context.Context, arg AccountStatementParams, begin func(AccountStatement) error, entry func(StatementEntry) error) error {
+	statement := AccountStatement{
+		AccountID: arg.AccountID,
+		From:      arg.From,
+		To:        arg.To,
+	}
+
+	err := store.execTxWithOptions(ctx, snapshotTxOptions, func(q *Queries) error {
+		var err error
+		statement.OpeningBalance, err = q.GetAccountBalanceBefore(ctx, GetAccountBalanceBeforeParams{
+			AccountID: arg.AccountID,
+			CreatedAt: arg.From,
+		})
+		if err != nil {
+			return fmt.Errorf("couldn't get opening balance: %w", err)
+		}
+
+		// formats like camt.053 put the closing balance before the entries
+		statement.ClosingBalance, err = q.GetAccountBalanceBefore(ctx, GetAccountBalanceBeforeParams{
+			AccountID: arg.AccountID,
+			CreatedAt: arg.To,
+		})
+		if err != nil {
+			return fmt.Errorf("couldn't get closing balance: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := begin(statement); err != nil {
+		return err
+	}
+
+	page := ListAccountStatementEntriesAfterParams{
+		AccountID:      arg.AccountID,
+		ToTime:         arg.To,
+		AfterCreatedAt: arg.From,
+		LimitCount:     statementPageSize,
+	}
+	balance := statement.OpeningBalance
+	for {
+		rows, err := store.ListAccountStatementEntriesAfter(ctx, page)
+		if err != nil {
+			return fmt.Errorf("couldn't list statement entries: %w", err)
+		}
+
+		for _, row := range rows {
+			balance += row.Amount
+			if err := entry(newStatementEntry(ListAccountStatementEntriesRow(row), balance)); err != nil {
+				return err
+			}
+		}
+
+		if len(rows) < int(page.LimitCount) {
+			return nil
+		}
+
+		last := rows[len(rows)-1]
+		page.AfterCreatedAt = last.CreatedAt
+		page.AfterID = last.ID
+	}
+}
+
+func newStatementEntry(row ListAccountStatementEntriesRow, balance int64) StatementEntry {
+	entry := StatementEntry{
+		Entry: Entry{
+			ID:         row.ID,
+			AccountID:  row.AccountID,
+			Amount:     row.Amount,
+			CreatedAt:  row.CreatedAt,
+			TransferID: row.TransferID,
+		},
+		Balance: balance,
+	}
+	if row.FromAccountID.Int64 == row.AccountID {
+		entry.CounterpartyAccountID = row.ToAccountID
+	} else {
+		entry.CounterpartyAccountID = row.FromAccountID
+	}
+
+	return entry
+}
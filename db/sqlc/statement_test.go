@@ -0,0 +1,57 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetAccountStatement(t *testing.T) {
+	store := NewStore(testDb)
+
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccountWithCurrency(t, account1.Currency)
+
+	before, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account2.ID,
+		ToAccountID:   account1.ID,
+		Amount:        5,
+	})
+	require.NoError(t, err)
+
+	from := time.Now()
+	for _, amount := range []int64{10, 20} {
+		_, err := store.TransferTx(context.Background(), TransferTxParams{
+			FromAccountID: account1.ID,
+			ToAccountID:   account2.ID,
+			Amount:        amount,
+		})
+		require.NoError(t, err)
+	}
+	to := time.Now()
+
+	statement, err := store.GetAccountStatement(context.Background(), AccountStatementParams{
+		AccountID: account1.ID,
+		From:      from,
+		To:        to,
+	})
+	require.NoError(t, err)
+
+	// entries don't include the initial balance, only what moved through transfers
+	require.Equal(t, before.ToEntry.Amount, statement.OpeningBalance)
+	require.Len(t, statement.Entries, 2)
+
+	require.Equal(t, int64(-10), statement.Entries[0].Amount)
+	require.Equal(t, statement.OpeningBalance-10, statement.Entries[0].Balance)
+	require.Equal(t, int64(-20), statement.Entries[1].Amount)
+	require.Equal(t, statement.OpeningBalance-30, statement.Entries[1].Balance)
+	require.Equal(t, statement.OpeningBalance-30, statement.ClosingBalance)
+
+	for _, entry := range statement.Entries {
+		require.True(t, entry.TransferID.Valid)
+		require.True(t, entry.CounterpartyAccountID.Valid)
+		require.Equal(t, account2.ID, entry.CounterpartyAccountID.Int64)
+	}
+}
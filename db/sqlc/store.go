@@ -3,34 +3,116 @@ package db
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"sort"
+
+	"github.com/crackz/simple-bank/risk"
+)
+
+var (
+	ErrAccountNotFound   = errors.New("account not found")
+	ErrCurrencyMismatch  = errors.New("accounts currencies don't match")
+	ErrInsufficientFunds = errors.New("insufficient funds")
 )
 
 type Store interface {
 	Querier
 	TransferTx(ctx context.Context, arg TransferTxParams) (TransferTxResult, error)
+	CreateAccountTx(ctx context.Context, arg CreateAccountTxParams) (Account, error)
+	PreviewTransferFee(ctx context.Context, fromAccountID int64, amount int64) (TransferFeePreview, error)
+	CreateFeeScheduleTx(ctx context.Context, arg CreateFeeScheduleParams) (FeeSchedule, error)
+	GetTransferLimitHeadroom(ctx context.Context, username string) ([]TransferLimitHeadroom, error)
+	CheckTransferRisk(ctx context.Context, arg CheckTransferRiskParams) (risk.Outcome, RiskDecision, error)
+	ReviewRiskDecisionTx(ctx context.Context, arg ReviewRiskDecisionTxParams) (ReviewRiskDecisionTxResult, error)
+	CreateUserTx(ctx context.Context, arg CreateUserParams) (User, error)
+	BatchTransferTx(ctx context.Context, arg BatchTransferTxParams) (BatchTransferTxResult, error)
+	ExchangeTransferTx(ctx context.Context, arg ExchangeTransferTxParams) (TransferTxResult, error)
+	ReverseTransferTx(ctx context.Context, arg ReverseTransferTxParams) (TransferTxResult, error)
+	CreateHoldTx(ctx context.Context, arg CreateHoldTxParams) (HoldTxResult, error)
+	CaptureHoldTx(ctx context.Context, arg CaptureHoldTxParams) (HoldTxResult, error)
+	VoidHoldTx(ctx context.Context, holdID int64) (HoldTxResult, error)
+	ExpireHolds(ctx context.Context, limit int32) ([]AccountHold, error)
+	Reconcile(ctx context.Context) (ReconciliationReport, error)
+	GetAccountStatement(ctx context.Context, arg AccountStatementParams) (AccountStatement, error)
+	StreamAccountStatement(ctx context.Context, arg AccountStatementParams, begin func(AccountStatement) error, entry func(StatementEntry) error) error
+	UpdateAccountStatusTx(ctx context.Context, accountID int64, status string) (Account, error)
+	CloseAccountTx(ctx context.Context, arg CloseAccountTxParams) (CloseAccountTxResult, error)
+	UpdateScheduledTransferTx(ctx context.Context, arg UpdateScheduledTransferParams) (ScheduledTransfer, error)
+	RunDueScheduledTransfers(ctx context.Context, arg RunDueScheduledTransfersParams) ([]ScheduledTransferRun, error)
+	RelayOutboxEvents(ctx context.Context, limit int32, publish func(context.Context, Outbox) error) (int, error)
+	RecordWebhookAttemptTx(ctx context.Context, arg RecordWebhookAttemptTxParams) (WebhookDelivery, error)
+	AssignInterestPlanTx(ctx context.Context, arg AssignInterestPlanTxParams) (AccountInterest, error)
+	AccrueInterestTx(ctx context.Context, arg AccrueInterestTxParams) (AccrueInterestTxResult, error)
 }
 
 type SQLStore struct {
 	*Queries
-	db *sql.DB
+	db    *sql.DB
+	retry RetryPolicy
 }
 
 func NewStore(db *sql.DB) Store {
 	return &SQLStore{
 		db:      db,
 		Queries: New(db),
+		retry:   DefaultRetryPolicy,
 	}
 }
 
+// snapshotTxOptions is for reports whose queries have to read one consistent
+// snapshot of the ledger.
+var snapshotTxOptions = &sql.TxOptions{
+	Isolation: sql.LevelRepeatableRead,
+	ReadOnly:  true,
+}
+
+// execTx runs fn in a read committed transaction, retrying it as described by
+// execTxWithOptions.
 func (store *SQLStore) execTx(ctx context.Context, fn func(*Queries) error) error {
-	tx, err := store.db.BeginTx(ctx, nil)
+	return store.execTxWithOptions(ctx, &sql.TxOptions{Isolation: sql.LevelReadCommitted}, fn)
+}
+
+// execTxWithOptions runs fn in a transaction with the given isolation level.
+// A transaction that fails with a serialization failure or a deadlock is
+// rolled back and fn runs again in a new one after a jittered backoff, up to
+// the store's retry policy, so fn must not carry state over from a previous
+// attempt.
+func (store *SQLStore) execTxWithOptions(ctx context.Context, opts *sql.TxOptions, fn func(*Queries) error) error {
+	for attempt := 1; ; attempt++ {
+		err := store.runTx(ctx, opts, fn)
+
+		code, retryable := retryableTxError(err)
+		if !retryable {
+			return err
+		}
+		if attempt >= store.retry.MaxAttempts {
+			txRetriesExhausted.Add(code, 1)
+			return err
+		}
+
+		txRetries.Add(code, 1)
+		if waitErr := store.retry.wait(ctx, attempt); waitErr != nil {
+			return err
+		}
+	}
+}
+
+func (store *SQLStore) runTx(ctx context.Context, opts *sql.TxOptions, fn func(*Queries) error) error {
+	tx, err := store.db.BeginTx(ctx, opts)
 	if err != nil {
 		return err
 	}
 
 	q := New(tx)
 	err = fn(q)
+
+	// read-only transactions have no change to write the audit log entry of
+	logger, audited := auditLoggerFromContext(ctx)
+	audited = audited && !opts.ReadOnly
+	if err == nil && audited {
+		err = logger.WriteAuditLog(ctx, q)
+	}
 	if err != nil {
 		if rbErr := tx.Rollback(); rbErr != nil {
 			return fmt.Errorf("tx error: %v, rbErr: %v", err, rbErr)
@@ -39,14 +121,25 @@ func (store *SQLStore) execTx(ctx context.Context, fn func(*Queries) error) erro
 		return err
 	}
 
-	return tx.Commit()
+	if err := tx.Commit(); err != nil {
+		return err
+	}
 
+	if audited {
+		logger.AuditLogCommitted()
+	}
+	return nil
 }
 
 type TransferTxParams struct {
 	FromAccountID int64 `json:"fromAccountId"`
 	ToAccountID   int64 `json:"toAccountId"`
 	Amount        int64 `json:"amount"`
+
+	Idempotency *IdempotencyKeyParams `json:"-"`
+	// Risk is the screening decision that allowed the transfer. It's recorded
+	// with the transfer.
+	Risk *risk.Decision `json:"-"`
 }
 
 type TransferTxResult struct {
@@ -55,56 +148,216 @@ type TransferTxResult struct {
 	ToAccount   Account  `json:"toAccount"`
 	FromEntry   Entry    `json:"fromEntry"`
 	ToEntry     Entry    `json:"toEntry"`
+	// Fee is only set when the transfer was charged a fee.
+	Fee *TransferFee `json:"fee,omitempty"`
+	// FeeRefund is only set when a reversal refunded part of the fee of the
+	// transfer it reverses.
+	FeeRefund *Transfer `json:"feeRefund,omitempty"`
 }
 
+// TransferTx moves money between two accounts of the same currency and charges
+// the fee of the currency's active fee schedule, if any, to the source account
+// in the same transaction. The amount, without the fee, counts towards the
+// transfer limits of the source account's owner.
 func (store *SQLStore) TransferTx(ctx context.Context, arg TransferTxParams) (TransferTxResult, error) {
 	var result TransferTxResult
 
-	err := store.execTx(ctx, func(q *Queries) error {
+	err := store.execIdempotentTx(ctx, arg.Idempotency, &result, func(q *Queries) error {
 		var err error
 
-		result.FromEntry, err = q.CreateEntry(ctx, CreateEntryParams{
-			AccountID: arg.FromAccountID,
-			Amount:    -arg.Amount,
-		})
+		result, err = transferTx(ctx, q, arg)
+		return err
+	})
 
-		if err != nil {
-			return err
+	return result, err
+
+}
+
+// transferTx makes a transfer as described by TransferTx within the caller's
+// transaction, and records the risk decision it was allowed by, if any.
+func transferTx(ctx context.Context, q *Queries, arg TransferTxParams) (TransferTxResult, error) {
+	schedule, fee, err := transferFee(ctx, q, arg.FromAccountID, arg.Amount)
+	if err != nil {
+		return TransferTxResult{}, err
+	}
+
+	ids := []int64{arg.FromAccountID, arg.ToAccountID}
+	if fee > 0 {
+		ids = append(ids, schedule.RevenueAccountID)
+	}
+	accounts, err := lockAccounts(ctx, q, ids...)
+	if err != nil {
+		return TransferTxResult{}, err
+	}
+
+	return chargedTransfer(ctx, q, accounts, arg, schedule, fee)
+}
+
+// chargedTransfer checks and makes a transfer charged fee by schedule. The
+// accounts of the transfer, and the revenue account when there is a fee, must
+// already be locked by the caller and are updated with their new balances, so
+// transfers made one after another in a transaction are checked against each
+// other.
+func chargedTransfer(ctx context.Context, q *Queries, accounts map[int64]Account, arg TransferTxParams, schedule FeeSchedule, fee int64) (TransferTxResult, error) {
+	err := checkTransfer(accounts[arg.FromAccountID], accounts[arg.ToAccountID], arg.Amount+fee)
+	if err != nil {
+		return TransferTxResult{}, err
+	}
+	if fee > 0 {
+		if err := checkAccountsStatus(accounts[arg.FromAccountID], accounts[schedule.RevenueAccountID]); err != nil {
+			return TransferTxResult{}, err
 		}
+	}
+	if err := checkTransferLimit(ctx, q, accounts[arg.FromAccountID], arg.Amount); err != nil {
+		return TransferTxResult{}, err
+	}
 
-		result.ToEntry, err = q.CreateEntry(ctx, CreateEntryParams{
-			AccountID: arg.FromAccountID,
-			Amount:    arg.Amount,
-		})
+	result, err := transfer(ctx, q, arg)
+	if err != nil {
+		return result, err
+	}
+	accounts[arg.FromAccountID] = result.FromAccount
+	accounts[arg.ToAccountID] = result.ToAccount
 
-		if err != nil {
-			return err
+	if fee > 0 {
+		if err := chargeFee(ctx, q, schedule, accounts, &result, fee); err != nil {
+			return result, err
 		}
+	}
+
+	if arg.Risk != nil {
+		_, err = recordRiskDecision(ctx, q, accounts[arg.FromAccountID].Owner, arg, *arg.Risk, result.Transfer.ID)
+	}
+
+	return result, err
+}
+
+func checkTransfer(fromAccount Account, toAccount Account, amount int64) error {
+	if err := checkAccountsStatus(fromAccount, toAccount); err != nil {
+		return err
+	}
+
+	if fromAccount.Currency != toAccount.Currency {
+		return ErrCurrencyMismatch
+	}
+
+	// funds reserved by holds can only be spent by capturing the hold
+	if fromAccount.AvailableBalance < amount {
+		return ErrInsufficientFunds
+	}
+
+	return nil
+}
+
+// transfer writes the transfer with its entries and moves the money. Both
+// accounts must already be locked by the caller.
+func transfer(ctx context.Context, q *Queries, arg TransferTxParams) (TransferTxResult, error) {
+	createdTransfer, err := q.CreateTransfer(ctx, CreateTransferParams{
+		FromAccountID: arg.FromAccountID,
+		ToAccountID:   arg.ToAccountID,
+		Amount:        arg.Amount,
+	})
+	if err != nil {
+		return TransferTxResult{}, err
+	}
+
+	return postTransfer(ctx, q, createdTransfer)
+}
+
+// postTransfer records the transfer.created event, writes the entries of an
+// already created transfer and moves the money, debiting Amount and crediting
+// ToAmount.
+func postTransfer(ctx context.Context, q *Queries, transfer Transfer) (result TransferTxResult, err error) {
+	result.Transfer = transfer
+	if err = recordTransferCreated(ctx, q, transfer); err != nil {
+		return
+	}
+
+	transferID := sql.NullInt64{Int64: transfer.ID, Valid: true}
+
+	result.FromEntry, err = q.CreateEntry(ctx, CreateEntryParams{
+		AccountID:  transfer.FromAccountID,
+		Amount:     -transfer.Amount,
+		TransferID: transferID,
+	})
+
+	if err != nil {
+		return
+	}
+
+	result.ToEntry, err = q.CreateEntry(ctx, CreateEntryParams{
+		AccountID:  transfer.ToAccountID,
+		Amount:     transfer.ToAmount,
+		TransferID: transferID,
+	})
+
+	if err != nil {
+		return
+	}
+
+	if transfer.FromAccountID < transfer.ToAccountID {
+		result.FromAccount, result.ToAccount, err = moveMoney(ctx, q, transfer.FromAccountID, -transfer.Amount, transfer.ToAccountID, transfer.ToAmount)
+	} else {
+		result.ToAccount, result.FromAccount, err = moveMoney(ctx, q, transfer.ToAccountID, transfer.ToAmount, transfer.FromAccountID, -transfer.Amount)
+	}
+
+	return
+}
+
+type CreateAccountTxParams struct {
+	CreateAccountParams
+
+	Idempotency *IdempotencyKeyParams `json:"-"`
+}
+
+func (store *SQLStore) CreateAccountTx(ctx context.Context, arg CreateAccountTxParams) (Account, error) {
+	var account Account
 
-		result.Transfer, err = q.CreateTransfer(ctx, CreateTransferParams{
-			FromAccountID: arg.FromAccountID,
-			ToAccountID:   arg.ToAccountID,
-			Amount:        arg.Amount,
-		})
+	err := store.execIdempotentTx(ctx, arg.Idempotency, &account, func(q *Queries) error {
+		var err error
+
+		account, err = q.CreateAccount(ctx, arg.CreateAccountParams)
 		if err != nil {
 			return err
 		}
 
-		if arg.FromAccountID < arg.ToAccountID {
-			result.FromAccount, result.ToAccount, err = moveMoney(ctx, q, arg.FromAccountID, -arg.Amount, arg.ToAccountID, arg.Amount)
-		} else {
-			result.ToAccount, result.FromAccount, err = moveMoney(ctx, q, arg.ToAccountID, arg.Amount, arg.FromAccountID, -arg.Amount)
+		return recordAccountCreated(ctx, q, account)
+	})
+
+	return account, err
+}
+
+// lockAccounts locks the given accounts in ascending ID order, the same order
+// moveMoney updates them in, so concurrent transactions touching overlapping
+// accounts can't deadlock. The locked accounts are returned by ID.
+func lockAccounts(ctx context.Context, q *Queries, ids ...int64) (map[int64]Account, error) {
+	sortedIDs := make([]int64, len(ids))
+	copy(sortedIDs, ids)
+	sort.Slice(sortedIDs, func(i, j int) bool { return sortedIDs[i] < sortedIDs[j] })
+
+	accounts := make(map[int64]Account, len(ids))
+	for _, id := range sortedIDs {
+		if _, ok := accounts[id]; ok {
+			continue
 		}
 
+		account, err := lockAccount(ctx, q, id)
 		if err != nil {
-			return err
+			return nil, err
 		}
+		accounts[id] = account
+	}
 
-		return nil
-	})
+	return accounts, nil
+}
 
-	return result, err
+func lockAccount(ctx context.Context, q *Queries, id int64) (Account, error) {
+	account, err := q.GetAccountForUpdate(ctx, id)
+	if err == sql.ErrNoRows {
+		return account, ErrAccountNotFound
+	}
 
+	return account, err
 }
 
 func moveMoney(
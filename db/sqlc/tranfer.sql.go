@@ -7,16 +7,106 @@ package db
 
 import (
 	"context"
+	"database/sql"
+	"time"
 )
 
+const createExchangeTransfer = `-- name: CreateExchangeTransfer :one
+INSERT INTO transfers (
+  from_account_id,
+  to_account_id,
+  amount,
+  to_amount,
+  exchange_rate
+) VALUES (
+  $1, $2, $3, $4, $5
+) RETURNING id, from_account_id, to_account_id, amount, created_at, to_amount, exchange_rate, reversal_of
+`
+
+type CreateExchangeTransferParams struct {
+	FromAccountID int64  `json:"fromAccountID"`
+	ToAccountID   int64  `json:"toAccountID"`
+	Amount        int64  `json:"amount"`
+	ToAmount      int64  `json:"toAmount"`
+	ExchangeRate  string `json:"exchangeRate"`
+}
+
+func (q *Queries) CreateExchangeTransfer(ctx context.Context, arg CreateExchangeTransferParams) (Transfer, error) {
+	row := q.db.QueryRowContext(ctx, createExchangeTransfer,
+		arg.FromAccountID,
+		arg.ToAccountID,
+		arg.Amount,
+		arg.ToAmount,
+		arg.ExchangeRate,
+	)
+	var i Transfer
+	err := row.Scan(
+		&i.ID,
+		&i.FromAccountID,
+		&i.ToAccountID,
+		&i.Amount,
+		&i.CreatedAt,
+		&i.ToAmount,
+		&i.ExchangeRate,
+		&i.ReversalOf,
+	)
+	return i, err
+}
+
+const createReversalTransfer = `-- name: CreateReversalTransfer :one
+INSERT INTO transfers (
+  from_account_id,
+  to_account_id,
+  amount,
+  to_amount,
+  exchange_rate,
+  reversal_of
+) VALUES (
+  $1, $2, $3, $4, $5, $6
+) RETURNING id, from_account_id, to_account_id, amount, created_at, to_amount, exchange_rate, reversal_of
+`
+
+type CreateReversalTransferParams struct {
+	FromAccountID int64         `json:"fromAccountID"`
+	ToAccountID   int64         `json:"toAccountID"`
+	Amount        int64         `json:"amount"`
+	ToAmount      int64         `json:"toAmount"`
+	ExchangeRate  string        `json:"exchangeRate"`
+	ReversalOf    sql.NullInt64 `json:"reversalOf"`
+}
+
+func (q *Queries) CreateReversalTransfer(ctx context.Context, arg CreateReversalTransferParams) (Transfer, error) {
+	row := q.db.QueryRowContext(ctx, createReversalTransfer,
+		arg.FromAccountID,
+		arg.ToAccountID,
+		arg.Amount,
+		arg.ToAmount,
+		arg.ExchangeRate,
+		arg.ReversalOf,
+	)
+	var i Transfer
+	err := row.Scan(
+		&i.ID,
+		&i.FromAccountID,
+		&i.ToAccountID,
+		&i.Amount,
+		&i.CreatedAt,
+		&i.ToAmount,
+		&i.ExchangeRate,
+		&i.ReversalOf,
+	)
+	return i, err
+}
+
 const createTransfer = `-- name: CreateTransfer :one
 INSERT INTO transfers (
   from_account_id, 
   to_account_id, 
-  amount
+  amount,
+  to_amount
 ) VALUES (
-  $1, $2, $3
-) RETURNING id, from_account_id, to_account_id, amount, created_at
+  $1, $2, $3, $3
+) RETURNING id, from_account_id, to_account_id, amount, created_at, to_amount, exchange_rate, reversal_of
 `
 
 type CreateTransferParams struct {
@@ -34,12 +124,27 @@ func (q *Queries) CreateTransfer(ctx context.Context, arg CreateTransferParams)
 		&i.ToAccountID,
 		&i.Amount,
 		&i.CreatedAt,
+		&i.ToAmount,
+		&i.ExchangeRate,
+		&i.ReversalOf,
 	)
 	return i, err
 }
 
+const getReversedAmount = `-- name: GetReversedAmount :one
+SELECT COALESCE(SUM(to_amount), 0)::bigint AS reversed_amount FROM transfers
+WHERE reversal_of = $1
+`
+
+func (q *Queries) GetReversedAmount(ctx context.Context, reversalOf sql.NullInt64) (int64, error) {
+	row := q.db.QueryRowContext(ctx, getReversedAmount, reversalOf)
+	var reversed_amount int64
+	err := row.Scan(&reversed_amount)
+	return reversed_amount, err
+}
+
 const getTransfer = `-- name: GetTransfer :one
-SELECT id, from_account_id, to_account_id, amount, created_at FROM transfers
+SELECT id, from_account_id, to_account_id, amount, created_at, to_amount, exchange_rate, reversal_of FROM transfers
 WHERE id = $1 LIMIT 1
 `
 
@@ -52,34 +157,91 @@ func (q *Queries) GetTransfer(ctx context.Context, id int64) (Transfer, error) {
 		&i.ToAccountID,
 		&i.Amount,
 		&i.CreatedAt,
+		&i.ToAmount,
+		&i.ExchangeRate,
+		&i.ReversalOf,
+	)
+	return i, err
+}
+
+const getTransferForUpdate = `-- name: GetTransferForUpdate :one
+SELECT id, from_account_id, to_account_id, amount, created_at, to_amount, exchange_rate, reversal_of FROM transfers
+WHERE id = $1 LIMIT 1
+FOR NO KEY UPDATE
+`
+
+func (q *Queries) GetTransferForUpdate(ctx context.Context, id int64) (Transfer, error) {
+	row := q.db.QueryRowContext(ctx, getTransferForUpdate, id)
+	var i Transfer
+	err := row.Scan(
+		&i.ID,
+		&i.FromAccountID,
+		&i.ToAccountID,
+		&i.Amount,
+		&i.CreatedAt,
+		&i.ToAmount,
+		&i.ExchangeRate,
+		&i.ReversalOf,
 	)
 	return i, err
 }
 
+const listTransferReversals = `-- name: ListTransferReversals :many
+SELECT id, from_account_id, to_account_id, amount, created_at, to_amount, exchange_rate, reversal_of FROM transfers
+WHERE reversal_of = $1
+ORDER BY id
+`
+
+func (q *Queries) ListTransferReversals(ctx context.Context, reversalOf sql.NullInt64) ([]Transfer, error) {
+	rows, err := q.db.QueryContext(ctx, listTransferReversals, reversalOf)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Transfer{}
+	for rows.Next() {
+		var i Transfer
+		if err := rows.Scan(
+			&i.ID,
+			&i.FromAccountID,
+			&i.ToAccountID,
+			&i.Amount,
+			&i.CreatedAt,
+			&i.ToAmount,
+			&i.ExchangeRate,
+			&i.ReversalOf,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listTransfers = `-- name: ListTransfers :many
-SELECT id, from_account_id, to_account_id, amount, created_at FROM transfers
-WHERE 
+SELECT id, from_account_id, to_account_id, amount, created_at, to_amount, exchange_rate, reversal_of FROM transfers
+WHERE
     from_account_id = $1 OR
-    to_account_id = $2
+    to_account_id = $1
 ORDER BY id
-LIMIT $3
-OFFSET $4
+LIMIT $2
+OFFSET $3
 `
 
 type ListTransfersParams struct {
-	FromAccountID int64 `json:"fromAccountID"`
-	ToAccountID   int64 `json:"toAccountID"`
-	Limit         int32 `json:"limit"`
-	Offset        int32 `json:"offset"`
+	AccountID   int64 `json:"accountID"`
+	LimitCount  int32 `json:"limitCount"`
+	OffsetCount int32 `json:"offsetCount"`
 }
 
 func (q *Queries) ListTransfers(ctx context.Context, arg ListTransfersParams) ([]Transfer, error) {
-	rows, err := q.db.QueryContext(ctx, listTransfers,
-		arg.FromAccountID,
-		arg.ToAccountID,
-		arg.Limit,
-		arg.Offset,
-	)
+	rows, err := q.db.QueryContext(ctx, listTransfers, arg.AccountID, arg.LimitCount, arg.OffsetCount)
 	if err != nil {
 		return nil, err
 	}
@@ -93,6 +255,181 @@ func (q *Queries) ListTransfers(ctx context.Context, arg ListTransfersParams) ([
 			&i.ToAccountID,
 			&i.Amount,
 			&i.CreatedAt,
+			&i.ToAmount,
+			&i.ExchangeRate,
+			&i.ReversalOf,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTransfersAfter = `-- name: ListTransfersAfter :many
+SELECT
+  t.id, t.from_account_id, t.to_account_id, t.amount, t.created_at, t.to_amount, t.exchange_rate, t.reversal_of,
+  fa.currency AS from_currency,
+  ta.currency AS to_currency
+FROM transfers t
+JOIN accounts fa ON fa.id = t.from_account_id
+JOIN accounts ta ON ta.id = t.to_account_id
+WHERE
+  (t.from_account_id = $1 OR t.to_account_id = $1) AND
+  (t.created_at, t.id) > ($2::timestamptz, $3::bigint)
+ORDER BY t.created_at, t.id
+LIMIT $4
+`
+
+type ListTransfersAfterParams struct {
+	AccountID      int64     `json:"accountID"`
+	AfterCreatedAt time.Time `json:"afterCreatedAt"`
+	AfterID        int64     `json:"afterID"`
+	LimitCount     int32     `json:"limitCount"`
+}
+
+type ListTransfersAfterRow struct {
+	ID            int64         `json:"id"`
+	FromAccountID int64         `json:"fromAccountID"`
+	ToAccountID   int64         `json:"toAccountID"`
+	Amount        int64         `json:"amount"`
+	CreatedAt     time.Time     `json:"createdAt"`
+	ToAmount      int64         `json:"toAmount"`
+	ExchangeRate  string        `json:"exchangeRate"`
+	ReversalOf    sql.NullInt64 `json:"reversalOf"`
+	FromCurrency  string        `json:"fromCurrency"`
+	ToCurrency    string        `json:"toCurrency"`
+}
+
+func (q *Queries) ListTransfersAfter(ctx context.Context, arg ListTransfersAfterParams) ([]ListTransfersAfterRow, error) {
+	rows, err := q.db.QueryContext(ctx, listTransfersAfter,
+		arg.AccountID,
+		arg.AfterCreatedAt,
+		arg.AfterID,
+		arg.LimitCount,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListTransfersAfterRow{}
+	for rows.Next() {
+		var i ListTransfersAfterRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.FromAccountID,
+			&i.ToAccountID,
+			&i.Amount,
+			&i.CreatedAt,
+			&i.ToAmount,
+			&i.ExchangeRate,
+			&i.ReversalOf,
+			&i.FromCurrency,
+			&i.ToCurrency,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listUserTransfers = `-- name: ListUserTransfers :many
+SELECT
+  t.id, t.from_account_id, t.to_account_id, t.amount, t.created_at, t.to_amount, t.exchange_rate, t.reversal_of,
+  fa.currency AS from_currency,
+  ta.currency AS to_currency
+FROM transfers t
+JOIN accounts fa ON fa.id = t.from_account_id
+JOIN accounts ta ON ta.id = t.to_account_id
+WHERE
+  (
+    ($1::boolean AND fa.owner = $2 AND ($3::bigint IS NULL OR fa.id = $3)) OR
+    ($4::boolean AND ta.owner = $2 AND ($3::bigint IS NULL OR ta.id = $3))
+  )
+  AND ($5::varchar IS NULL OR fa.currency = $5 OR ta.currency = $5)
+  AND ($6::bigint IS NULL OR (CASE WHEN fa.currency = $5 THEN t.amount ELSE t.to_amount END) >= $6)
+  AND ($7::bigint IS NULL OR (CASE WHEN fa.currency = $5 THEN t.amount ELSE t.to_amount END) <= $7)
+  AND ($8::timestamptz IS NULL OR t.created_at >= $8)
+  AND ($9::timestamptz IS NULL OR t.created_at < $9)
+  AND (t.created_at, t.id) > ($10::timestamptz, $11::bigint)
+ORDER BY t.created_at, t.id
+LIMIT $12
+`
+
+type ListUserTransfersParams struct {
+	Outgoing       bool           `json:"outgoing"`
+	Owner          string         `json:"owner"`
+	AccountID      sql.NullInt64  `json:"accountID"`
+	Incoming       bool           `json:"incoming"`
+	Currency       sql.NullString `json:"currency"`
+	MinAmount      sql.NullInt64  `json:"minAmount"`
+	MaxAmount      sql.NullInt64  `json:"maxAmount"`
+	FromTime       sql.NullTime   `json:"fromTime"`
+	ToTime         sql.NullTime   `json:"toTime"`
+	AfterCreatedAt time.Time      `json:"afterCreatedAt"`
+	AfterID        int64          `json:"afterID"`
+	LimitCount     int32          `json:"limitCount"`
+}
+
+type ListUserTransfersRow struct {
+	ID            int64         `json:"id"`
+	FromAccountID int64         `json:"fromAccountID"`
+	ToAccountID   int64         `json:"toAccountID"`
+	Amount        int64         `json:"amount"`
+	CreatedAt     time.Time     `json:"createdAt"`
+	ToAmount      int64         `json:"toAmount"`
+	ExchangeRate  string        `json:"exchangeRate"`
+	ReversalOf    sql.NullInt64 `json:"reversalOf"`
+	FromCurrency  string        `json:"fromCurrency"`
+	ToCurrency    string        `json:"toCurrency"`
+}
+
+func (q *Queries) ListUserTransfers(ctx context.Context, arg ListUserTransfersParams) ([]ListUserTransfersRow, error) {
+	rows, err := q.db.QueryContext(ctx, listUserTransfers,
+		arg.Outgoing,
+		arg.Owner,
+		arg.AccountID,
+		arg.Incoming,
+		arg.Currency,
+		arg.MinAmount,
+		arg.MaxAmount,
+		arg.FromTime,
+		arg.ToTime,
+		arg.AfterCreatedAt,
+		arg.AfterID,
+		arg.LimitCount,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListUserTransfersRow{}
+	for rows.Next() {
+		var i ListUserTransfersRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.FromAccountID,
+			&i.ToAccountID,
+			&i.Amount,
+			&i.CreatedAt,
+			&i.ToAmount,
+			&i.ExchangeRate,
+			&i.ReversalOf,
+			&i.FromCurrency,
+			&i.ToCurrency,
 		); err != nil {
 			return nil, err
 		}
@@ -0,0 +1,131 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+var ErrTransferLimitExceeded = errors.New("transfer limit exceeded")
+
+const (
+	transferLimitDay   = 24 * time.Hour
+	transferLimitMonth = 30 * transferLimitDay
+)
+
+type TransferLimitWindow struct {
+	Limit sql.NullInt64 `json:"limit"`
+	Used  int64         `json:"used"`
+	// Remaining is null when the window has no limit.
+	Remaining sql.NullInt64 `json:"remaining"`
+}
+
+// TransferLimitHeadroom is how much more a user can transfer out of their
+// accounts of a currency right now.
+type TransferLimitHeadroom struct {
+	Currency       string              `json:"currency"`
+	PerTransaction sql.NullInt64       `json:"perTransaction"`
+	Daily          TransferLimitWindow `json:"daily"`
+	Monthly        TransferLimitWindow `json:"monthly"`
+}
+
+// GetTransferLimitHeadroom returns the headroom left by every limit set for the
+// user, by currency. Currencies without limits aren't included.
+func (store *SQLStore) GetTransferLimitHeadroom(ctx context.Context, username string) ([]TransferLimitHeadroom, error) {
+	limits, err := store.ListTransferLimits(ctx, username)
+	if err != nil {
+		return nil, err
+	}
+
+	headroom := make([]TransferLimitHeadroom, 0, len(limits))
+	for _, limit := range limits {
+		h, err := transferLimitHeadroom(ctx, store.Queries, limit, time.Now())
+		if err != nil {
+			return nil, err
+		}
+		headroom = append(headroom, h)
+	}
+
+	return headroom, nil
+}
+
+// transferLimitHeadroom sums what the user transferred out of their accounts
+// of the limit's currency over the rolling windows ending at now. Fees and
+// reversals don't count towards the limits.
+func transferLimitHeadroom(ctx context.Context, q *Queries, limit TransferLimit, now time.Time) (TransferLimitHeadroom, error) {
+	used, err := q.GetTransferredAmounts(ctx, GetTransferredAmountsParams{
+		DayStart:   now.Add(-transferLimitDay),
+		Owner:      limit.Username,
+		Currency:   limit.Currency,
+		MonthStart: now.Add(-transferLimitMonth),
+	})
+	if err != nil {
+		return TransferLimitHeadroom{}, err
+	}
+
+	return TransferLimitHeadroom{
+		Currency:       limit.Currency,
+		PerTransaction: limit.PerTransaction,
+		Daily:          transferLimitWindow(limit.Daily, used.Daily),
+		Monthly:        transferLimitWindow(limit.Monthly, used.Monthly),
+	}, nil
+}
+
+func transferLimitWindow(limit sql.NullInt64, used int64) TransferLimitWindow {
+	window := TransferLimitWindow{Limit: limit, Used: used}
+	if limit.Valid {
+		// a limit lowered below what was already used leaves nothing
+		remaining := limit.Int64 - used
+		if remaining < 0 {
+			remaining = 0
+		}
+		window.Remaining = sql.NullInt64{Int64: remaining, Valid: true}
+	}
+
+	return window
+}
+
+// checkTransferLimit checks that the owner of the source account can transfer
+// amount more of its currency. The limit row is locked, so concurrent
+// transfers of the same user and currency are checked one after another
+// against each other's transfers. Users without limits aren't limited.
+func checkTransferLimit(ctx context.Context, q *Queries, fromAccount Account, amount int64) error {
+	limit, err := q.GetTransferLimitForUpdate(ctx, GetTransferLimitForUpdateParams{
+		Username: fromAccount.Owner,
+		Currency: fromAccount.Currency,
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil
+		}
+		return err
+	}
+
+	if limit.PerTransaction.Valid && amount > limit.PerTransaction.Int64 {
+		return fmt.Errorf("%w: the per-transaction limit is %d %s", ErrTransferLimitExceeded, limit.PerTransaction.Int64, limit.Currency)
+	}
+	if !limit.Daily.Valid && !limit.Monthly.Valid {
+		return nil
+	}
+
+	headroom, err := transferLimitHeadroom(ctx, q, limit, time.Now())
+	if err != nil {
+		return err
+	}
+
+	for _, w := range []struct {
+		name   string
+		window TransferLimitWindow
+	}{
+		{"daily", headroom.Daily},
+		{"monthly", headroom.Monthly},
+	} {
+		if w.window.Remaining.Valid && amount > w.window.Remaining.Int64 {
+			return fmt.Errorf("%w: %d %s left of the %s limit of %d", ErrTransferLimitExceeded, w.window.Remaining.Int64, limit.Currency, w.name, w.window.Limit.Int64)
+		}
+	}
+
+	return nil
+}
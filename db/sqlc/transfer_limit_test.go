@@ -0,0 +1,172 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func setTransferLimit(t *testing.T, account Account, perTransaction, daily, monthly sql.NullInt64) TransferLimit {
+	limit, err := testQueries.UpsertTransferLimit(context.Background(), UpsertTransferLimitParams{
+		Username:       account.Owner,
+		Currency:       account.Currency,
+		PerTransaction: perTransaction,
+		Daily:          daily,
+		Monthly:        monthly,
+	})
+	require.NoError(t, err)
+	require.Equal(t, account.Owner, limit.Username)
+
+	return limit
+}
+
+func TestTransferTxPerTransactionLimit(t *testing.T) {
+	store := NewStore(testDb)
+
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccountWithCurrency(t, account1.Currency)
+	setTransferLimit(t, account1, sql.NullInt64{Int64: 10, Valid: true}, sql.NullInt64{}, sql.NullInt64{})
+
+	_, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        11,
+	})
+	require.ErrorIs(t, err, ErrTransferLimitExceeded)
+
+	_, err = store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        10,
+	})
+	require.NoError(t, err)
+
+	// limits are per user, the receiver isn't limited
+	_, err = store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account2.ID,
+		ToAccountID:   account1.ID,
+		Amount:        50,
+	})
+	require.NoError(t, err)
+}
+
+func TestTransferTxDailyLimit(t *testing.T) {
+	store := NewStore(testDb)
+
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccountWithCurrency(t, account1.Currency)
+	setTransferLimit(t, account1, sql.NullInt64{}, sql.NullInt64{Int64: 50, Valid: true}, sql.NullInt64{Int64: 80, Valid: true})
+
+	for i := 0; i < 2; i++ {
+		_, err := store.TransferTx(context.Background(), TransferTxParams{
+			FromAccountID: account1.ID,
+			ToAccountID:   account2.ID,
+			Amount:        20,
+		})
+		require.NoError(t, err)
+	}
+
+	_, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        11,
+	})
+	require.ErrorIs(t, err, ErrTransferLimitExceeded)
+
+	headroom, err := store.GetTransferLimitHeadroom(context.Background(), account1.Owner)
+	require.NoError(t, err)
+	require.Len(t, headroom, 1)
+	require.Equal(t, account1.Currency, headroom[0].Currency)
+	require.False(t, headroom[0].PerTransaction.Valid)
+	require.Equal(t, int64(40), headroom[0].Daily.Used)
+	require.Equal(t, sql.NullInt64{Int64: 10, Valid: true}, headroom[0].Daily.Remaining)
+	require.Equal(t, int64(40), headroom[0].Monthly.Used)
+	require.Equal(t, sql.NullInt64{Int64: 40, Valid: true}, headroom[0].Monthly.Remaining)
+
+	// lowering a limit below what was used leaves nothing
+	setTransferLimit(t, account1, sql.NullInt64{}, sql.NullInt64{Int64: 30, Valid: true}, sql.NullInt64{})
+
+	headroom, err = store.GetTransferLimitHeadroom(context.Background(), account1.Owner)
+	require.NoError(t, err)
+	require.Len(t, headroom, 1)
+	require.Equal(t, sql.NullInt64{Int64: 0, Valid: true}, headroom[0].Daily.Remaining)
+	require.False(t, headroom[0].Monthly.Remaining.Valid)
+}
+
+func TestTransferTxLimitExcludesFees(t *testing.T) {
+	store := NewStore(testDb)
+
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccountWithCurrency(t, account1.Currency)
+	createRandomFeeSchedule(t, account1.Currency, CreateFeeScheduleParams{FlatAmount: 5, Percentage: "0"})
+	setTransferLimit(t, account1, sql.NullInt64{}, sql.NullInt64{Int64: 50, Valid: true}, sql.NullInt64{})
+
+	for i := 0; i < 2; i++ {
+		_, err := store.TransferTx(context.Background(), TransferTxParams{
+			FromAccountID: account1.ID,
+			ToAccountID:   account2.ID,
+			Amount:        25,
+		})
+		require.NoError(t, err)
+	}
+
+	headroom, err := store.GetTransferLimitHeadroom(context.Background(), account1.Owner)
+	require.NoError(t, err)
+	require.Len(t, headroom, 1)
+	require.Equal(t, int64(50), headroom[0].Daily.Used)
+}
+
+func TestBatchTransferTxLimit(t *testing.T) {
+	store := NewStore(testDb)
+
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccountWithCurrency(t, account1.Currency)
+	account3 := createRandomAccountWithCurrency(t, account1.Currency)
+	setTransferLimit(t, account1, sql.NullInt64{}, sql.NullInt64{Int64: 30, Valid: true}, sql.NullInt64{})
+
+	// each leg is within the limit, both together aren't
+	_, err := store.BatchTransferTx(context.Background(), BatchTransferTxParams{
+		Transfers: []TransferTxParams{
+			{FromAccountID: account1.ID, ToAccountID: account2.ID, Amount: 20},
+			{FromAccountID: account1.ID, ToAccountID: account3.ID, Amount: 20},
+		},
+	})
+	require.ErrorIs(t, err, ErrTransferLimitExceeded)
+
+	updatedAccount1, err := store.GetAccount(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Equal(t, account1.Balance, updatedAccount1.Balance)
+}
+
+func TestHoldTxLimit(t *testing.T) {
+	store := NewStore(testDb)
+
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccountWithCurrency(t, account1.Currency)
+	setTransferLimit(t, account1, sql.NullInt64{Int64: 20, Valid: true}, sql.NullInt64{Int64: 30, Valid: true}, sql.NullInt64{})
+
+	_, err := store.CreateHoldTx(context.Background(), CreateHoldTxParams{
+		AccountID:   account1.ID,
+		ToAccountID: account2.ID,
+		Amount:      21,
+		ExpiresAt:   time.Now().Add(time.Hour),
+	})
+	require.ErrorIs(t, err, ErrTransferLimitExceeded)
+
+	// each hold fits the daily limit, both captures together don't
+	first := createRandomHold(t, store, account1, account2, 20, time.Now().Add(time.Hour)).Hold
+	second := createRandomHold(t, store, account1, account2, 20, time.Now().Add(time.Hour)).Hold
+
+	_, err = store.CaptureHoldTx(context.Background(), CaptureHoldTxParams{HoldID: first.ID})
+	require.NoError(t, err)
+
+	_, err = store.CaptureHoldTx(context.Background(), CaptureHoldTxParams{HoldID: second.ID})
+	require.ErrorIs(t, err, ErrTransferLimitExceeded)
+
+	hold, err := store.GetAccountHold(context.Background(), second.ID)
+	require.NoError(t, err)
+	require.Equal(t, HoldActive, hold.Status)
+}
@@ -2,6 +2,7 @@ package db
 
 import (
 	"context"
+	"database/sql"
 	"testing"
 
 	"github.com/crackz/simple-bank/util"
@@ -22,6 +23,7 @@ func createRandomTransfer(t *testing.T, from_account *Account, to_account *Accou
 	require.Equal(t, transfer.FromAccountID, arg.FromAccountID)
 	require.Equal(t, transfer.ToAccountID, arg.ToAccountID)
 	require.Equal(t, transfer.Amount, arg.Amount)
+	require.Equal(t, transfer.ToAmount, arg.Amount)
 	require.NotZero(t, transfer.CreatedAt)
 
 	return transfer
@@ -58,10 +60,9 @@ func TestListTransfers(t *testing.T) {
 	}
 
 	arg := ListTransfersParams{
-		FromAccountID: from_account.ID,
-		ToAccountID:   to_account.ID,
-		Limit:         5,
-		Offset:        5,
+		AccountID:   to_account.ID,
+		LimitCount:  5,
+		OffsetCount: 5,
 	}
 
 	transfers, err := testQueries.ListTransfers(context.Background(), arg)
@@ -73,3 +74,90 @@ func TestListTransfers(t *testing.T) {
 	}
 
 }
+
+func TestListTransfersAfter(t *testing.T) {
+	account := createRandomAccount(t)
+	other := createRandomAccount(t)
+
+	outgoing := createRandomTransfer(t, &account, &other)
+	incoming := createRandomTransfer(t, &other, &account)
+	createRandomTransfer(t, &other, &other)
+
+	transfers, err := testQueries.ListTransfersAfter(context.Background(), ListTransfersAfterParams{
+		AccountID:  account.ID,
+		LimitCount: 10,
+	})
+	require.NoError(t, err)
+	require.Len(t, transfers, 2)
+	require.Equal(t, outgoing.ID, transfers[0].ID)
+	require.Equal(t, account.Currency, transfers[0].FromCurrency)
+	require.Equal(t, other.Currency, transfers[0].ToCurrency)
+	require.Equal(t, incoming.ID, transfers[1].ID)
+
+	transfers, err = testQueries.ListTransfersAfter(context.Background(), ListTransfersAfterParams{
+		AccountID:      account.ID,
+		AfterCreatedAt: transfers[0].CreatedAt,
+		AfterID:        transfers[0].ID,
+		LimitCount:     10,
+	})
+	require.NoError(t, err)
+	require.Len(t, transfers, 1)
+	require.Equal(t, incoming.ID, transfers[0].ID)
+}
+
+func TestListUserTransfers(t *testing.T) {
+	user := createRandomUser(t)
+
+	usd, err := testQueries.CreateAccount(context.Background(), CreateAccountParams{Owner: user.Username, Currency: util.USD})
+	require.NoError(t, err)
+	cad, err := testQueries.CreateAccount(context.Background(), CreateAccountParams{Owner: user.Username, Currency: util.CAD})
+	require.NoError(t, err)
+	other := createRandomAccountWithCurrency(t, util.USD)
+
+	outgoing := createRandomTransfer(t, &usd, &other)
+	incoming := createRandomTransfer(t, &other, &usd)
+	own := createRandomTransfer(t, &cad, &usd)
+	createRandomTransfer(t, &other, &other)
+
+	listIDs := func(arg ListUserTransfersParams) []int64 {
+		arg.Owner = user.Username
+		arg.LimitCount = 10
+
+		transfers, err := testQueries.ListUserTransfers(context.Background(), arg)
+		require.NoError(t, err)
+
+		ids := []int64{}
+		for _, transfer := range transfers {
+			ids = append(ids, transfer.ID)
+		}
+		return ids
+	}
+
+	require.Equal(t, []int64{outgoing.ID, incoming.ID, own.ID}, listIDs(ListUserTransfersParams{Outgoing: true, Incoming: true}))
+	require.Equal(t, []int64{outgoing.ID, own.ID}, listIDs(ListUserTransfersParams{Outgoing: true}))
+	require.Equal(t, []int64{incoming.ID, own.ID}, listIDs(ListUserTransfersParams{Incoming: true}))
+	require.Equal(t, []int64{own.ID}, listIDs(ListUserTransfersParams{
+		Outgoing:  true,
+		Incoming:  true,
+		AccountID: sql.NullInt64{Int64: cad.ID, Valid: true},
+	}))
+	require.Equal(t, []int64{own.ID}, listIDs(ListUserTransfersParams{
+		Outgoing: true,
+		Incoming: true,
+		Currency: sql.NullString{String: util.CAD, Valid: true},
+	}))
+	require.Equal(t, []int64{outgoing.ID}, listIDs(ListUserTransfersParams{
+		Outgoing:  true,
+		Incoming:  true,
+		Currency:  sql.NullString{String: util.USD, Valid: true},
+		MinAmount: sql.NullInt64{Int64: outgoing.Amount, Valid: true},
+		MaxAmount: sql.NullInt64{Int64: outgoing.Amount, Valid: true},
+		ToTime:    sql.NullTime{Time: incoming.CreatedAt, Valid: true},
+	}))
+	require.Equal(t, []int64{incoming.ID, own.ID}, listIDs(ListUserTransfersParams{
+		Outgoing:       true,
+		Incoming:       true,
+		AfterCreatedAt: outgoing.CreatedAt,
+		AfterID:        outgoing.ID,
+	}))
+}
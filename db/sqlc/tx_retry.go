@@ -0,0 +1,89 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"expvar"
+	"math/rand"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+const (
+	serializationFailure = "40001"
+	deadlockDetected     = "40P01"
+)
+
+var (
+	// txRetries counts retried transactions by SQLSTATE and txRetriesExhausted
+	// the ones that still failed on their last attempt. Both are served by the
+	// expvar handler.
+	txRetries          = expvar.NewMap("db_tx_retries")
+	txRetriesExhausted = expvar.NewMap("db_tx_retries_exhausted")
+)
+
+// RetryPolicy bounds how often and how fast a transaction that lost a
+// serialization failure or deadlock is retried.
+type RetryPolicy struct {
+	// MaxAttempts includes the first attempt.
+	MaxAttempts int
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+}
+
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 5,
+	BaseDelay:   10 * time.Millisecond,
+	MaxDelay:    500 * time.Millisecond,
+}
+
+// backoff returns how long to wait after the given failed attempt. The delay
+// doubles with every attempt and a random half of it is dropped, so
+// transactions that conflicted with each other don't retry in lockstep.
+func (policy RetryPolicy) backoff(attempt int) time.Duration {
+	delay := policy.BaseDelay
+	for i := 1; i < attempt && delay < policy.MaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > policy.MaxDelay {
+		delay = policy.MaxDelay
+	}
+	if delay <= 0 {
+		return 0
+	}
+
+	half := delay / 2
+	return half + time.Duration(rand.Int63n(int64(delay-half)+1))
+}
+
+// wait sleeps for the backoff of attempt and returns early with the context's
+// error if it is done first.
+func (policy RetryPolicy) wait(ctx context.Context, attempt int) error {
+	timer := time.NewTimer(policy.backoff(attempt))
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// retryableTxError reports whether err is a serialization failure or a
+// deadlock, which Postgres resolves by aborting one of the transactions
+// involved and which succeed when run again, and returns its SQLSTATE.
+func retryableTxError(err error) (string, bool) {
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) {
+		return "", false
+	}
+
+	switch pqErr.Code {
+	case serializationFailure, deadlockDetected:
+		return string(pqErr.Code), true
+	}
+
+	return "", false
+}
@@ -0,0 +1,173 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRetryableTxError(t *testing.T) {
+	testCases := []struct {
+		name      string
+		err       error
+		code      string
+		retryable bool
+	}{
+		{
+			name:      "SerializationFailure",
+			err:       &pq.Error{Code: serializationFailure},
+			code:      serializationFailure,
+			retryable: true,
+		},
+		{
+			name:      "DeadlockDetected",
+			err:       &pq.Error{Code: deadlockDetected},
+			code:      deadlockDetected,
+			retryable: true,
+		},
+		{
+			name:      "Wrapped",
+			err:       fmt.Errorf("tx error: %w", &pq.Error{Code: deadlockDetected}),
+			code:      deadlockDetected,
+			retryable: true,
+		},
+		{
+			name: "UniqueViolation",
+			err:  &pq.Error{Code: "23505"},
+		},
+		{
+			name: "NotAPqError",
+			err:  sql.ErrNoRows,
+		},
+		{
+			name: "Nil",
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			code, retryable := retryableTxError(tc.err)
+			require.Equal(t, tc.retryable, retryable)
+			require.Equal(t, tc.code, code)
+		})
+	}
+}
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	policy := RetryPolicy{MaxAttempts: 10, BaseDelay: 10 * time.Millisecond, MaxDelay: 80 * time.Millisecond}
+
+	for attempt := 1; attempt <= 6; attempt++ {
+		delay := 10 * time.Millisecond << (attempt - 1)
+		if delay > policy.MaxDelay {
+			delay = policy.MaxDelay
+		}
+
+		for i := 0; i < 20; i++ {
+			backoff := policy.backoff(attempt)
+			require.GreaterOrEqual(t, backoff, delay/2)
+			require.LessOrEqual(t, backoff, delay)
+		}
+	}
+}
+
+func TestRetryPolicyWaitCancelled(t *testing.T) {
+	policy := RetryPolicy{MaxAttempts: 2, BaseDelay: time.Hour, MaxDelay: time.Hour}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := policy.wait(ctx, 1)
+	require.ErrorIs(t, err, context.Canceled)
+}
+
+func TestExecTxRetry(t *testing.T) {
+	retryErr := &pq.Error{Code: serializationFailure}
+
+	testCases := []struct {
+		name     string
+		failures int
+		ctx      func() context.Context
+		retry    RetryPolicy
+		attempts int
+		check    func(t *testing.T, err error)
+	}{
+		{
+			name:     "SucceedsAfterRetries",
+			failures: 2,
+			ctx:      context.Background,
+			retry:    RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: 2 * time.Millisecond},
+			attempts: 3,
+			check: func(t *testing.T, err error) {
+				require.NoError(t, err)
+			},
+		},
+		{
+			name:     "Exhausted",
+			failures: 10,
+			ctx:      context.Background,
+			retry:    RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: 2 * time.Millisecond},
+			attempts: 3,
+			check: func(t *testing.T, err error) {
+				require.ErrorIs(t, err, retryErr)
+			},
+		},
+		{
+			name:     "NotRetryable",
+			failures: 0,
+			ctx:      context.Background,
+			retry:    RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: 2 * time.Millisecond},
+			attempts: 1,
+			check: func(t *testing.T, err error) {
+				require.ErrorIs(t, err, ErrInsufficientFunds)
+			},
+		},
+		{
+			name:     "ContextDone",
+			failures: 10,
+			ctx: func() context.Context {
+				ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+				t.Cleanup(cancel)
+				return ctx
+			},
+			retry:    RetryPolicy{MaxAttempts: 3, BaseDelay: time.Hour, MaxDelay: time.Hour},
+			attempts: 1,
+			check: func(t *testing.T, err error) {
+				require.ErrorIs(t, err, retryErr)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			store := &SQLStore{
+				db:      testDb,
+				Queries: New(testDb),
+				retry:   tc.retry,
+			}
+
+			attempts := 0
+			err := store.execTxWithOptions(tc.ctx(), &sql.TxOptions{Isolation: sql.LevelSerializable}, func(q *Queries) error {
+				attempts++
+				if attempts <= tc.failures {
+					return retryErr
+				}
+				if tc.failures == 0 {
+					return ErrInsufficientFunds
+				}
+				return nil
+			})
+
+			tc.check(t, err)
+			require.Equal(t, tc.attempts, attempts)
+		})
+	}
+}
@@ -26,6 +26,7 @@ func createRandomUser(t *testing.T) User {
 	require.Equal(t, arg.FullName, user.FullName)
 	require.Equal(t, arg.Email, user.Email)
 	require.Equal(t, arg.HashedPassword, user.HashedPassword)
+	require.Equal(t, util.CustomerRole, user.Role)
 
 	require.NotZero(t, user.CreatedAt)
 	require.NotZero(t, user.PasswordChangedAt)
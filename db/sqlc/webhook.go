@@ -0,0 +1,53 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+const (
+	WebhookDeliveryPending   = "pending"
+	WebhookDeliveryDelivered = "delivered"
+	WebhookDeliveryDead      = "dead"
+)
+
+type RecordWebhookAttemptTxParams struct {
+	Delivery   WebhookDelivery
+	StatusCode sql.NullInt32
+	Error      sql.NullString
+	// Status and NextAttemptAt are what the delivery moves to after the attempt.
+	Status        string
+	NextAttemptAt time.Time
+}
+
+// RecordWebhookAttemptTx logs an attempt to deliver a webhook and moves the
+// delivery on to its next state.
+func (store *SQLStore) RecordWebhookAttemptTx(ctx context.Context, arg RecordWebhookAttemptTxParams) (WebhookDelivery, error) {
+	var delivery WebhookDelivery
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+
+		delivery, err = q.UpdateWebhookDeliveryAttempt(ctx, UpdateWebhookDeliveryAttemptParams{
+			ID:            arg.Delivery.ID,
+			Status:        arg.Status,
+			NextAttemptAt: arg.NextAttemptAt,
+			LastError:     arg.Error,
+		})
+		if err != nil {
+			return err
+		}
+
+		_, err = q.CreateWebhookDeliveryAttempt(ctx, CreateWebhookDeliveryAttemptParams{
+			DeliveryID: delivery.ID,
+			WebhookID:  delivery.WebhookID,
+			Attempt:    delivery.Attempts,
+			StatusCode: arg.StatusCode,
+			Error:      arg.Error,
+		})
+		return err
+	})
+
+	return delivery, err
+}
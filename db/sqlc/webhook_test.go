@@ -0,0 +1,126 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomWebhookEndpoint(t *testing.T, owner string, eventTypes []string) WebhookEndpoint {
+	arg := CreateWebhookEndpointParams{
+		Owner:      owner,
+		Url:        "https://example.com/hooks",
+		Secret:     "whsec_test",
+		EventTypes: eventTypes,
+	}
+
+	endpoint, err := testQueries.CreateWebhookEndpoint(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, arg.Owner, endpoint.Owner)
+	require.Equal(t, arg.Url, endpoint.Url)
+	require.Equal(t, arg.EventTypes, endpoint.EventTypes)
+	require.True(t, endpoint.Active)
+
+	return endpoint
+}
+
+func TestWebhookDeliveries(t *testing.T) {
+	store := NewStore(testDb)
+	owner := createRandomUser(t).Username
+
+	all := createRandomWebhookEndpoint(t, owner, []string{})
+	filtered := createRandomWebhookEndpoint(t, owner, []string{"transfer.sent"})
+	disabled := createRandomWebhookEndpoint(t, owner, []string{})
+	_, err := testQueries.UpdateWebhookEndpoint(context.Background(), UpdateWebhookEndpointParams{
+		ID:     disabled.ID,
+		Active: sql.NullBool{Bool: false, Valid: true},
+	})
+	require.NoError(t, err)
+
+	event, err := testQueries.CreateOutboxEvent(context.Background(), CreateOutboxEventParams{
+		AggregateType: AggregateTransfer,
+		AggregateID:   "1",
+		EventType:     EventTransferCreated,
+		Payload:       json.RawMessage(`{"transferID":1}`),
+	})
+	require.NoError(t, err)
+
+	arg := EnqueueWebhookDeliveriesParams{
+		EventID:   event.ID,
+		EventType: "transfer.received",
+		Payload:   event.Payload,
+		Owner:     owner,
+	}
+
+	// only the active endpoint subscribed to every event gets it, once
+	for i := 0; i < 2; i++ {
+		enqueued, err := testQueries.EnqueueWebhookDeliveries(context.Background(), arg)
+		require.NoError(t, err)
+		require.Equal(t, int64(1-i), enqueued)
+	}
+
+	for _, endpoint := range []WebhookEndpoint{filtered, disabled} {
+		deliveries, err := testQueries.ListWebhookDeliveries(context.Background(), ListWebhookDeliveriesParams{WebhookID: endpoint.ID, Limit: 10})
+		require.NoError(t, err)
+		require.Empty(t, deliveries)
+	}
+
+	deliveries, err := testQueries.ListWebhookDeliveries(context.Background(), ListWebhookDeliveriesParams{WebhookID: all.ID, Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, deliveries, 1)
+	delivery := deliveries[0]
+	require.Equal(t, WebhookDeliveryPending, delivery.Status)
+	require.JSONEq(t, string(event.Payload), string(delivery.Payload))
+
+	failed, err := store.RecordWebhookAttemptTx(context.Background(), RecordWebhookAttemptTxParams{
+		Delivery:      delivery,
+		StatusCode:    sql.NullInt32{Int32: 500, Valid: true},
+		Error:         sql.NullString{String: "endpoint responded with 500 Internal Server Error", Valid: true},
+		Status:        WebhookDeliveryPending,
+		NextAttemptAt: time.Now().Add(-time.Second),
+	})
+	require.NoError(t, err)
+	require.Equal(t, int32(1), failed.Attempts)
+	require.False(t, failed.DeliveredAt.Valid)
+
+	// a claimed delivery is leased and not claimed again until the lease runs
+	// out, whatever other tests left pending
+	claimed, err := testQueries.ClaimDueWebhookDeliveries(context.Background(), ClaimDueWebhookDeliveriesParams{LeaseSeconds: 60, BatchSize: 1000})
+	require.NoError(t, err)
+	require.Contains(t, deliveryIDs(claimed), delivery.ID)
+
+	claimed, err = testQueries.ClaimDueWebhookDeliveries(context.Background(), ClaimDueWebhookDeliveriesParams{LeaseSeconds: 60, BatchSize: 1000})
+	require.NoError(t, err)
+	require.NotContains(t, deliveryIDs(claimed), delivery.ID)
+
+	delivered, err := store.RecordWebhookAttemptTx(context.Background(), RecordWebhookAttemptTxParams{
+		Delivery:      failed,
+		StatusCode:    sql.NullInt32{Int32: 200, Valid: true},
+		Status:        WebhookDeliveryDelivered,
+		NextAttemptAt: failed.NextAttemptAt,
+	})
+	require.NoError(t, err)
+	require.Equal(t, WebhookDeliveryDelivered, delivered.Status)
+	require.Equal(t, int32(2), delivered.Attempts)
+	require.True(t, delivered.DeliveredAt.Valid)
+
+	attempts, err := testQueries.ListWebhookDeliveryAttempts(context.Background(), ListWebhookDeliveryAttemptsParams{WebhookID: all.ID, Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, attempts, 2)
+	require.Equal(t, int32(2), attempts[0].Attempt)
+	require.Equal(t, int32(1), attempts[1].Attempt)
+	require.Equal(t, int32(500), attempts[1].StatusCode.Int32)
+}
+
+func deliveryIDs(deliveries []WebhookDelivery) []int64 {
+	ids := make([]int64, len(deliveries))
+	for i, delivery := range deliveries {
+		ids[i] = delivery.ID
+	}
+
+	return ids
+}
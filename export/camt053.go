@@ -0,0 +1,181 @@
+package export
+
+import (
+	"encoding/xml"
+	"io"
+	"strconv"
+	"time"
+
+	db "github.com/crackz/simple-bank/db/sqlc"
+)
+
+const camt053Namespace = "urn:iso:std:iso:20022:tech:xsd:camt.053.001.02"
+
+type camtAmount struct {
+	Currency string `xml:"Ccy,attr"`
+	Value    string `xml:",chardata"`
+}
+
+type camtBalance struct {
+	XMLName xml.Name   `xml:"Bal"`
+	Type    string     `xml:"Tp>CdOrPrtry>Cd"`
+	Amount  camtAmount `xml:"Amt"`
+	// CRDT or DBIT
+	CreditDebit string `xml:"CdtDbtInd"`
+	Date        string `xml:"Dt>DtTm"`
+}
+
+type camtAccount struct {
+	XMLName  xml.Name `xml:"Acct"`
+	ID       string   `xml:"Id>Othr>Id"`
+	Currency string   `xml:"Ccy"`
+	Owner    string   `xml:"Ownr>Nm"`
+}
+
+type camtBankTransactionCode struct {
+	Domain    string `xml:"Domn>Cd"`
+	Family    string `xml:"Domn>Fmly>Cd"`
+	SubFamily string `xml:"Domn>Fmly>SubFmlyCd"`
+}
+
+type camtEntry struct {
+	XMLName      xml.Name                `xml:"Ntry"`
+	Reference    string                  `xml:"NtryRef"`
+	Amount       camtAmount              `xml:"Amt"`
+	CreditDebit  string                  `xml:"CdtDbtInd"`
+	Status       string                  `xml:"Sts"`
+	BookingDate  string                  `xml:"BookgDt>DtTm"`
+	ValueDate    string                  `xml:"ValDt>DtTm"`
+	TransferCode camtBankTransactionCode `xml:"BkTxCd"`
+	// only entries of a transfer have details
+	References *camtReferences `xml:"NtryDtls>TxDtls>Refs"`
+}
+
+type camtReferences struct {
+	EndToEndID string `xml:"EndToEndId"`
+}
+
+// Camt053Writer writes an ISO 20022 camt.053.001.02 bank to customer
+// statement holding a single statement.
+type Camt053Writer struct {
+	xmlWriter
+	statement Statement
+}
+
+func NewCamt053Writer(w io.Writer) *Camt053Writer {
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	return &Camt053Writer{xmlWriter: xmlWriter{enc: enc}}
+}
+
+func (writer *Camt053Writer) WriteHeader(statement Statement) error {
+	writer.statement = statement
+	account := statement.Account
+
+	statementID := "STMT-" + strconv.FormatInt(account.ID, 10) + "-" + statement.To.UTC().Format("20060102150405")
+	createdAt := formatCamtTime(statement.CreatedAt)
+
+	document := startElement("Document")
+	document.Attr = []xml.Attr{{Name: xml.Name{Local: "xmlns"}, Value: camt053Namespace}}
+
+	tokens := []xml.Token{
+		xml.ProcInst{Target: "xml", Inst: []byte(`version="1.0" encoding="UTF-8"`)},
+		xml.CharData("\n"),
+		document,
+		startElement("BkToCstmrStmt"),
+		startElement("GrpHdr"),
+	}
+	if err := writer.encodeTokens(tokens...); err != nil {
+		return err
+	}
+	if err := writer.encodeElement("MsgId", statementID); err != nil {
+		return err
+	}
+	if err := writer.encodeElement("CreDtTm", createdAt); err != nil {
+		return err
+	}
+	if err := writer.encodeTokens(endElement("GrpHdr"), startElement("Stmt")); err != nil {
+		return err
+	}
+	if err := writer.encodeElement("Id", statementID); err != nil {
+		return err
+	}
+	if err := writer.encodeElement("CreDtTm", createdAt); err != nil {
+		return err
+	}
+
+	period := struct {
+		From string `xml:"FrDtTm"`
+		To   string `xml:"ToDtTm"`
+	}{formatCamtTime(statement.From), formatCamtTime(statement.To)}
+	if err := writer.enc.EncodeElement(period, startElement("FrToDt")); err != nil {
+		return err
+	}
+
+	camtAccount := camtAccount{
+		ID:       strconv.FormatInt(account.ID, 10),
+		Currency: account.Currency,
+		Owner:    account.Owner,
+	}
+	if err := writer.enc.Encode(camtAccount); err != nil {
+		return err
+	}
+
+	// opening booked and closing booked balances
+	if err := writer.enc.Encode(writer.balance("OPBD", statement.OpeningBalance, statement.From)); err != nil {
+		return err
+	}
+	return writer.enc.Encode(writer.balance("CLBD", statement.ClosingBalance, statement.To))
+}
+
+func (writer *Camt053Writer) WriteEntry(entry db.StatementEntry) error {
+	amount, credit := formatAmount(entry.Amount, writer.statement.Account.Currency)
+	bookedAt := formatCamtTime(entry.CreatedAt)
+
+	camtEntry := camtEntry{
+		Reference:   strconv.FormatInt(entry.ID, 10),
+		Amount:      camtAmount{Currency: writer.statement.Account.Currency, Value: amount},
+		CreditDebit: creditDebit(credit),
+		Status:      "BOOK",
+		BookingDate: bookedAt,
+		ValueDate:   bookedAt,
+		// payments, book transfer within the bank
+		TransferCode: camtBankTransactionCode{Domain: "PMNT", Family: "ICDT", SubFamily: "BOOK"},
+	}
+	if entry.TransferID.Valid {
+		camtEntry.References = &camtReferences{EndToEndID: formatNullID(entry.TransferID)}
+	}
+	if credit {
+		camtEntry.TransferCode.Family = "RCDT"
+	}
+
+	return writer.enc.Encode(camtEntry)
+}
+
+func (writer *Camt053Writer) Close() error {
+	return writer.encodeTokens(endElement("Stmt"), endElement("BkToCstmrStmt"), endElement("Document"))
+}
+
+func (writer *Camt053Writer) balance(balanceType string, amount int64, at time.Time) camtBalance {
+	value, credit := formatAmount(amount, writer.statement.Account.Currency)
+
+	return camtBalance{
+		Type:        balanceType,
+		Amount:      camtAmount{Currency: writer.statement.Account.Currency, Value: value},
+		CreditDebit: creditDebit(credit),
+		Date:        formatCamtTime(at),
+	}
+}
+
+func creditDebit(credit bool) string {
+	if credit {
+		return "CRDT"
+	}
+
+	return "DBIT"
+}
+
+// formatCamtTime formats t as an ISO 8601 date time in UTC.
+func formatCamtTime(t time.Time) string {
+	return t.UTC().Format("2006-01-02T15:04:05Z")
+}
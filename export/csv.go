@@ -0,0 +1,46 @@
+package export
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"time"
+
+	db "github.com/crackz/simple-bank/db/sqlc"
+	"github.com/crackz/simple-bank/money"
+)
+
+var csvHeader = []string{"date", "entryID", "transferID", "counterpartyAccountID", "amount", "currency", "balance"}
+
+// CSVWriter writes one row per entry, with signed decimal amounts and the
+// running balance after the entry.
+type CSVWriter struct {
+	w        *csv.Writer
+	currency string
+}
+
+func NewCSVWriter(w io.Writer) *CSVWriter {
+	return &CSVWriter{w: csv.NewWriter(w)}
+}
+
+func (writer *CSVWriter) WriteHeader(statement Statement) error {
+	writer.currency = statement.Account.Currency
+	return writer.w.Write(csvHeader)
+}
+
+func (writer *CSVWriter) WriteEntry(entry db.StatementEntry) error {
+	return writer.w.Write([]string{
+		entry.CreatedAt.UTC().Format(time.RFC3339),
+		strconv.FormatInt(entry.ID, 10),
+		formatNullID(entry.TransferID),
+		formatNullID(entry.CounterpartyAccountID),
+		money.New(entry.Amount, writer.currency).String(),
+		writer.currency,
+		money.New(entry.Balance, writer.currency).String(),
+	})
+}
+
+func (writer *CSVWriter) Close() error {
+	writer.w.Flush()
+	return writer.w.Error()
+}
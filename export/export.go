@@ -0,0 +1,88 @@
+// Package export renders account statements in formats other tools import:
+// CSV, OFX 2.x and ISO 20022 camt.053. Entries are written as they come so a
+// statement never has to be held in memory.
+package export
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	db "github.com/crackz/simple-bank/db/sqlc"
+	"github.com/crackz/simple-bank/money"
+)
+
+var ErrUnknownFormat = errors.New("unknown export format")
+
+// Format is a statement format, named after its file extension.
+type Format string
+
+const (
+	CSV     Format = "csv"
+	OFX     Format = "ofx"
+	Camt053 Format = "xml"
+)
+
+func (format Format) ContentType() string {
+	switch format {
+	case CSV:
+		return "text/csv; charset=utf-8"
+	case OFX:
+		return "application/x-ofx"
+	case Camt053:
+		return "application/xml; charset=utf-8"
+	}
+
+	return "application/octet-stream"
+}
+
+// Statement is everything about a statement but its entries.
+type Statement struct {
+	Account        db.Account
+	From           time.Time
+	To             time.Time
+	OpeningBalance int64
+	ClosingBalance int64
+	// CreatedAt is when the statement was generated.
+	CreatedAt time.Time
+}
+
+// Writer writes one statement: WriteHeader once, WriteEntry for each entry in
+// order, then Close to finish the document. Close doesn't close the
+// underlying writer.
+type Writer interface {
+	WriteHeader(statement Statement) error
+	WriteEntry(entry db.StatementEntry) error
+	Close() error
+}
+
+func NewWriter(format Format, w io.Writer) (Writer, error) {
+	switch format {
+	case CSV:
+		return NewCSVWriter(w), nil
+	case OFX:
+		return NewOFXWriter(w), nil
+	case Camt053:
+		return NewCamt053Writer(w), nil
+	}
+
+	return nil, fmt.Errorf("%w: %q", ErrUnknownFormat, format)
+}
+
+// formatAmount returns the decimal amount without its sign and whether it's
+// a credit, which is how OFX and camt.053 show debits and credits apart.
+func formatAmount(amount int64, currency string) (decimal string, credit bool) {
+	return strings.TrimPrefix(money.New(amount, currency).String(), "-"), amount >= 0
+}
+
+func formatNullID(id sql.NullInt64) string {
+	if !id.Valid {
+		return ""
+	}
+
+	return strconv.FormatInt(id.Int64, 10)
+}
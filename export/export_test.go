@@ -0,0 +1,160 @@
+package export
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/csv"
+	"encoding/xml"
+	"strings"
+	"testing"
+	"time"
+
+	db "github.com/crackz/simple-bank/db/sqlc"
+	"github.com/crackz/simple-bank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func testStatement() (Statement, []db.StatementEntry) {
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	statement := Statement{
+		Account:        db.Account{ID: 42, Owner: "alice", Currency: util.USD},
+		From:           from,
+		To:             from.AddDate(0, 1, 0),
+		OpeningBalance: 10000,
+		ClosingBalance: 10250,
+		CreatedAt:      from.AddDate(0, 1, 1),
+	}
+
+	entries := []db.StatementEntry{
+		{
+			Entry: db.Entry{
+				ID:         1,
+				AccountID:  42,
+				Amount:     -1250,
+				CreatedAt:  from.Add(time.Hour),
+				TransferID: sql.NullInt64{Int64: 7, Valid: true},
+			},
+			CounterpartyAccountID: sql.NullInt64{Int64: 43, Valid: true},
+			Balance:               8750,
+		},
+		{
+			Entry: db.Entry{
+				ID:        2,
+				AccountID: 42,
+				Amount:    1500,
+				CreatedAt: from.Add(2 * time.Hour),
+			},
+			Balance: 10250,
+		},
+	}
+
+	return statement, entries
+}
+
+func writeStatement(t *testing.T, format Format) []byte {
+	var buf bytes.Buffer
+	writer, err := NewWriter(format, &buf)
+	require.NoError(t, err)
+
+	statement, entries := testStatement()
+	require.NoError(t, writer.WriteHeader(statement))
+	for _, entry := range entries {
+		require.NoError(t, writer.WriteEntry(entry))
+	}
+	require.NoError(t, writer.Close())
+
+	return buf.Bytes()
+}
+
+func TestCSV(t *testing.T) {
+	records, err := csv.NewReader(bytes.NewReader(writeStatement(t, CSV))).ReadAll()
+	require.NoError(t, err)
+
+	require.Equal(t, [][]string{
+		csvHeader,
+		{"2024-01-01T01:00:00Z", "1", "7", "43", "-12.50", "USD", "87.50"},
+		{"2024-01-01T02:00:00Z", "2", "", "", "15.00", "USD", "102.50"},
+	}, records)
+}
+
+func TestOFX(t *testing.T) {
+	data := writeStatement(t, OFX)
+	require.True(t, bytes.HasPrefix(data, []byte(`<?xml version="1.0" encoding="UTF-8" standalone="no"?>`)))
+	require.Contains(t, string(data), `<?OFX OFXHEADER="200" VERSION="220"`)
+
+	var document struct {
+		XMLName  xml.Name `xml:"OFX"`
+		Response struct {
+			Currency string         `xml:"CURDEF"`
+			Account  ofxBankAccount `xml:"BANKACCTFROM"`
+			List     struct {
+				Start        string           `xml:"DTSTART"`
+				End          string           `xml:"DTEND"`
+				Transactions []ofxTransaction `xml:"STMTTRN"`
+			} `xml:"BANKTRANLIST"`
+			Balance ofxBalance `xml:"LEDGERBAL"`
+		} `xml:"BANKMSGSRSV1>STMTTRNRS>STMTRS"`
+	}
+	require.NoError(t, xml.Unmarshal(data, &document))
+
+	response := document.Response
+	require.Equal(t, util.USD, response.Currency)
+	require.Equal(t, "42", response.Account.AccountID)
+	require.Equal(t, "20240101000000.000[0:GMT]", response.List.Start)
+	require.Equal(t, "20240201000000.000[0:GMT]", response.List.End)
+	require.Len(t, response.List.Transactions, 2)
+	require.Equal(t, "DEBIT", response.List.Transactions[0].Type)
+	require.Equal(t, "-12.50", response.List.Transactions[0].Amount)
+	require.Equal(t, "1", response.List.Transactions[0].ID)
+	require.Equal(t, "Transfer 7 with account 43", response.List.Transactions[0].Memo)
+	require.Equal(t, "CREDIT", response.List.Transactions[1].Type)
+	require.Equal(t, "15.00", response.List.Transactions[1].Amount)
+	require.Empty(t, response.List.Transactions[1].Memo)
+	require.Equal(t, "102.50", response.Balance.Amount)
+}
+
+func TestCamt053(t *testing.T) {
+	data := writeStatement(t, Camt053)
+
+	var document struct {
+		XMLName   xml.Name `xml:"urn:iso:std:iso:20022:tech:xsd:camt.053.001.02 Document"`
+		MessageID string   `xml:"BkToCstmrStmt>GrpHdr>MsgId"`
+		Statement struct {
+			ID       string        `xml:"Id"`
+			From     string        `xml:"FrToDt>FrDtTm"`
+			To       string        `xml:"FrToDt>ToDtTm"`
+			Account  camtAccount   `xml:"Acct"`
+			Balances []camtBalance `xml:"Bal"`
+			Entries  []camtEntry   `xml:"Ntry"`
+		} `xml:"BkToCstmrStmt>Stmt"`
+	}
+	require.NoError(t, xml.Unmarshal(data, &document))
+
+	statement := document.Statement
+	require.Equal(t, document.MessageID, statement.ID)
+	require.Equal(t, "2024-01-01T00:00:00Z", statement.From)
+	require.Equal(t, "2024-02-01T00:00:00Z", statement.To)
+	require.Equal(t, "42", statement.Account.ID)
+	require.Equal(t, "alice", statement.Account.Owner)
+
+	require.Len(t, statement.Balances, 2)
+	require.Equal(t, "OPBD", statement.Balances[0].Type)
+	require.Equal(t, camtAmount{Currency: util.USD, Value: "100.00"}, statement.Balances[0].Amount)
+	require.Equal(t, "CLBD", statement.Balances[1].Type)
+	require.Equal(t, "102.50", statement.Balances[1].Amount.Value)
+
+	require.Len(t, statement.Entries, 2)
+	require.Equal(t, "12.50", statement.Entries[0].Amount.Value)
+	require.Equal(t, "DBIT", statement.Entries[0].CreditDebit)
+	require.Equal(t, "ICDT", statement.Entries[0].TransferCode.Family)
+	require.Equal(t, &camtReferences{EndToEndID: "7"}, statement.Entries[0].References)
+	require.Equal(t, "CRDT", statement.Entries[1].CreditDebit)
+	require.Equal(t, "RCDT", statement.Entries[1].TransferCode.Family)
+	require.Nil(t, statement.Entries[1].References)
+	require.Equal(t, 1, strings.Count(string(data), "<NtryDtls>"))
+}
+
+func TestNewWriterUnknownFormat(t *testing.T) {
+	_, err := NewWriter("pdf", &bytes.Buffer{})
+	require.ErrorIs(t, err, ErrUnknownFormat)
+}
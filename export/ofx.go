@@ -0,0 +1,155 @@
+package export
+
+import (
+	"encoding/xml"
+	"io"
+	"strconv"
+	"time"
+
+	db "github.com/crackz/simple-bank/db/sqlc"
+	"github.com/crackz/simple-bank/money"
+)
+
+// ofxBankID identifies the bank in BANKACCTFROM. OFX requires one even though
+// account IDs are unique on their own here.
+const ofxBankID = "SIMPLEBANK"
+
+type ofxStatus struct {
+	Code     int    `xml:"CODE"`
+	Severity string `xml:"SEVERITY"`
+}
+
+var ofxOK = ofxStatus{Code: 0, Severity: "INFO"}
+
+type ofxSignOn struct {
+	XMLName xml.Name  `xml:"SIGNONMSGSRSV1"`
+	Status  ofxStatus `xml:"SONRS>STATUS"`
+	Server  string    `xml:"SONRS>DTSERVER"`
+	Lang    string    `xml:"SONRS>LANGUAGE"`
+}
+
+type ofxBankAccount struct {
+	XMLName   xml.Name `xml:"BANKACCTFROM"`
+	BankID    string   `xml:"BANKID"`
+	AccountID string   `xml:"ACCTID"`
+	Type      string   `xml:"ACCTTYPE"`
+}
+
+type ofxTransaction struct {
+	XMLName xml.Name `xml:"STMTTRN"`
+	Type    string   `xml:"TRNTYPE"`
+	Posted  string   `xml:"DTPOSTED"`
+	Amount  string   `xml:"TRNAMT"`
+	// FITID has to be unique and stable for importers to skip duplicates.
+	ID   string `xml:"FITID"`
+	Memo string `xml:"MEMO,omitempty"`
+}
+
+type ofxBalance struct {
+	Amount string `xml:"BALAMT"`
+	AsOf   string `xml:"DTASOF"`
+}
+
+// OFXWriter writes an OFX 2.2 bank statement response.
+type OFXWriter struct {
+	xmlWriter
+	statement Statement
+}
+
+func NewOFXWriter(w io.Writer) *OFXWriter {
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	return &OFXWriter{xmlWriter: xmlWriter{enc: enc}}
+}
+
+func (writer *OFXWriter) WriteHeader(statement Statement) error {
+	writer.statement = statement
+	account := statement.Account
+
+	tokens := []xml.Token{
+		xml.ProcInst{Target: "xml", Inst: []byte(`version="1.0" encoding="UTF-8" standalone="no"`)},
+		xml.CharData("\n"),
+		xml.ProcInst{Target: "OFX", Inst: []byte(`OFXHEADER="200" VERSION="220" SECURITY="NONE" OLDFILEUID="NONE" NEWFILEUID="NONE"`)},
+		xml.CharData("\n"),
+		startElement("OFX"),
+	}
+	if err := writer.encodeTokens(tokens...); err != nil {
+		return err
+	}
+
+	signOn := ofxSignOn{Status: ofxOK, Server: formatOFXTime(statement.CreatedAt), Lang: "ENG"}
+	if err := writer.enc.Encode(signOn); err != nil {
+		return err
+	}
+
+	if err := writer.encodeTokens(startElement("BANKMSGSRSV1"), startElement("STMTTRNRS")); err != nil {
+		return err
+	}
+	if err := writer.encodeElement("TRNUID", "0"); err != nil {
+		return err
+	}
+	if err := writer.enc.EncodeElement(ofxOK, startElement("STATUS")); err != nil {
+		return err
+	}
+
+	if err := writer.encodeTokens(startElement("STMTRS")); err != nil {
+		return err
+	}
+	if err := writer.encodeElement("CURDEF", account.Currency); err != nil {
+		return err
+	}
+	bankAccount := ofxBankAccount{BankID: ofxBankID, AccountID: strconv.FormatInt(account.ID, 10), Type: "CHECKING"}
+	if err := writer.enc.Encode(bankAccount); err != nil {
+		return err
+	}
+
+	if err := writer.encodeTokens(startElement("BANKTRANLIST")); err != nil {
+		return err
+	}
+	if err := writer.encodeElement("DTSTART", formatOFXTime(statement.From)); err != nil {
+		return err
+	}
+	return writer.encodeElement("DTEND", formatOFXTime(statement.To))
+}
+
+func (writer *OFXWriter) WriteEntry(entry db.StatementEntry) error {
+	transaction := ofxTransaction{
+		Type:   "DEBIT",
+		Posted: formatOFXTime(entry.CreatedAt),
+		Amount: money.New(entry.Amount, writer.statement.Account.Currency).String(),
+		ID:     strconv.FormatInt(entry.ID, 10),
+	}
+	if entry.Amount >= 0 {
+		transaction.Type = "CREDIT"
+	}
+	if entry.TransferID.Valid {
+		transaction.Memo = "Transfer " + strconv.FormatInt(entry.TransferID.Int64, 10)
+		if entry.CounterpartyAccountID.Valid {
+			transaction.Memo += " with account " + strconv.FormatInt(entry.CounterpartyAccountID.Int64, 10)
+		}
+	}
+
+	return writer.enc.Encode(transaction)
+}
+
+func (writer *OFXWriter) Close() error {
+	if err := writer.encodeTokens(endElement("BANKTRANLIST")); err != nil {
+		return err
+	}
+
+	balance := ofxBalance{
+		Amount: money.New(writer.statement.ClosingBalance, writer.statement.Account.Currency).String(),
+		AsOf:   formatOFXTime(writer.statement.To),
+	}
+	if err := writer.enc.EncodeElement(balance, startElement("LEDGERBAL")); err != nil {
+		return err
+	}
+
+	return writer.encodeTokens(endElement("STMTRS"), endElement("STMTTRNRS"), endElement("BANKMSGSRSV1"), endElement("OFX"))
+}
+
+// formatOFXTime formats t in UTC the way OFX dates are written, e.g.
+// 20240131235959.000[0:GMT].
+func formatOFXTime(t time.Time) string {
+	return t.UTC().Format("20060102150405.000") + "[0:GMT]"
+}
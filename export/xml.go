@@ -0,0 +1,31 @@
+package export
+
+import "encoding/xml"
+
+// xmlWriter streams an XML document whose outer elements are opened and
+// closed by hand around elements that are encoded whole.
+type xmlWriter struct {
+	enc *xml.Encoder
+}
+
+func (writer xmlWriter) encodeTokens(tokens ...xml.Token) error {
+	for _, token := range tokens {
+		if err := writer.enc.EncodeToken(token); err != nil {
+			return err
+		}
+	}
+
+	return writer.enc.Flush()
+}
+
+func (writer xmlWriter) encodeElement(name string, value string) error {
+	return writer.enc.EncodeElement(value, startElement(name))
+}
+
+func startElement(name string) xml.StartElement {
+	return xml.StartElement{Name: xml.Name{Local: name}}
+}
+
+func endElement(name string) xml.EndElement {
+	return xml.EndElement{Name: xml.Name{Local: name}}
+}
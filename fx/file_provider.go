@@ -0,0 +1,75 @@
+package fx
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// FileRateProvider serves rates from a JSON file mapping "FROM/TO" pairs to
+// decimal strings, e.g. {"USD/CAD": "1.35"}. The file is reloaded whenever its
+// modification time changes so rates can be updated without a restart.
+type FileRateProvider struct {
+	path string
+
+	mu      sync.Mutex
+	modTime time.Time
+	rates   *StaticRateProvider
+}
+
+func NewFileRateProvider(path string) (*FileRateProvider, error) {
+	provider := &FileRateProvider{path: path}
+	if err := provider.reload(); err != nil {
+		return nil, err
+	}
+
+	return provider, nil
+}
+
+func (provider *FileRateProvider) Rate(ctx context.Context, from string, to string) (Rate, error) {
+	if err := provider.reload(); err != nil {
+		return Rate{}, err
+	}
+
+	provider.mu.Lock()
+	rates := provider.rates
+	provider.mu.Unlock()
+
+	return rates.Rate(ctx, from, to)
+}
+
+func (provider *FileRateProvider) reload() error {
+	info, err := os.Stat(provider.path)
+	if err != nil {
+		return fmt.Errorf("couldn't read rates file: %w", err)
+	}
+
+	provider.mu.Lock()
+	defer provider.mu.Unlock()
+
+	if provider.rates != nil && info.ModTime().Equal(provider.modTime) {
+		return nil
+	}
+
+	data, err := os.ReadFile(provider.path)
+	if err != nil {
+		return fmt.Errorf("couldn't read rates file: %w", err)
+	}
+
+	var table map[string]string
+	if err := json.Unmarshal(data, &table); err != nil {
+		return fmt.Errorf("couldn't parse rates file: %w", err)
+	}
+
+	rates, err := NewStaticRateProvider(table)
+	if err != nil {
+		return err
+	}
+
+	provider.rates = rates
+	provider.modTime = info.ModTime()
+	return nil
+}
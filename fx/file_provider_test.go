@@ -0,0 +1,42 @@
+package fx
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileRateProvider(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rates.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"USD/CAD": "1.35"}`), 0o600))
+
+	provider, err := NewFileRateProvider(path)
+	require.NoError(t, err)
+
+	rate, err := provider.Rate(context.Background(), "USD", "CAD")
+	require.NoError(t, err)
+	require.Equal(t, "1.3500000000", rate.String())
+
+	require.NoError(t, os.WriteFile(path, []byte(`{"USD/CAD": "1.4"}`), 0o600))
+	modTime := time.Now().Add(time.Second)
+	require.NoError(t, os.Chtimes(path, modTime, modTime))
+
+	rate, err = provider.Rate(context.Background(), "USD", "CAD")
+	require.NoError(t, err)
+	require.Equal(t, "1.4000000000", rate.String())
+}
+
+func TestFileRateProviderInvalidFile(t *testing.T) {
+	_, err := NewFileRateProvider(filepath.Join(t.TempDir(), "missing.json"))
+	require.Error(t, err)
+
+	path := filepath.Join(t.TempDir(), "rates.json")
+	require.NoError(t, os.WriteFile(path, []byte(`not json`), 0o600))
+
+	_, err = NewFileRateProvider(path)
+	require.Error(t, err)
+}
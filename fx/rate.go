@@ -0,0 +1,92 @@
+package fx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/crackz/simple-bank/money"
+)
+
+var (
+	ErrRateNotFound  = errors.New("exchange rate not found")
+	ErrInvalidRate   = errors.New("exchange rate must be a positive decimal")
+	ErrAmountTooHigh = errors.New("converted amount is too high")
+)
+
+// RateScale is the number of decimal places rates are stored and shown with.
+const RateScale = 10
+
+type RateProvider interface {
+	// Rate returns how many units of the to currency one unit of the from
+	// currency buys.
+	Rate(ctx context.Context, from string, to string) (Rate, error)
+}
+
+type Rate struct {
+	From  string
+	To    string
+	Value *big.Rat
+}
+
+func ParseRate(from string, to string, value string) (Rate, error) {
+	rat, ok := new(big.Rat).SetString(value)
+	if !ok || rat.Sign() <= 0 {
+		return Rate{}, fmt.Errorf("%s/%s: %w", from, to, ErrInvalidRate)
+	}
+
+	return Rate{From: from, To: to, Value: rat}, nil
+}
+
+// String formats the rate as a decimal with RateScale places, which is how
+// it's persisted so the applied rate can be reproduced exactly.
+func (rate Rate) String() string {
+	return rate.Value.FloatString(RateScale)
+}
+
+// Inverse returns the rate for the opposite direction.
+func (rate Rate) Inverse() Rate {
+	return Rate{From: rate.To, To: rate.From, Value: new(big.Rat).Inv(rate.Value)}
+}
+
+// Convert converts an amount in minor units of the from currency to minor
+// units of the to currency. Rates are per unit, so the amount is scaled by the
+// difference of the currencies' minor unit exponents, e.g. 100 times less for
+// USD cents to JPY. The result is rounded down so conversions never credit more
+// than was debited.
+func (rate Rate) Convert(amount int64) (int64, error) {
+	fromExponent, err := money.Exponent(rate.From)
+	if err != nil {
+		return 0, err
+	}
+	toExponent, err := money.Exponent(rate.To)
+	if err != nil {
+		return 0, err
+	}
+
+	num := new(big.Int).Mul(big.NewInt(amount), rate.Value.Num())
+	denom := new(big.Int).Set(rate.Value.Denom())
+
+	scale := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(abs(toExponent-fromExponent))), nil)
+	if toExponent > fromExponent {
+		num.Mul(num, scale)
+	} else {
+		denom.Mul(denom, scale)
+	}
+
+	converted := num.Quo(num, denom)
+	if !converted.IsInt64() {
+		return 0, ErrAmountTooHigh
+	}
+
+	return converted.Int64(), nil
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+
+	return n
+}
@@ -0,0 +1,106 @@
+package fx
+
+import (
+	"testing"
+
+	"github.com/crackz/simple-bank/money"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRate(t *testing.T) {
+	rate, err := ParseRate("USD", "CAD", "1.35")
+	require.NoError(t, err)
+	require.Equal(t, "USD", rate.From)
+	require.Equal(t, "CAD", rate.To)
+	require.Equal(t, "1.3500000000", rate.String())
+
+	for _, value := range []string{"", "abc", "0", "-1.2"} {
+		_, err = ParseRate("USD", "CAD", value)
+		require.ErrorIs(t, err, ErrInvalidRate)
+	}
+}
+
+func TestRateConvert(t *testing.T) {
+	testCases := []struct {
+		name     string
+		rate     string
+		amount   int64
+		expected int64
+	}{
+		{name: "Exact", rate: "1.35", amount: 100, expected: 135},
+		{name: "RoundsDown", rate: "0.92", amount: 7, expected: 6},
+		{name: "Zero", rate: "0.92", amount: 0, expected: 0},
+		{name: "TooSmall", rate: "0.5", amount: 1, expected: 0},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			rate, err := ParseRate("USD", "EUR", tc.rate)
+			require.NoError(t, err)
+
+			converted, err := rate.Convert(tc.amount)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, converted)
+		})
+	}
+
+	rate, err := ParseRate("USD", "EUR", "1000")
+	require.NoError(t, err)
+
+	_, err = rate.Convert(1 << 62)
+	require.ErrorIs(t, err, ErrAmountTooHigh)
+}
+
+func TestRateConvertExponents(t *testing.T) {
+	money.SetExponent("JPY", 0)
+	money.SetExponent("BHD", 3)
+
+	testCases := []struct {
+		name     string
+		from     string
+		to       string
+		rate     string
+		amount   int64
+		expected int64
+	}{
+		// 1.00 USD buys 150 JPY
+		{name: "ToFewerDecimals", from: "USD", to: "JPY", rate: "150", amount: 100, expected: 150},
+		// 150 JPY buy 1.00 USD
+		{name: "ToMoreDecimals", from: "JPY", to: "USD", rate: "0.0066666667", amount: 150, expected: 100},
+		// 1.00 USD buys 0.376 BHD
+		{name: "ToThreeDecimals", from: "USD", to: "BHD", rate: "0.376", amount: 100, expected: 376},
+		// 0.99 USD are 148.50 JPY, rounded down
+		{name: "RoundsDown", from: "USD", to: "JPY", rate: "150", amount: 99, expected: 148},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			rate, err := ParseRate(tc.from, tc.to, tc.rate)
+			require.NoError(t, err)
+
+			converted, err := rate.Convert(tc.amount)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, converted)
+		})
+	}
+
+	rate, err := ParseRate("USD", "XXX", "1")
+	require.NoError(t, err)
+
+	_, err = rate.Convert(100)
+	require.ErrorIs(t, err, money.ErrUnknownCurrency)
+}
+
+func TestRateInverse(t *testing.T) {
+	rate, err := ParseRate("USD", "EUR", "0.8")
+	require.NoError(t, err)
+
+	inverse := rate.Inverse()
+	require.Equal(t, "EUR", inverse.From)
+	require.Equal(t, "USD", inverse.To)
+	require.Equal(t, "1.2500000000", inverse.String())
+}
@@ -0,0 +1,59 @@
+package fx
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// DefaultRates is used when no rates file is configured. It's only meant for
+// local development.
+var DefaultRates = map[string]string{
+	"USD/EUR": "0.92",
+	"USD/CAD": "1.35",
+	"EUR/CAD": "1.47",
+}
+
+// StaticRateProvider serves rates from a fixed table keyed by "FROM/TO". A
+// missing direction is derived from the inverse pair.
+type StaticRateProvider struct {
+	rates map[string]Rate
+}
+
+func NewStaticRateProvider(rates map[string]string) (*StaticRateProvider, error) {
+	provider := &StaticRateProvider{
+		rates: make(map[string]Rate, len(rates)),
+	}
+
+	for pair, value := range rates {
+		from, to, ok := strings.Cut(pair, "/")
+		if !ok || from == "" || to == "" {
+			return nil, fmt.Errorf("invalid currency pair %q, it should be FROM/TO", pair)
+		}
+
+		rate, err := ParseRate(from, to, value)
+		if err != nil {
+			return nil, err
+		}
+		provider.rates[pair] = rate
+	}
+
+	return provider, nil
+}
+
+func (provider *StaticRateProvider) Rate(ctx context.Context, from string, to string) (Rate, error) {
+	if from == to {
+		return Rate{From: from, To: to, Value: big.NewRat(1, 1)}, nil
+	}
+
+	if rate, ok := provider.rates[from+"/"+to]; ok {
+		return rate, nil
+	}
+
+	if rate, ok := provider.rates[to+"/"+from]; ok {
+		return rate.Inverse(), nil
+	}
+
+	return Rate{}, fmt.Errorf("%s/%s: %w", from, to, ErrRateNotFound)
+}
@@ -0,0 +1,37 @@
+package fx
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestStaticRateProvider(t *testing.T) {
+	provider, err := NewStaticRateProvider(map[string]string{"USD/EUR": "0.8"})
+	require.NoError(t, err)
+
+	rate, err := provider.Rate(context.Background(), "USD", "EUR")
+	require.NoError(t, err)
+	require.Equal(t, "0.8000000000", rate.String())
+
+	rate, err = provider.Rate(context.Background(), "EUR", "USD")
+	require.NoError(t, err)
+	require.Equal(t, "EUR", rate.From)
+	require.Equal(t, "1.2500000000", rate.String())
+
+	rate, err = provider.Rate(context.Background(), "CAD", "CAD")
+	require.NoError(t, err)
+	require.Equal(t, "1.0000000000", rate.String())
+
+	_, err = provider.Rate(context.Background(), "USD", "CAD")
+	require.ErrorIs(t, err, ErrRateNotFound)
+}
+
+func TestStaticRateProviderInvalidTable(t *testing.T) {
+	_, err := NewStaticRateProvider(map[string]string{"USDEUR": "0.8"})
+	require.Error(t, err)
+
+	_, err = NewStaticRateProvider(map[string]string{"USD/EUR": "-0.8"})
+	require.ErrorIs(t, err, ErrInvalidRate)
+}
@@ -0,0 +1,145 @@
+package gapi
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/crackz/simple-bank/audit"
+	db "github.com/crackz/simple-bank/db/sqlc"
+	"github.com/crackz/simple-bank/pagination"
+	"github.com/crackz/simple-bank/pb"
+	"github.com/lib/pq"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (server *Server) CreateAccount(ctx context.Context, req *pb.CreateAccountRequest) (*pb.CreateAccountResponse, error) {
+	if err := server.validateCurrency(ctx, req.GetCurrency()); err != nil {
+		return nil, invalidArgumentError([]*errdetails.BadRequest_FieldViolation{fieldViolation("currency", err)})
+	}
+
+	payload := authPayload(ctx)
+	arg := db.CreateAccountParams{
+		Owner:    payload.Username,
+		Currency: req.GetCurrency(),
+		Balance:  0,
+	}
+
+	idempotency, err := idempotencyParams(ctx, payload.Username, req)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	account, err := server.store.CreateAccountTx(ctx, db.CreateAccountTxParams{
+		CreateAccountParams: arg,
+		Idempotency:         idempotency,
+	})
+	if err != nil {
+		if errors.Is(err, db.ErrIdempotencyKeyConflict) {
+			return nil, status.Error(codes.AlreadyExists, err.Error())
+		}
+		if pgErr, ok := err.(*pq.Error); ok {
+			switch pgErr.Code.Name() {
+			case "foreign_key_violation":
+				return nil, status.Error(codes.FailedPrecondition, "you should create an account first")
+			case "unique_violation":
+				return nil, status.Errorf(codes.AlreadyExists, "you have already opened an account with %s currency", arg.Currency)
+			}
+		}
+		return nil, status.Errorf(codes.Internal, "failed to create account: %s", err)
+	}
+
+	audit.FromContext(ctx).SetTarget(auditTargetAccount, account.ID)
+	audit.FromContext(ctx).SetAfter(account)
+
+	return &pb.CreateAccountResponse{Account: convertAccount(account)}, nil
+}
+
+func (server *Server) GetAccount(ctx context.Context, req *pb.GetAccountRequest) (*pb.GetAccountResponse, error) {
+	if err := validateID(req.GetId()); err != nil {
+		return nil, invalidArgumentError([]*errdetails.BadRequest_FieldViolation{fieldViolation("id", err)})
+	}
+
+	account, err := server.getOwnedAccount(ctx, req.GetId())
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.GetAccountResponse{Account: convertAccount(account)}, nil
+}
+
+// getOwnedAccount loads an account and checks that it belongs to the current
+// user.
+func (server *Server) getOwnedAccount(ctx context.Context, id int64) (db.Account, error) {
+	account, err := server.getAccount(ctx, id)
+	if err != nil {
+		return account, err
+	}
+
+	if account.Owner != authPayload(ctx).Username {
+		return account, status.Error(codes.PermissionDenied, "you are not allowed to access this account")
+	}
+
+	return account, nil
+}
+
+func (server *Server) getAccount(ctx context.Context, id int64) (db.Account, error) {
+	account, err := server.store.GetAccount(ctx, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return account, status.Errorf(codes.NotFound, "account %d not found", id)
+		}
+		return account, status.Errorf(codes.Internal, "failed to get account: %s", err)
+	}
+
+	return account, nil
+}
+
+// ListAccounts lists the current user's accounts, oldest first, a page at a
+// time.
+func (server *Server) ListAccounts(ctx context.Context, req *pb.ListAccountsRequest) (*pb.ListAccountsResponse, error) {
+	if err := validateLimit(req.GetLimit()); err != nil {
+		return nil, invalidArgumentError([]*errdetails.BadRequest_FieldViolation{fieldViolation("limit", err)})
+	}
+
+	payload := authPayload(ctx)
+	scope := "accounts:" + payload.Username
+
+	cursor, err := server.cursors.Decode(scope, req.GetCursor())
+	if err != nil {
+		return nil, invalidArgumentError([]*errdetails.BadRequest_FieldViolation{fieldViolation("cursor", err)})
+	}
+
+	limit := pageLimit(req.GetLimit())
+	accounts, err := server.store.ListAccountsAfter(ctx, db.ListAccountsAfterParams{
+		Owner:          payload.Username,
+		AfterCreatedAt: cursor.CreatedAt,
+		AfterID:        cursor.ID,
+		LimitCount:     limit + 1,
+	})
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to list accounts: %s", err)
+	}
+
+	accounts, nextCursor, hasMore, err := page(server, scope, accounts, limit, accountCursor)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to encode cursor: %s", err)
+	}
+
+	response := &pb.ListAccountsResponse{
+		Accounts:   make([]*pb.Account, 0, len(accounts)),
+		NextCursor: nextCursor,
+		HasMore:    hasMore,
+	}
+	for _, account := range accounts {
+		response.Accounts = append(response.Accounts, convertAccount(account))
+	}
+
+	return response, nil
+}
+
+func accountCursor(account db.Account) pagination.Cursor {
+	return pagination.Cursor{CreatedAt: account.CreatedAt, ID: account.ID}
+}
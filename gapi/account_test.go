@@ -0,0 +1,302 @@
+package gapi
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	mockdb "github.com/crackz/simple-bank/db/mock"
+	db "github.com/crackz/simple-bank/db/sqlc"
+	"github.com/crackz/simple-bank/pb"
+	"github.com/crackz/simple-bank/token"
+	"github.com/crackz/simple-bank/util"
+	"github.com/golang/mock/gomock"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestGetAccountAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	account := randomAccount(user.Username)
+
+	testCases := []struct {
+		name          string
+		accountID     int64
+		setupAuth     func(t *testing.T, ctx context.Context, tokenMaker token.Maker) context.Context
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, res *pb.GetAccountResponse, err error)
+	}{
+		{
+			name:      "OK",
+			accountID: account.ID,
+			setupAuth: func(t *testing.T, ctx context.Context, tokenMaker token.Maker) context.Context {
+				return withBearerToken(t, ctx, tokenMaker, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+			},
+			checkResponse: func(t *testing.T, res *pb.GetAccountResponse, err error) {
+				require.NoError(t, err)
+				require.Equal(t, convertAccount(account).String(), res.GetAccount().String())
+			},
+		},
+		{
+			name:      "NoAuthorization",
+			accountID: account.ID,
+			setupAuth: func(t *testing.T, ctx context.Context, tokenMaker token.Maker) context.Context {
+				return ctx
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, res *pb.GetAccountResponse, err error) {
+				requireStatus(t, err, codes.Unauthenticated)
+			},
+		},
+		{
+			name:      "UnsupportedAuthorization",
+			accountID: account.ID,
+			setupAuth: func(t *testing.T, ctx context.Context, tokenMaker token.Maker) context.Context {
+				accessToken, err := tokenMaker.CreateToken(user.Username, time.Minute)
+				require.NoError(t, err)
+				return metadata.AppendToOutgoingContext(ctx, authorizationHeader, "basic "+accessToken)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, res *pb.GetAccountResponse, err error) {
+				requireStatus(t, err, codes.Unauthenticated)
+			},
+		},
+		{
+			name:      "ExpiredToken",
+			accountID: account.ID,
+			setupAuth: func(t *testing.T, ctx context.Context, tokenMaker token.Maker) context.Context {
+				return withBearerToken(t, ctx, tokenMaker, user.Username, -time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, res *pb.GetAccountResponse, err error) {
+				requireStatus(t, err, codes.Unauthenticated)
+			},
+		},
+		{
+			name:      "NotOwner",
+			accountID: account.ID,
+			setupAuth: func(t *testing.T, ctx context.Context, tokenMaker token.Maker) context.Context {
+				return withBearerToken(t, ctx, tokenMaker, "someoneelse", time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+			},
+			checkResponse: func(t *testing.T, res *pb.GetAccountResponse, err error) {
+				requireStatus(t, err, codes.PermissionDenied)
+			},
+		},
+		{
+			name:      "NotFound",
+			accountID: account.ID,
+			setupAuth: func(t *testing.T, ctx context.Context, tokenMaker token.Maker) context.Context {
+				return withBearerToken(t, ctx, tokenMaker, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(db.Account{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, res *pb.GetAccountResponse, err error) {
+				requireStatus(t, err, codes.NotFound)
+			},
+		},
+		{
+			name:      "InvalidID",
+			accountID: 0,
+			setupAuth: func(t *testing.T, ctx context.Context, tokenMaker token.Maker) context.Context {
+				return withBearerToken(t, ctx, tokenMaker, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, res *pb.GetAccountResponse, err error) {
+				requireStatus(t, err, codes.InvalidArgument)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			client := newTestClient(t, server)
+
+			ctx := tc.setupAuth(t, context.Background(), server.tokenMaker)
+			res, err := client.GetAccount(ctx, &pb.GetAccountRequest{Id: tc.accountID})
+			tc.checkResponse(t, res, err)
+		})
+	}
+}
+
+func TestCreateAccountAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	account := randomAccount(user.Username)
+	account.Balance = 0
+
+	testCases := []struct {
+		name          string
+		currency      string
+		setupContext  func(ctx context.Context) context.Context
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, res *pb.CreateAccountResponse, err error)
+	}{
+		{
+			name:     "OK",
+			currency: account.Currency,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateAccountTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.CreateAccountTxParams) (db.Account, error) {
+						require.Equal(t, user.Username, arg.Owner)
+						require.Equal(t, account.Currency, arg.Currency)
+						require.Nil(t, arg.Idempotency)
+						return account, nil
+					})
+			},
+			checkResponse: func(t *testing.T, res *pb.CreateAccountResponse, err error) {
+				require.NoError(t, err)
+				require.Equal(t, account.ID, res.GetAccount().GetId())
+			},
+		},
+		{
+			name:     "IdempotencyKey",
+			currency: account.Currency,
+			setupContext: func(ctx context.Context) context.Context {
+				return metadata.AppendToOutgoingContext(ctx, idempotencyKeyHeader, "key-1")
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateAccountTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.CreateAccountTxParams) (db.Account, error) {
+						require.NotNil(t, arg.Idempotency)
+						require.Equal(t, user.Username, arg.Idempotency.Owner)
+						require.Equal(t, "key-1", arg.Idempotency.Key)
+						require.NotEmpty(t, arg.Idempotency.RequestHash)
+						return db.Account{}, db.ErrIdempotencyKeyConflict
+					})
+			},
+			checkResponse: func(t *testing.T, res *pb.CreateAccountResponse, err error) {
+				requireStatus(t, err, codes.AlreadyExists)
+			},
+		},
+		{
+			name:     "UnsupportedCurrency",
+			currency: "JPY",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateAccountTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, res *pb.CreateAccountResponse, err error) {
+				requireStatus(t, err, codes.InvalidArgument)
+			},
+		},
+		{
+			name:     "DuplicateCurrency",
+			currency: account.Currency,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateAccountTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.Account{}, &pq.Error{Code: "23505"})
+			},
+			checkResponse: func(t *testing.T, res *pb.CreateAccountResponse, err error) {
+				requireStatus(t, err, codes.AlreadyExists)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			client := newTestClient(t, server)
+
+			ctx := withBearerToken(t, context.Background(), server.tokenMaker, user.Username, time.Minute)
+			if tc.setupContext != nil {
+				ctx = tc.setupContext(ctx)
+			}
+			res, err := client.CreateAccount(ctx, &pb.CreateAccountRequest{Currency: tc.currency})
+			tc.checkResponse(t, res, err)
+		})
+	}
+}
+
+func TestListAccountsAPI(t *testing.T) {
+	user, _ := randomUser(t)
+
+	accounts := make([]db.Account, 3)
+	for i := range accounts {
+		accounts[i] = randomAccount(user.Username)
+		accounts[i].ID = int64(i + 1)
+		accounts[i].CreatedAt = time.Now().UTC().Truncate(time.Microsecond).Add(time.Duration(i) * time.Second)
+	}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	gomock.InOrder(
+		store.EXPECT().
+			ListAccountsAfter(gomock.Any(), gomock.Eq(db.ListAccountsAfterParams{Owner: user.Username, LimitCount: 3})).
+			Times(1).
+			Return(accounts, nil),
+		store.EXPECT().
+			ListAccountsAfter(gomock.Any(), gomock.Any()).
+			Times(1).
+			DoAndReturn(func(_ context.Context, arg db.ListAccountsAfterParams) ([]db.Account, error) {
+				require.True(t, accounts[1].CreatedAt.Equal(arg.AfterCreatedAt))
+				require.Equal(t, accounts[1].ID, arg.AfterID)
+				return accounts[2:], nil
+			}),
+	)
+
+	server := newTestServer(t, store)
+	client := newTestClient(t, server)
+	ctx := withBearerToken(t, context.Background(), server.tokenMaker, user.Username, time.Minute)
+
+	res, err := client.ListAccounts(ctx, &pb.ListAccountsRequest{Limit: 2})
+	require.NoError(t, err)
+	require.Len(t, res.GetAccounts(), 2)
+	require.True(t, res.GetHasMore())
+	require.NotEmpty(t, res.GetNextCursor())
+
+	next, err := client.ListAccounts(ctx, &pb.ListAccountsRequest{Limit: 2, Cursor: res.GetNextCursor()})
+	require.NoError(t, err)
+	require.Len(t, next.GetAccounts(), 1)
+	require.Equal(t, accounts[2].ID, next.GetAccounts()[0].GetId())
+	require.False(t, next.GetHasMore())
+	require.Empty(t, next.GetNextCursor())
+
+	// a cursor only pages through the accounts of the user it was made for
+	otherCtx := withBearerToken(t, context.Background(), server.tokenMaker, util.RandOwner(), time.Minute)
+	_, err = client.ListAccounts(otherCtx, &pb.ListAccountsRequest{Limit: 2, Cursor: res.GetNextCursor()})
+	requireStatus(t, err, codes.InvalidArgument)
+
+	_, err = client.ListAccounts(ctx, &pb.ListAccountsRequest{Limit: 101})
+	requireStatus(t, err, codes.InvalidArgument)
+}
@@ -0,0 +1,66 @@
+package gapi
+
+import (
+	"context"
+	"net"
+
+	"github.com/crackz/simple-bank/audit"
+	"github.com/crackz/simple-bank/pb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// auditMethod is the method of audit log entries of gRPC calls. Their route is
+// the full method name and their status code the gRPC status code.
+const auditMethod = "GRPC"
+
+const (
+	auditTargetAccount = "account"
+	auditTargetUser    = "user"
+)
+
+// readMethods don't change anything and aren't audited, like GET requests of
+// the REST API.
+var readMethods = map[string]bool{
+	pb.SimpleBank_GetAccount_FullMethodName:    true,
+	pb.SimpleBank_ListAccounts_FullMethodName:  true,
+	pb.SimpleBank_GetTransfer_FullMethodName:   true,
+	pb.SimpleBank_ListTransfers_FullMethodName: true,
+}
+
+// auditInterceptor records every mutating call, successful or not, like the
+// audit middleware of the REST API. It runs before the auth interceptor, so
+// calls without a valid token are recorded as well.
+func (server *Server) auditInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	if readMethods[info.FullMethod] {
+		return handler(ctx, req)
+	}
+
+	call := audit.Call{
+		Method:   auditMethod,
+		Route:    info.FullMethod,
+		ClientIP: clientIP(ctx),
+	}
+	entry := audit.NewEntry(call)
+	resp, err := handler(audit.NewContext(ctx, entry), req)
+
+	call.StatusCode = int32(status.Code(err))
+	audit.Record(server.auditRecorder, entry, call)
+
+	return resp, err
+}
+
+func clientIP(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return ""
+	}
+
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		return p.Addr.String()
+	}
+
+	return host
+}
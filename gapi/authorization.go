@@ -0,0 +1,103 @@
+package gapi
+
+import (
+	"context"
+	"strings"
+
+	"github.com/crackz/simple-bank/audit"
+	"github.com/crackz/simple-bank/pb"
+	"github.com/crackz/simple-bank/token"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	authorizationHeader = "authorization"
+	authorizationBearer = "bearer"
+)
+
+// publicMethods can be called without an access token.
+var publicMethods = map[string]bool{
+	pb.SimpleBank_CreateUser_FullMethodName: true,
+	pb.SimpleBank_LoginUser_FullMethodName:  true,
+}
+
+type authorizationPayloadKey struct{}
+
+// unaryAuthInterceptor verifies the bearer token of the authorization metadata
+// like the REST auth middleware, and puts its payload in the context.
+func (server *Server) unaryAuthInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	if publicMethods[info.FullMethod] {
+		return handler(ctx, req)
+	}
+
+	payload, err := server.authorize(ctx)
+	if err != nil {
+		return nil, err
+	}
+	audit.FromContext(ctx).SetActor(payload.Username)
+
+	return handler(context.WithValue(ctx, authorizationPayloadKey{}, payload), req)
+}
+
+func (server *Server) streamAuthInterceptor(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	if publicMethods[info.FullMethod] {
+		return handler(srv, stream)
+	}
+
+	payload, err := server.authorize(stream.Context())
+	if err != nil {
+		return err
+	}
+
+	return handler(srv, &authorizedStream{
+		ServerStream: stream,
+		ctx:          context.WithValue(stream.Context(), authorizationPayloadKey{}, payload),
+	})
+}
+
+type authorizedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (stream *authorizedStream) Context() context.Context {
+	return stream.ctx
+}
+
+func (server *Server) authorize(ctx context.Context) (*token.Payload, error) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "missing metadata")
+	}
+
+	values := md.Get(authorizationHeader)
+	if len(values) == 0 {
+		return nil, status.Error(codes.Unauthenticated, "authorization header is not provided")
+	}
+
+	fields := strings.Fields(values[0])
+	if len(fields) < 2 {
+		return nil, status.Error(codes.Unauthenticated, "authorization header format is invalid")
+	}
+
+	if strings.ToLower(fields[0]) != authorizationBearer {
+		return nil, status.Error(codes.Unauthenticated, "authorization type is unsupported")
+	}
+
+	payload, err := server.tokenMaker.VerifyToken(fields[1])
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+
+	return payload, nil
+}
+
+// authPayload is the payload of the caller's token. It's only nil in public
+// methods.
+func authPayload(ctx context.Context) *token.Payload {
+	payload, _ := ctx.Value(authorizationPayloadKey{}).(*token.Payload)
+	return payload
+}
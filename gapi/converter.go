@@ -0,0 +1,68 @@
+package gapi
+
+import (
+	db "github.com/crackz/simple-bank/db/sqlc"
+	"github.com/crackz/simple-bank/money"
+	"github.com/crackz/simple-bank/pb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func convertUser(user db.User) *pb.User {
+	return &pb.User{
+		Username:          user.Username,
+		FullName:          user.FullName,
+		Email:             user.Email,
+		PasswordChangedAt: timestamppb.New(user.PasswordChangedAt),
+		CreatedAt:         timestamppb.New(user.CreatedAt),
+	}
+}
+
+func convertMoney(amount int64, currency string) *pb.Money {
+	return &pb.Money{
+		Amount:   money.New(amount, currency).String(),
+		Currency: currency,
+	}
+}
+
+func convertAccount(account db.Account) *pb.Account {
+	return &pb.Account{
+		Id:               account.ID,
+		Owner:            account.Owner,
+		Balance:          convertMoney(account.Balance, account.Currency),
+		HeldBalance:      convertMoney(account.HeldBalance, account.Currency),
+		AvailableBalance: convertMoney(account.AvailableBalance, account.Currency),
+		Currency:         account.Currency,
+		Status:           account.Status,
+		CreatedAt:        timestamppb.New(account.CreatedAt),
+	}
+}
+
+// convertTransfer needs the currencies of both accounts, the amount being in
+// the source account's and the credited amount in the destination's.
+func convertTransfer(transfer db.Transfer, fromCurrency string, toCurrency string) *pb.Transfer {
+	return &pb.Transfer{
+		Id:            transfer.ID,
+		FromAccountId: transfer.FromAccountID,
+		ToAccountId:   transfer.ToAccountID,
+		Amount:        convertMoney(transfer.Amount, fromCurrency),
+		ToAmount:      convertMoney(transfer.ToAmount, toCurrency),
+		ExchangeRate:  transfer.ExchangeRate,
+		ReversalOf:    transfer.ReversalOf.Int64,
+		CreatedAt:     timestamppb.New(transfer.CreatedAt),
+	}
+}
+
+func convertUserTransfer(row db.ListUserTransfersRow) *pb.Transfer {
+	transfer := db.Transfer{
+		ID:            row.ID,
+		FromAccountID: row.FromAccountID,
+		ToAccountID:   row.ToAccountID,
+		Amount:        row.Amount,
+		CreatedAt:     row.CreatedAt,
+		ToAmount:      row.ToAmount,
+		ExchangeRate:  row.ExchangeRate,
+		ReversalOf:    row.ReversalOf,
+	}
+
+	return convertTransfer(transfer, row.FromCurrency, row.ToCurrency)
+}
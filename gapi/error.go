@@ -0,0 +1,50 @@
+package gapi
+
+import (
+	"errors"
+
+	db "github.com/crackz/simple-bank/db/sqlc"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func fieldViolation(field string, err error) *errdetails.BadRequest_FieldViolation {
+	return &errdetails.BadRequest_FieldViolation{
+		Field:       field,
+		Description: err.Error(),
+	}
+}
+
+// invalidArgumentError reports the fields of a request that failed validation
+// in a BadRequest detail.
+func invalidArgumentError(violations []*errdetails.BadRequest_FieldViolation) error {
+	badRequest := &errdetails.BadRequest{FieldViolations: violations}
+	statusInvalid := status.New(codes.InvalidArgument, "invalid parameters")
+
+	statusDetails, err := statusInvalid.WithDetails(badRequest)
+	if err != nil {
+		return statusInvalid.Err()
+	}
+
+	return statusDetails.Err()
+}
+
+// transferErrorCode is the status code of an error of the store's
+// transactions, like transferErrorStatus of the REST API.
+func transferErrorCode(err error) codes.Code {
+	switch {
+	case errors.Is(err, db.ErrAccountNotFound), errors.Is(err, db.ErrTransferNotFound):
+		return codes.NotFound
+	case errors.Is(err, db.ErrCurrencyMismatch):
+		return codes.InvalidArgument
+	case errors.Is(err, db.ErrInsufficientFunds), errors.Is(err, db.ErrTransferLimitExceeded):
+		return codes.FailedPrecondition
+	case errors.Is(err, db.ErrAccountFrozen), errors.Is(err, db.ErrAccountClosed):
+		return codes.FailedPrecondition
+	case errors.Is(err, db.ErrIdempotencyKeyConflict):
+		return codes.AlreadyExists
+	}
+
+	return codes.Internal
+}
@@ -0,0 +1,37 @@
+package gapi
+
+import (
+	"context"
+	"fmt"
+
+	db "github.com/crackz/simple-bank/db/sqlc"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/proto"
+)
+
+const idempotencyKeyHeader = "idempotency-key"
+
+// idempotencyParams reads the idempotency-key metadata of the call and hashes
+// the method with its request, like the Idempotency-Key header of the REST
+// API. It returns nil if no key was sent.
+func idempotencyParams(ctx context.Context, owner string, req proto.Message) (*db.IdempotencyKeyParams, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	values := md.Get(idempotencyKeyHeader)
+	if len(values) == 0 || values[0] == "" {
+		return nil, nil
+	}
+
+	key := values[0]
+	if len(key) > db.MaxIdempotencyKeyLength {
+		return nil, fmt.Errorf("%s metadata must be at most %d characters", idempotencyKeyHeader, db.MaxIdempotencyKeyLength)
+	}
+
+	body, err := proto.MarshalOptions{Deterministic: true}.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+
+	method, _ := grpc.Method(ctx)
+	return db.NewIdempotencyKeyParams(owner, key, method, body), nil
+}
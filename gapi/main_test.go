@@ -0,0 +1,109 @@
+package gapi
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/crackz/simple-bank/currency"
+	db "github.com/crackz/simple-bank/db/sqlc"
+	"github.com/crackz/simple-bank/pb"
+	"github.com/crackz/simple-bank/token"
+	"github.com/crackz/simple-bank/util"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func newTestServer(t *testing.T, store db.Store) *Server {
+	config := &util.Config{
+		JwtSecret:   util.RandString(32),
+		JwtDuration: time.Minute,
+	}
+
+	server, err := NewServer(config, store, currency.NewRegistry(testCurrencyStore{}, time.Hour))
+	require.NoError(t, err)
+
+	// tests that check the audit log record to the store again
+	server.auditRecorder = discardAuditRecorder{}
+	return server
+}
+
+// newTestClient serves server over an in-memory connection for the duration of
+// the test.
+func newTestClient(t *testing.T, server *Server) pb.SimpleBankClient {
+	listener := bufconn.Listen(1 << 20)
+	grpcServer := server.GRPCServer()
+	go grpcServer.Serve(listener)
+	t.Cleanup(grpcServer.Stop)
+
+	conn, err := grpc.DialContext(context.Background(), "bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	return pb.NewSimpleBankClient(conn)
+}
+
+// withBearerToken is the context of a call authenticated as username.
+func withBearerToken(t *testing.T, ctx context.Context, tokenMaker token.Maker, username string, duration time.Duration) context.Context {
+	accessToken, err := tokenMaker.CreateToken(username, duration)
+	require.NoError(t, err)
+
+	return metadata.AppendToOutgoingContext(ctx, authorizationHeader, fmt.Sprintf("%s %s", authorizationBearer, accessToken))
+}
+
+// testCurrencyStore has the currencies that are enabled by default.
+type testCurrencyStore struct{}
+
+func (testCurrencyStore) ListCurrencies(ctx context.Context) ([]db.Currency, error) {
+	return []db.Currency{
+		{Code: util.CAD, NumericCode: "124", Name: "Canadian Dollar", MinorUnits: 2, Enabled: true},
+		{Code: util.EUR, NumericCode: "978", Name: "Euro", MinorUnits: 2, Enabled: true},
+		{Code: "JPY", NumericCode: "392", Name: "Yen", MinorUnits: 0},
+		{Code: util.USD, NumericCode: "840", Name: "US Dollar", MinorUnits: 2, Enabled: true},
+	}, nil
+}
+
+type discardAuditRecorder struct{}
+
+func (discardAuditRecorder) CreateAuditLog(ctx context.Context, arg db.CreateAuditLogParams) (db.AuditLog, error) {
+	return db.AuditLog{}, nil
+}
+
+func (discardAuditRecorder) CompleteAuditLog(ctx context.Context, arg db.CompleteAuditLogParams) (db.AuditLog, error) {
+	return db.AuditLog{}, nil
+}
+
+func randomUser(t *testing.T) (db.User, string) {
+	password := util.RandString(6)
+	hashedPassword, err := util.HashPassword(password)
+	require.NoError(t, err)
+
+	user := db.User{
+		Username:       util.RandOwner(),
+		HashedPassword: hashedPassword,
+		FullName:       util.RandString(6),
+		Email:          util.RandomEmail(),
+	}
+
+	return user, password
+}
+
+func randomAccount(owner string) db.Account {
+	return db.Account{
+		ID:       util.RandomInt(1, 1000),
+		Owner:    owner,
+		Balance:  util.RandBalance(),
+		Currency: util.RandomCurrency(),
+		Status:   db.AccountActive,
+	}
+}
@@ -0,0 +1,30 @@
+package gapi
+
+import "github.com/crackz/simple-bank/pagination"
+
+const defaultPageLimit = 10
+
+func pageLimit(limit int32) int32 {
+	if limit == 0 {
+		return defaultPageLimit
+	}
+
+	return limit
+}
+
+// page trims rows fetched with a limit one more than the page size, the extra
+// row telling whether there is a next page, and signs the cursor of the next
+// page if there is one.
+func page[Row any](server *Server, scope string, rows []Row, limit int32, position func(Row) pagination.Cursor) ([]Row, string, bool, error) {
+	if int32(len(rows)) <= limit {
+		return rows, "", false, nil
+	}
+
+	rows = rows[:limit]
+	nextCursor, err := server.cursors.Encode(scope, position(rows[len(rows)-1]))
+	if err != nil {
+		return nil, "", false, err
+	}
+
+	return rows, nextCursor, true, nil
+}
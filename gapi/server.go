@@ -0,0 +1,81 @@
+// Package gapi serves the bank over gRPC, next to the REST API of package api
+// and on the same store, tokens and rules.
+package gapi
+
+import (
+	"context"
+	"fmt"
+	"net"
+
+	"github.com/crackz/simple-bank/audit"
+	"github.com/crackz/simple-bank/currency"
+	db "github.com/crackz/simple-bank/db/sqlc"
+	"github.com/crackz/simple-bank/pagination"
+	"github.com/crackz/simple-bank/pb"
+	"github.com/crackz/simple-bank/risk"
+	"github.com/crackz/simple-bank/token"
+	"github.com/crackz/simple-bank/util"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/reflection"
+)
+
+type Server struct {
+	pb.UnimplementedSimpleBankServer
+	config     *util.Config
+	store      db.Store
+	tokenMaker token.Maker
+	riskRules  risk.Rules
+	// auditRecorder is the store outside of tests
+	auditRecorder audit.Recorder
+	// currencies is the registry of the process, shared with the REST server
+	currencies *currency.Registry
+	cursors    pagination.Signer
+}
+
+func NewServer(config *util.Config, store db.Store, currencies *currency.Registry) (*Server, error) {
+	tokenMaker, err := token.NewJWTMaker(config.JwtSecret)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't create token maker: %w", err)
+	}
+
+	riskRules, err := risk.NewRules(config)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't load risk rules: %w", err)
+	}
+
+	return &Server{
+		config:        config,
+		store:         store,
+		tokenMaker:    tokenMaker,
+		riskRules:     riskRules,
+		auditRecorder: store,
+		currencies:    currencies,
+		cursors:       pagination.NewSigner(config.JwtSecret),
+	}, nil
+}
+
+// GRPCServer returns a grpc.Server serving the API, with the calls
+// authenticated and audited by its interceptors.
+func (server *Server) GRPCServer() *grpc.Server {
+	grpcServer := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(server.auditInterceptor, server.unaryAuthInterceptor),
+		grpc.ChainStreamInterceptor(server.streamAuthInterceptor),
+	)
+	pb.RegisterSimpleBankServer(grpcServer, server)
+	reflection.Register(grpcServer)
+
+	return grpcServer
+}
+
+func (server *Server) Start(address string) error {
+	if err := server.currencies.Load(context.Background()); err != nil {
+		return err
+	}
+
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return err
+	}
+
+	return server.GRPCServer().Serve(listener)
+}
@@ -0,0 +1,240 @@
+package gapi
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+
+	"github.com/crackz/simple-bank/audit"
+	db "github.com/crackz/simple-bank/db/sqlc"
+	"github.com/crackz/simple-bank/money"
+	"github.com/crackz/simple-bank/pagination"
+	"github.com/crackz/simple-bank/pb"
+	"github.com/crackz/simple-bank/risk"
+	"github.com/crackz/simple-bank/screening"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	directionIncoming = "incoming"
+	directionOutgoing = "outgoing"
+)
+
+// CreateTransfer moves money from one of the current user's accounts through
+// the risk rules. A transfer held for review isn't made and only its
+// risk_decision_id is set in the response.
+func (server *Server) CreateTransfer(ctx context.Context, req *pb.CreateTransferRequest) (*pb.CreateTransferResponse, error) {
+	if violations := server.validateCreateTransferRequest(ctx, req); violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	audit.FromContext(ctx).SetTarget(auditTargetAccount, req.GetFromAccountId())
+
+	fromAccount, err := server.getAccount(ctx, req.GetFromAccountId())
+	if err != nil {
+		return nil, err
+	}
+	toAccount, err := server.getAccount(ctx, req.GetToAccountId())
+	if err != nil {
+		return nil, err
+	}
+
+	if fromAccount.Currency != req.GetCurrency() {
+		return nil, status.Errorf(codes.InvalidArgument, "from account doesn't support currency: %v", fromAccount.Currency)
+	}
+
+	payload := authPayload(ctx)
+	if fromAccount.Owner != payload.Username {
+		return nil, status.Errorf(codes.PermissionDenied, "account id: %v doesn't belong to current user", fromAccount.ID)
+	}
+
+	if toAccount.Currency != req.GetCurrency() {
+		return nil, status.Errorf(codes.InvalidArgument, "to account doesn't support currency: %v", req.GetCurrency())
+	}
+	audit.FromContext(ctx).SetBefore(map[string]db.Account{"fromAccount": fromAccount, "toAccount": toAccount})
+
+	// the amount was checked against the currency by the validation
+	amount, _ := money.ParsePositive(req.GetAmount(), req.GetCurrency())
+
+	idempotency, err := idempotencyParams(ctx, payload.Username, req)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	arg := db.TransferTxParams{
+		FromAccountID: fromAccount.ID,
+		ToAccountID:   toAccount.ID,
+		Amount:        amount.Amount,
+		Idempotency:   idempotency,
+	}
+
+	screened, err := screening.Screen(ctx, server.store, db.CheckTransferRiskParams{
+		Rules:      server.riskRules,
+		Owner:      payload.Username,
+		Transfer:   &arg,
+		Reviewable: true,
+	})
+	if err != nil {
+		return nil, status.Errorf(transferErrorCode(err), "failed to screen transfer: %s", err)
+	}
+	if !screened.Allowed() {
+		audit.FromContext(ctx).SetAfter(screened.RiskDecision)
+
+		// the triggered rules are only shown to staff
+		if screened.Outcome == risk.Block {
+			return nil, status.Error(codes.PermissionDenied, db.ErrTransferBlocked.Error())
+		}
+		return &pb.CreateTransferResponse{RiskDecisionId: screened.RiskDecision.ID}, nil
+	}
+
+	result, err := server.store.TransferTx(ctx, arg)
+	if err != nil {
+		code := transferErrorCode(err)
+		if code == codes.Internal {
+			log.Printf("Couldn't Make Transfer From Account %d : %s", arg.FromAccountID, err)
+		}
+		return nil, status.Error(code, err.Error())
+	}
+	audit.FromContext(ctx).SetAfter(result)
+
+	response := &pb.CreateTransferResponse{
+		Transfer:    convertTransfer(result.Transfer, result.FromAccount.Currency, result.ToAccount.Currency),
+		FromAccount: convertAccount(result.FromAccount),
+		ToAccount:   convertAccount(result.ToAccount),
+	}
+	if result.Fee != nil {
+		// the fee is paid from the source account
+		response.Fee = convertMoney(result.Fee.Amount, result.FromAccount.Currency)
+	}
+
+	return response, nil
+}
+
+func (server *Server) validateCreateTransferRequest(ctx context.Context, req *pb.CreateTransferRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := validateID(req.GetFromAccountId()); err != nil {
+		violations = append(violations, fieldViolation("from_account_id", err))
+	}
+	if err := validateID(req.GetToAccountId()); err != nil {
+		violations = append(violations, fieldViolation("to_account_id", err))
+	}
+	if err := server.validateCurrency(ctx, req.GetCurrency()); err != nil {
+		violations = append(violations, fieldViolation("currency", err))
+	} else if _, err := money.ParsePositive(req.GetAmount(), req.GetCurrency()); err != nil {
+		violations = append(violations, fieldViolation("amount", err))
+	}
+
+	return violations
+}
+
+// GetTransfer returns a transfer from or to one of the current user's
+// accounts.
+func (server *Server) GetTransfer(ctx context.Context, req *pb.GetTransferRequest) (*pb.GetTransferResponse, error) {
+	if err := validateID(req.GetId()); err != nil {
+		return nil, invalidArgumentError([]*errdetails.BadRequest_FieldViolation{fieldViolation("id", err)})
+	}
+
+	transfer, err := server.store.GetTransfer(ctx, req.GetId())
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, status.Error(codes.NotFound, db.ErrTransferNotFound.Error())
+		}
+		return nil, status.Errorf(codes.Internal, "failed to get transfer: %s", err)
+	}
+
+	fromAccount, err := server.getAccount(ctx, transfer.FromAccountID)
+	if err != nil {
+		return nil, err
+	}
+	toAccount, err := server.getAccount(ctx, transfer.ToAccountID)
+	if err != nil {
+		return nil, err
+	}
+
+	username := authPayload(ctx).Username
+	if fromAccount.Owner != username && toAccount.Owner != username {
+		return nil, status.Error(codes.PermissionDenied, "you are not allowed to access this transfer")
+	}
+
+	return &pb.GetTransferResponse{
+		Transfer: convertTransfer(transfer, fromAccount.Currency, toAccount.Currency),
+	}, nil
+}
+
+// ListTransfers lists the transfers from or to any of the current user's
+// accounts, oldest first, a page at a time, optionally narrowed down to one
+// account and one direction. A transfer between two of the user's accounts is
+// both incoming and outgoing.
+func (server *Server) ListTransfers(ctx context.Context, req *pb.ListTransfersRequest) (*pb.ListTransfersResponse, error) {
+	if violations := validateListTransfersRequest(req); violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	payload := authPayload(ctx)
+
+	// a cursor is only good for the filters it was made with
+	scope := fmt.Sprintf("transfers:%s:%d:%s", payload.Username, req.GetAccountId(), req.GetDirection())
+	cursor, err := server.cursors.Decode(scope, req.GetCursor())
+	if err != nil {
+		return nil, invalidArgumentError([]*errdetails.BadRequest_FieldViolation{fieldViolation("cursor", err)})
+	}
+
+	limit := pageLimit(req.GetLimit())
+	arg := db.ListUserTransfersParams{
+		Owner:          payload.Username,
+		Outgoing:       req.GetDirection() != directionIncoming,
+		Incoming:       req.GetDirection() != directionOutgoing,
+		AfterCreatedAt: cursor.CreatedAt,
+		AfterID:        cursor.ID,
+		LimitCount:     limit + 1,
+	}
+	if req.GetAccountId() != 0 {
+		if _, err := server.getOwnedAccount(ctx, req.GetAccountId()); err != nil {
+			return nil, err
+		}
+		arg.AccountID = sql.NullInt64{Int64: req.GetAccountId(), Valid: true}
+	}
+
+	transfers, err := server.store.ListUserTransfers(ctx, arg)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to list transfers: %s", err)
+	}
+
+	transfers, nextCursor, hasMore, err := page(server, scope, transfers, limit, userTransferCursor)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to encode cursor: %s", err)
+	}
+
+	response := &pb.ListTransfersResponse{
+		Transfers:  make([]*pb.Transfer, 0, len(transfers)),
+		NextCursor: nextCursor,
+		HasMore:    hasMore,
+	}
+	for _, transfer := range transfers {
+		response.Transfers = append(response.Transfers, convertUserTransfer(transfer))
+	}
+
+	return response, nil
+}
+
+func validateListTransfersRequest(req *pb.ListTransfersRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := validateLimit(req.GetLimit()); err != nil {
+		violations = append(violations, fieldViolation("limit", err))
+	}
+	if req.GetAccountId() < 0 {
+		violations = append(violations, fieldViolation("account_id", validateID(req.GetAccountId())))
+	}
+	switch req.GetDirection() {
+	case "", directionIncoming, directionOutgoing:
+	default:
+		violations = append(violations, fieldViolation("direction", fmt.Errorf("must be %s or %s", directionIncoming, directionOutgoing)))
+	}
+
+	return violations
+}
+
+func userTransferCursor(row db.ListUserTransfersRow) pagination.Cursor {
+	return pagination.Cursor{CreatedAt: row.CreatedAt, ID: row.ID}
+}
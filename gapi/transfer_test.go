@@ -0,0 +1,303 @@
+package gapi
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	mockdb "github.com/crackz/simple-bank/db/mock"
+	db "github.com/crackz/simple-bank/db/sqlc"
+	"github.com/crackz/simple-bank/pb"
+	"github.com/crackz/simple-bank/risk"
+	"github.com/crackz/simple-bank/util"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+)
+
+func TestCreateTransferAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	other, _ := randomUser(t)
+
+	fromAccount := db.Account{ID: 1, Owner: user.Username, Currency: util.USD, Balance: 10000, Status: db.AccountActive}
+	toAccount := db.Account{ID: 2, Owner: other.Username, Currency: util.USD, Status: db.AccountActive}
+	eurAccount := db.Account{ID: 3, Owner: other.Username, Currency: util.EUR, Status: db.AccountActive}
+
+	valid := &pb.CreateTransferRequest{FromAccountId: 1, ToAccountId: 2, Amount: "12.50", Currency: util.USD}
+
+	testCases := []struct {
+		name          string
+		req           *pb.CreateTransferRequest
+		username      string
+		rules         risk.Rules
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, res *pb.CreateTransferResponse, err error)
+	}{
+		{
+			name:     "OK",
+			req:      valid,
+			username: user.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(toAccount.ID)).Times(1).Return(toAccount, nil)
+				store.EXPECT().
+					TransferTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.TransferTxParams) (db.TransferTxResult, error) {
+						require.Equal(t, fromAccount.ID, arg.FromAccountID)
+						require.Equal(t, toAccount.ID, arg.ToAccountID)
+						require.Equal(t, int64(1250), arg.Amount)
+						return db.TransferTxResult{
+							Transfer:    db.Transfer{ID: 7, FromAccountID: 1, ToAccountID: 2, Amount: 1250, ToAmount: 1250},
+							FromAccount: fromAccount,
+							ToAccount:   toAccount,
+							Fee:         &db.TransferFee{Amount: 25},
+						}, nil
+					})
+			},
+			checkResponse: func(t *testing.T, res *pb.CreateTransferResponse, err error) {
+				require.NoError(t, err)
+				require.Equal(t, int64(7), res.GetTransfer().GetId())
+				require.Equal(t, "12.50", res.GetTransfer().GetAmount().GetAmount())
+				require.Equal(t, "0.25", res.GetFee().GetAmount())
+				require.Zero(t, res.GetRiskDecisionId())
+			},
+		},
+		{
+			name:     "InvalidAmount",
+			req:      &pb.CreateTransferRequest{FromAccountId: 1, ToAccountId: 2, Amount: "1.001", Currency: util.USD},
+			username: user.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, res *pb.CreateTransferResponse, err error) {
+				requireStatus(t, err, codes.InvalidArgument)
+			},
+		},
+		{
+			name:     "CurrencyMismatch",
+			req:      &pb.CreateTransferRequest{FromAccountId: 1, ToAccountId: 3, Amount: "1", Currency: util.USD},
+			username: user.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(eurAccount.ID)).Times(1).Return(eurAccount, nil)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, res *pb.CreateTransferResponse, err error) {
+				requireStatus(t, err, codes.InvalidArgument)
+			},
+		},
+		{
+			name:     "NotOwner",
+			req:      valid,
+			username: other.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(toAccount.ID)).Times(1).Return(toAccount, nil)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, res *pb.CreateTransferResponse, err error) {
+				requireStatus(t, err, codes.PermissionDenied)
+			},
+		},
+		{
+			name:     "ToAccountNotFound",
+			req:      valid,
+			username: user.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(toAccount.ID)).Times(1).Return(db.Account{}, sql.ErrNoRows)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, res *pb.CreateTransferResponse, err error) {
+				requireStatus(t, err, codes.NotFound)
+			},
+		},
+		{
+			name:     "InsufficientFunds",
+			req:      valid,
+			username: user.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(toAccount.ID)).Times(1).Return(toAccount, nil)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(1).Return(db.TransferTxResult{}, db.ErrInsufficientFunds)
+			},
+			checkResponse: func(t *testing.T, res *pb.CreateTransferResponse, err error) {
+				requireStatus(t, err, codes.FailedPrecondition)
+			},
+		},
+		{
+			name:     "HeldForReview",
+			req:      valid,
+			username: user.Username,
+			rules: risk.Rules{
+				VelocityCount:   1,
+				VelocityWindow:  time.Minute,
+				VelocityOutcome: risk.Review,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(toAccount.ID)).Times(1).Return(toAccount, nil)
+				store.EXPECT().
+					CheckTransferRisk(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(risk.Review, db.RiskDecision{ID: 3, Status: db.RiskPending}, nil)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, res *pb.CreateTransferResponse, err error) {
+				require.NoError(t, err)
+				require.Equal(t, int64(3), res.GetRiskDecisionId())
+				require.Nil(t, res.GetTransfer())
+			},
+		},
+		{
+			name:     "Blocked",
+			req:      valid,
+			username: user.Username,
+			rules: risk.Rules{
+				VelocityCount:   1,
+				VelocityWindow:  time.Minute,
+				VelocityOutcome: risk.Block,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(toAccount.ID)).Times(1).Return(toAccount, nil)
+				store.EXPECT().
+					CheckTransferRisk(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(risk.Block, db.RiskDecision{ID: 4, Status: db.RiskBlocked}, nil)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, res *pb.CreateTransferResponse, err error) {
+				requireStatus(t, err, codes.PermissionDenied)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			server.riskRules = tc.rules
+			client := newTestClient(t, server)
+
+			ctx := withBearerToken(t, context.Background(), server.tokenMaker, tc.username, time.Minute)
+			res, err := client.CreateTransfer(ctx, tc.req)
+			tc.checkResponse(t, res, err)
+		})
+	}
+}
+
+func TestGetTransferAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	other, _ := randomUser(t)
+
+	fromAccount := db.Account{ID: 1, Owner: other.Username, Currency: util.USD}
+	toAccount := db.Account{ID: 2, Owner: user.Username, Currency: util.EUR}
+	transfer := db.Transfer{ID: 5, FromAccountID: 1, ToAccountID: 2, Amount: 1000, ToAmount: 900, ExchangeRate: "0.9"}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().GetTransfer(gomock.Any(), gomock.Eq(transfer.ID)).Times(2).Return(transfer, nil)
+	store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(2).Return(fromAccount, nil)
+	store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(toAccount.ID)).Times(2).Return(toAccount, nil)
+	store.EXPECT().GetTransfer(gomock.Any(), gomock.Eq(int64(6))).Times(1).Return(db.Transfer{}, sql.ErrNoRows)
+
+	server := newTestServer(t, store)
+	client := newTestClient(t, server)
+
+	// the receiving side can see the transfer too
+	ctx := withBearerToken(t, context.Background(), server.tokenMaker, user.Username, time.Minute)
+	res, err := client.GetTransfer(ctx, &pb.GetTransferRequest{Id: transfer.ID})
+	require.NoError(t, err)
+	require.Equal(t, "10.00", res.GetTransfer().GetAmount().GetAmount())
+	require.Equal(t, util.USD, res.GetTransfer().GetAmount().GetCurrency())
+	require.Equal(t, "9.00", res.GetTransfer().GetToAmount().GetAmount())
+	require.Equal(t, util.EUR, res.GetTransfer().GetToAmount().GetCurrency())
+
+	_, err = client.GetTransfer(ctx, &pb.GetTransferRequest{Id: 6})
+	requireStatus(t, err, codes.NotFound)
+
+	otherCtx := withBearerToken(t, context.Background(), server.tokenMaker, util.RandOwner(), time.Minute)
+	_, err = client.GetTransfer(otherCtx, &pb.GetTransferRequest{Id: transfer.ID})
+	requireStatus(t, err, codes.PermissionDenied)
+}
+
+func TestListTransfersAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	account := db.Account{ID: 1, Owner: user.Username, Currency: util.USD}
+
+	rows := make([]db.ListUserTransfersRow, 3)
+	for i := range rows {
+		rows[i] = db.ListUserTransfersRow{
+			ID:            int64(i + 1),
+			FromAccountID: 2,
+			ToAccountID:   account.ID,
+			Amount:        100,
+			ToAmount:      100,
+			CreatedAt:     time.Now().UTC().Truncate(time.Microsecond).Add(time.Duration(i) * time.Second),
+			FromCurrency:  util.USD,
+			ToCurrency:    util.USD,
+		}
+	}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(2).Return(account, nil)
+	gomock.InOrder(
+		store.EXPECT().
+			ListUserTransfers(gomock.Any(), gomock.Any()).
+			Times(1).
+			DoAndReturn(func(_ context.Context, arg db.ListUserTransfersParams) ([]db.ListUserTransfersRow, error) {
+				require.Equal(t, user.Username, arg.Owner)
+				require.Equal(t, sql.NullInt64{Int64: account.ID, Valid: true}, arg.AccountID)
+				require.True(t, arg.Incoming)
+				require.False(t, arg.Outgoing)
+				require.Equal(t, int32(3), arg.LimitCount)
+				return rows, nil
+			}),
+		store.EXPECT().
+			ListUserTransfers(gomock.Any(), gomock.Any()).
+			Times(1).
+			DoAndReturn(func(_ context.Context, arg db.ListUserTransfersParams) ([]db.ListUserTransfersRow, error) {
+				require.Equal(t, rows[1].ID, arg.AfterID)
+				return rows[2:], nil
+			}),
+	)
+
+	server := newTestServer(t, store)
+	client := newTestClient(t, server)
+	ctx := withBearerToken(t, context.Background(), server.tokenMaker, user.Username, time.Minute)
+
+	req := &pb.ListTransfersRequest{Limit: 2, AccountId: account.ID, Direction: directionIncoming}
+	res, err := client.ListTransfers(ctx, req)
+	require.NoError(t, err)
+	require.Len(t, res.GetTransfers(), 2)
+	require.True(t, res.GetHasMore())
+
+	req.Cursor = res.GetNextCursor()
+	next, err := client.ListTransfers(ctx, req)
+	require.NoError(t, err)
+	require.Len(t, next.GetTransfers(), 1)
+	require.False(t, next.GetHasMore())
+
+	// a cursor is only good for the filters it was made with
+	req.Direction = directionOutgoing
+	_, err = client.ListTransfers(ctx, req)
+	requireStatus(t, err, codes.InvalidArgument)
+
+	_, err = client.ListTransfers(ctx, &pb.ListTransfersRequest{Direction: "sideways"})
+	requireStatus(t, err, codes.InvalidArgument)
+}
@@ -0,0 +1,77 @@
+package gapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/mail"
+	"regexp"
+)
+
+const (
+	minPasswordLength = 6
+	maxPageLimit      = 100
+)
+
+var isAlphanumeric = regexp.MustCompile(`^[a-zA-Z0-9]+$`).MatchString
+
+func validateUsername(value string) error {
+	if !isAlphanumeric(value) {
+		return errors.New("must contain only letters and digits")
+	}
+
+	return nil
+}
+
+func validatePassword(value string) error {
+	if len(value) < minPasswordLength {
+		return fmt.Errorf("must be at least %d characters", minPasswordLength)
+	}
+
+	return nil
+}
+
+func validateFullName(value string) error {
+	if value == "" {
+		return errors.New("is required")
+	}
+
+	return nil
+}
+
+func validateEmail(value string) error {
+	if address, err := mail.ParseAddress(value); err != nil || address.Address != value {
+		return errors.New("is not a valid email address")
+	}
+
+	return nil
+}
+
+func validateID(value int64) error {
+	if value < 1 {
+		return errors.New("must be positive")
+	}
+
+	return nil
+}
+
+// validateLimit checks a page limit. Zero asks for the default limit.
+func validateLimit(value int32) error {
+	if value < 0 {
+		return errors.New("must not be negative")
+	}
+	if value > maxPageLimit {
+		return fmt.Errorf("must be at most %d", maxPageLimit)
+	}
+
+	return nil
+}
+
+// validateCurrency checks that value is an enabled currency of the registry.
+func (server *Server) validateCurrency(ctx context.Context, value string) error {
+	if !server.currencies.IsEnabled(ctx, value) {
+		return fmt.Errorf("currency %q is not supported", value)
+	}
+
+	return nil
+}
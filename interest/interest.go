@@ -0,0 +1,137 @@
+package interest
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+)
+
+var (
+	ErrInvalidRate     = errors.New("interest rate must be a non-negative decimal")
+	ErrInvalidAccrued  = errors.New("accrued interest must be a decimal")
+	ErrUnknownDayCount = errors.New("unknown day-count convention")
+	ErrAmountTooHigh   = errors.New("interest amount is too high")
+)
+
+// Day-count conventions decide which fraction of the yearly rate a day earns.
+const (
+	// ACT365 earns 1/365 of the rate on every calendar day, leap years
+	// included.
+	ACT365 = "ACT/365"
+	// Thirty360 treats every month as 30 days and the year as 360, so each
+	// month earns exactly 1/12 of the rate however long it is.
+	Thirty360 = "30/360"
+)
+
+// AccrualScale is the number of decimal places of a minor unit accruals are
+// stored with. Each daily accrual is truncated to it, which loses less than
+// 10^-AccrualScale minor units a day.
+const AccrualScale = 10
+
+var accrualUnit = new(big.Int).Exp(big.NewInt(10), big.NewInt(AccrualScale), nil)
+
+// ParseRate parses a yearly rate given as a fraction, e.g. 0.025 for 2.5%.
+func ParseRate(value string) (*big.Rat, error) {
+	rate, ok := new(big.Rat).SetString(value)
+	if !ok || rate.Sign() < 0 {
+		return nil, ErrInvalidRate
+	}
+
+	return rate, nil
+}
+
+func ValidDayCount(dayCount string) bool {
+	return dayCount == ACT365 || dayCount == Thirty360
+}
+
+// DayFraction returns the fraction of a year between from and to under the
+// given convention. Only the dates of from and to are used.
+func DayFraction(dayCount string, from time.Time, to time.Time) (*big.Rat, error) {
+	switch dayCount {
+	case ACT365:
+		return big.NewRat(int64(Date(to).Sub(Date(from))/(24*time.Hour)), 365), nil
+	case Thirty360:
+		return big.NewRat(days30360(Date(from), Date(to)), 360), nil
+	}
+
+	return nil, fmt.Errorf("%s: %w", dayCount, ErrUnknownDayCount)
+}
+
+// days30360 counts days the 30/360 bond basis way: a 31st counts as the 30th,
+// and the end date only does when the start is at the end of its month too.
+func days30360(from time.Time, to time.Time) int64 {
+	y1, m1, d1 := from.Date()
+	y2, m2, d2 := to.Date()
+
+	if d1 == 31 {
+		d1 = 30
+	}
+	if d2 == 31 && d1 == 30 {
+		d2 = 30
+	}
+
+	return int64(360*(y2-y1) + 30*(int(m2)-int(m1)) + (d2 - d1))
+}
+
+// DailyAccrual returns the interest an end-of-day balance earns on day at the
+// yearly rate, in minor units truncated to AccrualScale places. Balances that
+// aren't positive earn nothing.
+func DailyAccrual(balance int64, rate *big.Rat, dayCount string, day time.Time) (*big.Rat, error) {
+	fraction, err := DayFraction(dayCount, day, Date(day).AddDate(0, 0, 1))
+	if err != nil {
+		return nil, err
+	}
+	if balance <= 0 {
+		return new(big.Rat), nil
+	}
+
+	amount := new(big.Rat).SetInt64(balance)
+	amount.Mul(amount, rate)
+	amount.Mul(amount, fraction)
+
+	scaled := new(big.Int).Mul(amount.Num(), accrualUnit)
+	scaled.Quo(scaled, amount.Denom())
+
+	return new(big.Rat).SetFrac(scaled, accrualUnit), nil
+}
+
+func ParseAccrued(value string) (*big.Rat, error) {
+	accrued, ok := new(big.Rat).SetString(value)
+	if !ok {
+		return nil, ErrInvalidAccrued
+	}
+
+	return accrued, nil
+}
+
+// FormatAccrued formats an accrued amount with AccrualScale places, which is
+// how it's persisted.
+func FormatAccrued(accrued *big.Rat) string {
+	return accrued.FloatString(AccrualScale)
+}
+
+// Split splits accrued interest into the whole minor units that can be posted
+// and the fraction that is carried over to the next period.
+func Split(accrued *big.Rat) (int64, *big.Rat, error) {
+	whole := new(big.Int).Quo(accrued.Num(), accrued.Denom())
+	if !whole.IsInt64() {
+		return 0, nil, ErrAmountTooHigh
+	}
+
+	rest := new(big.Rat).Sub(accrued, new(big.Rat).SetInt(whole))
+	return whole.Int64(), rest, nil
+}
+
+// Date truncates t to midnight UTC of its day, the calendar interest is
+// accrued on.
+func Date(t time.Time) time.Time {
+	year, month, day := t.UTC().Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+// IsMonthEnd reports whether day is the last day of its month, after which
+// the month's interest is posted.
+func IsMonthEnd(day time.Time) bool {
+	return Date(day).AddDate(0, 0, 1).Day() == 1
+}
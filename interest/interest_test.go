@@ -0,0 +1,172 @@
+package interest
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func TestDayFraction(t *testing.T) {
+	testCases := []struct {
+		name     string
+		dayCount string
+		from     time.Time
+		to       time.Time
+		fraction *big.Rat
+	}{
+		{
+			name:     "ACT365 Day",
+			dayCount: ACT365,
+			from:     date(2024, time.February, 28),
+			to:       date(2024, time.February, 29),
+			fraction: big.NewRat(1, 365),
+		},
+		{
+			name:     "ACT365 Leap Year",
+			dayCount: ACT365,
+			from:     date(2024, time.January, 1),
+			to:       date(2025, time.January, 1),
+			fraction: big.NewRat(366, 365),
+		},
+		{
+			name:     "30360 Thirty First",
+			dayCount: Thirty360,
+			from:     date(2023, time.January, 31),
+			to:       date(2023, time.February, 1),
+			fraction: big.NewRat(1, 360),
+		},
+		{
+			name:     "30360 Thirtieth To Thirty First",
+			dayCount: Thirty360,
+			from:     date(2023, time.January, 30),
+			to:       date(2023, time.January, 31),
+			fraction: new(big.Rat),
+		},
+		{
+			name:     "30360 End Of February",
+			dayCount: Thirty360,
+			from:     date(2023, time.February, 28),
+			to:       date(2023, time.March, 1),
+			fraction: big.NewRat(3, 360),
+		},
+		{
+			name:     "30360 Year",
+			dayCount: Thirty360,
+			from:     date(2023, time.March, 15),
+			to:       date(2024, time.March, 15),
+			fraction: big.NewRat(1, 1),
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			fraction, err := DayFraction(tc.dayCount, tc.from, tc.to)
+			require.NoError(t, err)
+			require.Zero(t, tc.fraction.Cmp(fraction), fraction.String())
+		})
+	}
+
+	_, err := DayFraction("ACT/ACT", date(2023, time.January, 1), date(2023, time.January, 2))
+	require.ErrorIs(t, err, ErrUnknownDayCount)
+}
+
+// TestMonthlyAccrual checks that the daily accruals of a month add up to what
+// each convention promises for it, less at most the truncated digits.
+func TestMonthlyAccrual(t *testing.T) {
+	rate, err := ParseRate("0.06")
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name     string
+		dayCount string
+		month    time.Time
+		total    *big.Rat
+	}{
+		{
+			name:     "30360 February",
+			dayCount: Thirty360,
+			month:    date(2023, time.February, 1),
+			// 1,000,000 * 6% / 12
+			total: big.NewRat(5000, 1),
+		},
+		{
+			name:     "30360 March",
+			dayCount: Thirty360,
+			month:    date(2023, time.March, 1),
+			total:    big.NewRat(5000, 1),
+		},
+		{
+			name:     "ACT365 February",
+			dayCount: ACT365,
+			month:    date(2023, time.February, 1),
+			// 1,000,000 * 6% * 28 / 365
+			total: big.NewRat(1_680_000, 365),
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			total := new(big.Rat)
+			days := int64(0)
+			for day := tc.month; day.Month() == tc.month.Month(); day = day.AddDate(0, 0, 1) {
+				amount, err := DailyAccrual(1_000_000, rate, tc.dayCount, day)
+				require.NoError(t, err)
+				total.Add(total, amount)
+				days++
+			}
+
+			lost := new(big.Rat).Sub(tc.total, total)
+			require.GreaterOrEqual(t, lost.Sign(), 0)
+			require.Negative(t, lost.Cmp(new(big.Rat).SetFrac(big.NewInt(days), accrualUnit)), lost.String())
+		})
+	}
+}
+
+func TestDailyAccrual(t *testing.T) {
+	rate, err := ParseRate("0.05")
+	require.NoError(t, err)
+
+	amount, err := DailyAccrual(12345, rate, ACT365, date(2023, time.May, 10))
+	require.NoError(t, err)
+	// 12345 * 0.05 / 365 = 1.69109589041...
+	require.Equal(t, "1.6910958904", FormatAccrued(amount))
+
+	amount, err = DailyAccrual(-500, rate, ACT365, date(2023, time.May, 10))
+	require.NoError(t, err)
+	require.Zero(t, amount.Sign())
+
+	_, err = ParseRate("-0.01")
+	require.ErrorIs(t, err, ErrInvalidRate)
+}
+
+func TestSplit(t *testing.T) {
+	accrued, err := ParseAccrued("152.7500000001")
+	require.NoError(t, err)
+
+	whole, rest, err := Split(accrued)
+	require.NoError(t, err)
+	require.Equal(t, int64(152), whole)
+	require.Equal(t, "0.7500000001", FormatAccrued(rest))
+
+	whole, rest, err = Split(rest)
+	require.NoError(t, err)
+	require.Zero(t, whole)
+	require.Equal(t, "0.7500000001", FormatAccrued(rest))
+}
+
+func TestIsMonthEnd(t *testing.T) {
+	require.True(t, IsMonthEnd(date(2024, time.February, 29)))
+	require.False(t, IsMonthEnd(date(2023, time.February, 27)))
+	require.True(t, IsMonthEnd(date(2023, time.February, 28)))
+	require.True(t, IsMonthEnd(time.Date(2023, time.December, 31, 23, 59, 0, 0, time.UTC)))
+}
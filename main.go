@@ -1,12 +1,19 @@
 package main
 
 import (
+	"context"
 	"database/sql"
 	"log"
 
 	"github.com/crackz/simple-bank/api"
+	"github.com/crackz/simple-bank/currency"
 	db "github.com/crackz/simple-bank/db/sqlc"
+	"github.com/crackz/simple-bank/gapi"
+	"github.com/crackz/simple-bank/outbox"
+	"github.com/crackz/simple-bank/risk"
+	"github.com/crackz/simple-bank/scheduler"
 	"github.com/crackz/simple-bank/util"
+	"github.com/crackz/simple-bank/webhook"
 	_ "github.com/lib/pq"
 )
 
@@ -22,7 +29,41 @@ func main() {
 	}
 
 	store := db.NewStore(conn)
-	server, err := api.NewServer(config, store)
+	currencies := currency.NewRegistry(store, config.CurrencyCacheTTL)
+
+	riskRules, err := risk.NewRules(config)
+	if err != nil {
+		log.Fatal("Couldn't Load Risk Rules : ", err)
+	}
+	go scheduler.NewScheduler(store, riskRules, config.SchedulerInterval, config.SchedulerBatchSize).Start(context.Background())
+
+	publishers := []outbox.Publisher{webhook.NewDispatcher(store)}
+	if config.OutboxFile != "" {
+		filePublisher, err := outbox.NewFilePublisher(config.OutboxFile)
+		if err != nil {
+			log.Fatal("Couldn't Open Outbox File : ", err)
+		}
+		defer filePublisher.Close()
+
+		publishers = append(publishers, filePublisher)
+	}
+	go outbox.NewRelay(store, outbox.NewFanoutPublisher(publishers...), config.OutboxInterval, 0).Start(context.Background())
+	go webhook.NewWorker(store, nil, config.WebhookInterval, 0, config.WebhookMaxAttempts).Start(context.Background())
+
+	if config.GRPCServerAddress != "" {
+		grpcServer, err := gapi.NewServer(config, store, currencies)
+		if err != nil {
+			log.Fatal("Couldn't Create A gRPC Server : ", err)
+		}
+
+		go func() {
+			if err := grpcServer.Start(config.GRPCServerAddress); err != nil {
+				log.Fatal("Couldn't Start gRPC Server : ", err)
+			}
+		}()
+	}
+
+	server, err := api.NewServer(config, store, currencies)
 	if err != nil {
 		log.Fatal("Couldn't Create A Server : ", err)
 	}
@@ -0,0 +1,250 @@
+package money
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/crackz/simple-bank/util"
+)
+
+var (
+	ErrUnknownCurrency  = errors.New("unknown currency")
+	ErrInvalidAmount    = errors.New("invalid amount")
+	ErrOverflow         = errors.New("amount is out of range")
+	ErrCurrencyMismatch = errors.New("amounts are in different currencies")
+	ErrNotPositive      = errors.New("amount must be positive")
+)
+
+var (
+	exponentsMu sync.RWMutex
+	// exponents holds the number of decimal places of the minor unit of each
+	// currency, e.g. 2 when 100 minor units make a unit. The currencies that
+	// are enabled by default are known before the currency registry is loaded.
+	exponents = map[string]int{
+		util.USD: 2,
+		util.CAD: 2,
+		util.EUR: 2,
+	}
+)
+
+// SetExponent records the number of decimal places of the currency's minor
+// unit.
+func SetExponent(currency string, exponent int) {
+	exponentsMu.Lock()
+	defer exponentsMu.Unlock()
+
+	exponents[currency] = exponent
+}
+
+// Exponent returns the number of decimal places of the currency's minor unit.
+func Exponent(currency string) (int, error) {
+	exponentsMu.RLock()
+	exponent, ok := exponents[currency]
+	exponentsMu.RUnlock()
+
+	if !ok {
+		return 0, fmt.Errorf("%w: %s", ErrUnknownCurrency, currency)
+	}
+
+	return exponent, nil
+}
+
+// Money is an amount of a currency, counted in minor units of the currency.
+type Money struct {
+	Amount   int64
+	Currency string
+}
+
+func New(amount int64, currency string) Money {
+	return Money{Amount: amount, Currency: currency}
+}
+
+// Parse reads a decimal amount such as "12.34" or "-5" in currency. The amount
+// can't have more decimal places than the currency's minor unit.
+func Parse(s string, currency string) (Money, error) {
+	exponent, err := Exponent(currency)
+	if err != nil {
+		return Money{}, err
+	}
+
+	amount, err := parseDecimal(s, exponent)
+	if err != nil {
+		return Money{}, err
+	}
+
+	return New(amount, currency), nil
+}
+
+// ParsePositive reads an amount like Parse that must be positive, like the
+// amount of a transfer.
+func ParsePositive(s string, currency string) (Money, error) {
+	m, err := Parse(s, currency)
+	if err != nil {
+		return Money{}, err
+	}
+	if !m.IsPositive() {
+		return Money{}, fmt.Errorf("%w: %q", ErrNotPositive, s)
+	}
+
+	return m, nil
+}
+
+func parseDecimal(s string, exponent int) (int64, error) {
+	digits := strings.TrimPrefix(s, "-")
+	negative := len(digits) < len(s)
+
+	whole, fraction, hasPoint := strings.Cut(digits, ".")
+	if whole == "" || (hasPoint && fraction == "") || !isDigits(whole) || !isDigits(fraction) {
+		return 0, fmt.Errorf("%w: %q", ErrInvalidAmount, s)
+	}
+	if len(fraction) > exponent {
+		return 0, fmt.Errorf("%w: %q has more than %d decimal places", ErrInvalidAmount, s, exponent)
+	}
+
+	// parsing as unsigned lets the smallest int64 through
+	minor, err := strconv.ParseUint(whole+fraction+strings.Repeat("0", exponent-len(fraction)), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %q", ErrOverflow, s)
+	}
+
+	if negative {
+		if minor > math.MaxInt64+1 {
+			return 0, fmt.Errorf("%w: %q", ErrOverflow, s)
+		}
+		return int64(-minor), nil
+	}
+	if minor > math.MaxInt64 {
+		return 0, fmt.Errorf("%w: %q", ErrOverflow, s)
+	}
+
+	return int64(minor), nil
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+
+	return true
+}
+
+// String formats the amount as a decimal with all the decimal places of the
+// currency, e.g. "12.30". Amounts of an unknown currency are shown in minor
+// units.
+func (m Money) String() string {
+	exponent, err := Exponent(m.Currency)
+	if err != nil {
+		return strconv.FormatInt(m.Amount, 10)
+	}
+
+	return formatDecimal(m.Amount, exponent)
+}
+
+func formatDecimal(amount int64, exponent int) string {
+	sign := ""
+	// converting before negating keeps the smallest int64 from overflowing
+	minor := uint64(amount)
+	if amount < 0 {
+		sign = "-"
+		minor = -minor
+	}
+
+	digits := strconv.FormatUint(minor, 10)
+	if exponent == 0 {
+		return sign + digits
+	}
+	if len(digits) <= exponent {
+		digits = strings.Repeat("0", exponent-len(digits)+1) + digits
+	}
+
+	point := len(digits) - exponent
+	return sign + digits[:point] + "." + digits[point:]
+}
+
+func (m Money) IsZero() bool {
+	return m.Amount == 0
+}
+
+func (m Money) IsPositive() bool {
+	return m.Amount > 0
+}
+
+// Add returns m + other. Both have to be in the same currency.
+func (m Money) Add(other Money) (Money, error) {
+	if m.Currency != other.Currency {
+		return Money{}, fmt.Errorf("%w: %s and %s", ErrCurrencyMismatch, m.Currency, other.Currency)
+	}
+
+	sum := m.Amount + other.Amount
+	// the sum overflowed when both operands have the sign it doesn't have
+	if (m.Amount > 0 && other.Amount > 0 && sum < 0) || (m.Amount < 0 && other.Amount < 0 && sum >= 0) {
+		return Money{}, fmt.Errorf("%w: %s + %s", ErrOverflow, m, other)
+	}
+
+	return New(sum, m.Currency), nil
+}
+
+// Sub returns m - other. Both have to be in the same currency.
+func (m Money) Sub(other Money) (Money, error) {
+	if m.Currency != other.Currency {
+		return Money{}, fmt.Errorf("%w: %s and %s", ErrCurrencyMismatch, m.Currency, other.Currency)
+	}
+
+	diff := m.Amount - other.Amount
+	// subtracting must move the amount away from other's sign
+	if (other.Amount > 0 && diff > m.Amount) || (other.Amount < 0 && diff < m.Amount) {
+		return Money{}, fmt.Errorf("%w: %s - %s", ErrOverflow, m, other)
+	}
+
+	return New(diff, m.Currency), nil
+}
+
+// Neg returns -m.
+func (m Money) Neg() (Money, error) {
+	if m.Amount == math.MinInt64 {
+		return Money{}, fmt.Errorf("%w: -(%s)", ErrOverflow, m)
+	}
+
+	return New(-m.Amount, m.Currency), nil
+}
+
+type moneyJSON struct {
+	Amount   string `json:"amount"`
+	Currency string `json:"currency"`
+}
+
+// MarshalJSON encodes the amount as a decimal string so clients don't have to
+// know the currency's minor unit, e.g. {"amount":"12.34","currency":"USD"}.
+func (m Money) MarshalJSON() ([]byte, error) {
+	exponent, err := Exponent(m.Currency)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(moneyJSON{
+		Amount:   formatDecimal(m.Amount, exponent),
+		Currency: m.Currency,
+	})
+}
+
+func (m *Money) UnmarshalJSON(data []byte) error {
+	var v moneyJSON
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+
+	parsed, err := Parse(v.Amount, v.Currency)
+	if err != nil {
+		return err
+	}
+
+	*m = parsed
+	return nil
+}
@@ -0,0 +1,131 @@
+package money
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+
+	"github.com/crackz/simple-bank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	testCases := []struct {
+		value  string
+		amount int64
+	}{
+		{value: "12.34", amount: 1234},
+		{value: "12.3", amount: 1230},
+		{value: "12", amount: 1200},
+		{value: "0.01", amount: 1},
+		{value: "-5.5", amount: -550},
+		{value: "0", amount: 0},
+		{value: "92233720368547758.07", amount: math.MaxInt64},
+		{value: "-92233720368547758.08", amount: math.MinInt64},
+	}
+
+	for _, tc := range testCases {
+		m, err := Parse(tc.value, util.USD)
+		require.NoError(t, err, tc.value)
+		require.Equal(t, New(tc.amount, util.USD), m, tc.value)
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	testCases := []struct {
+		value    string
+		currency string
+		err      error
+	}{
+		{value: "", currency: util.USD, err: ErrInvalidAmount},
+		{value: "abc", currency: util.USD, err: ErrInvalidAmount},
+		{value: "1.234", currency: util.USD, err: ErrInvalidAmount},
+		{value: "1.", currency: util.USD, err: ErrInvalidAmount},
+		{value: ".5", currency: util.USD, err: ErrInvalidAmount},
+		{value: "+1", currency: util.USD, err: ErrInvalidAmount},
+		{value: "1e3", currency: util.USD, err: ErrInvalidAmount},
+		{value: "92233720368547758.08", currency: util.USD, err: ErrOverflow},
+		{value: "-92233720368547758.09", currency: util.USD, err: ErrOverflow},
+		{value: "1", currency: "XXX", err: ErrUnknownCurrency},
+	}
+
+	for _, tc := range testCases {
+		_, err := Parse(tc.value, tc.currency)
+		require.ErrorIs(t, err, tc.err, tc.value)
+	}
+}
+
+func TestParsePositive(t *testing.T) {
+	m, err := ParsePositive("0.01", util.USD)
+	require.NoError(t, err)
+	require.Equal(t, New(1, util.USD), m)
+
+	_, err = ParsePositive("0", util.USD)
+	require.ErrorIs(t, err, ErrNotPositive)
+
+	_, err = ParsePositive("-1", util.USD)
+	require.ErrorIs(t, err, ErrNotPositive)
+
+	_, err = ParsePositive("1.234", util.USD)
+	require.ErrorIs(t, err, ErrInvalidAmount)
+}
+
+func TestString(t *testing.T) {
+	require.Equal(t, "12.34", New(1234, util.USD).String())
+	require.Equal(t, "0.05", New(5, util.CAD).String())
+	require.Equal(t, "-0.05", New(-5, util.CAD).String())
+	require.Equal(t, "0.00", New(0, util.EUR).String())
+	require.Equal(t, "-92233720368547758.08", New(math.MinInt64, util.USD).String())
+	require.Equal(t, "1234", New(1234, "XXX").String())
+}
+
+func TestArithmetic(t *testing.T) {
+	sum, err := New(150, util.USD).Add(New(-200, util.USD))
+	require.NoError(t, err)
+	require.Equal(t, New(-50, util.USD), sum)
+
+	diff, err := New(150, util.USD).Sub(New(200, util.USD))
+	require.NoError(t, err)
+	require.Equal(t, New(-50, util.USD), diff)
+
+	diff, err = New(-1, util.USD).Sub(New(math.MinInt64, util.USD))
+	require.NoError(t, err)
+	require.Equal(t, New(math.MaxInt64, util.USD), diff)
+
+	neg, err := New(150, util.USD).Neg()
+	require.NoError(t, err)
+	require.Equal(t, New(-150, util.USD), neg)
+
+	_, err = New(math.MaxInt64, util.USD).Add(New(1, util.USD))
+	require.ErrorIs(t, err, ErrOverflow)
+
+	_, err = New(math.MinInt64, util.USD).Add(New(-1, util.USD))
+	require.ErrorIs(t, err, ErrOverflow)
+
+	_, err = New(0, util.USD).Sub(New(math.MinInt64, util.USD))
+	require.ErrorIs(t, err, ErrOverflow)
+
+	_, err = New(math.MinInt64, util.USD).Neg()
+	require.ErrorIs(t, err, ErrOverflow)
+
+	_, err = New(1, util.USD).Add(New(1, util.CAD))
+	require.ErrorIs(t, err, ErrCurrencyMismatch)
+
+	_, err = New(1, util.USD).Sub(New(1, util.CAD))
+	require.ErrorIs(t, err, ErrCurrencyMismatch)
+}
+
+func TestJSON(t *testing.T) {
+	data, err := json.Marshal(New(1205, util.USD))
+	require.NoError(t, err)
+	require.JSONEq(t, `{"amount":"12.05","currency":"USD"}`, string(data))
+
+	var m Money
+	require.NoError(t, json.Unmarshal(data, &m))
+	require.Equal(t, New(1205, util.USD), m)
+
+	_, err = json.Marshal(New(1, "XXX"))
+	require.ErrorIs(t, err, ErrUnknownCurrency)
+
+	require.ErrorIs(t, json.Unmarshal([]byte(`{"amount":"1.234","currency":"USD"}`), &m), ErrInvalidAmount)
+}
@@ -0,0 +1,121 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"sync"
+	"time"
+
+	db "github.com/crackz/simple-bank/db/sqlc"
+)
+
+// Event is a domain event as it is handed to publishers.
+type Event struct {
+	ID            int64           `json:"id"`
+	AggregateType string          `json:"aggregateType"`
+	AggregateID   string          `json:"aggregateID"`
+	Type          string          `json:"type"`
+	Payload       json.RawMessage `json:"payload"`
+	OccurredAt    time.Time       `json:"occurredAt"`
+}
+
+func newEvent(record db.Outbox) Event {
+	return Event{
+		ID:            record.ID,
+		AggregateType: record.AggregateType,
+		AggregateID:   record.AggregateID,
+		Type:          record.EventType,
+		Payload:       record.Payload,
+		OccurredAt:    record.CreatedAt,
+	}
+}
+
+// Publisher delivers events to the outside world. Publish must only return
+// nil once the event is delivered; the event is retried otherwise. Since an
+// event can be delivered more than once, consumers should deduplicate by ID.
+type Publisher interface {
+	Publish(ctx context.Context, event Event) error
+}
+
+// FanoutPublisher publishes every event to each of its publishers in turn and
+// fails if any of them does. The ones before the failing publisher see the
+// event again when it is retried.
+type FanoutPublisher struct {
+	publishers []Publisher
+}
+
+func NewFanoutPublisher(publishers ...Publisher) *FanoutPublisher {
+	return &FanoutPublisher{publishers: publishers}
+}
+
+func (publisher *FanoutPublisher) Publish(ctx context.Context, event Event) error {
+	for _, p := range publisher.publishers {
+		if err := p.Publish(ctx, event); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ChannelPublisher delivers events to in-process consumers over a channel.
+type ChannelPublisher struct {
+	events chan Event
+}
+
+func NewChannelPublisher(buffer int) *ChannelPublisher {
+	return &ChannelPublisher{events: make(chan Event, buffer)}
+}
+
+// Events returns the channel events are delivered on.
+func (publisher *ChannelPublisher) Events() <-chan Event {
+	return publisher.events
+}
+
+// Publish blocks until the event is received or buffered, or ctx is done.
+func (publisher *ChannelPublisher) Publish(ctx context.Context, event Event) error {
+	select {
+	case publisher.events <- event:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// FilePublisher appends events to a file as newline delimited JSON.
+type FilePublisher struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+func NewFilePublisher(path string) (*FilePublisher, error) {
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, err
+	}
+
+	return &FilePublisher{file: file}, nil
+}
+
+// Publish writes the event on its own line and syncs the file, so a published
+// event survives a crash.
+func (publisher *FilePublisher) Publish(ctx context.Context, event Event) error {
+	line, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	publisher.mu.Lock()
+	defer publisher.mu.Unlock()
+
+	if _, err := publisher.file.Write(append(line, '\n')); err != nil {
+		return err
+	}
+
+	return publisher.file.Sync()
+}
+
+func (publisher *FilePublisher) Close() error {
+	return publisher.file.Close()
+}
@@ -0,0 +1,98 @@
+package outbox
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	db "github.com/crackz/simple-bank/db/sqlc"
+	"github.com/stretchr/testify/require"
+)
+
+func randomEvent(id int64) Event {
+	return Event{
+		ID:            id,
+		AggregateType: db.AggregateAccount,
+		AggregateID:   "1",
+		Type:          db.EventAccountCreated,
+		Payload:       json.RawMessage(`{"accountID":1}`),
+		OccurredAt:    time.Now().UTC().Truncate(time.Second),
+	}
+}
+
+func TestChannelPublisher(t *testing.T) {
+	publisher := NewChannelPublisher(1)
+	event := randomEvent(1)
+
+	require.NoError(t, publisher.Publish(context.Background(), event))
+	require.Equal(t, event, <-publisher.Events())
+
+	// a full channel blocks until ctx is done
+	require.NoError(t, publisher.Publish(context.Background(), event))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	require.ErrorIs(t, publisher.Publish(ctx, event), context.DeadlineExceeded)
+}
+
+func TestFilePublisher(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.ndjson")
+
+	publisher, err := NewFilePublisher(path)
+	require.NoError(t, err)
+
+	events := []Event{randomEvent(1), randomEvent(2)}
+	for _, event := range events {
+		require.NoError(t, publisher.Publish(context.Background(), event))
+	}
+	require.NoError(t, publisher.Close())
+
+	// reopening appends instead of truncating
+	publisher, err = NewFilePublisher(path)
+	require.NoError(t, err)
+	events = append(events, randomEvent(3))
+	require.NoError(t, publisher.Publish(context.Background(), events[2]))
+	require.NoError(t, publisher.Close())
+
+	file, err := os.Open(path)
+	require.NoError(t, err)
+	defer file.Close()
+
+	var written []Event
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var event Event
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &event))
+		written = append(written, event)
+	}
+	require.NoError(t, scanner.Err())
+	require.Equal(t, len(events), len(written))
+	for i := range events {
+		require.Equal(t, events[i].ID, written[i].ID)
+		require.Equal(t, events[i].Type, written[i].Type)
+		require.JSONEq(t, string(events[i].Payload), string(written[i].Payload))
+		require.True(t, events[i].OccurredAt.Equal(written[i].OccurredAt))
+	}
+}
+
+func TestFanoutPublisher(t *testing.T) {
+	first := NewChannelPublisher(1)
+	second := NewChannelPublisher(1)
+	event := randomEvent(1)
+
+	publisher := NewFanoutPublisher(first, second)
+	require.NoError(t, publisher.Publish(context.Background(), event))
+	require.Equal(t, event, <-first.Events())
+	require.Equal(t, event, <-second.Events())
+
+	// the second publisher is full, so the event fails and is retried later
+	require.NoError(t, second.Publish(context.Background(), event))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	require.ErrorIs(t, publisher.Publish(ctx, event), context.DeadlineExceeded)
+}
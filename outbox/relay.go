@@ -0,0 +1,77 @@
+package outbox
+
+import (
+	"context"
+	"log"
+	"time"
+
+	db "github.com/crackz/simple-bank/db/sqlc"
+)
+
+const (
+	defaultInterval  = time.Second
+	defaultBatchSize = 100
+)
+
+// Relay periodically publishes the events recorded in the outbox. Events are
+// published in the order they were recorded, which keeps them ordered per
+// aggregate, and an event that fails to publish holds back the ones after it
+// until it goes through.
+type Relay struct {
+	store     db.Store
+	publisher Publisher
+	interval  time.Duration
+	batchSize int32
+}
+
+func NewRelay(store db.Store, publisher Publisher, interval time.Duration, batchSize int32) *Relay {
+	if interval <= 0 {
+		interval = defaultInterval
+	}
+	if batchSize <= 0 {
+		batchSize = defaultBatchSize
+	}
+
+	return &Relay{
+		store:     store,
+		publisher: publisher,
+		interval:  interval,
+		batchSize: batchSize,
+	}
+}
+
+// Start blocks publishing pending events every interval until ctx is done.
+func (relay *Relay) Start(ctx context.Context) {
+	ticker := time.NewTicker(relay.interval)
+	defer ticker.Stop()
+
+	for {
+		relay.RelayPending(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RelayPending publishes batches of pending events until none is left or
+// publishing fails.
+func (relay *Relay) RelayPending(ctx context.Context) {
+	for ctx.Err() == nil {
+		published, err := relay.store.RelayOutboxEvents(ctx, relay.batchSize, relay.publish)
+		if err != nil {
+			log.Println("Couldn't Relay Outbox Events : ", err)
+			return
+		}
+
+		if published < int(relay.batchSize) {
+			return
+		}
+	}
+}
+
+func (relay *Relay) publish(ctx context.Context, record db.Outbox) error {
+	return relay.publisher.Publish(ctx, newEvent(record))
+}
@@ -0,0 +1,97 @@
+package outbox
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	mockdb "github.com/crackz/simple-bank/db/mock"
+	db "github.com/crackz/simple-bank/db/sqlc"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+// relayRecords stubs RelayOutboxEvents by publishing records the way the store
+// does, stopping at the first failure.
+func relayRecords(records ...db.Outbox) func(context.Context, int32, func(context.Context, db.Outbox) error) (int, error) {
+	return func(ctx context.Context, _ int32, publish func(context.Context, db.Outbox) error) (int, error) {
+		for i, record := range records {
+			if err := publish(ctx, record); err != nil {
+				return i, err
+			}
+		}
+		return len(records), nil
+	}
+}
+
+type failingPublisher struct{}
+
+func (failingPublisher) Publish(context.Context, Event) error {
+	return errors.New("sink unavailable")
+}
+
+func TestRelayPending(t *testing.T) {
+	testCases := []struct {
+		name       string
+		publisher  func() Publisher
+		buildStubs func(store *mockdb.MockStore)
+		check      func(t *testing.T, publisher Publisher)
+	}{
+		{
+			name:      "Drains Full Batches In Order",
+			publisher: func() Publisher { return NewChannelPublisher(3) },
+			buildStubs: func(store *mockdb.MockStore) {
+				gomock.InOrder(
+					store.EXPECT().RelayOutboxEvents(gomock.Any(), gomock.Eq(int32(2)), gomock.Any()).Times(1).DoAndReturn(relayRecords(db.Outbox{ID: 1}, db.Outbox{ID: 2})),
+					store.EXPECT().RelayOutboxEvents(gomock.Any(), gomock.Eq(int32(2)), gomock.Any()).Times(1).DoAndReturn(relayRecords(db.Outbox{ID: 3})),
+				)
+			},
+			check: func(t *testing.T, publisher Publisher) {
+				events := publisher.(*ChannelPublisher).Events()
+				for id := int64(1); id <= 3; id++ {
+					require.Equal(t, id, (<-events).ID)
+				}
+			},
+		},
+		{
+			name:      "Stops On Publish Failure",
+			publisher: func() Publisher { return failingPublisher{} },
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					RelayOutboxEvents(gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(relayRecords(db.Outbox{ID: 1}, db.Outbox{ID: 2}))
+			},
+			check: func(t *testing.T, publisher Publisher) {},
+		},
+		{
+			name:      "Stops On Error",
+			publisher: func() Publisher { return NewChannelPublisher(0) },
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					RelayOutboxEvents(gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(0, sql.ErrConnDone)
+			},
+			check: func(t *testing.T, publisher Publisher) {},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			publisher := tc.publisher()
+			NewRelay(store, publisher, time.Second, 2).RelayPending(context.Background())
+			tc.check(t, publisher)
+		})
+	}
+}
@@ -0,0 +1,47 @@
+package pagination
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/crackz/simple-bank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSigner(t *testing.T) {
+	signer := NewSigner(util.RandString(32))
+	cursor := Cursor{CreatedAt: time.Now().UTC().Truncate(time.Microsecond), ID: 42}
+
+	encoded, err := signer.Encode("accounts:alice", cursor)
+	require.NoError(t, err)
+
+	decoded, err := signer.Decode("accounts:alice", encoded)
+	require.NoError(t, err)
+	require.True(t, cursor.CreatedAt.Equal(decoded.CreatedAt))
+	require.Equal(t, cursor.ID, decoded.ID)
+
+	decoded, err = signer.Decode("accounts:alice", "")
+	require.NoError(t, err)
+	require.Equal(t, Cursor{}, decoded)
+
+	// a cursor only pages through the list it was made for
+	_, err = signer.Decode("accounts:bob", encoded)
+	require.ErrorIs(t, err, ErrInvalidCursor)
+
+	payload, signature, _ := strings.Cut(encoded, ".")
+	tampered, err := signer.Encode("accounts:alice", Cursor{CreatedAt: cursor.CreatedAt, ID: 43})
+	require.NoError(t, err)
+	tamperedPayload, _, _ := strings.Cut(tampered, ".")
+	require.NotEqual(t, payload, tamperedPayload)
+
+	invalid := []string{"garbage", tamperedPayload + "." + signature, payload + ".", "." + signature}
+	for _, s := range invalid {
+		_, err = signer.Decode("accounts:alice", s)
+		require.ErrorIs(t, err, ErrInvalidCursor, s)
+	}
+
+	// cursors signed with another secret don't verify
+	_, err = NewSigner(util.RandString(32)).Decode("accounts:alice", encoded)
+	require.ErrorIs(t, err, ErrInvalidCursor)
+}
@@ -0,0 +1,146 @@
+// Package pain reads ISO 20022 pain.001 customer credit transfer initiations
+// and writes the pain.002 payment status reports that answer them.
+package pain
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+)
+
+const pain001Namespace = "urn:iso:std:iso:20022:tech:xsd:pain.001.001.03"
+
+// MaxTransactions is the most credit transfers a file may hold.
+const MaxTransactions = 1000
+
+var ErrInvalidDocument = errors.New("invalid pain.001 document")
+
+// Initiation is a pain.001.001.03 customer credit transfer initiation. Only
+// the elements needed to make the transfers are read.
+type Initiation struct {
+	MessageID            string `xml:"GrpHdr>MsgId"`
+	CreatedAt            string `xml:"GrpHdr>CreDtTm"`
+	NumberOfTransactions int    `xml:"GrpHdr>NbOfTxs"`
+	// ControlSum is the sum of all amounts regardless of currency, if sent.
+	ControlSum      string    `xml:"GrpHdr>CtrlSum"`
+	InitiatingParty string    `xml:"GrpHdr>InitgPty>Nm"`
+	Payments        []Payment `xml:"PmtInf"`
+}
+
+// Payment is a payment information block: credit transfers debited from the
+// same account.
+type Payment struct {
+	ID                     string `xml:"PmtInfId"`
+	Method                 string `xml:"PmtMtd"`
+	RequestedExecutionDate string `xml:"ReqdExctnDt"`
+	DebtorName             string `xml:"Dbtr>Nm"`
+	// DebtorAccount is the proprietary identification of the account, which
+	// is the account ID. It's empty when the account is identified otherwise.
+	DebtorAccount string           `xml:"DbtrAcct>Id>Othr>Id"`
+	Transactions  []CreditTransfer `xml:"CdtTrfTxInf"`
+}
+
+type CreditTransfer struct {
+	InstructionID string `xml:"PmtId>InstrId"`
+	EndToEndID    string `xml:"PmtId>EndToEndId"`
+	Amount        Amount `xml:"Amt>InstdAmt"`
+	CreditorName  string `xml:"Cdtr>Nm"`
+	// CreditorAccount is identified like Payment.DebtorAccount.
+	CreditorAccount string `xml:"CdtrAcct>Id>Othr>Id"`
+	Remittance      string `xml:"RmtInf>Ustrd"`
+}
+
+// Amount is a decimal amount as written in the file.
+type Amount struct {
+	Currency string `xml:"Ccy,attr"`
+	Value    string `xml:",chardata"`
+}
+
+type pain001Document struct {
+	XMLName    xml.Name   `xml:"Document"`
+	Initiation Initiation `xml:"CstmrCdtTrfInitn"`
+}
+
+// Parse reads a pain.001.001.03 document and checks that it's consistent: the
+// number of transactions and control sum of the group header match the
+// transactions, and every element needed to make a transfer is there.
+// Whether the accounts and amounts are acceptable is left to the caller, so
+// each instruction can be rejected on its own.
+func Parse(r io.Reader) (Initiation, error) {
+	var document pain001Document
+
+	if err := xml.NewDecoder(r).Decode(&document); err != nil {
+		return Initiation{}, fmt.Errorf("%w: %w", ErrInvalidDocument, err)
+	}
+
+	if document.XMLName.Space != pain001Namespace {
+		return Initiation{}, fmt.Errorf("%w: namespace must be %s", ErrInvalidDocument, pain001Namespace)
+	}
+
+	initiation := document.Initiation
+	if err := initiation.validate(); err != nil {
+		return Initiation{}, fmt.Errorf("%w: %s", ErrInvalidDocument, err)
+	}
+
+	return initiation, nil
+}
+
+func (initiation Initiation) validate() error {
+	if initiation.MessageID == "" {
+		return errors.New("missing message id")
+	}
+	if len(initiation.Payments) == 0 {
+		return errors.New("missing payment information")
+	}
+
+	count := 0
+	sum := new(big.Rat)
+	for _, payment := range initiation.Payments {
+		if payment.ID == "" {
+			return errors.New("missing payment information id")
+		}
+		if payment.Method != "TRF" {
+			return fmt.Errorf("payment %s: payment method must be TRF", payment.ID)
+		}
+		if len(payment.Transactions) == 0 {
+			return fmt.Errorf("payment %s: missing credit transfer", payment.ID)
+		}
+
+		for _, transfer := range payment.Transactions {
+			if transfer.EndToEndID == "" {
+				return fmt.Errorf("payment %s: missing end to end id", payment.ID)
+			}
+			if transfer.Amount.Currency == "" {
+				return fmt.Errorf("payment %s: transfer %s: missing currency", payment.ID, transfer.EndToEndID)
+			}
+
+			amount, ok := new(big.Rat).SetString(transfer.Amount.Value)
+			if !ok {
+				return fmt.Errorf("payment %s: transfer %s: invalid amount %q", payment.ID, transfer.EndToEndID, transfer.Amount.Value)
+			}
+			sum.Add(sum, amount)
+			count++
+		}
+	}
+
+	if count > MaxTransactions {
+		return fmt.Errorf("at most %d transactions are allowed", MaxTransactions)
+	}
+	if count != initiation.NumberOfTransactions {
+		return fmt.Errorf("number of transactions is %d but %d were sent", initiation.NumberOfTransactions, count)
+	}
+
+	if initiation.ControlSum != "" {
+		controlSum, ok := new(big.Rat).SetString(initiation.ControlSum)
+		if !ok {
+			return fmt.Errorf("invalid control sum %q", initiation.ControlSum)
+		}
+		if controlSum.Cmp(sum) != 0 {
+			return fmt.Errorf("control sum is %s but the amounts add up to %s", initiation.ControlSum, sum.FloatString(5))
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,207 @@
+package pain
+
+import (
+	"encoding/xml"
+	"io"
+	"strconv"
+	"time"
+)
+
+const pain002Namespace = "urn:iso:std:iso:20022:tech:xsd:pain.002.001.03"
+
+// Transaction and group statuses of a status report.
+const (
+	// StatusAccepted means the instruction passed validation but wasn't made,
+	// which is the answer to a dry run.
+	StatusAccepted = "ACTC"
+	// StatusSettled means the transfer was made.
+	StatusSettled = "ACSC"
+	// StatusPending means the transfer is held for review.
+	StatusPending  = "PDNG"
+	StatusRejected = "RJCT"
+	// StatusPartiallyAccepted is only a group or payment status, for
+	// transactions that don't all have the same status.
+	StatusPartiallyAccepted = "PART"
+)
+
+// Status reason codes of rejected transactions, from the ISO 20022 external
+// status reason code set.
+const (
+	ReasonIncorrectAccount     = "AC01"
+	ReasonClosedAccount        = "AC04"
+	ReasonBlockedAccount       = "AC06"
+	ReasonTransactionForbidden = "AG01"
+	ReasonAmountNotAllowed     = "AM02"
+	ReasonCurrencyNotAllowed   = "AM03"
+	ReasonInsufficientFunds    = "AM04"
+	ReasonDuplicate            = "AM05"
+	ReasonInvalidCurrency      = "AM11"
+	ReasonInvalidAmount        = "AM12"
+	ReasonNarrative            = "NARR"
+)
+
+// StatusReport is a pain.002.001.03 customer payment status report answering
+// an Initiation, with the status of each of its transactions.
+type StatusReport struct {
+	MessageID         string
+	CreatedAt         time.Time
+	OriginalMessageID string
+	Payments          []PaymentStatus
+}
+
+type PaymentStatus struct {
+	OriginalPaymentID string
+	Transactions      []TransactionStatus
+}
+
+type TransactionStatus struct {
+	OriginalInstructionID string
+	OriginalEndToEndID    string
+	Status                string
+	// Reason and Information are only set for rejected or pending
+	// transactions.
+	Reason      string
+	Information string
+	// TransferID is the transfer made for a settled transaction.
+	TransferID int64
+}
+
+// NewStatusReport starts the report answering initiation, with one payment
+// status for each of its payments and no transaction statuses yet.
+func NewStatusReport(messageID string, createdAt time.Time, initiation Initiation) StatusReport {
+	report := StatusReport{
+		MessageID:         messageID,
+		CreatedAt:         createdAt,
+		OriginalMessageID: initiation.MessageID,
+		Payments:          make([]PaymentStatus, 0, len(initiation.Payments)),
+	}
+	for _, payment := range initiation.Payments {
+		report.Payments = append(report.Payments, PaymentStatus{
+			OriginalPaymentID: payment.ID,
+			Transactions:      make([]TransactionStatus, 0, len(payment.Transactions)),
+		})
+	}
+
+	return report
+}
+
+// Status is the status shared by all transactions of the report, or
+// StatusPartiallyAccepted if they don't all have the same.
+func (report StatusReport) Status() string {
+	var transactions []TransactionStatus
+	for _, payment := range report.Payments {
+		transactions = append(transactions, payment.Transactions...)
+	}
+
+	return commonStatus(transactions)
+}
+
+func (payment PaymentStatus) Status() string {
+	return commonStatus(payment.Transactions)
+}
+
+func commonStatus(transactions []TransactionStatus) string {
+	if len(transactions) == 0 {
+		return StatusRejected
+	}
+
+	status := transactions[0].Status
+	for _, transaction := range transactions[1:] {
+		if transaction.Status != status {
+			return StatusPartiallyAccepted
+		}
+	}
+
+	return status
+}
+
+type statusDocument struct {
+	XMLName   xml.Name     `xml:"Document"`
+	Namespace string       `xml:"xmlns,attr"`
+	Report    statusReport `xml:"CstmrPmtStsRpt"`
+}
+
+type statusReport struct {
+	MessageID           string          `xml:"GrpHdr>MsgId"`
+	CreatedAt           string          `xml:"GrpHdr>CreDtTm"`
+	OriginalMessageID   string          `xml:"OrgnlGrpInfAndSts>OrgnlMsgId"`
+	OriginalMessageName string          `xml:"OrgnlGrpInfAndSts>OrgnlMsgNmId"`
+	GroupStatus         string          `xml:"OrgnlGrpInfAndSts>GrpSts"`
+	Payments            []paymentStatus `xml:"OrgnlPmtInfAndSts"`
+}
+
+type paymentStatus struct {
+	OriginalPaymentID string              `xml:"OrgnlPmtInfId"`
+	Status            string              `xml:"PmtInfSts"`
+	Transactions      []transactionStatus `xml:"TxInfAndSts"`
+}
+
+type transactionStatus struct {
+	OriginalInstructionID string        `xml:"OrgnlInstrId,omitempty"`
+	OriginalEndToEndID    string        `xml:"OrgnlEndToEndId"`
+	Status                string        `xml:"TxSts"`
+	Reason                *statusReason `xml:"StsRsnInf"`
+	// the account servicer reference is the transfer id
+	TransferID string `xml:"AcctSvcrRef,omitempty"`
+}
+
+type statusReason struct {
+	Code        string `xml:"Rsn>Cd,omitempty"`
+	Information string `xml:"AddtlInf,omitempty"`
+}
+
+// WriteStatusReport writes report as a pain.002.001.03 document.
+func WriteStatusReport(w io.Writer, report StatusReport) error {
+	document := statusDocument{
+		Namespace: pain002Namespace,
+		Report: statusReport{
+			MessageID:           report.MessageID,
+			CreatedAt:           report.CreatedAt.UTC().Format("2006-01-02T15:04:05Z"),
+			OriginalMessageID:   report.OriginalMessageID,
+			OriginalMessageName: "pain.001.001.03",
+			GroupStatus:         report.Status(),
+			Payments:            make([]paymentStatus, 0, len(report.Payments)),
+		},
+	}
+
+	for _, payment := range report.Payments {
+		status := paymentStatus{
+			OriginalPaymentID: payment.OriginalPaymentID,
+			Status:            payment.Status(),
+			Transactions:      make([]transactionStatus, 0, len(payment.Transactions)),
+		}
+		for _, transaction := range payment.Transactions {
+			status.Transactions = append(status.Transactions, newTransactionStatus(transaction))
+		}
+		document.Report.Payments = append(document.Report.Payments, status)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(document); err != nil {
+		return err
+	}
+
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+func newTransactionStatus(transaction TransactionStatus) transactionStatus {
+	status := transactionStatus{
+		OriginalInstructionID: transaction.OriginalInstructionID,
+		OriginalEndToEndID:    transaction.OriginalEndToEndID,
+		Status:                transaction.Status,
+	}
+	if transaction.Reason != "" || transaction.Information != "" {
+		status.Reason = &statusReason{Code: transaction.Reason, Information: transaction.Information}
+	}
+	if transaction.TransferID != 0 {
+		status.TransferID = strconv.FormatInt(transaction.TransferID, 10)
+	}
+
+	return status
+}
@@ -0,0 +1,150 @@
+package pain
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+const testInitiation = `<?xml version="1.0" encoding="UTF-8"?>
+<Document xmlns="urn:iso:std:iso:20022:tech:xsd:pain.001.001.03">
+  <CstmrCdtTrfInitn>
+    <GrpHdr>
+      <MsgId>MSG-1</MsgId>
+      <CreDtTm>2024-03-01T09:30:00</CreDtTm>
+      <NbOfTxs>%d</NbOfTxs>
+      <CtrlSum>%s</CtrlSum>
+      <InitgPty><Nm>ACME</Nm></InitgPty>
+    </GrpHdr>
+    <PmtInf>
+      <PmtInfId>PMT-1</PmtInfId>
+      <PmtMtd>TRF</PmtMtd>
+      <ReqdExctnDt>2024-03-01</ReqdExctnDt>
+      <Dbtr><Nm>ACME</Nm></Dbtr>
+      <DbtrAcct><Id><Othr><Id>1</Id></Othr></Id></DbtrAcct>
+      <CdtTrfTxInf>
+        <PmtId><InstrId>I-1</InstrId><EndToEndId>E2E-1</EndToEndId></PmtId>
+        <Amt><InstdAmt Ccy="USD">10.50</InstdAmt></Amt>
+        <Cdtr><Nm>Supplier</Nm></Cdtr>
+        <CdtrAcct><Id><Othr><Id>2</Id></Othr></Id></CdtrAcct>
+        <RmtInf><Ustrd>Invoice 42</Ustrd></RmtInf>
+      </CdtTrfTxInf>
+      <CdtTrfTxInf>
+        <PmtId><EndToEndId>E2E-2</EndToEndId></PmtId>
+        <Amt><InstdAmt Ccy="USD">4.5</InstdAmt></Amt>
+        <CdtrAcct><Id><IBAN>DE89370400440532013000</IBAN></Id></CdtrAcct>
+      </CdtTrfTxInf>
+    </PmtInf>
+  </CstmrCdtTrfInitn>
+</Document>`
+
+func initiationFile(numberOfTransactions int, controlSum string) string {
+	return fmt.Sprintf(testInitiation, numberOfTransactions, controlSum)
+}
+
+func TestParse(t *testing.T) {
+	initiation, err := Parse(strings.NewReader(initiationFile(2, "15.00")))
+	require.NoError(t, err)
+
+	require.Equal(t, "MSG-1", initiation.MessageID)
+	require.Equal(t, "ACME", initiation.InitiatingParty)
+	require.Len(t, initiation.Payments, 1)
+
+	payment := initiation.Payments[0]
+	require.Equal(t, "PMT-1", payment.ID)
+	require.Equal(t, "1", payment.DebtorAccount)
+	require.Len(t, payment.Transactions, 2)
+
+	require.Equal(t, CreditTransfer{
+		InstructionID:   "I-1",
+		EndToEndID:      "E2E-1",
+		Amount:          Amount{Currency: "USD", Value: "10.50"},
+		CreditorName:    "Supplier",
+		CreditorAccount: "2",
+		Remittance:      "Invoice 42",
+	}, payment.Transactions[0])
+
+	// accounts that aren't identified by account id are left for the caller
+	// to reject
+	require.Empty(t, payment.Transactions[1].CreditorAccount)
+}
+
+func TestParseInvalid(t *testing.T) {
+	testCases := []struct {
+		name string
+		file string
+	}{
+		{name: "NumberOfTransactions", file: initiationFile(3, "15.00")},
+		{name: "ControlSum", file: initiationFile(2, "15.01")},
+		{name: "Namespace", file: strings.Replace(initiationFile(2, "15"), "pain.001.001.03", "pain.001.001.09", 1)},
+		{name: "PaymentMethod", file: strings.Replace(initiationFile(2, "15"), "<PmtMtd>TRF", "<PmtMtd>CHK", 1)},
+		{name: "MissingEndToEndID", file: strings.Replace(initiationFile(2, "15"), "E2E-2", "", 1)},
+		{name: "Amount", file: strings.Replace(initiationFile(2, "15"), "4.5<", "four<", 1)},
+		{name: "NotXML", file: "amount,currency\n10,USD\n"},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := Parse(strings.NewReader(tc.file))
+			require.ErrorIs(t, err, ErrInvalidDocument)
+		})
+	}
+}
+
+func TestWriteStatusReport(t *testing.T) {
+	initiation, err := Parse(strings.NewReader(initiationFile(2, "15")))
+	require.NoError(t, err)
+
+	report := NewStatusReport("RPT-1", time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC), initiation)
+	report.Payments[0].Transactions = append(report.Payments[0].Transactions,
+		TransactionStatus{OriginalInstructionID: "I-1", OriginalEndToEndID: "E2E-1", Status: StatusSettled, TransferID: 7},
+		TransactionStatus{OriginalEndToEndID: "E2E-2", Status: StatusRejected, Reason: ReasonIncorrectAccount, Information: "creditor account must be an account id"},
+	)
+	require.Equal(t, StatusPartiallyAccepted, report.Status())
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteStatusReport(&buf, report))
+
+	expected := `<?xml version="1.0" encoding="UTF-8"?>
+<Document xmlns="urn:iso:std:iso:20022:tech:xsd:pain.002.001.03">
+  <CstmrPmtStsRpt>
+    <GrpHdr>
+      <MsgId>RPT-1</MsgId>
+      <CreDtTm>2024-03-01T10:00:00Z</CreDtTm>
+    </GrpHdr>
+    <OrgnlGrpInfAndSts>
+      <OrgnlMsgId>MSG-1</OrgnlMsgId>
+      <OrgnlMsgNmId>pain.001.001.03</OrgnlMsgNmId>
+      <GrpSts>PART</GrpSts>
+    </OrgnlGrpInfAndSts>
+    <OrgnlPmtInfAndSts>
+      <OrgnlPmtInfId>PMT-1</OrgnlPmtInfId>
+      <PmtInfSts>PART</PmtInfSts>
+      <TxInfAndSts>
+        <OrgnlInstrId>I-1</OrgnlInstrId>
+        <OrgnlEndToEndId>E2E-1</OrgnlEndToEndId>
+        <TxSts>ACSC</TxSts>
+        <AcctSvcrRef>7</AcctSvcrRef>
+      </TxInfAndSts>
+      <TxInfAndSts>
+        <OrgnlEndToEndId>E2E-2</OrgnlEndToEndId>
+        <TxSts>RJCT</TxSts>
+        <StsRsnInf>
+          <Rsn>
+            <Cd>AC01</Cd>
+          </Rsn>
+          <AddtlInf>creditor account must be an account id</AddtlInf>
+        </StsRsnInf>
+      </TxInfAndSts>
+    </OrgnlPmtInfAndSts>
+  </CstmrPmtStsRpt>
+</Document>
+`
+	require.Equal(t, expected, buf.String())
+}
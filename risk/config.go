@@ -0,0 +1,41 @@
+package risk
+
+import "github.com/crackz/simple-bank/util"
+
+// NewRules reads the risk rules from the config. A rule that is on but has
+// no outcome holds transfers for review.
+func NewRules(config *util.Config) (Rules, error) {
+	rules := Rules{
+		VelocityCount:        config.RiskVelocityCount,
+		VelocityWindow:       config.RiskVelocityWindow,
+		NewPayeeAmount:       config.RiskNewPayeeAmount,
+		RoundAmountUnit:      config.RiskRoundAmountUnit,
+		RoundAmountCount:     config.RiskRoundAmountCount,
+		RoundAmountWindow:    config.RiskRoundAmountWindow,
+		PasswordChangeWindow: config.RiskPasswordChangeWindow,
+	}
+
+	outcomes := []struct {
+		value   string
+		outcome *Outcome
+	}{
+		{config.RiskVelocityOutcome, &rules.VelocityOutcome},
+		{config.RiskNewPayeeOutcome, &rules.NewPayeeOutcome},
+		{config.RiskRoundAmountOutcome, &rules.RoundAmountOutcome},
+		{config.RiskPasswordChangeOutcome, &rules.PasswordChangeOutcome},
+	}
+	for _, o := range outcomes {
+		if o.value == "" {
+			*o.outcome = Review
+			continue
+		}
+
+		outcome, err := ParseOutcome(o.value)
+		if err != nil {
+			return Rules{}, err
+		}
+		*o.outcome = outcome
+	}
+
+	return rules, nil
+}
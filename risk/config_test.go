@@ -0,0 +1,28 @@
+package risk
+
+import (
+	"testing"
+	"time"
+
+	"github.com/crackz/simple-bank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewRules(t *testing.T) {
+	rules, err := NewRules(&util.Config{
+		RiskVelocityCount:     5,
+		RiskVelocityWindow:    time.Minute,
+		RiskVelocityOutcome:   "block",
+		RiskNewPayeeAmount:    1000,
+		RiskRoundAmountUnit:   100,
+		RiskRoundAmountCount:  3,
+		RiskRoundAmountWindow: time.Hour,
+	})
+	require.NoError(t, err)
+	require.True(t, rules.Enabled())
+	require.Equal(t, Block, rules.VelocityOutcome)
+	require.Equal(t, Review, rules.NewPayeeOutcome)
+
+	_, err = NewRules(&util.Config{RiskNewPayeeOutcome: "hold"})
+	require.ErrorIs(t, err, ErrUnknownOutcome)
+}
@@ -0,0 +1,127 @@
+package risk
+
+import (
+	"errors"
+	"time"
+)
+
+var ErrUnknownOutcome = errors.New("risk outcome must be allow, review or block")
+
+// Outcome is what happens to a transfer that triggers a rule.
+type Outcome string
+
+const (
+	// Allow makes the transfer and only records that the rule triggered.
+	Allow Outcome = "allow"
+	// Review holds the transfer until staff approve or reject it.
+	Review Outcome = "review"
+	// Block refuses the transfer.
+	Block Outcome = "block"
+)
+
+func ParseOutcome(value string) (Outcome, error) {
+	switch outcome := Outcome(value); outcome {
+	case Allow, Review, Block:
+		return outcome, nil
+	}
+
+	return "", ErrUnknownOutcome
+}
+
+// stricter reports whether o stops more transfers than other.
+func (o Outcome) stricter(other Outcome) bool {
+	severity := map[Outcome]int{Allow: 0, Review: 1, Block: 2}
+	return severity[o] > severity[other]
+}
+
+// Rule names, as recorded with a decision.
+const (
+	RuleVelocity            = "velocity"
+	RuleNewPayeeLargeAmount = "new_payee_large_amount"
+	RuleRoundAmount         = "round_amount"
+	RulePasswordChange      = "first_transfer_after_password_change"
+)
+
+// Rules configures the screening rules. A rule with a zero threshold is off.
+type Rules struct {
+	// VelocityCount is how many transfers a user can make within
+	// VelocityWindow before the next one triggers the rule.
+	VelocityCount   int64
+	VelocityWindow  time.Duration
+	VelocityOutcome Outcome
+
+	// NewPayeeAmount is the amount from which a transfer to an account the
+	// user never sent money to triggers the rule.
+	NewPayeeAmount  int64
+	NewPayeeOutcome Outcome
+
+	// A transfer of a multiple of RoundAmountUnit triggers the rule when it
+	// makes RoundAmountCount such transfers within RoundAmountWindow, which is
+	// how amounts are split to stay under reporting thresholds.
+	RoundAmountUnit    int64
+	RoundAmountCount   int64
+	RoundAmountWindow  time.Duration
+	RoundAmountOutcome Outcome
+
+	// The first transfer within PasswordChangeWindow of a password change
+	// triggers the rule, since taking over an account usually starts there.
+	PasswordChangeWindow  time.Duration
+	PasswordChangeOutcome Outcome
+}
+
+// Enabled reports whether any rule is on, so transfers need to be screened.
+func (r Rules) Enabled() bool {
+	return r.VelocityCount > 0 ||
+		r.NewPayeeAmount > 0 ||
+		(r.RoundAmountUnit > 0 && r.RoundAmountCount > 0) ||
+		r.PasswordChangeWindow > 0
+}
+
+// Facts is what the rules need to know about the sender's earlier transfers.
+// Fees and reversals aren't counted as transfers.
+type Facts struct {
+	// RecentTransfers is the number of transfers within the velocity window.
+	RecentTransfers int64
+	// PayeeTransfers is the number of transfers to the same destination.
+	PayeeTransfers int64
+	// RoundTransfers is the number of transfers of a multiple of the round
+	// unit within the round amount window.
+	RoundTransfers               int64
+	PasswordChangedAt            time.Time
+	TransfersSincePasswordChange int64
+}
+
+type Decision struct {
+	Outcome Outcome `json:"outcome"`
+	// Rules are the names of the rules the transfer triggered.
+	Rules []string `json:"rules"`
+}
+
+// Evaluate screens a transfer of amount made at now. The decision's outcome is
+// the strictest outcome of the triggered rules, and Allow when none triggered.
+func (r Rules) Evaluate(amount int64, facts Facts, now time.Time) Decision {
+	decision := Decision{Outcome: Allow, Rules: []string{}}
+	trigger := func(rule string, outcome Outcome) {
+		decision.Rules = append(decision.Rules, rule)
+		if outcome.stricter(decision.Outcome) {
+			decision.Outcome = outcome
+		}
+	}
+
+	if r.VelocityCount > 0 && facts.RecentTransfers >= r.VelocityCount {
+		trigger(RuleVelocity, r.VelocityOutcome)
+	}
+	if r.NewPayeeAmount > 0 && facts.PayeeTransfers == 0 && amount >= r.NewPayeeAmount {
+		trigger(RuleNewPayeeLargeAmount, r.NewPayeeOutcome)
+	}
+	if r.RoundAmountUnit > 0 && r.RoundAmountCount > 0 && amount%r.RoundAmountUnit == 0 &&
+		facts.RoundTransfers+1 >= r.RoundAmountCount {
+		trigger(RuleRoundAmount, r.RoundAmountOutcome)
+	}
+	if r.PasswordChangeWindow > 0 && facts.TransfersSincePasswordChange == 0 &&
+		now.Sub(facts.PasswordChangedAt) < r.PasswordChangeWindow {
+		trigger(RulePasswordChange, r.PasswordChangeOutcome)
+	}
+
+	return decision
+}
@@ -0,0 +1,147 @@
+package risk
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseOutcome(t *testing.T) {
+	for _, value := range []string{"allow", "review", "block"} {
+		outcome, err := ParseOutcome(value)
+		require.NoError(t, err)
+		require.Equal(t, Outcome(value), outcome)
+	}
+
+	_, err := ParseOutcome("hold")
+	require.ErrorIs(t, err, ErrUnknownOutcome)
+}
+
+func TestEvaluate(t *testing.T) {
+	now := time.Now()
+
+	rules := Rules{
+		VelocityCount:         3,
+		VelocityWindow:        10 * time.Minute,
+		VelocityOutcome:       Review,
+		NewPayeeAmount:        100000,
+		NewPayeeOutcome:       Review,
+		RoundAmountUnit:       10000,
+		RoundAmountCount:      3,
+		RoundAmountWindow:     24 * time.Hour,
+		RoundAmountOutcome:    Allow,
+		PasswordChangeWindow:  24 * time.Hour,
+		PasswordChangeOutcome: Block,
+	}
+
+	// a user with a history that triggers nothing
+	quiet := Facts{
+		RecentTransfers:              1,
+		PayeeTransfers:               2,
+		RoundTransfers:               0,
+		PasswordChangedAt:            now.AddDate(0, -1, 0),
+		TransfersSincePasswordChange: 10,
+	}
+
+	testCases := []struct {
+		name     string
+		amount   int64
+		facts    func(facts *Facts)
+		decision Decision
+	}{
+		{
+			name:     "Allow",
+			amount:   1234,
+			facts:    func(facts *Facts) {},
+			decision: Decision{Outcome: Allow, Rules: []string{}},
+		},
+		{
+			name:     "Velocity",
+			amount:   1234,
+			facts:    func(facts *Facts) { facts.RecentTransfers = 3 },
+			decision: Decision{Outcome: Review, Rules: []string{RuleVelocity}},
+		},
+		{
+			name:     "NewPayeeSmallAmount",
+			amount:   99999,
+			facts:    func(facts *Facts) { facts.PayeeTransfers = 0 },
+			decision: Decision{Outcome: Allow, Rules: []string{}},
+		},
+		{
+			name:     "NewPayeeLargeAmount",
+			amount:   100001,
+			facts:    func(facts *Facts) { facts.PayeeTransfers = 0 },
+			decision: Decision{Outcome: Review, Rules: []string{RuleNewPayeeLargeAmount}},
+		},
+		{
+			name:     "RoundAmount",
+			amount:   50000,
+			facts:    func(facts *Facts) { facts.RoundTransfers = 2 },
+			decision: Decision{Outcome: Allow, Rules: []string{RuleRoundAmount}},
+		},
+		{
+			name:     "RoundAmountBelowCount",
+			amount:   50000,
+			facts:    func(facts *Facts) { facts.RoundTransfers = 1 },
+			decision: Decision{Outcome: Allow, Rules: []string{}},
+		},
+		{
+			name:     "NotRoundAmount",
+			amount:   50001,
+			facts:    func(facts *Facts) { facts.RoundTransfers = 5 },
+			decision: Decision{Outcome: Allow, Rules: []string{}},
+		},
+		{
+			name:   "PasswordChange",
+			amount: 1234,
+			facts: func(facts *Facts) {
+				facts.PasswordChangedAt = now.Add(-time.Hour)
+				facts.TransfersSincePasswordChange = 0
+			},
+			decision: Decision{Outcome: Block, Rules: []string{RulePasswordChange}},
+		},
+		{
+			name:   "PasswordChangeAlreadyTransferred",
+			amount: 1234,
+			facts: func(facts *Facts) {
+				facts.PasswordChangedAt = now.Add(-time.Hour)
+				facts.TransfersSincePasswordChange = 1
+			},
+			decision: Decision{Outcome: Allow, Rules: []string{}},
+		},
+		{
+			name:   "StrictestOutcomeWins",
+			amount: 200000,
+			facts: func(facts *Facts) {
+				facts.RecentTransfers = 5
+				facts.PayeeTransfers = 0
+				facts.PasswordChangedAt = now.Add(-time.Hour)
+				facts.TransfersSincePasswordChange = 0
+			},
+			decision: Decision{
+				Outcome: Block,
+				Rules:   []string{RuleVelocity, RuleNewPayeeLargeAmount, RulePasswordChange},
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			facts := quiet
+			tc.facts(&facts)
+
+			require.Equal(t, tc.decision, rules.Evaluate(tc.amount, facts, now))
+		})
+	}
+}
+
+func TestEvaluateDisabledRules(t *testing.T) {
+	rules := Rules{}
+	require.False(t, rules.Enabled())
+
+	facts := Facts{PasswordChangedAt: time.Now()}
+	require.Equal(t, Decision{Outcome: Allow, Rules: []string{}}, rules.Evaluate(100000000, facts, time.Now()))
+}
@@ -0,0 +1,135 @@
+package scheduler
+
+import (
+	"context"
+	"log"
+	"time"
+
+	db "github.com/crackz/simple-bank/db/sqlc"
+	"github.com/crackz/simple-bank/interest"
+	"github.com/crackz/simple-bank/risk"
+)
+
+const (
+	defaultInterval  = time.Minute
+	defaultBatchSize = 50
+)
+
+// Scheduler periodically executes due scheduled transfers, releases expired
+// holds and accrues interest. Several replicas can run one each since due rows are claimed with
+// SKIP LOCKED.
+type Scheduler struct {
+	store db.Store
+	// riskRules screen scheduled transfers like the transfers of the API
+	riskRules risk.Rules
+	interval  time.Duration
+	batchSize int32
+}
+
+func NewScheduler(store db.Store, riskRules risk.Rules, interval time.Duration, batchSize int32) *Scheduler {
+	if interval <= 0 {
+		interval = defaultInterval
+	}
+	if batchSize <= 0 {
+		batchSize = defaultBatchSize
+	}
+
+	return &Scheduler{
+		store:     store,
+		riskRules: riskRules,
+		interval:  interval,
+		batchSize: batchSize,
+	}
+}
+
+// Start blocks running due work every interval until ctx is done.
+func (scheduler *Scheduler) Start(ctx context.Context) {
+	ticker := time.NewTicker(scheduler.interval)
+	defer ticker.Stop()
+
+	for {
+		scheduler.RunDue(ctx)
+		scheduler.ExpireHolds(ctx)
+		scheduler.AccrueInterest(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunDue executes batches of due scheduled transfers until none is left.
+func (scheduler *Scheduler) RunDue(ctx context.Context) {
+	for ctx.Err() == nil {
+		runs, err := scheduler.store.RunDueScheduledTransfers(ctx, db.RunDueScheduledTransfersParams{
+			Limit: scheduler.batchSize,
+			Rules: scheduler.riskRules,
+		})
+		if err != nil {
+			log.Println("Couldn't Run Scheduled Transfers : ", err)
+			return
+		}
+
+		for _, run := range runs {
+			if run.Status == db.ScheduledRunFailed {
+				log.Printf("Scheduled Transfer %d Failed : %s", run.ScheduledTransferID, run.Error.String)
+			}
+		}
+
+		if len(runs) < int(scheduler.batchSize) {
+			return
+		}
+	}
+}
+
+// ExpireHolds releases batches of expired holds until none is left.
+func (scheduler *Scheduler) ExpireHolds(ctx context.Context) {
+	for ctx.Err() == nil {
+		holds, err := scheduler.store.ExpireHolds(ctx, scheduler.batchSize)
+		if err != nil {
+			log.Println("Couldn't Expire Holds : ", err)
+			return
+		}
+
+		if len(holds) < int(scheduler.batchSize) {
+			return
+		}
+	}
+}
+
+// AccrueInterest accrues interest through the previous day for every account
+// on an interest plan that is behind, posting it at month ends. An account that
+// fails is logged and skipped until the next run.
+func (scheduler *Scheduler) AccrueInterest(ctx context.Context) {
+	through := interest.Date(time.Now()).AddDate(0, 0, -1)
+
+	var afterAccountID int64
+	for ctx.Err() == nil {
+		accountIDs, err := scheduler.store.ListDueAccountInterest(ctx, db.ListDueAccountInterestParams{
+			Through:        through,
+			AfterAccountID: afterAccountID,
+			BatchSize:      scheduler.batchSize,
+		})
+		if err != nil {
+			log.Println("Couldn't List Due Interest Accruals : ", err)
+			return
+		}
+
+		for _, accountID := range accountIDs {
+			_, err := scheduler.store.AccrueInterestTx(ctx, db.AccrueInterestTxParams{
+				AccountID: accountID,
+				Through:   through,
+			})
+			if err != nil {
+				log.Printf("Couldn't Accrue Interest Of Account %d : %s", accountID, err)
+			}
+			afterAccountID = accountID
+		}
+
+		if len(accountIDs) < int(scheduler.batchSize) {
+			return
+		}
+	}
+}
@@ -0,0 +1,176 @@
+package scheduler
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	mockdb "github.com/crackz/simple-bank/db/mock"
+	db "github.com/crackz/simple-bank/db/sqlc"
+	"github.com/crackz/simple-bank/interest"
+	"github.com/crackz/simple-bank/risk"
+	"github.com/golang/mock/gomock"
+)
+
+func TestRunDue(t *testing.T) {
+	rules := risk.Rules{NewPayeeAmount: 100, NewPayeeOutcome: risk.Review}
+	arg := db.RunDueScheduledTransfersParams{Limit: 2, Rules: rules}
+
+	testCases := []struct {
+		name       string
+		buildStubs func(store *mockdb.MockStore)
+	}{
+		{
+			name: "Drains Full Batches",
+			buildStubs: func(store *mockdb.MockStore) {
+				fullBatch := []db.ScheduledTransferRun{{ID: 1}, {ID: 2}}
+				gomock.InOrder(
+					store.EXPECT().RunDueScheduledTransfers(gomock.Any(), gomock.Eq(arg)).Times(2).Return(fullBatch, nil),
+					store.EXPECT().RunDueScheduledTransfers(gomock.Any(), gomock.Eq(arg)).Times(1).Return([]db.ScheduledTransferRun{{ID: 3}}, nil),
+				)
+			},
+		},
+		{
+			name: "Stops On Error",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					RunDueScheduledTransfers(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil, sql.ErrConnDone)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			NewScheduler(store, rules, time.Minute, 2).RunDue(context.Background())
+		})
+	}
+}
+
+func TestExpireHolds(t *testing.T) {
+	testCases := []struct {
+		name       string
+		buildStubs func(store *mockdb.MockStore)
+	}{
+		{
+			name: "Drains Full Batches",
+			buildStubs: func(store *mockdb.MockStore) {
+				gomock.InOrder(
+					store.EXPECT().ExpireHolds(gomock.Any(), gomock.Eq(int32(2))).Times(1).Return([]db.AccountHold{{ID: 1}, {ID: 2}}, nil),
+					store.EXPECT().ExpireHolds(gomock.Any(), gomock.Eq(int32(2))).Times(1).Return([]db.AccountHold{}, nil),
+				)
+			},
+		},
+		{
+			name: "Stops On Error",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ExpireHolds(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil, sql.ErrConnDone)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			NewScheduler(store, risk.Rules{}, time.Minute, 2).ExpireHolds(context.Background())
+		})
+	}
+}
+
+func TestAccrueInterest(t *testing.T) {
+	through := interest.Date(time.Now()).AddDate(0, 0, -1)
+
+	testCases := []struct {
+		name       string
+		buildStubs func(store *mockdb.MockStore)
+	}{
+		{
+			name: "Drains Full Batches",
+			buildStubs: func(store *mockdb.MockStore) {
+				gomock.InOrder(
+					store.EXPECT().
+						ListDueAccountInterest(gomock.Any(), gomock.Eq(db.ListDueAccountInterestParams{Through: through, AfterAccountID: 0, BatchSize: 2})).
+						Times(1).
+						Return([]int64{1, 2}, nil),
+					store.EXPECT().
+						ListDueAccountInterest(gomock.Any(), gomock.Eq(db.ListDueAccountInterestParams{Through: through, AfterAccountID: 2, BatchSize: 2})).
+						Times(1).
+						Return([]int64{3}, nil),
+				)
+				store.EXPECT().
+					AccrueInterestTx(gomock.Any(), gomock.Any()).
+					Times(3).
+					Return(db.AccrueInterestTxResult{}, nil)
+			},
+		},
+		{
+			name: "Skips Failed Accounts",
+			buildStubs: func(store *mockdb.MockStore) {
+				gomock.InOrder(
+					store.EXPECT().
+						ListDueAccountInterest(gomock.Any(), gomock.Eq(db.ListDueAccountInterestParams{Through: through, AfterAccountID: 0, BatchSize: 2})).
+						Times(1).
+						Return([]int64{1, 2}, nil),
+					store.EXPECT().
+						ListDueAccountInterest(gomock.Any(), gomock.Eq(db.ListDueAccountInterestParams{Through: through, AfterAccountID: 2, BatchSize: 2})).
+						Times(1).
+						Return([]int64{}, nil),
+				)
+				store.EXPECT().
+					AccrueInterestTx(gomock.Any(), gomock.Eq(db.AccrueInterestTxParams{AccountID: 1, Through: through})).
+					Times(1).
+					Return(db.AccrueInterestTxResult{}, db.ErrAccountFrozen)
+				store.EXPECT().
+					AccrueInterestTx(gomock.Any(), gomock.Eq(db.AccrueInterestTxParams{AccountID: 2, Through: through})).
+					Times(1).
+					Return(db.AccrueInterestTxResult{}, nil)
+			},
+		},
+		{
+			name: "Stops On Error",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListDueAccountInterest(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil, sql.ErrConnDone)
+				store.EXPECT().
+					AccrueInterestTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			NewScheduler(store, risk.Rules{}, time.Minute, 2).AccrueInterest(context.Background())
+		})
+	}
+}
@@ -0,0 +1,46 @@
+// Package screening screens transfers with the risk rules before they're made,
+// for the REST and gRPC servers to answer alike.
+package screening
+
+import (
+	"context"
+
+	db "github.com/crackz/simple-bank/db/sqlc"
+	"github.com/crackz/simple-bank/risk"
+)
+
+// Store is what transfers are screened against, the db store outside of
+// tests.
+type Store interface {
+	CheckTransferRisk(ctx context.Context, arg db.CheckTransferRiskParams) (risk.Outcome, db.RiskDecision, error)
+}
+
+// Result is what happened to a screened transfer, for each server to map to
+// its own response.
+type Result struct {
+	Outcome risk.Outcome
+	// RiskDecision is only set when the transfer was held for review or
+	// blocked, as it's recorded then. An allowed transfer carries its decision
+	// on and records it when it's made.
+	RiskDecision db.RiskDecision
+}
+
+// Allowed reports whether the transfer can be made.
+func (result Result) Allowed() bool {
+	return result.Outcome == risk.Allow
+}
+
+// Screen runs the rules on a transfer the owner is about to make, as described
+// by db.CheckTransferRisk. The store isn't asked at all when no rule is enabled.
+func Screen(ctx context.Context, store Store, arg db.CheckTransferRiskParams) (Result, error) {
+	if !arg.Rules.Enabled() {
+		return Result{Outcome: risk.Allow}, nil
+	}
+
+	outcome, riskDecision, err := store.CheckTransferRisk(ctx, arg)
+	if err != nil {
+		return Result{}, err
+	}
+
+	return Result{Outcome: outcome, RiskDecision: riskDecision}, nil
+}
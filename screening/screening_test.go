@@ -0,0 +1,61 @@
+package screening
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	db "github.com/crackz/simple-bank/db/sqlc"
+	"github.com/crackz/simple-bank/risk"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeStore struct {
+	outcome      risk.Outcome
+	riskDecision db.RiskDecision
+	err          error
+	calls        int
+}
+
+func (store *fakeStore) CheckTransferRisk(ctx context.Context, arg db.CheckTransferRiskParams) (risk.Outcome, db.RiskDecision, error) {
+	store.calls++
+	return store.outcome, store.riskDecision, store.err
+}
+
+func TestScreen(t *testing.T) {
+	rules := risk.Rules{NewPayeeAmount: 100, NewPayeeOutcome: risk.Review}
+	arg := db.CheckTransferRiskParams{
+		Rules:      rules,
+		Owner:      "alice",
+		Transfer:   &db.TransferTxParams{FromAccountID: 1, ToAccountID: 2, Amount: 500},
+		Reviewable: true,
+	}
+
+	store := &fakeStore{outcome: risk.Review, riskDecision: db.RiskDecision{ID: 7, Status: db.RiskPending}}
+	result, err := Screen(context.Background(), store, arg)
+	require.NoError(t, err)
+	require.False(t, result.Allowed())
+	require.Equal(t, risk.Review, result.Outcome)
+	require.Equal(t, int64(7), result.RiskDecision.ID)
+
+	store = &fakeStore{outcome: risk.Allow}
+	result, err = Screen(context.Background(), store, arg)
+	require.NoError(t, err)
+	require.True(t, result.Allowed())
+
+	store = &fakeStore{err: errors.New("connection refused")}
+	_, err = Screen(context.Background(), store, arg)
+	require.Error(t, err)
+}
+
+func TestScreenDisabled(t *testing.T) {
+	store := &fakeStore{outcome: risk.Block}
+
+	result, err := Screen(context.Background(), store, db.CheckTransferRiskParams{
+		Owner:    "alice",
+		Transfer: &db.TransferTxParams{FromAccountID: 1, ToAccountID: 2, Amount: 500},
+	})
+	require.NoError(t, err)
+	require.True(t, result.Allowed())
+	require.Zero(t, store.calls)
+}
@@ -7,11 +7,34 @@ import (
 )
 
 type Config struct {
-	DBDriver      string        `mapstructure:"DB_DRIVER"`
-	DBSource      string        `mapstructure:"DB_SOURCE"`
-	ServerAddress string        `mapstructure:"SERVER_ADDRESS"`
-	JwtSecret     string        `mapstructure:"JWT_SECRET"`
-	JwtDuration   time.Duration `mapstructure:"JWT_DURATION"`
+	DBDriver           string        `mapstructure:"DB_DRIVER"`
+	DBSource           string        `mapstructure:"DB_SOURCE"`
+	ServerAddress      string        `mapstructure:"SERVER_ADDRESS"`
+	GRPCServerAddress  string        `mapstructure:"GRPC_SERVER_ADDRESS"`
+	JwtSecret          string        `mapstructure:"JWT_SECRET"`
+	JwtDuration        time.Duration `mapstructure:"JWT_DURATION"`
+	SchedulerInterval  time.Duration `mapstructure:"SCHEDULER_INTERVAL"`
+	SchedulerBatchSize int32         `mapstructure:"SCHEDULER_BATCH_SIZE"`
+	FXRatesFile        string        `mapstructure:"FX_RATES_FILE"`
+	FXQuoteTTL         time.Duration `mapstructure:"FX_QUOTE_TTL"`
+	CurrencyCacheTTL   time.Duration `mapstructure:"CURRENCY_CACHE_TTL"`
+	HoldTTL            time.Duration `mapstructure:"HOLD_TTL"`
+	OutboxFile         string        `mapstructure:"OUTBOX_FILE"`
+	OutboxInterval     time.Duration `mapstructure:"OUTBOX_INTERVAL"`
+	WebhookInterval    time.Duration `mapstructure:"WEBHOOK_INTERVAL"`
+	WebhookMaxAttempts int32         `mapstructure:"WEBHOOK_MAX_ATTEMPTS"`
+
+	RiskVelocityCount         int64         `mapstructure:"RISK_VELOCITY_COUNT"`
+	RiskVelocityWindow        time.Duration `mapstructure:"RISK_VELOCITY_WINDOW"`
+	RiskVelocityOutcome       string        `mapstructure:"RISK_VELOCITY_OUTCOME"`
+	RiskNewPayeeAmount        int64         `mapstructure:"RISK_NEW_PAYEE_AMOUNT"`
+	RiskNewPayeeOutcome       string        `mapstructure:"RISK_NEW_PAYEE_OUTCOME"`
+	RiskRoundAmountUnit       int64         `mapstructure:"RISK_ROUND_AMOUNT_UNIT"`
+	RiskRoundAmountCount      int64         `mapstructure:"RISK_ROUND_AMOUNT_COUNT"`
+	RiskRoundAmountWindow     time.Duration `mapstructure:"RISK_ROUND_AMOUNT_WINDOW"`
+	RiskRoundAmountOutcome    string        `mapstructure:"RISK_ROUND_AMOUNT_OUTCOME"`
+	RiskPasswordChangeWindow  time.Duration `mapstructure:"RISK_PASSWORD_CHANGE_WINDOW"`
+	RiskPasswordChangeOutcome string        `mapstructure:"RISK_PASSWORD_CHANGE_OUTCOME"`
 }
 
 func LoadConfig(path string) (config *Config, err error) {
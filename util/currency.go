@@ -1,15 +1,9 @@
 package util
 
+// Currencies that are enabled by default. The full list is kept in the
+// currencies table.
 const (
-	EURO = "EURO"
-	USD  = "USD"
-	CAD  = "CAD"
+	EUR = "EUR"
+	USD = "USD"
+	CAD = "CAD"
 )
-
-func IsSupportedCurrency(currency string) bool {
-	switch currency {
-	case EURO, USD, CAD:
-		return true
-	}
-	return false
-}
@@ -40,7 +40,7 @@ func RandBalance() int64 {
 }
 
 func RandomCurrency() string {
-	currencies := []string{USD, EURO, CAD}
+	currencies := []string{USD, EUR, CAD}
 	n := len(currencies)
 	return currencies[rand.Intn(n)]
 }
@@ -0,0 +1,88 @@
+package webhook
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// staticResolver resolves every host to its addresses.
+type staticResolver []string
+
+func (r staticResolver) LookupIPAddr(ctx context.Context, host string) ([]net.IPAddr, error) {
+	addrs := make([]net.IPAddr, 0, len(r))
+	for _, ip := range r {
+		addrs = append(addrs, net.IPAddr{IP: net.ParseIP(ip)})
+	}
+
+	return addrs, nil
+}
+
+func TestCheckURL(t *testing.T) {
+	public := staticResolver{"93.184.216.34"}
+
+	testCases := []struct {
+		name     string
+		url      string
+		resolver Resolver
+		err      error
+	}{
+		{name: "PublicHost", url: "https://example.com/hooks", resolver: public},
+		{name: "PublicIP", url: "http://93.184.216.34/hooks", resolver: public},
+		{name: "InvalidScheme", url: "ftp://example.com/hooks", resolver: public, err: ErrInvalidURL},
+		{name: "NoHost", url: "https:///hooks", resolver: public, err: ErrInvalidURL},
+		{name: "Loopback", url: "http://127.0.0.1:8080/hooks", resolver: public, err: ErrForbiddenAddress},
+		{name: "LoopbackIPv6", url: "http://[::1]/hooks", resolver: public, err: ErrForbiddenAddress},
+		{name: "Private", url: "http://10.1.2.3/hooks", resolver: public, err: ErrForbiddenAddress},
+		{name: "LinkLocal", url: "http://169.254.169.254/latest/meta-data", resolver: public, err: ErrForbiddenAddress},
+		{name: "Unspecified", url: "http://0.0.0.0/hooks", resolver: public, err: ErrForbiddenAddress},
+		{name: "HostResolvingToPrivate", url: "https://example.com/hooks", resolver: staticResolver{"93.184.216.34", "192.168.0.1"}, err: ErrForbiddenAddress},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			err := CheckURL(context.Background(), tc.resolver, tc.url)
+			if tc.err == nil {
+				require.NoError(t, err)
+			} else {
+				require.ErrorIs(t, err, tc.err)
+			}
+		})
+	}
+}
+
+func TestNewClient(t *testing.T) {
+	called := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	}))
+	defer server.Close()
+
+	// the test server listens on a loopback address
+	_, err := NewClient(time.Second).Post(server.URL, "application/json", nil)
+	require.ErrorIs(t, err, ErrForbiddenAddress)
+	require.False(t, called)
+}
+
+func TestNewClientRedirect(t *testing.T) {
+	client := NewClient(time.Second)
+	// dial the loopback test server anyway to see how redirects are handled
+	client.Transport = http.DefaultTransport
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "http://169.254.169.254/latest/meta-data", http.StatusFound)
+	}))
+	defer server.Close()
+
+	res, err := client.Post(server.URL, "application/json", nil)
+	require.NoError(t, err)
+	defer res.Body.Close()
+	require.Equal(t, http.StatusFound, res.StatusCode)
+}
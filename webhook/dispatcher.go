@@ -0,0 +1,72 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+
+	db "github.com/crackz/simple-bank/db/sqlc"
+	"github.com/crackz/simple-bank/outbox"
+)
+
+// Event types endpoints can subscribe to.
+const (
+	EventTransferReceived = "transfer.received"
+	EventTransferSent     = "transfer.sent"
+	EventAccountCreated   = "account.created"
+)
+
+// Dispatcher is an outbox publisher that turns domain events into webhook
+// deliveries for the endpoints of the users they concern. A transfer is
+// delivered as transfer.sent to the owner of the debited account and as
+// transfer.received to the owner of the credited one. Enqueueing the same
+// event twice is a no-op, so redelivery by the relay is harmless.
+type Dispatcher struct {
+	store db.Store
+}
+
+func NewDispatcher(store db.Store) *Dispatcher {
+	return &Dispatcher{store: store}
+}
+
+func (dispatcher *Dispatcher) Publish(ctx context.Context, event outbox.Event) error {
+	switch event.Type {
+	case db.EventTransferCreated:
+		var transfer db.TransferCreatedEvent
+		if err := json.Unmarshal(event.Payload, &transfer); err != nil {
+			return err
+		}
+
+		fromAccount, err := dispatcher.store.GetAccount(ctx, transfer.FromAccountID)
+		if err != nil {
+			return err
+		}
+		toAccount, err := dispatcher.store.GetAccount(ctx, transfer.ToAccountID)
+		if err != nil {
+			return err
+		}
+
+		if err := dispatcher.enqueue(ctx, event, fromAccount.Owner, EventTransferSent); err != nil {
+			return err
+		}
+		return dispatcher.enqueue(ctx, event, toAccount.Owner, EventTransferReceived)
+	case db.EventAccountCreated:
+		var account db.AccountCreatedEvent
+		if err := json.Unmarshal(event.Payload, &account); err != nil {
+			return err
+		}
+
+		return dispatcher.enqueue(ctx, event, account.Owner, EventAccountCreated)
+	}
+
+	return nil
+}
+
+func (dispatcher *Dispatcher) enqueue(ctx context.Context, event outbox.Event, owner string, eventType string) error {
+	_, err := dispatcher.store.EnqueueWebhookDeliveries(ctx, db.EnqueueWebhookDeliveriesParams{
+		EventID:   event.ID,
+		EventType: eventType,
+		Payload:   event.Payload,
+		Owner:     owner,
+	})
+	return err
+}
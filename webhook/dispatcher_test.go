@@ -0,0 +1,89 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	mockdb "github.com/crackz/simple-bank/db/mock"
+	db "github.com/crackz/simple-bank/db/sqlc"
+	"github.com/crackz/simple-bank/outbox"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDispatcherPublish(t *testing.T) {
+	fromAccount := db.Account{ID: 1, Owner: "alice"}
+	toAccount := db.Account{ID: 2, Owner: "bob"}
+
+	transferPayload, err := json.Marshal(db.TransferCreatedEvent{TransferID: 5, FromAccountID: fromAccount.ID, ToAccountID: toAccount.ID, Amount: 10})
+	require.NoError(t, err)
+	accountPayload, err := json.Marshal(db.AccountCreatedEvent{AccountID: toAccount.ID, Owner: toAccount.Owner})
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name       string
+		event      outbox.Event
+		buildStubs func(store *mockdb.MockStore, event outbox.Event)
+	}{
+		{
+			name:  "Transfer",
+			event: outbox.Event{ID: 10, Type: db.EventTransferCreated, Payload: transferPayload},
+			buildStubs: func(store *mockdb.MockStore, event outbox.Event) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(toAccount.ID)).Times(1).Return(toAccount, nil)
+				store.EXPECT().
+					EnqueueWebhookDeliveries(gomock.Any(), gomock.Eq(db.EnqueueWebhookDeliveriesParams{
+						EventID:   event.ID,
+						EventType: EventTransferSent,
+						Payload:   event.Payload,
+						Owner:     fromAccount.Owner,
+					})).
+					Times(1)
+				store.EXPECT().
+					EnqueueWebhookDeliveries(gomock.Any(), gomock.Eq(db.EnqueueWebhookDeliveriesParams{
+						EventID:   event.ID,
+						EventType: EventTransferReceived,
+						Payload:   event.Payload,
+						Owner:     toAccount.Owner,
+					})).
+					Times(1)
+			},
+		},
+		{
+			name:  "Account",
+			event: outbox.Event{ID: 11, Type: db.EventAccountCreated, Payload: accountPayload},
+			buildStubs: func(store *mockdb.MockStore, event outbox.Event) {
+				store.EXPECT().
+					EnqueueWebhookDeliveries(gomock.Any(), gomock.Eq(db.EnqueueWebhookDeliveriesParams{
+						EventID:   event.ID,
+						EventType: EventAccountCreated,
+						Payload:   event.Payload,
+						Owner:     toAccount.Owner,
+					})).
+					Times(1)
+			},
+		},
+		{
+			name:  "Not Delivered",
+			event: outbox.Event{ID: 12, Type: db.EventUserCreated, Payload: json.RawMessage(`{}`)},
+			buildStubs: func(store *mockdb.MockStore, event outbox.Event) {
+				store.EXPECT().EnqueueWebhookDeliveries(gomock.Any(), gomock.Any()).Times(0)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store, tc.event)
+
+			require.NoError(t, NewDispatcher(store).Publish(context.Background(), tc.event))
+		})
+	}
+}
@@ -0,0 +1,84 @@
+package webhook
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func signedHeader(secret string, timestamp time.Time, body []byte) http.Header {
+	header := http.Header{}
+	header.Set(TimestampHeader, strconv.FormatInt(timestamp.Unix(), 10))
+	header.Set(SignatureHeader, Sign(secret, timestamp, body))
+	return header
+}
+
+func TestNewSecret(t *testing.T) {
+	secret1, err := NewSecret()
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(secret1, secretPrefix))
+
+	secret2, err := NewSecret()
+	require.NoError(t, err)
+	require.NotEqual(t, secret1, secret2)
+}
+
+func TestVerify(t *testing.T) {
+	secret := "whsec_test"
+	body := []byte(`{"id":1}`)
+	now := time.Now()
+
+	testCases := []struct {
+		name   string
+		header http.Header
+		body   []byte
+		err    error
+	}{
+		{
+			name:   "Valid",
+			header: signedHeader(secret, now, body),
+			body:   body,
+		},
+		{
+			name:   "Tampered Body",
+			header: signedHeader(secret, now, body),
+			body:   []byte(`{"id":2}`),
+			err:    ErrInvalidSignature,
+		},
+		{
+			name:   "Wrong Secret",
+			header: signedHeader("whsec_other", now, body),
+			body:   body,
+			err:    ErrInvalidSignature,
+		},
+		{
+			name:   "Replayed",
+			header: signedHeader(secret, now.Add(-10*time.Minute), body),
+			body:   body,
+			err:    ErrInvalidTimestamp,
+		},
+		{
+			name:   "Missing Timestamp",
+			header: http.Header{SignatureHeader: []string{Sign(secret, now, body)}},
+			body:   body,
+			err:    ErrInvalidTimestamp,
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			err := Verify(secret, tc.header, tc.body, 5*time.Minute)
+			if tc.err == nil {
+				require.NoError(t, err)
+			} else {
+				require.ErrorIs(t, err, tc.err)
+			}
+		})
+	}
+}
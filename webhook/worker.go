@@ -0,0 +1,219 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	db "github.com/crackz/simple-bank/db/sqlc"
+)
+
+const (
+	defaultInterval    = 5 * time.Second
+	defaultBatchSize   = 50
+	defaultMaxAttempts = 8
+	defaultTimeout     = 10 * time.Second
+
+	baseBackoff = 30 * time.Second
+	maxBackoff  = 6 * time.Hour
+)
+
+var ErrEndpointDisabled = errors.New("webhook endpoint is disabled")
+
+// Body is the JSON body posted to endpoints.
+type Body struct {
+	ID        int64           `json:"id"`
+	EventID   int64           `json:"eventID"`
+	Type      string          `json:"type"`
+	CreatedAt time.Time       `json:"createdAt"`
+	Data      json.RawMessage `json:"data"`
+}
+
+// Worker posts pending deliveries to their endpoints. A delivery that fails is
+// retried with exponential backoff and dead-lettered after maxAttempts. Due
+// deliveries are leased rather than kept locked while they are sent, so
+// several replicas can run a worker each and a delivery whose worker dies is
+// picked up again once its lease runs out.
+type Worker struct {
+	store       db.Store
+	client      *http.Client
+	interval    time.Duration
+	batchSize   int32
+	maxAttempts int32
+}
+
+// NewWorker returns a worker sending deliveries with client, the client of
+// NewClient when nil.
+func NewWorker(store db.Store, client *http.Client, interval time.Duration, batchSize int32, maxAttempts int32) *Worker {
+	if client == nil {
+		client = NewClient(defaultTimeout)
+	}
+	if interval <= 0 {
+		interval = defaultInterval
+	}
+	if batchSize <= 0 {
+		batchSize = defaultBatchSize
+	}
+	if maxAttempts <= 0 {
+		maxAttempts = defaultMaxAttempts
+	}
+
+	return &Worker{
+		store:       store,
+		client:      client,
+		interval:    interval,
+		batchSize:   batchSize,
+		maxAttempts: maxAttempts,
+	}
+}
+
+// Start blocks delivering due webhooks every interval until ctx is done.
+func (worker *Worker) Start(ctx context.Context) {
+	ticker := time.NewTicker(worker.interval)
+	defer ticker.Stop()
+
+	for {
+		worker.DeliverDue(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// DeliverDue sends batches of due deliveries until none is left.
+func (worker *Worker) DeliverDue(ctx context.Context) {
+	for ctx.Err() == nil {
+		deliveries, err := worker.store.ClaimDueWebhookDeliveries(ctx, db.ClaimDueWebhookDeliveriesParams{
+			LeaseSeconds: worker.leaseSeconds(),
+			BatchSize:    worker.batchSize,
+		})
+		if err != nil {
+			log.Println("Couldn't Claim Webhook Deliveries : ", err)
+			return
+		}
+
+		for _, delivery := range deliveries {
+			if err := worker.Deliver(ctx, delivery); err != nil {
+				log.Printf("Couldn't Record Webhook Delivery %d : %s", delivery.ID, err)
+			}
+		}
+
+		if len(deliveries) < int(worker.batchSize) {
+			return
+		}
+	}
+}
+
+// leaseSeconds outlasts a whole batch of requests timing out, so a delivery
+// isn't claimed again while it is still being sent.
+func (worker *Worker) leaseSeconds() int32 {
+	timeout := worker.client.Timeout
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+
+	return int32((time.Duration(worker.batchSize)*timeout + time.Minute) / time.Second)
+}
+
+// Deliver makes one attempt at sending the delivery and records its outcome.
+func (worker *Worker) Deliver(ctx context.Context, delivery db.WebhookDelivery) error {
+	arg := db.RecordWebhookAttemptTxParams{
+		Delivery:      delivery,
+		Status:        db.WebhookDeliveryDelivered,
+		NextAttemptAt: delivery.NextAttemptAt,
+	}
+
+	endpoint, err := worker.store.GetWebhookEndpoint(ctx, delivery.WebhookID)
+	if err != nil {
+		// the lease runs out and the delivery is retried
+		return err
+	}
+
+	var attemptErr error
+	if endpoint.Active {
+		arg.StatusCode, attemptErr = worker.send(ctx, endpoint, delivery)
+	} else {
+		attemptErr = ErrEndpointDisabled
+	}
+
+	if attemptErr != nil {
+		arg.Error = sql.NullString{String: attemptErr.Error(), Valid: true}
+
+		switch {
+		case !endpoint.Active || delivery.Attempts+1 >= worker.maxAttempts:
+			arg.Status = db.WebhookDeliveryDead
+		default:
+			arg.Status = db.WebhookDeliveryPending
+			arg.NextAttemptAt = time.Now().Add(backoff(delivery.Attempts + 1))
+		}
+	}
+
+	_, err = worker.store.RecordWebhookAttemptTx(ctx, arg)
+	return err
+}
+
+func (worker *Worker) send(ctx context.Context, endpoint db.WebhookEndpoint, delivery db.WebhookDelivery) (sql.NullInt32, error) {
+	var statusCode sql.NullInt32
+
+	body, err := json.Marshal(Body{
+		ID:        delivery.ID,
+		EventID:   delivery.EventID,
+		Type:      delivery.EventType,
+		CreatedAt: delivery.CreatedAt,
+		Data:      delivery.Payload,
+	})
+	if err != nil {
+		return statusCode, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint.Url, bytes.NewReader(body))
+	if err != nil {
+		return statusCode, err
+	}
+
+	now := time.Now()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(IDHeader, strconv.FormatInt(delivery.ID, 10))
+	req.Header.Set(TimestampHeader, strconv.FormatInt(now.Unix(), 10))
+	req.Header.Set(SignatureHeader, Sign(endpoint.Secret, now, body))
+
+	res, err := worker.client.Do(req)
+	if err != nil {
+		return statusCode, err
+	}
+	defer res.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(res.Body, 64<<10))
+
+	statusCode = sql.NullInt32{Int32: int32(res.StatusCode), Valid: true}
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return statusCode, fmt.Errorf("endpoint responded with %s", res.Status)
+	}
+
+	return statusCode, nil
+}
+
+// backoff returns how long to wait after the given number of failed attempts,
+// doubling from baseBackoff up to maxBackoff.
+func backoff(attempts int32) time.Duration {
+	delay := baseBackoff
+	for i := int32(1); i < attempts && delay < maxBackoff; i++ {
+		delay *= 2
+	}
+
+	if delay > maxBackoff {
+		delay = maxBackoff
+	}
+
+	return delay
+}
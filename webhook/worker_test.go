@@ -0,0 +1,175 @@
+package webhook
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	mockdb "github.com/crackz/simple-bank/db/mock"
+	db "github.com/crackz/simple-bank/db/sqlc"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+// newReceiver starts an endpoint that checks the signature of every webhook it
+// receives and answers with status.
+func newReceiver(t *testing.T, secret string, status int, received chan<- Body) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		require.NoError(t, Verify(secret, r.Header, data, time.Minute))
+		require.Equal(t, "application/json", r.Header.Get("Content-Type"))
+
+		var body Body
+		require.NoError(t, json.Unmarshal(data, &body))
+		require.Equal(t, strconv.FormatInt(body.ID, 10), r.Header.Get(IDHeader))
+		received <- body
+
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func TestDeliver(t *testing.T) {
+	secret := "whsec_test"
+	delivery := db.WebhookDelivery{
+		ID:        7,
+		WebhookID: 3,
+		EventID:   42,
+		EventType: EventTransferReceived,
+		Payload:   json.RawMessage(`{"transferID":1}`),
+		Status:    db.WebhookDeliveryPending,
+		CreatedAt: time.Now().UTC().Truncate(time.Second),
+	}
+
+	testCases := []struct {
+		name        string
+		status      int
+		attempts    int32
+		maxAttempts int32
+		active      bool
+		check       func(t *testing.T, arg db.RecordWebhookAttemptTxParams)
+	}{
+		{
+			name:   "Delivered",
+			status: http.StatusNoContent,
+			active: true,
+			check: func(t *testing.T, arg db.RecordWebhookAttemptTxParams) {
+				require.Equal(t, db.WebhookDeliveryDelivered, arg.Status)
+				require.Equal(t, sql.NullInt32{Int32: http.StatusNoContent, Valid: true}, arg.StatusCode)
+				require.False(t, arg.Error.Valid)
+			},
+		},
+		{
+			name:        "Retried With Backoff",
+			status:      http.StatusInternalServerError,
+			attempts:    2,
+			maxAttempts: 8,
+			active:      true,
+			check: func(t *testing.T, arg db.RecordWebhookAttemptTxParams) {
+				require.Equal(t, db.WebhookDeliveryPending, arg.Status)
+				require.Equal(t, sql.NullInt32{Int32: http.StatusInternalServerError, Valid: true}, arg.StatusCode)
+				require.True(t, arg.Error.Valid)
+				require.WithinDuration(t, time.Now().Add(4*baseBackoff), arg.NextAttemptAt, time.Second)
+			},
+		},
+		{
+			name:        "Dead Lettered",
+			status:      http.StatusBadGateway,
+			attempts:    2,
+			maxAttempts: 3,
+			active:      true,
+			check: func(t *testing.T, arg db.RecordWebhookAttemptTxParams) {
+				require.Equal(t, db.WebhookDeliveryDead, arg.Status)
+				require.True(t, arg.Error.Valid)
+			},
+		},
+		{
+			name:   "Disabled Endpoint",
+			active: false,
+			check: func(t *testing.T, arg db.RecordWebhookAttemptTxParams) {
+				require.Equal(t, db.WebhookDeliveryDead, arg.Status)
+				require.False(t, arg.StatusCode.Valid)
+				require.Equal(t, ErrEndpointDisabled.Error(), arg.Error.String)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			received := make(chan Body, 1)
+			receiver := newReceiver(t, secret, tc.status, received)
+
+			delivery := delivery
+			delivery.Attempts = tc.attempts
+
+			store := mockdb.NewMockStore(ctrl)
+			store.EXPECT().
+				GetWebhookEndpoint(gomock.Any(), gomock.Eq(delivery.WebhookID)).
+				Times(1).
+				Return(db.WebhookEndpoint{ID: delivery.WebhookID, Url: receiver.URL, Secret: secret, Active: tc.active}, nil)
+			store.EXPECT().
+				RecordWebhookAttemptTx(gomock.Any(), gomock.Any()).
+				Times(1).
+				DoAndReturn(func(_ context.Context, arg db.RecordWebhookAttemptTxParams) (db.WebhookDelivery, error) {
+					require.Equal(t, delivery, arg.Delivery)
+					tc.check(t, arg)
+					return delivery, nil
+				})
+
+			worker := NewWorker(store, receiver.Client(), time.Second, 10, tc.maxAttempts)
+			require.NoError(t, worker.Deliver(context.Background(), delivery))
+
+			if tc.active {
+				body := <-received
+				require.Equal(t, delivery.ID, body.ID)
+				require.Equal(t, delivery.EventID, body.EventID)
+				require.Equal(t, delivery.EventType, body.Type)
+				require.True(t, delivery.CreatedAt.Equal(body.CreatedAt))
+				require.JSONEq(t, string(delivery.Payload), string(body.Data))
+			} else {
+				require.Empty(t, received)
+			}
+		})
+	}
+}
+
+func TestDeliverDue(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	received := make(chan Body, 3)
+	receiver := newReceiver(t, "whsec_test", http.StatusOK, received)
+	endpoint := db.WebhookEndpoint{ID: 1, Url: receiver.URL, Secret: "whsec_test", Active: true}
+
+	store := mockdb.NewMockStore(ctrl)
+	gomock.InOrder(
+		store.EXPECT().ClaimDueWebhookDeliveries(gomock.Any(), gomock.Any()).Times(1).Return([]db.WebhookDelivery{{ID: 1, WebhookID: 1}, {ID: 2, WebhookID: 1}}, nil),
+		store.EXPECT().ClaimDueWebhookDeliveries(gomock.Any(), gomock.Any()).Times(1).Return([]db.WebhookDelivery{{ID: 3, WebhookID: 1}}, nil),
+	)
+	store.EXPECT().GetWebhookEndpoint(gomock.Any(), gomock.Eq(endpoint.ID)).Times(3).Return(endpoint, nil)
+	store.EXPECT().RecordWebhookAttemptTx(gomock.Any(), gomock.Any()).Times(3).Return(db.WebhookDelivery{}, nil)
+
+	NewWorker(store, receiver.Client(), time.Second, 2, 0).DeliverDue(context.Background())
+	require.Len(t, received, 3)
+}
+
+func TestBackoff(t *testing.T) {
+	require.Equal(t, baseBackoff, backoff(1))
+	require.Equal(t, 2*baseBackoff, backoff(2))
+	require.Equal(t, 8*baseBackoff, backoff(4))
+	require.Equal(t, maxBackoff, backoff(20))
+}